@@ -0,0 +1,424 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/marcboeker/go-duckdb"
+)
+
+// analyticsTextTable holds the searchable text of every record by position
+const analyticsTextTable = "record_text"
+
+// AnalyticsStatus describes analytics mode for the loaded file
+type AnalyticsStatus struct {
+	Enabled bool   `json:"enabled"` // plain searches run in DuckDB for the loaded records
+	SQL     bool   `json:"sql"`     // RunSQL runs in DuckDB; false when the records table could not be created
+	Records int    `json:"records"`
+	LoadMs  int64  `json:"loadMs"`
+	Version string `json:"version"` // data version the records were loaded for
+}
+
+// analyticsStore is an embedded DuckDB database holding the loaded records in two
+// tables: record_text with each record's searchable text by position, and records
+// with the fields as columns, inferred by read_json_auto, for RunSQL
+type analyticsStore struct {
+	db       *sql.DB
+	cache    *RecordCache // the records loaded, see activeAnalytics
+	version  uint64
+	records  int
+	typed    bool // the records table was created
+	duration time.Duration
+}
+
+// searchText is the text a plain search matches a record against: its raw JSON and
+// the decoded text of its values, see recordMatchesWith, separated by NULs so no
+// match of a query without one spans two of them
+func searchText(record JSONRecord) string {
+	var sb strings.Builder
+	sb.WriteString(record.RawJSON)
+	recordText(record, func(text string) bool {
+		sb.WriteByte(0)
+		sb.WriteString(text)
+		return false
+	})
+	// DuckDB only stores valid UTF-8
+	return strings.ToValidUTF8(sb.String(), "\uFFFD")
+}
+
+// loadAnalyticsStore bulk-loads the cached records into a new in-memory database
+func loadAnalyticsStore(cache *RecordCache) (*analyticsStore, error) {
+	start := time.Now()
+	db, err := sql.Open("duckdb", "")
+	if err != nil {
+		return nil, err
+	}
+
+	store := &analyticsStore{db: db, cache: cache, version: cache.version, records: cache.totalCount}
+	if err := store.loadText(cache); err != nil {
+		db.Close()
+		return nil, err
+	}
+	store.typed = store.loadTyped(cache) == nil
+
+	// Queries only read the loaded tables, never files on disk
+	if _, err := db.Exec("SET GLOBAL enable_external_access = false; SET GLOBAL lock_configuration = true"); err != nil {
+		db.Close()
+		return nil, err
+	}
+	store.duration = time.Since(start)
+	return store, nil
+}
+
+// loadText fills record_text through the DuckDB appender, with each record's search
+// text as is and case-folded
+func (s *analyticsStore) loadText(cache *RecordCache) error {
+	create := fmt.Sprintf("CREATE TABLE %s (position INTEGER, text VARCHAR, folded VARCHAR)", analyticsTextTable)
+	if _, err := s.db.Exec(create); err != nil {
+		return err
+	}
+
+	conn, err := s.db.Conn(context.Background())
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return conn.Raw(func(driverConn interface{}) error {
+		appender, err := duckdb.NewAppenderFromConn(driverConn.(driver.Conn), "", analyticsTextTable)
+		if err != nil {
+			return err
+		}
+
+		position := int32(0)
+		readErr := cache.forEach(func(record JSONRecord) bool {
+			text := searchText(record)
+			err = appender.AppendRow(position, text, foldCase(text))
+			position++
+			return err == nil
+		})
+		closeErr := appender.Close()
+		return errors.Join(readErr, err, closeErr)
+	})
+}
+
+// loadTyped creates the records table from the records' fields, virtual fields
+// included, letting DuckDB infer column types from a temporary JSONL copy. As with
+// the built-in SQL, records that are not objects have no fields.
+func (s *analyticsStore) loadTyped(cache *RecordCache) error {
+	file, err := os.CreateTemp("", "jsonl-viewer-analytics-*.jsonl")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	writer := bufio.NewWriter(file)
+	longest := 0
+	var encodeErr error
+	readErr := cache.forEach(func(record JSONRecord) bool {
+		line := []byte("{}")
+		if record.Content != nil {
+			if line, encodeErr = json.Marshal(record.Content); encodeErr != nil {
+				return false
+			}
+		}
+		longest = max(longest, len(line)+1)
+		writer.Write(line)
+		writer.WriteByte('\n')
+		return true
+	})
+	if err := errors.Join(readErr, encodeErr, writer.Flush(), file.Close()); err != nil {
+		return err
+	}
+
+	path := strings.ReplaceAll(file.Name(), "'", "''")
+	_, err = s.db.Exec(fmt.Sprintf(
+		"CREATE TABLE %s AS SELECT * FROM read_json_auto('%s', format = 'newline_delimited', sample_size = -1, maximum_object_size = %d)",
+		sqlTable, path, max(longest, defaultMaxLineSize)))
+	return err
+}
+
+// search returns the number of records containing query and the positions, in file
+// order, of up to limit of them from offset; a negative limit returns them all
+func (s *analyticsStore) search(ctx context.Context, query string, caseSensitive bool, offset, limit int) (int, []int, error) {
+	column := "text"
+	if !caseSensitive {
+		column, query = "folded", foldCase(query)
+	}
+	where := fmt.Sprintf("FROM %s WHERE contains(%s, ?)", analyticsTextTable, column)
+
+	selectPositions := "SELECT position " + where + " ORDER BY position"
+	args := []interface{}{query}
+	if limit >= 0 {
+		selectPositions += " LIMIT ? OFFSET ?"
+		args = append(args, limit, offset)
+	}
+	rows, err := s.db.QueryContext(ctx, selectPositions, args...)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer rows.Close()
+
+	positions := []int{}
+	for rows.Next() {
+		var position int
+		if err := rows.Scan(&position); err != nil {
+			return 0, nil, err
+		}
+		positions = append(positions, position)
+	}
+	if err := rows.Err(); err != nil {
+		return 0, nil, err
+	}
+	if limit < 0 {
+		return len(positions), positions, nil
+	}
+
+	var total int
+	if err := s.db.QueryRowContext(ctx, "SELECT count(*) "+where, query).Scan(&total); err != nil {
+		return 0, nil, err
+	}
+	return total, positions, nil
+}
+
+// checkSelect rejects anything but a single SELECT statement, as parsed by DuckDB
+func (s *analyticsStore) checkSelect(query string) error {
+	var serialized string
+	if err := s.db.QueryRow("SELECT json_serialize_sql(?::VARCHAR)", query).Scan(&serialized); err != nil {
+		return err
+	}
+	var parsed struct {
+		Error        bool              `json:"error"`
+		ErrorType    string            `json:"error_type"`
+		ErrorMessage string            `json:"error_message"`
+		Statements   []json.RawMessage `json:"statements"`
+	}
+	if err := json.Unmarshal([]byte(serialized), &parsed); err != nil {
+		return err
+	}
+	switch {
+	case parsed.Error && parsed.ErrorType == "parser":
+		return errors.New(parsed.ErrorMessage)
+	case parsed.Error:
+		return errors.New("only SELECT statements are supported")
+	case len(parsed.Statements) != 1:
+		return errors.New("expected a single SELECT statement")
+	}
+	return nil
+}
+
+// runSQL runs a SELECT statement in DuckDB
+func (s *analyticsStore) runSQL(query string) (*SQLResult, error) {
+	start := time.Now()
+	if err := s.checkSelect(query); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	result := &SQLResult{Columns: columns, Rows: [][]interface{}{}, Scanned: s.records}
+	for rows.Next() {
+		if len(result.Rows) == maxSQLRows {
+			result.Truncated = true
+			break
+		}
+		values := make([]interface{}, len(columns))
+		pointers := make([]interface{}, len(columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			return nil, err
+		}
+		for i, value := range values {
+			values[i] = analyticsValue(value)
+		}
+		result.Rows = append(result.Rows, values)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	result.DurationMs = time.Since(start).Milliseconds()
+	return result, nil
+}
+
+// analyticsValue converts a DuckDB value into one the frontend receives as JSON:
+// decimals become numbers, blobs text and MAP keys strings
+func analyticsValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case duckdb.Decimal:
+		return v.Float64()
+	case []byte:
+		return string(v)
+	case duckdb.Map:
+		converted := make(map[string]interface{}, len(v))
+		for key, nested := range v {
+			converted[fmt.Sprintf("%v", key)] = analyticsValue(nested)
+		}
+		return converted
+	case map[string]interface{}:
+		for key, nested := range v {
+			v[key] = analyticsValue(nested)
+		}
+	case []interface{}:
+		for i, nested := range v {
+			v[i] = analyticsValue(nested)
+		}
+	}
+	return value
+}
+
+func (s *analyticsStore) close() {
+	s.db.Close()
+}
+
+// activeAnalytics returns the analytics store when analytics mode is enabled and
+// holds the current records; the caller holds stateMu
+func (a *App) activeAnalytics() *analyticsStore {
+	if a.analytics == nil || a.cache == nil || a.analytics.cache != a.cache || a.analytics.version != a.cache.version {
+		return nil
+	}
+	return a.analytics
+}
+
+// analyticsSearchable reports whether a search can run in DuckDB with the same
+// results as the in-memory matcher: a plain substring search over whole records
+func analyticsSearchable(options SearchOptions) bool {
+	return !options.UseLucene && !options.UseJSONPath && !options.IgnoreDiacritics &&
+		!options.WholeWord && len(searchFields(options.SelectedFields)) == 0 &&
+		!strings.ContainsRune(options.Query, 0)
+}
+
+// searchAnalytics runs a plain search in DuckDB and reads the page's records from
+// the cache. Sorted searches read every match and order them as in memory, so
+// values compare with the same typed comparison. The caller holds stateMu.
+func (a *App) searchAnalytics(store *analyticsStore, options SearchOptions, sortKeys []SortKey) (*SearchResult, error) {
+	scan, done := a.beginSearch(options.Token, a.cache.totalCount)
+	defer done()
+
+	offset, limit := options.Offset, options.Limit
+	if sortKeys != nil {
+		offset, limit = 0, -1
+	}
+	totalMatches, positions, err := store.search(scan.ctx, options.Query, options.CaseSensitive, offset, limit)
+	if err != nil {
+		if scan.ctx.Err() != nil {
+			return nil, &JSONLError{
+				Message: "Search was cancelled",
+				Err:     ErrSearchCancelled,
+			}
+		}
+		return nil, &JSONLError{
+			Message: fmt.Sprintf("Analytics search failed: %v", err),
+			Err:     err,
+		}
+	}
+
+	records, err := a.cache.at(positions)
+	if err != nil {
+		return nil, &JSONLError{
+			Message: "Failed to read records",
+			Err:     err,
+		}
+	}
+	if sortKeys != nil {
+		records = a.sortRecords(records, sortKeys)
+		start := min(options.Offset, len(records))
+		records = records[start:min(start+options.Limit, len(records))]
+	}
+
+	a.audit(AuditEntry{Action: AuditSearchRun, Path: a.auditPath(), Query: options.Query, Records: totalMatches})
+	return &SearchResult{
+		Records:      records,
+		Offset:       options.Offset,
+		Limit:        options.Limit,
+		Total:        a.cache.totalCount,
+		TotalMatches: totalMatches,
+		HasMore:      options.Offset+len(records) < totalMatches,
+		Query:        options.Query,
+		Version:      a.cache.versionToken(),
+	}, nil
+}
+
+// EnableAnalyticsMode bulk-loads the records into an embedded DuckDB database, for
+// very large files. While enabled, plain searches and RunSQL run as SQL in DuckDB
+// and return the same result shapes, RunSQL with DuckDB's full SELECT dialect;
+// other searches keep using the in-memory matcher. Once the records change, e.g.
+// on reload, analytics mode is off until it is enabled again.
+func (a *App) EnableAnalyticsMode() (*AnalyticsStatus, error) {
+	// Loading only reads the records, so other reads go on meanwhile
+	a.stateMu.RLock()
+	if a.currentFile == nil || a.cache == nil {
+		a.stateMu.RUnlock()
+		return nil, &JSONLError{
+			Message: "No file currently loaded",
+			Err:     ErrNoFileLoaded,
+		}
+	}
+	store, err := loadAnalyticsStore(a.cache)
+	a.stateMu.RUnlock()
+	if err != nil {
+		return nil, &JSONLError{
+			Message: fmt.Sprintf("Failed to load records into DuckDB: %v", err),
+			Err:     err,
+		}
+	}
+
+	a.stateMu.Lock()
+	defer a.stateMu.Unlock()
+	a.closeAnalytics()
+	a.analytics = store
+	status := a.analyticsStatus()
+	return &status, nil
+}
+
+// DisableAnalyticsMode drops the DuckDB database and returns to in-memory search
+func (a *App) DisableAnalyticsMode() {
+	a.stateMu.Lock()
+	defer a.stateMu.Unlock()
+	a.closeAnalytics()
+}
+
+// closeAnalytics implements DisableAnalyticsMode; the caller holds stateMu
+func (a *App) closeAnalytics() {
+	if a.analytics != nil {
+		a.analytics.close()
+		a.analytics = nil
+	}
+}
+
+// GetAnalyticsStatus reports whether analytics mode is enabled for the loaded records
+func (a *App) GetAnalyticsStatus() AnalyticsStatus {
+	a.stateMu.RLock()
+	defer a.stateMu.RUnlock()
+	return a.analyticsStatus()
+}
+
+// analyticsStatus implements GetAnalyticsStatus; the caller holds stateMu
+func (a *App) analyticsStatus() AnalyticsStatus {
+	var status AnalyticsStatus
+	if store := a.activeAnalytics(); store != nil {
+		status.Enabled = true
+		status.SQL = store.typed
+		status.Records = store.records
+		status.LoadMs = store.duration.Milliseconds()
+		status.Version = fmt.Sprintf("v%d", store.version)
+	}
+	return status
+}
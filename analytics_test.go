@@ -0,0 +1,163 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestAnalyticsSearch(t *testing.T) {
+	app := newTestApp(t, `{"msg":"Error: disk full","level":"error","n":3}
+{"msg":"say \"hi\"","level":"info","n":10}
+{"msg":"Straße gesperrt","level":"warn","n":"2"}
+{"msg":"café opened","tags":["Error","x"],"n":1.5}
+{"nested":{"deep":{"key":"needle"}},"n":null}
+[1,"ERROR"]
+{"msg":"all good","level":"info"}
+`)
+
+	searches := []SearchOptions{
+		{Query: "error"},
+		{Query: "Error", CaseSensitive: true},
+		{Query: `"hi"`},
+		{Query: `\"hi\"`},
+		{Query: "STRASSE"},
+		{Query: "café"},
+		{Query: "needle"},
+		{Query: "deep"},
+		{Query: "info", Limit: 1},
+		{Query: "info", Offset: 1, Limit: 1},
+		{Query: "n", Offset: 9},
+		{Query: "\"n\"", SortBy: &SortKey{Field: "n", Direction: SortDescending}},
+		{Query: "msg", SortBy: &SortKey{Field: "msg"}, Offset: 1, Limit: 2},
+		{Query: "missing"},
+	}
+	lines := func(result *SearchResult) string {
+		var numbers []int
+		for _, record := range result.Records {
+			numbers = append(numbers, record.LineNumber)
+		}
+		return fmt.Sprintf("%v total=%d hasMore=%v", numbers, result.TotalMatches, result.HasMore)
+	}
+
+	// Results in memory are the reference
+	expected := make([]string, len(searches))
+	for i, options := range searches {
+		result, err := app.SearchRecords(options)
+		if err != nil {
+			t.Fatalf("SearchRecords(%+v) returned error: %v", options, err)
+		}
+		expected[i] = lines(result)
+	}
+
+	status, err := app.EnableAnalyticsMode()
+	if err != nil {
+		t.Fatalf("EnableAnalyticsMode returned error: %v", err)
+	}
+	if !status.Enabled || !status.SQL || status.Records != 7 || status.Version != app.cache.versionToken() {
+		t.Errorf("Unexpected analytics status: %+v", status)
+	}
+
+	for i, options := range searches {
+		result, err := app.SearchRecords(options)
+		if err != nil {
+			t.Fatalf("SearchRecords(%+v) returned error in analytics mode: %v", options, err)
+		}
+		if got := lines(result); got != expected[i] {
+			t.Errorf("SearchRecords(%+v) in analytics mode = %s, expected %s", options, got, expected[i])
+		}
+	}
+
+	// Searches DuckDB cannot run the same way stay in memory
+	result, err := app.SearchRecords(SearchOptions{Query: "level:info", UseLucene: true})
+	if err != nil || result.TotalMatches != 2 {
+		t.Errorf("Expected the Lucene search to match 2 records, got %+v (%v)", result, err)
+	}
+
+	// Changed records turn analytics mode off until it is enabled again
+	app.stateMu.Lock()
+	app.bumpDataVersion()
+	app.stateMu.Unlock()
+	if status := app.GetAnalyticsStatus(); status.Enabled {
+		t.Errorf("Expected analytics mode to be off after the records changed, got %+v", status)
+	}
+	if result, err := app.SearchRecords(searches[0]); err != nil || lines(result) != expected[0] {
+		t.Errorf("Expected the in-memory search after the records changed, got %+v (%v)", result, err)
+	}
+
+	if _, err := app.EnableAnalyticsMode(); err != nil {
+		t.Fatalf("EnableAnalyticsMode returned error: %v", err)
+	}
+	app.DisableAnalyticsMode()
+	if status := app.GetAnalyticsStatus(); status.Enabled || app.analytics != nil {
+		t.Errorf("Expected analytics mode to be disabled, got %+v", status)
+	}
+
+	app = &App{}
+	var jsonlErr *JSONLError
+	if _, err := app.EnableAnalyticsMode(); !errors.As(err, &jsonlErr) || !errors.Is(jsonlErr.Err, ErrNoFileLoaded) {
+		t.Errorf("Expected ErrNoFileLoaded without a file, got %v", err)
+	}
+}
+
+func TestAnalyticsSQL(t *testing.T) {
+	app := newTestApp(t, sqlTestRecords)
+	if err := app.SetComputedFields([]ComputedField{{Name: "slow", Expression: "latency_ms > 500"}}); err != nil {
+		t.Fatalf("SetComputedFields returned error: %v", err)
+	}
+	if _, err := app.EnableAnalyticsMode(); err != nil {
+		t.Fatalf("EnableAnalyticsMode returned error: %v", err)
+	}
+
+	tests := []struct {
+		query   string
+		columns string
+		rows    string
+	}{
+		{
+			"SELECT status, count(*) AS n FROM records GROUP BY status ORDER BY n DESC, status",
+			"[status n]", "[[200 2] [500 2] [404 1]]",
+		},
+		{
+			"SELECT user.country AS country, max(latency_ms) FROM records WHERE user.country IS NOT NULL GROUP BY ALL ORDER BY 1",
+			"[country max(latency_ms)]", "[[DE 1500] [US 900]]",
+		},
+		{
+			// Virtual fields are columns, and DuckDB's dialect beyond the built-in SQL works
+			"SELECT path, latency_ms FROM records WHERE slow QUALIFY row_number() OVER (PARTITION BY path ORDER BY latency_ms DESC) = 1 ORDER BY path",
+			"[path latency_ms]", "[[/a 1500] [/b 900] [/c 650]]",
+		},
+	}
+	for _, tt := range tests {
+		result, err := app.RunSQL(tt.query)
+		if err != nil {
+			t.Errorf("RunSQL(%q) returned error: %v", tt.query, err)
+			continue
+		}
+		if got := fmt.Sprint(result.Columns); got != tt.columns {
+			t.Errorf("RunSQL(%q) columns = %s, expected %s", tt.query, got, tt.columns)
+		}
+		if got := fmt.Sprint(result.Rows); got != tt.rows {
+			t.Errorf("RunSQL(%q) rows = %s, expected %s", tt.query, got, tt.rows)
+		}
+		if result.Scanned != 5 {
+			t.Errorf("RunSQL(%q) scanned %d records, expected 5", tt.query, result.Scanned)
+		}
+	}
+
+	// Only single SELECT statements over the loaded tables run
+	for _, query := range []string{
+		"DROP TABLE records",
+		"SELECT 1; DROP TABLE records",
+		"SELECT * FROM read_csv('analytics_test.go')",
+		"SELEC 1",
+	} {
+		var jsonlErr *JSONLError
+		if _, err := app.RunSQL(query); !errors.As(err, &jsonlErr) || !errors.Is(jsonlErr.Err, ErrInvalidSQL) {
+			t.Errorf("Expected ErrInvalidSQL for %q, got %v", query, err)
+		}
+	}
+	if result, err := app.RunSQL("SELECT count(*) FROM records"); err != nil || fmt.Sprint(result.Rows) != "[[5]]" {
+		t.Errorf("Expected the records table to be intact, got %+v (%v)", result, err)
+	}
+}
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Anonymization modes
+const (
+	AnonymizeHash = "hash" // replace values with a keyed hash
+	AnonymizeFake = "fake" // replace characters while preserving the value's format
+)
+
+// AnonymizeOptions selects the fields to pseudonymize and how
+type AnonymizeOptions struct {
+	Fields []string `json:"fields"` // dot-separated field paths
+	Mode   string   `json:"mode"`   // 'hash' or 'fake'
+	Key    string   `json:"key"`    // secret key; a random key is used when empty
+}
+
+// anonymizer replaces field values with stable pseudonyms derived from a keyed HMAC,
+// so the same input always maps to the same output for a given key
+type anonymizer struct {
+	fields []string
+	mode   string
+	key    []byte
+}
+
+// newAnonymizer validates options and prepares an anonymizer
+func newAnonymizer(options AnonymizeOptions) (*anonymizer, error) {
+	if len(options.Fields) == 0 {
+		return nil, &JSONLError{
+			Message: "No fields selected for anonymization",
+			Err:     errors.New("empty anonymization field list"),
+		}
+	}
+
+	mode := options.Mode
+	if mode == "" {
+		mode = AnonymizeHash
+	}
+	if mode != AnonymizeHash && mode != AnonymizeFake {
+		return nil, &JSONLError{
+			Message: fmt.Sprintf("Unknown anonymization mode %q", options.Mode),
+			Err:     errors.New("invalid anonymization mode"),
+		}
+	}
+
+	key := []byte(options.Key)
+	if len(key) == 0 {
+		key = make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			return nil, fmt.Errorf("failed to generate anonymization key: %w", err)
+		}
+	}
+
+	return &anonymizer{
+		fields: options.Fields,
+		mode:   mode,
+		key:    key,
+	}, nil
+}
+
+// digest returns the keyed HMAC of a value
+func (an *anonymizer) digest(value string) []byte {
+	mac := hmac.New(sha256.New, an.key)
+	mac.Write([]byte(value))
+	return mac.Sum(nil)
+}
+
+// fakeString replaces letters with letters and digits with digits, keeping case,
+// punctuation and length, using the HMAC of the whole value as the source
+func (an *anonymizer) fakeString(value string) string {
+	const lower = "abcdefghijklmnopqrstuvwxyz"
+	const digits = "0123456789"
+
+	stream := an.digest(value)
+	var b strings.Builder
+	i := 0
+	next := func() byte {
+		if i == len(stream) {
+			// Extend the stream for long values by chaining digests
+			stream = an.digest(string(stream))
+			i = 0
+		}
+		v := stream[i]
+		i++
+		return v
+	}
+
+	for _, r := range value {
+		switch {
+		case r < unicode.MaxASCII && unicode.IsDigit(r):
+			b.WriteByte(digits[int(next())%len(digits)])
+		case r < unicode.MaxASCII && unicode.IsUpper(r):
+			b.WriteByte(lower[int(next())%len(lower)] - 'a' + 'A')
+		case r < unicode.MaxASCII && unicode.IsLower(r):
+			b.WriteByte(lower[int(next())%len(lower)])
+		case unicode.IsLetter(r):
+			b.WriteByte(lower[int(next())%len(lower)])
+		default:
+			b.WriteRune(r)
+		}
+	}
+
+	return b.String()
+}
+
+// pseudonym returns the replacement for a single leaf value
+func (an *anonymizer) pseudonym(value interface{}) interface{} {
+	switch v := value.(type) {
+	case nil, bool:
+		// Too little information to leak; keeping them preserves structure
+		return v
+	case string:
+		if an.mode == AnonymizeFake {
+			return an.fakeString(v)
+		}
+		return hex.EncodeToString(an.digest(v))[:16]
+	}
+
+	valueStr := valueToString(value)
+	if an.mode == AnonymizeFake {
+		if _, isNumber := toFloat64(value); isNumber {
+			faked := an.fakeString(valueStr)
+			// Avoid a leading zero changing how the number reads
+			if strings.HasPrefix(faked, "0") && len(faked) > 1 && faked[1] != '.' {
+				faked = "1" + faked[1:]
+			}
+			if number, err := strconv.ParseFloat(faked, 64); err == nil {
+				return number
+			}
+		}
+		return an.fakeString(valueStr)
+	}
+	return hex.EncodeToString(an.digest(valueStr))[:16]
+}
+
+// anonymizeValue replaces every leaf inside value, keeping objects and arrays intact
+func (an *anonymizer) anonymizeValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, nested := range v {
+			result[key] = an.anonymizeValue(nested)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, nested := range v {
+			result[i] = an.anonymizeValue(nested)
+		}
+		return result
+	default:
+		return an.pseudonym(v)
+	}
+}
+
+// apply returns a copy of the record with the selected fields pseudonymized
+func (an *anonymizer) apply(record JSONRecord) JSONRecord {
+	content := deepCopyValue(record.Content).(map[string]interface{})
+
+	changed := false
+	for _, field := range an.fields {
+		if value, exists := getFieldValue(content, field); exists {
+			setFieldValue(content, field, an.anonymizeValue(value))
+			changed = true
+		}
+	}
+	if !changed {
+		return record
+	}
+
+	rawJSON := record.RawJSON
+	if jsonBytes, err := json.Marshal(content); err == nil {
+		rawJSON = string(jsonBytes)
+	}
+
+	return JSONRecord{
+		LineNumber: record.LineNumber,
+		Content:    content,
+		RawJSON:    rawJSON,
+	}
+}
+
+// deepCopyValue copies nested maps and slices of a decoded JSON value
+func deepCopyValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, nested := range v {
+			result[key] = deepCopyValue(nested)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, nested := range v {
+			result[i] = deepCopyValue(nested)
+		}
+		return result
+	default:
+		return v
+	}
+}
+
+// setFieldValue sets a dot-separated field path that getFieldValue resolved
+func setFieldValue(content map[string]interface{}, path string, value interface{}) {
+	if _, exists := content[path]; exists {
+		content[path] = value
+		return
+	}
+
+	current := content
+	parts := strings.Split(path, ".")
+	for _, part := range parts[:len(parts)-1] {
+		nested, ok := current[part].(map[string]interface{})
+		if !ok {
+			return
+		}
+		current = nested
+	}
+	current[parts[len(parts)-1]] = value
+}
+
+// ExportAnonymizedResults exports search results like ExportSearchResults, replacing
+// the values of the selected fields with consistent pseudonyms
+func (a *App) ExportAnonymizedResults(searchQuery string, shownFields []string, hiddenFields []string, options AnonymizeOptions) (string, error) {
+	an, err := newAnonymizer(options)
+	if err != nil {
+		return "", err
+	}
+
+	a.stateMu.RLock()
+	defer a.stateMu.RUnlock()
+	return a.exportRecords(searchQuery, shownFields, hiddenFields, an.apply)
+}
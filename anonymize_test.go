@@ -0,0 +1,76 @@
+package main
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestAnonymizerIsConsistent(t *testing.T) {
+	app := newTestApp(t, `{"user":"alice@example.com","amount":1250,"profile":{"phone":"+1 555-0100"},"kept":"visible"}
+{"user":"bob@example.com","amount":80,"profile":{"phone":"+1 555-0199"},"kept":"visible"}
+{"user":"alice@example.com","amount":1250,"profile":{"phone":"+1 555-0100"},"kept":"visible"}`)
+	records := app.records
+
+	tests := []struct {
+		name string
+		mode string
+	}{
+		{"Hash mode", AnonymizeHash},
+		{"Fake mode", AnonymizeFake},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			an, err := newAnonymizer(AnonymizeOptions{
+				Fields: []string{"user", "amount", "profile.phone"},
+				Mode:   tt.mode,
+				Key:    "secret",
+			})
+			if err != nil {
+				t.Fatalf("newAnonymizer returned error: %v", err)
+			}
+
+			first := an.apply(records[0])
+			second := an.apply(records[1])
+			third := an.apply(records[2])
+
+			if first.Content["user"] == "alice@example.com" {
+				t.Errorf("Expected user to be replaced, got %v", first.Content["user"])
+			}
+			if first.Content["user"] != third.Content["user"] || first.Content["amount"] != third.Content["amount"] {
+				t.Errorf("Expected identical inputs to map to identical outputs: %v vs %v", first.Content, third.Content)
+			}
+			if first.Content["user"] == second.Content["user"] {
+				t.Errorf("Expected different inputs to map to different outputs")
+			}
+			if first.Content["kept"] != "visible" {
+				t.Errorf("Expected unselected fields to be untouched, got %v", first.Content["kept"])
+			}
+			if records[0].Content["user"] != "alice@example.com" {
+				t.Errorf("Expected source record to be unchanged")
+			}
+
+			if tt.mode == AnonymizeFake {
+				if !regexp.MustCompile(`^[a-z]+@[a-z]+\.[a-z]+$`).MatchString(first.Content["user"].(string)) {
+					t.Errorf("Expected fake email to keep its format, got %v", first.Content["user"])
+				}
+				if _, isNumber := first.Content["amount"].(float64); !isNumber {
+					t.Errorf("Expected fake amount to stay numeric, got %T", first.Content["amount"])
+				}
+				phone := first.Content["profile"].(map[string]interface{})["phone"].(string)
+				if !regexp.MustCompile(`^\+\d \d{3}-\d{4}$`).MatchString(phone) {
+					t.Errorf("Expected fake phone to keep its format, got %v", phone)
+				}
+			}
+		})
+	}
+}
+
+func TestNewAnonymizerRejectsInvalidOptions(t *testing.T) {
+	if _, err := newAnonymizer(AnonymizeOptions{}); err == nil {
+		t.Error("Expected error when no fields are selected")
+	}
+	if _, err := newAnonymizer(AnonymizeOptions{Fields: []string{"a"}, Mode: "rot13"}); err == nil {
+		t.Error("Expected error for unknown mode")
+	}
+}
@@ -1,15 +1,19 @@
 package main
 
 import (
-	"bufio"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
+	"unicode/utf8"
 
 	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
@@ -22,42 +26,75 @@ type JSONLFile struct {
 	Records    int       `json:"records"`
 	LoadedAt   time.Time `json:"loadedAt"`
 	ModifiedAt time.Time `json:"modifiedAt"`
+	Sources    []string  `json:"sources,omitempty"` // files merged into this dataset, see LoadJSONLGlob
+	Format     string    `json:"format,omitempty"`  // input format when not JSONL, e.g. FormatJSON or ParseModeMultiline
+	Table      string    `json:"table,omitempty"`   // table read from a FormatSQLite database
 }
 
 // JSONRecord represents a single JSON record from a JSONL file
 type JSONRecord struct {
-	LineNumber int                    `json:"lineNumber"`
-	Content    map[string]interface{} `json:"content"`
-	RawJSON    string                 `json:"rawJSON"`
+	LineNumber int                      `json:"lineNumber"`
+	Content    map[string]interface{}   `json:"content"`
+	RawJSON    string                   `json:"rawJSON"`
+	Value      interface{}              `json:"value,omitempty"`      // top-level array or scalar of a non-object line, whose Content is nil
+	Repaired   bool                     `json:"repaired,omitempty"`   // the line was almost-JSON repaired by lenient parsing
+	Duplicates map[string][]interface{} `json:"duplicates,omitempty"` // every value of keys repeated in the line, see SetKeepDuplicateKeys
+	Invalid    bool                     `json:"invalid,omitempty"`    // a line that failed to parse, held as text in RawJSON, see GetInvalidLines
+	SourceFile string                   `json:"sourceFile,omitempty"` // originating file of a merged dataset
+	SourceLine int                      `json:"sourceLine,omitempty"` // line number within SourceFile
+	// Field-level differences from the pinned baseline record, set on the records
+	// returned by GetRecords and GetRecordByLineNumber, see PinBaseline
+	BaselineDiffs []FieldDiff `json:"baselineDiffs,omitempty"`
 }
 
 // FileStats provides detailed statistics about a JSONL file
 type FileStats struct {
-	TotalLines   int      `json:"totalLines"`
-	ValidRecords int      `json:"validRecords"`
-	InvalidLines []int    `json:"invalidLines"`
-	CommonFields []string `json:"commonFields"`
-	FileSize     int64    `json:"fileSize"`
+	TotalLines    int                `json:"totalLines"`
+	ValidRecords  int                `json:"validRecords"`
+	InvalidLines  []int              `json:"invalidLines"`
+	CommonFields  []string           `json:"commonFields"`
+	FileSize      int64              `json:"fileSize"`
+	LongLines     []int              `json:"longLines,omitempty"`     // invalid lines skipped for exceeding the max line size
+	RepairedLines []int              `json:"repairedLines,omitempty"` // lines loaded after lenient repair, see SetLenientParsing
+	DuplicateKeys []DuplicateKeyLine `json:"duplicateKeys,omitempty"` // lines repeating a key within an object; the last value is kept
+	TruncatedLine int                `json:"truncatedLine,omitempty"` // final line without newline that does not parse yet; not counted as invalid and parsed again on reload
+	RecordSizes   *RecordSizeStats   `json:"recordSizes,omitempty"`   // byte lengths of the valid record lines, see GetLargestRecords
+
+	invalidDetails map[int]InvalidLineDetail // why each invalid line failed, recorded while parsing, see GetInvalidLineDetails
 }
 
 // SearchOptions defines parameters for searching through records
 type SearchOptions struct {
-	Query         string `json:"query"`
-	CaseSensitive bool   `json:"caseSensitive"`
-	UseLucene     bool   `json:"useLucene"`
-	SelectedField string `json:"selectedField"`
-	Offset        int    `json:"offset"`
-	Limit         int    `json:"limit"`
+	Query            string   `json:"query"`
+	CaseSensitive    bool     `json:"caseSensitive"`
+	UseLucene        bool     `json:"useLucene"`
+	UseJSONPath      bool     `json:"useJSONPath"`       // Query is a JSONPath expression; results hold the values it selects
+	Progressive      bool     `json:"progressive"`       // return an estimated count early on large files
+	IgnoreDiacritics bool     `json:"ignoreDiacritics"`  // match "Muller" to "Müller" and composed to decomposed forms
+	WholeWord        bool     `json:"wholeWord"`         // match terms only at word boundaries
+	Token            string   `json:"token,omitempty"`   // identifies the search for CancelSearch and "search:progress" events
+	StrictTypes      bool     `json:"strictTypes"`       // field:value compares booleans and numbers by value, not as text
+	SelectedFields   []string `json:"selectedFields"`    // empty or "all" searches every field
+	TimestampField   string   `json:"timestampField"`    // field queried as _time; empty detects it
+	SortBy           *SortKey `json:"sortBy,omitempty"`  // order results by a field instead of file order
+	Version          string   `json:"version,omitempty"` // when set, fail if the loaded data has another version
+	Offset           int      `json:"offset"`
+	Limit            int      `json:"limit"`
 }
 
 // LuceneQuery represents a parsed Lucene query
 type LuceneQuery struct {
-	Type  string       `json:"type"` // 'term', 'field', 'and', 'or', 'not', 'wildcard', 'phrase'
-	Field string       `json:"field,omitempty"`
-	Value string       `json:"value,omitempty"`
-	Left  *LuceneQuery `json:"left,omitempty"`
-	Right *LuceneQuery `json:"right,omitempty"`
-	Query *LuceneQuery `json:"query,omitempty"`
+	Type         string       `json:"type"` // 'term', 'field', 'and', 'or', 'not', 'wildcard', 'phrase', 'range', 'fuzzy', 'exists', 'exact'
+	Field        string       `json:"field,omitempty"`
+	Value        string       `json:"value,omitempty"`
+	Left         *LuceneQuery `json:"left,omitempty"`
+	Right        *LuceneQuery `json:"right,omitempty"`
+	Query        *LuceneQuery `json:"query,omitempty"`
+	Lower        string       `json:"lower,omitempty"` // range bounds, * when open
+	Upper        string       `json:"upper,omitempty"`
+	IncludeLower bool         `json:"includeLower,omitempty"`
+	IncludeUpper bool         `json:"includeUpper,omitempty"`
+	Distance     int          `json:"distance,omitempty"` // maximum edit distance of fuzzy terms
 }
 
 // SearchResult represents a search result with highlighting information
@@ -69,6 +106,9 @@ type SearchResult struct {
 	TotalMatches int          `json:"totalMatches"`
 	HasMore      bool         `json:"hasMore"`
 	Query        string       `json:"query"`
+	Estimated    bool         `json:"estimated"`          // TotalMatches is an estimate, refined via "search:count" events
+	SearchID     string       `json:"searchId,omitempty"` // identifies "search:count" events for this search and stops its count with CancelSearch; the Token when set
+	Version      string       `json:"version"`            // data version the results were read from
 }
 
 // ExportData represents the data structure for exporting search results
@@ -106,6 +146,11 @@ type HighlightMatch struct {
 	StartPos  int    `json:"startPos"`
 	EndPos    int    `json:"endPos"`
 	FieldName string `json:"fieldName"`
+	Path      string `json:"path"` // JSON pointer of the key or value holding the match, "" between tokens
+
+	// Byte range of the match within the decoded value, for field matches
+	ValueStart int `json:"valueStart"`
+	ValueEnd   int `json:"valueEnd"`
 }
 
 // Custom error types for JSONL operations
@@ -116,6 +161,7 @@ var (
 	ErrParsingFailed  = errors.New("JSON parsing failed")
 	ErrInvalidLineNum = errors.New("invalid line number")
 	ErrNoFileLoaded   = errors.New("no file currently loaded")
+	ErrStaleVersion   = errors.New("data changed since the version was issued")
 )
 
 // JSONLError provides detailed error information with line numbers
@@ -136,8 +182,48 @@ func (e *JSONLError) Error() string {
 // RecordCache provides efficient caching for record retrieval
 type RecordCache struct {
 	records    []JSONRecord
+	index      *lineIndex // set instead of records for disk-backed files
 	pageSize   int
 	totalCount int
+	stats      *FileStats // statistics captured when the records were parsed
+
+	// distinctValues holds sorted distinct values per field, built lazily, and
+	// exactValues the same rendered by exactText; both guarded by distinctValuesMu
+	distinctValues   map[string][]ValueCount
+	exactValues      map[string][]ValueCount
+	distinctValuesMu sync.Mutex
+
+	// fieldStats holds GetFieldStats results per field, built lazily
+	fieldStats   map[string]*FieldStats
+	fieldStatsMu sync.Mutex
+
+	// pages holds highlighted pages served or prefetched for this file
+	pages pageCache
+
+	avgRecordBytes float64                              // measured serialized record size, set in auto page size mode
+	version        uint64                               // data version of these records, see bumpDataVersion
+	derive         func(content map[string]interface{}) // adds virtual fields to records as they are read, see applyDerivedFields
+
+	// readOffset and readLines mark the end of the last complete line parsed, where
+	// ReloadIncremental resumes
+	readOffset  int64
+	readLines   int
+	fieldCounts map[string]int // per-field record counts, kept up to date by ReloadIncremental
+
+	lastOffset atomic.Int64 // offset of the last page requested, saved with the session
+
+	searchIndex   atomic.Pointer[searchIndex] // built on request by BuildSearchIndex
+	indexBuilding atomic.Bool
+
+	filters  []*stackedFilter // refine-within-results stack, see PushFilter
+	filterMu sync.Mutex       // serializes re-applying the stack for concurrent readers
+
+	// sortKeys is the order set by SortRecords. While it is set, fileOrder holds
+	// the file position of each record and sortedIndex the index in records of
+	// each file position.
+	sortKeys    []SortKey
+	fileOrder   []int
+	sortedIndex []int
 }
 
 // PaginatedRecords represents a paginated response of records
@@ -147,25 +233,115 @@ type PaginatedRecords struct {
 	Limit   int          `json:"limit"`
 	Total   int          `json:"total"`
 	HasMore bool         `json:"hasMore"`
+	Version string       `json:"version"` // data version the page was read from
 }
 
-// App struct
-type App struct {
-	ctx         context.Context
+// fileState is the state of one loaded file. The App holds the active file's
+// state; each open document keeps its own, see documents.go.
+type fileState struct {
 	currentFile *JSONLFile
 	records     []JSONRecord
 	cache       *RecordCache
+
+	validationReport *ValidationReport
+	baseline         *JSONRecord // pinned record that other records are diffed against
+	s3Profile        string      // AWS profile the file was loaded from S3 with, reused on reload
+}
+
+// App struct
+type App struct {
+	ctx context.Context
+
+	// stateMu guards the file state, the open documents and the app settings:
+	// Wails runs bound methods concurrently, so methods reading the loaded records
+	// hold it shared and those replacing or reordering them hold it exclusively
+	stateMu sync.RWMutex
+	fileState
+
+	validationRules []*compiledRule
+
+	progressiveCancel context.CancelFunc // stops the background count of the last progressive search
+	activeSearch      SearchOptions      // the most recent search, used to highlight prefetched pages
+
+	autoPageSize  bool   // derive the page size from record sizes for every loaded file
+	payloadBudget int    // serialized bytes per page targeted in auto page size mode
+	dataVersion   uint64 // bumped whenever the loaded records change
+
+	extractionRules []*compiledExtraction    // regex rules deriving virtual fields
+	computedFields  []*compiledComputed      // expressions deriving virtual fields
+	coercions       map[string]FieldCoercion // per-field type overrides for comparison and sorting
+
+	documents        []*document // open files in tab order, see documents.go
+	activeDocumentID string
+	nextDocumentID   int
+	startupFiles     []string     // files passed on the command line, opened once the UI is ready
+	uiReady          bool         // domReady has run, see openLaunchFiles
+	following        *followState // polling of the followed file, see StartFollow
+
+	maxLineSize       int               // longest line loaded as a record, 0 for the default
+	parseMode         string            // how loaded files are split into records, see SetParseMode
+	lenientParsing    bool              // repair almost-JSON lines, see SetLenientParsing
+	keepDuplicateKeys bool              // keep every value of repeated keys, see SetKeepDuplicateKeys
+	urlOptions        URLRequestOptions // headers and auth for LoadJSONLFromURL
+
+	loadMu     sync.Mutex
+	loadCancel context.CancelFunc // cancels the load in progress, see CancelLoad
+	loadSeq    int
+
+	searchMu      sync.Mutex               // guards searchCancels, activeSearch and progressiveCancel
+	searchCancels map[string]runningSearch // running searches by token, see CancelSearch
+	progressive   progressiveTuning        // when searches become progressive, see progressive.go
+	scanning      scanTuning               // how often searches report and check for cancellation, see searchcancel.go
+
+	analytics *analyticsStore // DuckDB copy of the records in analytics mode, see analytics.go
+
+	auditLog *auditLog // records significant actions when enabled
+	journal  journal   // write-ahead log of unsaved changes
+
+	trackRecentFiles bool     // record opened files in the recent files, see recent.go
+	settings         Settings // persisted user defaults, see settings.go
 }
 
 // NewApp creates a new App application struct
 func NewApp() *App {
-	return &App{}
+	app := &App{auditLog: newAuditLog(), trackRecentFiles: true}
+	app.applySettings(loadSettings())
+	return app
 }
 
 // startup is called when the app starts. The context is saved
 // so we can call the runtime methods
 func (a *App) startup(ctx context.Context) {
 	a.ctx = ctx
+	runtime.OnFileDrop(ctx, func(_, _ int, paths []string) {
+		a.HandleDroppedFiles(paths)
+	})
+}
+
+// domReady is called once the frontend has loaded, so load events reach it
+func (a *App) domReady(ctx context.Context) {
+	a.offerJournalRecovery()
+	a.stateMu.Lock()
+	a.uiReady = true
+	paths := a.startupFiles
+	a.startupFiles = nil
+	a.stateMu.Unlock()
+	a.openLaunchFiles(paths)
+}
+
+// shutdown is called when the app exits and saves the session for RestoreLastSession
+func (a *App) shutdown(ctx context.Context) {
+	a.StopFollow()
+	a.saveSession()
+}
+
+// emitEvent sends an event to the frontend, doing nothing when the app was not
+// started by the Wails runtime (e.g. in tests or CLI mode)
+func (a *App) emitEvent(name string, data ...interface{}) {
+	if a.ctx == nil {
+		return
+	}
+	runtime.EventsEmit(a.ctx, name, data...)
 }
 
 // Greet returns a greeting for the given name
@@ -175,12 +351,73 @@ func (a *App) Greet(name string) string {
 
 // JSONLParser handles parsing of JSONL files
 type JSONLParser struct {
-	file      *os.File
-	scanner   *bufio.Scanner
-	lineCount int
+	file           *os.File
+	source         io.ReadCloser // file content, decompressed when needed
+	lines          *lineReader
+	lineCount      int
+	mode           string // parse mode, see SetParseMode; "" reads JSONL
+	lenient        bool   // repair almost-JSON lines, see SetLenientParsing
+	keepDuplicates bool   // keep every value of repeated keys, see SetKeepDuplicateKeys
+
+	// Position after the last newline-terminated line, where an incremental
+	// reload resumes; a final line without newline may still be growing
+	completeOffset int64
+	completeLines  int
+	bytesRead      int64
+
+	ctx      context.Context                    // cancels parsing when done, optional
+	progress func(bytesRead int64, records int) // called every loadProgressLines lines, optional
+}
+
+// newLineReader returns a line reader over r that tracks the parser's read position
+func (p *JSONLParser) newLineReader(r io.Reader) *lineReader {
+	lines := newLineReader(r, 0)
+	lines.onLine = func(bytesRead int64, _ int) {
+		p.completeOffset = bytesRead
+		p.completeLines = p.lineCount + 1
+	}
+	return lines
+}
+
+// setMaxLineSize sets the longest line loaded as a record; 0 keeps the default
+func (p *JSONLParser) setMaxLineSize(size int) {
+	if size > 0 {
+		p.lines.limit = size
+	}
+}
+
+// parseOptions are the loading settings a parse applies
+type parseOptions struct {
+	mode           string
+	lenient        bool
+	keepDuplicates bool
+	maxLineSize    int
+}
+
+// loadOptions reads the loading settings once per load, so a concurrent change
+// applies to the next load as a whole
+func (a *App) loadOptions() parseOptions {
+	a.stateMu.RLock()
+	defer a.stateMu.RUnlock()
+	return parseOptions{
+		mode:           a.parseMode,
+		lenient:        a.lenientParsing,
+		keepDuplicates: a.keepDuplicateKeys,
+		maxLineSize:    a.maxLineSize,
+	}
+}
+
+// applyOptions sets the lenient, duplicate key and line size options; the mode is
+// left to the caller
+func (p *JSONLParser) applyOptions(options parseOptions) {
+	p.lenient = options.lenient
+	p.keepDuplicates = options.keepDuplicates
+	p.setMaxLineSize(options.maxLineSize)
 }
 
-// NewJSONLParser creates a new JSONL parser for the given file path
+// NewJSONLParser creates a new JSONL parser for the given file path. Compressed
+// files are decompressed and UTF-16 or BOM-prefixed text is transcoded to UTF-8
+// while they are read.
 func NewJSONLParser(filePath string) (*JSONLParser, error) {
 	file, err := os.Open(filePath)
 	if err != nil {
@@ -190,16 +427,37 @@ func NewJSONLParser(filePath string) (*JSONLParser, error) {
 		}
 	}
 
-	scanner := bufio.NewScanner(file)
-	return &JSONLParser{
+	source, err := openJSONLSource(filePath)
+	if err != nil {
+		file.Close()
+		return nil, &JSONLError{
+			Message: "Failed to decompress file",
+			Err:     err,
+		}
+	}
+
+	parser := &JSONLParser{
 		file:      file,
-		scanner:   scanner,
+		source:    source,
 		lineCount: 0,
-	}, nil
+	}
+	parser.lines = parser.newLineReader(source)
+	return parser, nil
+}
+
+// NewJSONLReaderParser creates a JSONL parser reading from r, e.g. a download stream.
+// Its stats report no file size.
+func NewJSONLReaderParser(r io.Reader) *JSONLParser {
+	parser := &JSONLParser{}
+	parser.lines = parser.newLineReader(r)
+	return parser
 }
 
 // Close closes the file and cleans up resources
 func (p *JSONLParser) Close() error {
+	if p.source != nil {
+		p.source.Close()
+	}
 	if p.file != nil {
 		return p.file.Close()
 	}
@@ -208,74 +466,133 @@ func (p *JSONLParser) Close() error {
 
 // ParseJSONL parses the entire JSONL file and returns all records
 func (p *JSONLParser) ParseJSONL() ([]JSONRecord, *FileStats, error) {
+	if p.mode == ParseModeMultiline {
+		return p.parseConcatenated()
+	}
+
 	var records []JSONRecord
 	var invalidLines []int
 	fieldCounts := make(map[string]int)
 	totalRecords := 0
 
 	// Get file info for size
-	fileInfo, err := p.file.Stat()
-	if err != nil {
-		return nil, nil, &JSONLError{
-			Message: "Failed to get file information",
-			Err:     err,
+	var fileSize int64
+	if p.file != nil {
+		fileInfo, err := p.file.Stat()
+		if err != nil {
+			return nil, nil, &JSONLError{
+				Message: "Failed to get file information",
+				Err:     err,
+			}
 		}
+		fileSize = fileInfo.Size()
 	}
 
-	for p.scanner.Scan() {
-		p.lineCount++
-		line := strings.TrimSpace(p.scanner.Text())
+	// Lines are read here and unmarshalled in chunks by a worker pool
+	pool := newChunkPool()
+	convert := lineConverters[p.mode]
+	chunk := lineChunk{lenient: p.lenient, keepDuplicates: p.keepDuplicates, convert: convert}
+	var longLines []int
+	for {
+		raw, tooLong, err := p.lines.next()
+		p.bytesRead = p.lines.bytes
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			pool.wait()
+			return nil, nil, &JSONLError{
+				Message: "Error reading file",
+				Err:     err,
+			}
+		}
 
-		// Skip empty lines
-		if line == "" {
+		p.lineCount++
+		if p.lineCount%loadProgressLines == 0 {
+			if p.ctx != nil && p.ctx.Err() != nil {
+				pool.wait()
+				return nil, nil, &JSONLError{
+					Message: "Loading was cancelled",
+					Err:     ErrLoadCancelled,
+				}
+			}
+			if p.progress != nil {
+				p.progress(p.bytesRead, int(pool.parsed.Load()))
+			}
+		}
+		// Lines over the size limit are reported rather than loaded
+		if tooLong {
+			longLines = append(longLines, p.lineCount)
 			continue
 		}
+		line := string(raw)
 
-		// Try to parse the JSON line
-		var content map[string]interface{}
-		if err := json.Unmarshal([]byte(line), &content); err != nil {
-			invalidLines = append(invalidLines, p.lineCount)
+		// Skip empty lines
+		if strings.TrimSpace(line) == "" {
 			continue
 		}
 
-		// Count fields for common fields analysis
-		for field := range content {
-			fieldCounts[field]++
+		chunk.lines = append(chunk.lines, line)
+		chunk.lineNumbers = append(chunk.lineNumbers, p.lineCount)
+		if len(chunk.lines) == parseChunkLines {
+			pool.submit(chunk)
+			chunk = lineChunk{index: chunk.index + 1, lenient: p.lenient, keepDuplicates: p.keepDuplicates, convert: convert}
 		}
+	}
+	if len(chunk.lines) > 0 {
+		pool.submit(chunk)
+	}
+	chunks := pool.wait()
 
-		// Create record
-		record := JSONRecord{
-			LineNumber: p.lineCount,
-			Content:    content,
-			RawJSON:    line,
+	var repairedLines []int
+	var duplicateKeys []DuplicateKeyLine
+	invalidDetails := make(map[int]InvalidLineDetail)
+	for _, parsed := range chunks {
+		records = append(records, parsed.records...)
+		invalidLines = append(invalidLines, parsed.invalidLines...)
+		for _, detail := range parsed.invalidDetails {
+			invalidDetails[detail.LineNumber] = detail
+		}
+		repairedLines = append(repairedLines, parsed.repairedLines...)
+		duplicateKeys = append(duplicateKeys, parsed.duplicateKeys...)
+		for field, count := range parsed.fieldCounts {
+			fieldCounts[field] += count
 		}
-		records = append(records, record)
-		totalRecords++
 	}
+	totalRecords = len(records)
 
-	// Check for scanner errors
-	if err := p.scanner.Err(); err != nil {
-		return nil, nil, &JSONLError{
-			Message: "Error reading file",
-			Err:     err,
+	// A final line without newline may still be being written; it is parsed again
+	// on reload rather than reported as invalid. Its diagnostic is kept for merged
+	// datasets, which report it.
+	truncatedLine := 0
+	if n := len(invalidLines); n > 0 && invalidLines[n-1] == p.lineCount && p.lineCount > p.completeLines {
+		truncatedLine = p.lineCount
+		invalidLines = invalidLines[:n-1]
+	}
+	if len(longLines) > 0 {
+		for _, line := range longLines {
+			invalidDetails[line] = longLineDetail(line, p.lines.limit)
 		}
+		invalidLines = append(invalidLines, longLines...)
+		sort.Ints(invalidLines)
 	}
 
 	// Calculate common fields (fields that appear in at least 50% of records)
-	var commonFields []string
-	threshold := totalRecords / 2
-	for field, count := range fieldCounts {
-		if count >= threshold {
-			commonFields = append(commonFields, field)
-		}
-	}
+	commonFields := selectCommonFields(fieldCounts, totalRecords, CommonFieldOptions{})
 
 	stats := &FileStats{
-		TotalLines:   p.lineCount,
-		ValidRecords: totalRecords,
-		InvalidLines: invalidLines,
-		CommonFields: commonFields,
-		FileSize:     fileInfo.Size(),
+		TotalLines:    p.lineCount,
+		ValidRecords:  totalRecords,
+		InvalidLines:  invalidLines,
+		CommonFields:  commonFields,
+		FileSize:      fileSize,
+		LongLines:     longLines,
+		RepairedLines: repairedLines,
+		DuplicateKeys: duplicateKeys,
+		TruncatedLine: truncatedLine,
+		RecordSizes:   measureRecordSizes(recordSizes(records)),
+
+		invalidDetails: invalidDetails,
 	}
 
 	return records, stats, nil
@@ -291,8 +608,7 @@ func ValidateJSONLLine(line string, lineNumber int) error {
 	}
 
 	// Try to parse as JSON
-	var content map[string]interface{}
-	if err := json.Unmarshal([]byte(line), &content); err != nil {
+	if _, _, err := parseRecordLine(line); err != nil {
 		return &JSONLError{
 			Message:    "Invalid JSON format",
 			LineNumber: lineNumber,
@@ -306,16 +622,25 @@ func ValidateJSONLLine(line string, lineNumber int) error {
 
 // ParseJSONLFromString parses JSONL content from a string (useful for clipboard)
 func ParseJSONLFromString(content string) ([]JSONRecord, *FileStats, error) {
+	return parseJSONLString(content, false)
+}
+
+// parseJSONLString parses JSONL content from a string, keeping every value of
+// repeated keys in the records' Duplicates with keepDuplicates set
+func parseJSONLString(content string, keepDuplicates bool) ([]JSONRecord, *FileStats, error) {
 	var records []JSONRecord
 	var invalidLines []int
+	invalidDetails := make(map[int]InvalidLineDetail)
+	var duplicateKeys []DuplicateKeyLine
 	fieldCounts := make(map[string]int)
 	totalRecords := 0
 
-	lines := strings.Split(content, "\n")
+	lines := strings.Split(decodeTextString(content), "\n")
 
-	for i, line := range lines {
+	for i, raw := range lines {
 		lineNumber := i + 1
-		line = strings.TrimSpace(line)
+		raw = strings.TrimRight(raw, "\r")
+		line := strings.TrimSpace(raw)
 
 		// Skip empty lines
 		if line == "" {
@@ -323,9 +648,10 @@ func ParseJSONLFromString(content string) ([]JSONRecord, *FileStats, error) {
 		}
 
 		// Try to parse the JSON line
-		var jsonContent map[string]interface{}
-		if err := json.Unmarshal([]byte(line), &jsonContent); err != nil {
+		jsonContent, value, err := parseRecordLine(line)
+		if err != nil {
 			invalidLines = append(invalidLines, lineNumber)
+			invalidDetails[lineNumber] = invalidLineDetail(lineNumber, raw, err, jsonErrorOffset(err))
 			continue
 		}
 
@@ -339,36 +665,37 @@ func ParseJSONLFromString(content string) ([]JSONRecord, *FileStats, error) {
 			LineNumber: lineNumber,
 			Content:    jsonContent,
 			RawJSON:    line,
+			Value:      value,
+		}
+		if duplicate, found := detectDuplicateKeys(&record, keepDuplicates); found {
+			duplicateKeys = append(duplicateKeys, duplicate)
 		}
 		records = append(records, record)
 		totalRecords++
 	}
 
 	// Calculate common fields (fields that appear in at least 50% of records)
-	var commonFields []string
-	threshold := totalRecords / 2
-	for field, count := range fieldCounts {
-		if count >= threshold {
-			commonFields = append(commonFields, field)
-		}
-	}
+	commonFields := selectCommonFields(fieldCounts, totalRecords, CommonFieldOptions{})
 
 	stats := &FileStats{
-		TotalLines:   len(lines),
-		ValidRecords: totalRecords,
-		InvalidLines: invalidLines,
-		CommonFields: commonFields,
-		FileSize:     int64(len(content)),
+		TotalLines:    len(lines),
+		ValidRecords:  totalRecords,
+		InvalidLines:  invalidLines,
+		CommonFields:  commonFields,
+		FileSize:      int64(len(content)),
+		DuplicateKeys: duplicateKeys,
+		RecordSizes:   measureRecordSizes(recordSizes(records)),
+
+		invalidDetails: invalidDetails,
 	}
 
 	return records, stats, nil
 }
 
-// OpenFile opens a native file dialog and returns the selected file path
-func (a *App) OpenFile() (string, error) {
-	// Configure file dialog options
-	dialogOptions := runtime.OpenDialogOptions{
-		Title: "Select JSONL File",
+// jsonlDialogOptions returns the file dialog options with the JSONL file filters
+func jsonlDialogOptions(title string) runtime.OpenDialogOptions {
+	return runtime.OpenDialogOptions{
+		Title: title,
 		Filters: []runtime.FileFilter{
 			{
 				DisplayName: "JSONL Files (*.jsonl)",
@@ -378,6 +705,14 @@ func (a *App) OpenFile() (string, error) {
 				DisplayName: "JSON Lines Files (*.jsonlines)",
 				Pattern:     "*.jsonlines",
 			},
+			{
+				DisplayName: "Compressed JSONL Files (*.gz, *.zst, *.bz2)",
+				Pattern:     "*.gz;*.zst;*.bz2",
+			},
+			{
+				DisplayName: "Log Files (*.log)",
+				Pattern:     "*.log",
+			},
 			{
 				DisplayName: "Text Files (*.txt)",
 				Pattern:     "*.txt",
@@ -388,9 +723,12 @@ func (a *App) OpenFile() (string, error) {
 			},
 		},
 	}
+}
 
+// OpenFile opens a native file dialog and returns the selected file path
+func (a *App) OpenFile() (string, error) {
 	// Open the file dialog
-	filePath, err := runtime.OpenFileDialog(a.ctx, dialogOptions)
+	filePath, err := runtime.OpenFileDialog(a.ctx, jsonlDialogOptions("Select JSONL File"))
 	if err != nil {
 		return "", &JSONLError{
 			Message: "Failed to open file dialog",
@@ -411,6 +749,11 @@ func (a *App) OpenFile() (string, error) {
 
 // LoadJSONLFile loads and parses a JSONL file from the given file path
 func (a *App) LoadJSONLFile(filePath string) (*JSONLFile, error) {
+	return a.loadFile(filePath, a.loadOptions().mode)
+}
+
+// loadFile loads a local file in the given parse mode
+func (a *App) loadFile(filePath, mode string) (*JSONLFile, error) {
 	// Validate file path
 	if filePath == "" {
 		return nil, &JSONLError{
@@ -428,6 +771,16 @@ func (a *App) LoadJSONLFile(filePath string) (*JSONLFile, error) {
 		}
 	}
 
+	// Very large JSONL files are indexed instead of held in memory
+	options := a.loadOptions()
+	if fileInfo.Size() >= diskBackedThreshold && mode == "" && !options.lenient &&
+		!isCompressedFile(filePath) && !isTranscodedFile(filePath) {
+		return a.LoadJSONLFileIndexed(filePath)
+	}
+
+	ctx, done := a.beginLoad()
+	defer done()
+
 	// Create parser
 	parser, err := NewJSONLParser(filePath)
 	if err != nil {
@@ -435,12 +788,40 @@ func (a *App) LoadJSONLFile(filePath string) (*JSONLFile, error) {
 	}
 	defer parser.Close()
 
+	// Report progress as the file is parsed; compressed or transcoded sizes do not
+	// match the bytes read
+	progress := LoadProgress{Source: filePath, TotalBytes: fileInfo.Size()}
+	if isCompressedFile(filePath) || isTranscodedFile(filePath) {
+		progress.TotalBytes = -1
+	}
+	parser.ctx = ctx
+	parser.mode = mode
+	parser.applyOptions(options)
+	parser.progress = func(bytesRead int64, records int) {
+		progress.BytesRead = bytesRead
+		progress.RecordsParsed = records
+		a.emitLoadProgress(progress)
+	}
+
 	// Parse the file
 	records, stats, err := parser.ParseJSONL()
 	if err != nil {
 		return nil, err
 	}
+	progress.BytesRead = parser.bytesRead
+	progress.RecordsParsed = stats.ValidRecords
+	progress.Done = true
+	a.emitLoadProgress(progress)
+
+	jsonlFile := a.storeLoadedFile(filePath, fileInfo, mode, records, stats, nil)
+	a.cache.readOffset = parser.completeOffset
+	a.cache.readLines = parser.completeLines
+	return jsonlFile, nil
+}
 
+// storeLoadedFile makes a file parsed in the given format the current file.
+// Disk-backed files pass their line index instead of records.
+func (a *App) storeLoadedFile(filePath string, fileInfo os.FileInfo, format string, records []JSONRecord, stats *FileStats, index *lineIndex) *JSONLFile {
 	// Create JSONLFile metadata
 	fileName := filepath.Base(filePath)
 	jsonlFile := &JSONLFile{
@@ -450,6 +831,21 @@ func (a *App) LoadJSONLFile(filePath string) (*JSONLFile, error) {
 		Records:    stats.ValidRecords,
 		LoadedAt:   time.Now(),
 		ModifiedAt: fileInfo.ModTime(),
+		Format:     format,
+	}
+	a.storeFile(jsonlFile, records, stats, index)
+	return jsonlFile
+}
+
+// storeFile makes jsonlFile the current file and resets the state derived from
+// the previous one
+func (a *App) storeFile(jsonlFile *JSONLFile, records []JSONRecord, stats *FileStats, index *lineIndex) {
+	a.stateMu.Lock()
+	defer a.stateMu.Unlock()
+
+	// A pinned baseline belongs to the file it was taken from
+	if a.currentFile == nil || a.currentFile.Path != jsonlFile.Path {
+		a.baseline = nil
 	}
 
 	// Store in app state
@@ -459,39 +855,61 @@ func (a *App) LoadJSONLFile(filePath string) (*JSONLFile, error) {
 	// Initialize cache for efficient pagination
 	a.cache = &RecordCache{
 		records:    records,
-		pageSize:   50, // Default page size for virtual scrolling
-		totalCount: len(records),
-	}
-
-	return jsonlFile, nil
+		index:      index,
+		pageSize:   a.settings.withDefaults().DefaultPageSize,
+		totalCount: stats.ValidRecords,
+		stats:      stats,
+	}
+	a.validationReport = nil
+	a.closeAnalytics()
+	a.applyDerivedFields()
+	a.applyPageSizeMode()
+	a.bumpDataVersion()
+	a.applyFilePreferences()
+
+	a.audit(AuditEntry{Action: AuditFileOpened, Path: jsonlFile.Path, Records: jsonlFile.Records})
+	a.addRecentFile(jsonlFile)
 }
 
-// GetFileStats returns detailed statistics about the currently loaded file
+// GetFileStats returns detailed statistics about the currently loaded file, as
+// recorded when it was loaded
 func (a *App) GetFileStats() (*FileStats, error) {
-	if a.currentFile == nil {
+	a.stateMu.RLock()
+	defer a.stateMu.RUnlock()
+	return a.fileStats()
+}
+
+// fileStats implements GetFileStats; the caller holds stateMu
+func (a *App) fileStats() (*FileStats, error) {
+	if a.currentFile == nil || a.cache == nil || a.cache.stats == nil {
 		return nil, &JSONLError{
 			Message: "No file currently loaded",
 			Err:     ErrNoFileLoaded,
 		}
 	}
+	return a.cache.stats.clone(), nil
+}
 
-	// Re-parse the file to get fresh statistics
-	parser, err := NewJSONLParser(a.currentFile.Path)
-	if err != nil {
-		return nil, err
-	}
-	defer parser.Close()
-
-	_, stats, err := parser.ParseJSONL()
-	if err != nil {
-		return nil, err
-	}
-
-	return stats, nil
+// clone copies stats so callers cannot alter the statistics of the loaded file
+func (stats *FileStats) clone() *FileStats {
+	copied := *stats
+	copied.InvalidLines = append([]int{}, stats.InvalidLines...)
+	copied.CommonFields = append([]string{}, stats.CommonFields...)
+	copied.LongLines = append([]int(nil), stats.LongLines...)
+	copied.RepairedLines = append([]int(nil), stats.RepairedLines...)
+	copied.DuplicateKeys = append([]DuplicateKeyLine(nil), stats.DuplicateKeys...)
+	return &copied
 }
 
 // CheckFileModification checks if the currently loaded file has been modified since it was loaded
 func (a *App) CheckFileModification() (bool, error) {
+	a.stateMu.RLock()
+	defer a.stateMu.RUnlock()
+	return a.checkFileModification()
+}
+
+// checkFileModification implements CheckFileModification; the caller holds stateMu
+func (a *App) checkFileModification() (bool, error) {
 	if a.currentFile == nil {
 		return false, &JSONLError{
 			Message: "No file currently loaded",
@@ -499,27 +917,40 @@ func (a *App) CheckFileModification() (bool, error) {
 		}
 	}
 
-	// Skip modification check for clipboard content
-	if a.currentFile.Path == "<clipboard>" {
+	// Skip modification check for clipboard content and remote sources
+	if a.currentFile.Path == "<clipboard>" || isRemotePath(a.currentFile.Path) {
 		return false, nil
 	}
 
-	// Get current file info
-	fileInfo, err := os.Stat(a.currentFile.Path)
-	if err != nil {
-		return false, &JSONLError{
-			Message: "Failed to check file modification time",
-			Err:     err,
-		}
+	// A merged dataset is modified when any of its files is
+	paths := []string{a.currentFile.Path}
+	if len(a.currentFile.Sources) > 0 {
+		paths = a.currentFile.Sources
 	}
 
-	// Compare modification times
-	currentModTime := fileInfo.ModTime()
-	return currentModTime.After(a.currentFile.ModifiedAt), nil
+	for _, path := range paths {
+		// Get current file info
+		fileInfo, err := os.Stat(path)
+		if err != nil {
+			return false, &JSONLError{
+				Message: "Failed to check file modification time",
+				Err:     err,
+			}
+		}
+
+		// Compare modification times
+		if fileInfo.ModTime().After(a.currentFile.ModifiedAt) {
+			return true, nil
+		}
+	}
+	return false, nil
 }
 
 // GetFileModificationInfo returns information about file modification status
 func (a *App) GetFileModificationInfo() (map[string]interface{}, error) {
+	a.stateMu.RLock()
+	defer a.stateMu.RUnlock()
+
 	if a.currentFile == nil {
 		return nil, &JSONLError{
 			Message: "No file currently loaded",
@@ -532,10 +963,11 @@ func (a *App) GetFileModificationInfo() (map[string]interface{}, error) {
 		"loadedAt":        a.currentFile.LoadedAt,
 		"originalModTime": a.currentFile.ModifiedAt,
 		"isClipboard":     a.currentFile.Path == "<clipboard>",
+		"isRemote":        isRemotePath(a.currentFile.Path),
 	}
 
-	// Skip modification check for clipboard content
-	if a.currentFile.Path == "<clipboard>" {
+	// Skip modification check for clipboard content and remote sources
+	if a.currentFile.Path == "<clipboard>" || isRemotePath(a.currentFile.Path) {
 		result["isModified"] = false
 		result["currentModTime"] = nil
 		return result, nil
@@ -559,7 +991,13 @@ func (a *App) GetFileModificationInfo() (map[string]interface{}, error) {
 
 // ReloadCurrentFile reloads the currently loaded file if it has been modified
 func (a *App) ReloadCurrentFile() (*JSONLFile, error) {
-	if a.currentFile == nil {
+	// Reopening stores the new file under stateMu, so only the checks hold it
+	a.stateMu.RLock()
+	currentFile := a.currentFile
+	isModified, err := a.checkFileModification()
+	a.stateMu.RUnlock()
+
+	if currentFile == nil {
 		return nil, &JSONLError{
 			Message: "No file currently loaded",
 			Err:     ErrNoFileLoaded,
@@ -567,29 +1005,39 @@ func (a *App) ReloadCurrentFile() (*JSONLFile, error) {
 	}
 
 	// Cannot reload clipboard content
-	if a.currentFile.Path == "<clipboard>" {
+	if currentFile.Path == "<clipboard>" {
 		return nil, &JSONLError{
 			Message: "Cannot reload clipboard content",
 			Err:     errors.New("clipboard content cannot be reloaded"),
 		}
 	}
 
-	// Check if file has been modified
-	isModified, err := a.CheckFileModification()
+	// Remote sources cannot report modification, so they are always fetched again
+	if isRemotePath(currentFile.Path) {
+		return a.reopenSource(currentFile.Path, currentFile.Format, currentFile.Table, false)
+	}
+
 	if err != nil {
 		return nil, err
 	}
 
 	if !isModified {
-		return a.currentFile, nil
+		return currentFile, nil
 	}
 
 	// Reload the file
-	return a.LoadJSONLFile(a.currentFile.Path)
+	return a.reopenSource(currentFile.Path, currentFile.Format, currentFile.Table, len(currentFile.Sources) > 0)
 }
 
 // GetRecords returns a paginated subset of records with offset and limit parameters
 func (a *App) GetRecords(offset, limit int) (*PaginatedRecords, error) {
+	a.stateMu.RLock()
+	defer a.stateMu.RUnlock()
+	return a.getRecords(offset, limit)
+}
+
+// getRecords implements GetRecords; the caller holds stateMu
+func (a *App) getRecords(offset, limit int) (*PaginatedRecords, error) {
 	if a.currentFile == nil || a.cache == nil {
 		return nil, &JSONLError{
 			Message: "No file currently loaded",
@@ -618,6 +1066,7 @@ func (a *App) GetRecords(offset, limit int) (*PaginatedRecords, error) {
 			Limit:   limit,
 			Total:   totalRecords,
 			HasMore: false,
+			Version: a.cache.versionToken(),
 		}, nil
 	}
 
@@ -627,23 +1076,54 @@ func (a *App) GetRecords(offset, limit int) (*PaginatedRecords, error) {
 		endIndex = totalRecords
 	}
 
-	// Extract the requested slice of records
-	records := a.cache.records[offset:endIndex]
+	// Serve the page from the page cache when it was prefetched, otherwise extract
+	// the requested slice of records
+	a.cache.lastOffset.Store(int64(offset))
+	key := newPageKey(offset, limit, a.currentSearch())
+	var records []JSONRecord
+	if page, exists := a.cache.pages.get(key); exists {
+		records = page.Page.Records
+	} else {
+		var err error
+		records, err = a.cache.slice(offset, endIndex)
+		if err != nil {
+			return nil, &JSONLError{
+				Message: "Failed to read records",
+				Err:     err,
+			}
+		}
+	}
 
 	// Determine if there are more records available
 	hasMore := endIndex < totalRecords
 
+	// Prepare neighbouring pages, highlighted for the active search, ahead of scrolling
+	a.prefetchAdjacentPages(key)
+
 	return &PaginatedRecords{
-		Records: records,
+		Records: a.withBaselineDiffs(records),
 		Offset:  offset,
 		Limit:   limit,
 		Total:   totalRecords,
 		HasMore: hasMore,
+		Version: a.cache.versionToken(),
 	}, nil
 }
 
 // GetRecordByLineNumber retrieves a specific record by its line number
 func (a *App) GetRecordByLineNumber(lineNumber int) (*JSONRecord, error) {
+	a.stateMu.RLock()
+	defer a.stateMu.RUnlock()
+
+	record, err := a.recordByLineNumber(lineNumber)
+	if err != nil {
+		return nil, err
+	}
+	return &a.withBaselineDiffs([]JSONRecord{*record})[0], nil
+}
+
+// recordByLineNumber implements GetRecordByLineNumber; the caller holds stateMu
+func (a *App) recordByLineNumber(lineNumber int) (*JSONRecord, error) {
 	if a.currentFile == nil || a.cache == nil {
 		return nil, &JSONLError{
 			Message: "No file currently loaded",
@@ -660,10 +1140,8 @@ func (a *App) GetRecordByLineNumber(lineNumber int) (*JSONRecord, error) {
 	}
 
 	// Search for the record with the specified line number
-	for _, record := range a.cache.records {
-		if record.LineNumber == lineNumber {
-			return &record, nil
-		}
+	if record, exists := a.cache.recordByLine(lineNumber); exists {
+		return record, nil
 	}
 
 	return nil, &JSONLError{
@@ -675,6 +1153,13 @@ func (a *App) GetRecordByLineNumber(lineNumber int) (*JSONRecord, error) {
 
 // GetRecordRange returns records within a specific line number range
 func (a *App) GetRecordRange(startLine, endLine int) ([]JSONRecord, error) {
+	a.stateMu.RLock()
+	defer a.stateMu.RUnlock()
+	return a.recordRange(startLine, endLine)
+}
+
+// recordRange implements GetRecordRange; the caller holds stateMu
+func (a *App) recordRange(startLine, endLine int) ([]JSONRecord, error) {
 	if a.currentFile == nil || a.cache == nil {
 		return nil, &JSONLError{
 			Message: "No file currently loaded",
@@ -689,18 +1174,93 @@ func (a *App) GetRecordRange(startLine, endLine int) ([]JSONRecord, error) {
 		}
 	}
 
-	var result []JSONRecord
-	for _, record := range a.cache.records {
-		if record.LineNumber >= startLine && record.LineNumber <= endLine {
-			result = append(result, record)
+	result, err := a.cache.lineRange(startLine, endLine)
+	if err != nil {
+		return nil, &JSONLError{
+			Message: "Failed to read records",
+			Err:     err,
 		}
 	}
-
 	return result, nil
 }
 
+// Chunk sizes for streaming large raw records
+const (
+	defaultRecordChunkSize = 256 * 1024
+	maxRecordChunkSize     = 4 * 1024 * 1024
+)
+
+// RecordChunk is a slice of a record's raw JSON text
+type RecordChunk struct {
+	LineNumber  int    `json:"lineNumber"`
+	Offset      int    `json:"offset"`
+	Length      int    `json:"length"`
+	TotalLength int    `json:"totalLength"`
+	Data        string `json:"data"`
+	HasMore     bool   `json:"hasMore"`
+}
+
+// GetRecordChunk returns up to length bytes of a record's raw JSON starting at offset,
+// so very large records can be streamed to the frontend progressively. Chunks never
+// split a UTF-8 sequence; the next chunk starts at Offset+Length.
+func (a *App) GetRecordChunk(lineNumber, offset, length int) (*RecordChunk, error) {
+	record, err := a.GetRecordByLineNumber(lineNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	// Validate parameters
+	raw := record.RawJSON
+	if offset < 0 {
+		offset = 0
+	}
+	if offset > len(raw) {
+		offset = len(raw)
+	}
+	if length <= 0 {
+		length = defaultRecordChunkSize
+	}
+	if length > maxRecordChunkSize {
+		length = maxRecordChunkSize
+	}
+
+	// Align the start and end with rune boundaries
+	for offset > 0 && offset < len(raw) && !utf8.RuneStart(raw[offset]) {
+		offset--
+	}
+	end := offset + length
+	if end >= len(raw) {
+		end = len(raw)
+	} else {
+		for end > offset && !utf8.RuneStart(raw[end]) {
+			end--
+		}
+		if end == offset {
+			// Chunk smaller than one rune: include the whole rune
+			_, size := utf8.DecodeRuneInString(raw[offset:])
+			end = offset + size
+		}
+	}
+
+	return &RecordChunk{
+		LineNumber:  lineNumber,
+		Offset:      offset,
+		Length:      end - offset,
+		TotalLength: len(raw),
+		Data:        raw[offset:end],
+		HasMore:     end < len(raw),
+	}, nil
+}
+
 // GetTotalRecordCount returns the total number of records in the current file
 func (a *App) GetTotalRecordCount() (int, error) {
+	a.stateMu.RLock()
+	defer a.stateMu.RUnlock()
+	return a.totalRecordCount()
+}
+
+// totalRecordCount implements GetTotalRecordCount; the caller holds stateMu
+func (a *App) totalRecordCount() (int, error) {
 	if a.currentFile == nil || a.cache == nil {
 		return 0, &JSONLError{
 			Message: "No file currently loaded",
@@ -711,8 +1271,11 @@ func (a *App) GetTotalRecordCount() (int, error) {
 	return a.cache.totalCount, nil
 }
 
-// SetPageSize updates the default page size for pagination
+// SetPageSize updates the default page size for pagination, leaving auto mode
 func (a *App) SetPageSize(pageSize int) error {
+	a.stateMu.Lock()
+	defer a.stateMu.Unlock()
+
 	if a.cache == nil {
 		return &JSONLError{
 			Message: "No file currently loaded",
@@ -721,18 +1284,22 @@ func (a *App) SetPageSize(pageSize int) error {
 	}
 
 	if pageSize <= 0 {
-		pageSize = 50 // Default page size
+		pageSize = a.settings.withDefaults().DefaultPageSize
 	}
 	if pageSize > 1000 {
 		pageSize = 1000 // Cap maximum page size
 	}
 
+	a.autoPageSize = false
 	a.cache.pageSize = pageSize
 	return nil
 }
 
 // GetPageSize returns the current page size setting
 func (a *App) GetPageSize() (int, error) {
+	a.stateMu.RLock()
+	defer a.stateMu.RUnlock()
+
 	if a.cache == nil {
 		return 0, &JSONLError{
 			Message: "No file currently loaded",
@@ -743,6 +1310,23 @@ func (a *App) GetPageSize() (int, error) {
 	return a.cache.pageSize, nil
 }
 
+// loadedRecords returns the in-memory records of the current file
+func (a *App) loadedRecords() []JSONRecord {
+	a.stateMu.RLock()
+	defer a.stateMu.RUnlock()
+	return a.records
+}
+
+// loadedFile returns the current file, or nil when no file is loaded
+func (a *App) loadedFile() *JSONLFile {
+	a.stateMu.RLock()
+	defer a.stateMu.RUnlock()
+	if a.cache == nil {
+		return nil
+	}
+	return a.currentFile
+}
+
 // TestFileLoading tests the file loading functionality with the sample file
 func (a *App) TestFileLoading() (string, error) {
 	// Test loading the sample file
@@ -774,7 +1358,7 @@ func (a *App) TestFileLoading() (string, error) {
 	result += fmt.Sprintf("File Size: %d bytes\n", stats.FileSize)
 
 	result += fmt.Sprintf("\nLoaded Records:\n")
-	for i, record := range a.records {
+	for i, record := range a.loadedRecords() {
 		result += fmt.Sprintf("Line %d: %s\n", record.LineNumber, record.RawJSON)
 		if i >= 2 { // Show only first 3 records
 			break
@@ -804,7 +1388,7 @@ func (a *App) LoadJSONLFromClipboard() (*JSONLFile, error) {
 	}
 
 	// Parse the clipboard content as JSONL
-	records, stats, err := ParseJSONLFromString(clipboardContent)
+	records, stats, err := parseJSONLString(clipboardContent, a.loadOptions().keepDuplicates)
 	if err != nil {
 		return nil, &JSONLError{
 			Message: "Failed to parse clipboard content as JSONL",
@@ -831,16 +1415,25 @@ func (a *App) LoadJSONLFromClipboard() (*JSONLFile, error) {
 	}
 
 	// Store in app state
+	a.stateMu.Lock()
 	a.currentFile = jsonlFile
 	a.records = records
 
 	// Initialize cache for clipboard content
 	a.cache = &RecordCache{
 		records:    records,
-		pageSize:   50, // Default page size for virtual scrolling
+		pageSize:   a.settings.withDefaults().DefaultPageSize,
 		totalCount: len(records),
+		stats:      stats,
 	}
+	a.validationReport = nil
+	a.closeAnalytics()
+	a.applyDerivedFields()
+	a.applyPageSizeMode()
+	a.bumpDataVersion()
+	a.stateMu.Unlock()
 
+	a.audit(AuditEntry{Action: AuditFileOpened, Path: jsonlFile.Path, Records: jsonlFile.Records, Detail: "clipboard"})
 	return jsonlFile, nil
 }
 
@@ -973,7 +1566,7 @@ func (a *App) TestClipboardLoading() (string, error) {
 	// For clipboard content, we need to recalculate stats since there's no file
 	clipboardContent := strings.Join(func() []string {
 		var lines []string
-		for _, record := range a.records {
+		for _, record := range a.loadedRecords() {
 			lines = append(lines, record.RawJSON)
 		}
 		return lines
@@ -998,7 +1591,7 @@ func (a *App) TestClipboardLoading() (string, error) {
 	result += fmt.Sprintf("Common Fields: %v\n", stats.CommonFields)
 
 	result += fmt.Sprintf("\nLoaded Records:\n")
-	for i, record := range a.records {
+	for i, record := range a.loadedRecords() {
 		result += fmt.Sprintf("Line %d: %s\n", record.LineNumber, record.RawJSON)
 		if i >= 2 { // Show only first 3 records
 			break
@@ -1010,6 +1603,13 @@ func (a *App) TestClipboardLoading() (string, error) {
 
 // SearchRecords searches through records with query filtering and returns paginated results
 func (a *App) SearchRecords(options SearchOptions) (*SearchResult, error) {
+	a.stateMu.RLock()
+	defer a.stateMu.RUnlock()
+	return a.searchRecords(options)
+}
+
+// searchRecords implements SearchRecords; the caller holds stateMu
+func (a *App) searchRecords(options SearchOptions) (*SearchResult, error) {
 	if a.currentFile == nil || a.cache == nil {
 		return nil, &JSONLError{
 			Message: "No file currently loaded",
@@ -1017,6 +1617,12 @@ func (a *App) SearchRecords(options SearchOptions) (*SearchResult, error) {
 		}
 	}
 
+	if err := a.checkVersion(options.Version); err != nil {
+		return nil, err
+	}
+
+	a.setActiveSearch(options)
+
 	// Validate search options
 	if strings.TrimSpace(options.Query) == "" {
 		return &SearchResult{
@@ -1027,6 +1633,7 @@ func (a *App) SearchRecords(options SearchOptions) (*SearchResult, error) {
 			TotalMatches: 0,
 			HasMore:      false,
 			Query:        options.Query,
+			Version:      a.cache.versionToken(),
 		}, nil
 	}
 
@@ -1040,48 +1647,100 @@ func (a *App) SearchRecords(options SearchOptions) (*SearchResult, error) {
 	if options.Limit > 1000 {
 		options.Limit = 1000 // Cap maximum limit
 	}
+	var sortKeys []SortKey
+	if options.SortBy != nil {
+		key := *options.SortBy
+		if err := key.validate(); err != nil {
+			return nil, &JSONLError{
+				Message: err.Error(),
+				Err:     ErrInvalidSort,
+			}
+		}
+		sortKeys = []SortKey{key}
+	}
 
-	// Perform search
-	var matchingRecords []JSONRecord
+	scope, scoped, err := a.filterScope()
+	if err != nil {
+		return nil, err
+	}
 
-	if options.UseLucene {
-		// Use Lucene syntax parsing
-		luceneQuery := parseLuceneQuery(options.Query)
+	// In analytics mode plain searches run in DuckDB
+	if store := a.activeAnalytics(); store != nil && !scoped && analyticsSearchable(options) {
+		return a.searchAnalytics(store, options, sortKeys)
+	}
 
-		if luceneQuery != nil {
-			for _, record := range a.cache.records {
-				if a.evaluateLuceneQuery(luceneQuery, record, options.CaseSensitive) {
-					matchingRecords = append(matchingRecords, record)
-				}
-			}
-		}
-	} else {
-		// Traditional search with optional field filtering
-		query := options.Query
-		if !options.CaseSensitive {
-			query = strings.ToLower(query)
+	// Perform search
+	path, err := searchJSONPath(options)
+	if err != nil {
+		return nil, err
+	}
+	matches := a.queryPredicate(options, path)
+
+	opts := matchOptionsFor(options)
+	opts.Coercions = a.coercions
+	candidates, indexed := a.cache.searchCandidates(options, opts)
+	indexed = indexed && !scoped
+	if !indexed && !scoped && sortKeys == nil && options.Progressive && a.cache.totalCount >= a.progressiveSettings().threshold {
+		result, err := a.searchProgressive(options, matches)
+		if err != nil {
+			return nil, err
 		}
+		result.Records = a.projectSearchRecords(path, result.Records)
+		return result, nil
+	}
 
-		for _, record := range a.cache.records {
-			var matches bool
+	scan, done := a.beginSearch(options.Token, a.cache.totalCount)
+	defer done()
 
-			if options.SelectedField != "" && options.SelectedField != "all" {
-				// Field-specific search
-				if fieldValue, exists := record.Content[options.SelectedField]; exists {
-					matches = a.matchFieldValue(fieldValue, options.Query, options.CaseSensitive)
+	var matchingRecords []JSONRecord
+	switch {
+	case scoped:
+		scan.total = len(scope)
+		matchingRecords, err = filterRecords(scan, scope, matches, scan.tuning.parallelThreshold)
+	case indexed:
+		candidateRecords := make([]JSONRecord, 0, len(candidates))
+		for _, position := range candidates {
+			records, readErr := a.cache.slice(int(position), int(position)+1)
+			if readErr != nil {
+				return nil, &JSONLError{
+					Message: "Failed to read records",
+					Err:     readErr,
 				}
-			} else {
-				// Search all fields
-				matches = a.recordMatches(record, query, options.CaseSensitive)
 			}
-
-			if matches {
+			candidateRecords = append(candidateRecords, records...)
+		}
+		scan.total = len(candidateRecords)
+		matchingRecords, err = filterRecords(scan, candidateRecords, matches, scan.tuning.parallelThreshold)
+	case !a.cache.diskBacked():
+		matchingRecords, err = filterRecords(scan, a.cache.derivedRecords(), matches, scan.tuning.parallelThreshold)
+	default:
+		readErr := a.cache.forEach(func(record JSONRecord) bool {
+			matched := 0
+			if matches(record) {
 				matchingRecords = append(matchingRecords, record)
+				matched = 1
 			}
+			if !scan.step(1, matched) {
+				err = ErrSearchCancelled
+				return false
+			}
+			return true
+		})
+		if readErr != nil {
+			return nil, readErr
+		}
+	}
+	if err != nil {
+		return nil, &JSONLError{
+			Message: "Search was cancelled",
+			Err:     err,
 		}
 	}
 
+	matchingRecords = a.sortRecords(matchingRecords, sortKeys)
+
 	totalMatches := len(matchingRecords)
+	a.audit(AuditEntry{Action: AuditSearchRun, Path: a.auditPath(), Query: options.Query, Records: totalMatches})
 
 	// Apply pagination to matching records
 	startIndex := options.Offset
@@ -1094,6 +1753,7 @@ func (a *App) SearchRecords(options SearchOptions) (*SearchResult, error) {
 			TotalMatches: totalMatches,
 			HasMore:      false,
 			Query:        options.Query,
+			Version:      a.cache.versionToken(),
 		}, nil
 	}
 
@@ -1102,7 +1762,7 @@ func (a *App) SearchRecords(options SearchOptions) (*SearchResult, error) {
 		endIndex = totalMatches
 	}
 
-	paginatedRecords := matchingRecords[startIndex:endIndex]
+	paginatedRecords := a.projectSearchRecords(path, matchingRecords[startIndex:endIndex])
 	hasMore := endIndex < totalMatches
 
 	return &SearchResult{
@@ -1113,196 +1773,218 @@ func (a *App) SearchRecords(options SearchOptions) (*SearchResult, error) {
 		TotalMatches: totalMatches,
 		HasMore:      hasMore,
 		Query:        options.Query,
+		Version:      a.cache.versionToken(),
 	}, nil
 }
 
-// recordMatches checks if a record matches the search query
-func (a *App) recordMatches(record JSONRecord, query string, caseSensitive bool) bool {
-	// Search in raw JSON string
-	searchText := record.RawJSON
-	if !caseSensitive {
-		searchText = strings.ToLower(searchText)
+// searchPredicate builds the function deciding whether a record matches the search
+// options, parsing the query once up front. An invalid JSONPath query is an error.
+func (a *App) searchPredicate(options SearchOptions) (func(JSONRecord) bool, error) {
+	path, err := searchJSONPath(options)
+	if err != nil {
+		return nil, err
 	}
+	return a.queryPredicate(options, path), nil
+}
 
-	if strings.Contains(searchText, query) {
-		return true
+// searchJSONPath parses the query of a JSONPath search, returning nil for other
+// searches
+func searchJSONPath(options SearchOptions) (jsonPath, error) {
+	if !options.UseJSONPath {
+		return nil, nil
 	}
-
-	// Also search in individual field values for more precise matching
-	for _, value := range record.Content {
-		valueStr := fmt.Sprintf("%v", value)
-		if !caseSensitive {
-			valueStr = strings.ToLower(valueStr)
-		}
-		if strings.Contains(valueStr, query) {
-			return true
+	path, err := parseJSONPath(options.Query)
+	if err != nil {
+		return nil, &JSONLError{
+			Message: fmt.Sprintf("Invalid JSONPath: %v", err),
+			Err:     ErrInvalidJSONPath,
 		}
 	}
-
-	return false
+	return path, nil
 }
 
-// parseLuceneQuery parses a Lucene query string into a structured query
-func parseLuceneQuery(query string) *LuceneQuery {
-	if strings.TrimSpace(query) == "" {
-		return nil
-	}
+// queryPredicate implements searchPredicate, given the parsed path of a JSONPath
+// search
+func (a *App) queryPredicate(options SearchOptions, path jsonPath) func(JSONRecord) bool {
+	opts := matchOptionsFor(options)
+	opts.Coercions = a.coercions
 
-	query = strings.TrimSpace(query)
-
-	// Handle OR operator
-	if strings.Contains(query, " OR ") {
-		parts := strings.Split(query, " OR ")
-		if len(parts) >= 2 {
-			// For multiple OR conditions, create left-associative tree
-			left := parseLuceneQuery(strings.TrimSpace(parts[0]))
-			for i := 1; i < len(parts); i++ {
-				right := parseLuceneQuery(strings.TrimSpace(parts[i]))
-				left = &LuceneQuery{
-					Type:  "or",
-					Left:  left,
-					Right: right,
-				}
-			}
-			return left
-		}
-	}
-
-	// Handle AND operator
-	if strings.Contains(query, " AND ") {
-		parts := strings.Split(query, " AND ")
-		if len(parts) >= 2 {
-			// For multiple AND conditions, create left-associative tree
-			left := parseLuceneQuery(strings.TrimSpace(parts[0]))
-			for i := 1; i < len(parts); i++ {
-				right := parseLuceneQuery(strings.TrimSpace(parts[i]))
-				left = &LuceneQuery{
-					Type:  "and",
-					Left:  left,
-					Right: right,
-				}
-			}
-			return left
+	if options.UseJSONPath {
+		return func(record JSONRecord) bool {
+			return len(path.evaluate(jsonPathInput(record))) > 0
 		}
 	}
 
-	// Handle NOT operator
-	if strings.HasPrefix(query, "NOT ") {
-		return &LuceneQuery{
-			Type:  "not",
-			Query: parseLuceneQuery(strings.TrimSpace(query[4:])),
+	if options.UseLucene {
+		// Use Lucene syntax parsing
+		luceneQuery := parseLuceneQuery(options.Query)
+		if luceneQuery == nil {
+			return func(JSONRecord) bool { return false }
+		}
+		if queryUsesField(luceneQuery, timeQueryField) {
+			field := a.queryTimestampField(options.TimestampField)
+			luceneQuery = withTimestampField(luceneQuery, field)
+			opts.Coercions = withTimestampCoercion(opts.Coercions, field)
+		}
+		return func(record JSONRecord) bool {
+			return a.evaluateQuery(luceneQuery, record, opts)
 		}
 	}
 
-	// Handle field:value syntax
-	if strings.Contains(query, ":") {
-		parts := strings.SplitN(query, ":", 2)
-		if len(parts) == 2 {
-			field := strings.TrimSpace(parts[0])
-			value := strings.TrimSpace(parts[1])
-
-			// Handle quoted phrases
-			if strings.HasPrefix(value, "\"") && strings.HasSuffix(value, "\"") && len(value) > 1 {
-				return &LuceneQuery{
-					Type:  "phrase",
-					Field: field,
-					Value: value[1 : len(value)-1],
-				}
-			}
-
-			// Handle wildcards
-			if strings.Contains(value, "*") || strings.Contains(value, "?") {
-				return &LuceneQuery{
-					Type:  "wildcard",
-					Field: field,
-					Value: value,
-				}
-			}
+	// Traditional search with optional field filtering
+	fields := searchFields(options.SelectedFields)
+	return func(record JSONRecord) bool {
+		if len(fields) == 0 {
+			// Search all fields
+			return a.recordMatchesWith(record, options.Query, opts)
+		}
 
-			return &LuceneQuery{
-				Type:  "field",
-				Field: field,
-				Value: value,
+		// Field-specific search: any of the selected fields may match
+		for _, field := range fields {
+			matched := anyFieldValue(record.Content, field, func(fieldValue interface{}) bool {
+				return opts.matchField(field, fieldValue, options.Query)
+			})
+			if matched {
+				return true
 			}
 		}
+		return false
 	}
+}
 
-	// Handle quoted phrases
-	if strings.HasPrefix(query, "\"") && strings.HasSuffix(query, "\"") && len(query) > 1 {
-		return &LuceneQuery{
-			Type:  "phrase",
-			Value: query[1 : len(query)-1],
-		}
+// projectSearchRecords replaces a page of JSONPath matches with the values the
+// path selects; other searches return the records as they are
+func (a *App) projectSearchRecords(path jsonPath, records []JSONRecord) []JSONRecord {
+	if path == nil {
+		return records
 	}
+	return projectJSONPath(path, records)
+}
 
-	// Handle wildcards
-	if strings.Contains(query, "*") || strings.Contains(query, "?") {
-		return &LuceneQuery{
-			Type:  "wildcard",
-			Value: query,
+// searchFields returns the fields a search is restricted to, or nil when every
+// field should be searched (no selection, or "all" selected)
+func searchFields(selected []string) []string {
+	var fields []string
+	for _, field := range selected {
+		field = strings.TrimSpace(field)
+		if field == "all" {
+			return nil
+		}
+		if field != "" {
+			fields = append(fields, field)
 		}
 	}
+	return fields
+}
 
-	// Default term search
-	return &LuceneQuery{
-		Type:  "term",
-		Value: query,
+// recordMatches checks if a record matches the search query
+func (a *App) recordMatches(record JSONRecord, query string, caseSensitive bool) bool {
+	return a.recordMatchesWith(record, query, matchOptions{CaseSensitive: caseSensitive})
+}
+
+// recordMatchesWith checks if a record matches the search query using the given match options
+func (a *App) recordMatchesWith(record JSONRecord, query string, opts matchOptions) bool {
+	query = opts.normalize(query)
+
+	// Search in raw JSON string
+	if opts.contains(opts.normalize(record.RawJSON), query) {
+		return true
 	}
+
+	// Also search the decoded values, which can differ from their escaped form in
+	// the raw JSON
+	return recordText(record, func(text string) bool {
+		return opts.contains(opts.normalize(text), query)
+	})
 }
 
 // evaluateLuceneQuery evaluates a Lucene query against a record
 func (a *App) evaluateLuceneQuery(query *LuceneQuery, record JSONRecord, caseSensitive bool) bool {
+	return a.evaluateQuery(query, record, matchOptions{CaseSensitive: caseSensitive})
+}
+
+// evaluateQuery evaluates a Lucene query against a record using the given match options
+func (a *App) evaluateQuery(query *LuceneQuery, record JSONRecord, opts matchOptions) bool {
 	if query == nil {
 		return false
 	}
 
 	switch query.Type {
 	case "and":
-		return a.evaluateLuceneQuery(query.Left, record, caseSensitive) &&
-			a.evaluateLuceneQuery(query.Right, record, caseSensitive)
+		return a.evaluateQuery(query.Left, record, opts) &&
+			a.evaluateQuery(query.Right, record, opts)
 
 	case "or":
-		return a.evaluateLuceneQuery(query.Left, record, caseSensitive) ||
-			a.evaluateLuceneQuery(query.Right, record, caseSensitive)
+		return a.evaluateQuery(query.Left, record, opts) ||
+			a.evaluateQuery(query.Right, record, opts)
 
 	case "not":
-		return !a.evaluateLuceneQuery(query.Query, record, caseSensitive)
+		return !a.evaluateQuery(query.Query, record, opts)
 
 	case "field":
-		if fieldValue, exists := record.Content[query.Field]; exists {
-			return a.matchFieldValue(fieldValue, query.Value, caseSensitive)
-		}
-		return false
+		return anyFieldValue(record.Content, query.Field, func(fieldValue interface{}) bool {
+			return opts.matchField(query.Field, fieldValue, query.Value)
+		})
 
 	case "phrase":
 		if query.Field != "" {
-			if fieldValue, exists := record.Content[query.Field]; exists {
-				return a.matchPhrase(fmt.Sprintf("%v", fieldValue), query.Value, caseSensitive)
-			}
-			return false
+			return anyFieldValue(record.Content, query.Field, func(fieldValue interface{}) bool {
+				return matchValueText(fieldValue, func(text string) bool {
+					return opts.matchPhrase(text, query.Value)
+				})
+			})
 		} else {
-			return a.matchPhrase(record.RawJSON, query.Value, caseSensitive)
+			return opts.matchPhrase(record.RawJSON, query.Value)
 		}
 
 	case "wildcard":
 		if query.Field != "" {
-			if fieldValue, exists := record.Content[query.Field]; exists {
-				return a.matchWildcard(fmt.Sprintf("%v", fieldValue), query.Value, caseSensitive)
-			}
-			return false
+			return anyFieldValue(record.Content, query.Field, func(fieldValue interface{}) bool {
+				return matchValueText(fieldValue, func(text string) bool {
+					return opts.matchWildcard(text, query.Value)
+				})
+			})
 		} else {
-			return a.matchWildcard(record.RawJSON, query.Value, caseSensitive)
+			// The pattern spans the whole raw JSON or any one decoded value
+			return opts.matchWildcard(record.RawJSON, query.Value) ||
+				recordText(record, func(text string) bool {
+					return opts.matchWildcard(text, query.Value)
+				})
 		}
 
 	case "term":
 		if query.Field != "" {
-			if fieldValue, exists := record.Content[query.Field]; exists {
-				return a.matchFieldValue(fieldValue, query.Value, caseSensitive)
-			}
-			return false
+			return anyFieldValue(record.Content, query.Field, func(fieldValue interface{}) bool {
+				return opts.matchField(query.Field, fieldValue, query.Value)
+			})
 		} else {
-			return a.matchTerm(record.RawJSON, query.Value, caseSensitive)
+			return opts.matchTerm(record.RawJSON, query.Value)
+		}
+
+	case "range":
+		return anyFieldValue(record.Content, query.Field, func(fieldValue interface{}) bool {
+			return opts.matchRange(query, fieldValue)
+		})
+
+	case "exists":
+		return anyFieldValue(record.Content, query.Field, func(fieldValue interface{}) bool {
+			return fieldValue != nil
+		})
+
+	case "exact":
+		// The whole value must be equal, case included, as facets count it
+		value, exists := getFieldValue(record.Content, query.Field)
+		return exists && exactText(value) == query.Value
+
+	case "fuzzy":
+		if query.Field != "" {
+			return anyFieldValue(record.Content, query.Field, func(fieldValue interface{}) bool {
+				return fieldValue != nil && matchValueText(fieldValue, func(text string) bool {
+					return opts.matchFuzzy(text, query.Value, query.Distance)
+				})
+			})
 		}
+		return opts.matchFuzzy(record.RawJSON, query.Value, query.Distance)
 
 	default:
 		return false
@@ -1311,165 +1993,156 @@ func (a *App) evaluateLuceneQuery(query *LuceneQuery, record JSONRecord, caseSen
 
 // matchFieldValue checks if a field value matches the search value
 func (a *App) matchFieldValue(fieldValue interface{}, searchValue string, caseSensitive bool) bool {
+	return matchOptions{CaseSensitive: caseSensitive}.matchFieldValue(fieldValue, searchValue)
+}
+
+// matchFieldValue checks if a field value matches the search value
+func (o matchOptions) matchFieldValue(fieldValue interface{}, searchValue string) bool {
 	if fieldValue == nil {
 		return false
 	}
 
-	fieldStr := fmt.Sprintf("%v", fieldValue)
-	searchStr := searchValue
-	targetStr := fieldStr
-
-	if !caseSensitive {
-		searchStr = strings.ToLower(searchStr)
-		targetStr = strings.ToLower(targetStr)
-	}
-
-	return strings.Contains(targetStr, searchStr)
+	searchValue = o.normalize(searchValue)
+	return matchValueText(fieldValue, func(text string) bool {
+		return o.contains(o.normalize(text), searchValue)
+	})
 }
 
 // matchPhrase checks if text contains the exact phrase
 func (a *App) matchPhrase(text, phrase string, caseSensitive bool) bool {
+	return matchOptions{CaseSensitive: caseSensitive}.matchPhrase(text, phrase)
+}
+
+// matchPhrase checks if text contains the exact phrase
+func (o matchOptions) matchPhrase(text, phrase string) bool {
 	if text == "" {
 		return false
 	}
 
-	searchStr := phrase
-	targetStr := text
-
-	if !caseSensitive {
-		searchStr = strings.ToLower(searchStr)
-		targetStr = strings.ToLower(targetStr)
-	}
-
-	return strings.Contains(targetStr, searchStr)
+	return o.contains(o.normalize(text), o.normalize(phrase))
 }
 
 // matchWildcard checks if text matches a wildcard pattern
 func (a *App) matchWildcard(text, pattern string, caseSensitive bool) bool {
+	return matchOptions{CaseSensitive: caseSensitive}.matchWildcard(text, pattern)
+}
+
+// matchWildcard checks if text matches a wildcard pattern
+func (o matchOptions) matchWildcard(text, pattern string) bool {
 	if text == "" {
 		return false
 	}
 
-	searchStr := pattern
-	targetStr := text
-
-	if !caseSensitive {
-		searchStr = strings.ToLower(searchStr)
-		targetStr = strings.ToLower(targetStr)
-	}
-
-	// Convert wildcard pattern to regex
-	regexPattern := strings.ReplaceAll(searchStr, "*", ".*")
-	regexPattern = strings.ReplaceAll(regexPattern, "?", ".")
-	regexPattern = "^" + regexPattern + "$"
-
-	// Use simple pattern matching instead of regex for better performance
-	return a.simpleWildcardMatch(targetStr, searchStr)
+	return wildcardMatch(o.normalize(text), o.normalize(pattern))
 }
 
-// simpleWildcardMatch performs simple wildcard matching
-func (a *App) simpleWildcardMatch(text, pattern string) bool {
-	// Simple implementation for * and ? wildcards
-	if pattern == "*" {
-		return true
-	}
-
-	if !strings.Contains(pattern, "*") && !strings.Contains(pattern, "?") {
+// wildcardMatch reports whether the whole text matches a pattern in which * stands
+// for any run of characters and ? for exactly one. A pattern without wildcards
+// matches anywhere in the text.
+func wildcardMatch(text, pattern string) bool {
+	if !strings.ContainsAny(pattern, "*?") {
 		return strings.Contains(text, pattern)
 	}
 
-	// For complex patterns, use basic matching
-	if strings.HasPrefix(pattern, "*") && strings.HasSuffix(pattern, "*") {
-		middle := pattern[1 : len(pattern)-1]
-		return strings.Contains(text, middle)
-	}
-
-	if strings.HasPrefix(pattern, "*") {
-		suffix := pattern[1:]
-		return strings.HasSuffix(text, suffix)
+	// Greedy matching that backtracks to the last star, retrying it with one more
+	// character each time; linear unless stars have to be retried
+	t, p := 0, 0
+	star, starText := -1, 0
+	for t < len(text) {
+		if p < len(pattern) {
+			switch pattern[p] {
+			case '*':
+				star, starText = p, t
+				p++
+				continue
+			case '?':
+				_, size := utf8.DecodeRuneInString(text[t:])
+				t += size
+				p++
+				continue
+			default:
+				if pattern[p] == text[t] {
+					t++
+					p++
+					continue
+				}
+			}
+		}
+		if star < 0 {
+			return false
+		}
+		_, size := utf8.DecodeRuneInString(text[starText:])
+		starText += size
+		t, p = starText, star+1
 	}
 
-	if strings.HasSuffix(pattern, "*") {
-		prefix := pattern[:len(pattern)-1]
-		return strings.HasPrefix(text, prefix)
+	for p < len(pattern) && pattern[p] == '*' {
+		p++
 	}
-
-	return strings.Contains(text, strings.ReplaceAll(pattern, "*", ""))
+	return p == len(pattern)
 }
 
 // matchTerm checks if text contains the search term
 func (a *App) matchTerm(text, term string, caseSensitive bool) bool {
+	return matchOptions{CaseSensitive: caseSensitive}.matchTerm(text, term)
+}
+
+// matchTerm checks if text contains the search term
+func (o matchOptions) matchTerm(text, term string) bool {
 	if text == "" {
 		return false
 	}
 
-	searchStr := term
-	targetStr := text
-
-	if !caseSensitive {
-		searchStr = strings.ToLower(searchStr)
-		targetStr = strings.ToLower(targetStr)
-	}
-
-	return strings.Contains(targetStr, searchStr)
+	return o.contains(o.normalize(text), o.normalize(term))
 }
 
-// GetSearchHighlights returns highlighting information for search matches in a record
+// GetSearchHighlights returns highlighting information for search matches in a record:
+// every occurrence of the query in the raw JSON, then every occurrence in each
+// decoded value. Positions are byte offsets into the raw JSON and paths are JSON
+// pointers.
 func (a *App) GetSearchHighlights(record JSONRecord, query string, caseSensitive bool) ([]HighlightMatch, error) {
 	if strings.TrimSpace(query) == "" {
 		return []HighlightMatch{}, nil
 	}
 
+	opts := matchOptions{CaseSensitive: caseSensitive}
+	tokens := scanJSONTokens(record.RawJSON)
 	var highlights []HighlightMatch
-	searchQuery := query
-	if !caseSensitive {
-		searchQuery = strings.ToLower(searchQuery)
-	}
-
-	// Find matches in raw JSON
-	searchText := record.RawJSON
-	if !caseSensitive {
-		searchText = strings.ToLower(searchText)
-	}
 
 	// Find all occurrences of the query in the raw JSON
-	startPos := 0
-	for {
-		index := strings.Index(searchText[startPos:], searchQuery)
-		if index == -1 {
-			break
-		}
-
-		actualPos := startPos + index
-		highlights = append(highlights, HighlightMatch{
-			Text:      record.RawJSON[actualPos : actualPos+len(query)],
-			StartPos:  actualPos,
-			EndPos:    actualPos + len(query),
+	for _, match := range opts.findMatches(record.RawJSON, query) {
+		highlight := HighlightMatch{
+			Text:      record.RawJSON[match[0]:match[1]],
+			StartPos:  match[0],
+			EndPos:    match[1],
 			FieldName: "raw",
-		})
-
-		startPos = actualPos + len(searchQuery)
+		}
+		if token, found := tokenAt(tokens, match[0]); found {
+			highlight.Path = token.path
+		}
+		highlights = append(highlights, highlight)
 	}
 
-	// Find matches in individual fields
-	for fieldName, value := range record.Content {
-		valueStr := fmt.Sprintf("%v", value)
-		searchValueStr := valueStr
-		if !caseSensitive {
-			searchValueStr = strings.ToLower(searchValueStr)
+	// Find the occurrences in each value once decoded, nested ones included
+	for _, token := range tokens {
+		if token.key {
+			continue
 		}
-
-		if strings.Contains(searchValueStr, searchQuery) {
-			// Find the position in the original raw JSON
-			fieldStartPos := strings.Index(record.RawJSON, fmt.Sprintf("\"%s\"", fieldName))
-			if fieldStartPos != -1 {
-				highlights = append(highlights, HighlightMatch{
-					Text:      valueStr,
-					StartPos:  fieldStartPos,
-					EndPos:    fieldStartPos + len(valueStr),
-					FieldName: fieldName,
-				})
-			}
+		text, rawRange := decodeToken(record.RawJSON, token)
+		if text == "" {
+			continue
+		}
+		for _, match := range opts.findMatches(text, query) {
+			start, end := rawRange(match[0], match[1])
+			highlights = append(highlights, HighlightMatch{
+				Text:       text[match[0]:match[1]],
+				StartPos:   start,
+				EndPos:     end,
+				FieldName:  token.field,
+				Path:       token.path,
+				ValueStart: match[0],
+				ValueEnd:   match[1],
+			})
 		}
 	}
 
@@ -1498,7 +2171,11 @@ func (a *App) SearchRecordsWithHighlights(options SearchOptions) (*SearchResult,
 }
 
 // GetCommonFields analyzes and returns common field names across all records
-func (a *App) GetCommonFields() ([]string, error) {
+// using the strategy and threshold given in options
+func (a *App) GetCommonFields(options CommonFieldOptions) ([]string, error) {
+	a.stateMu.RLock()
+	defer a.stateMu.RUnlock()
+
 	if a.currentFile == nil || a.cache == nil {
 		return nil, &JSONLError{
 			Message: "No file currently loaded",
@@ -1507,29 +2184,31 @@ func (a *App) GetCommonFields() ([]string, error) {
 	}
 
 	fieldCounts := make(map[string]int)
-	totalRecords := len(a.cache.records)
+	totalRecords := a.cache.totalCount
 
 	// Count occurrences of each field
-	for _, record := range a.cache.records {
+	err := a.cache.forEach(func(record JSONRecord) bool {
 		for fieldName := range record.Content {
 			fieldCounts[fieldName]++
 		}
+		return true
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	// Calculate common fields (fields that appear in at least 50% of records)
-	var commonFields []string
-	threshold := totalRecords / 2
-	for field, count := range fieldCounts {
-		if count >= threshold {
-			commonFields = append(commonFields, field)
-		}
-	}
-
-	return commonFields, nil
+	return selectCommonFields(fieldCounts, totalRecords, options), nil
 }
 
 // GetAllFields returns all unique field names found across all records
 func (a *App) GetAllFields() ([]string, error) {
+	a.stateMu.RLock()
+	defer a.stateMu.RUnlock()
+	return a.allFields()
+}
+
+// allFields implements GetAllFields; the caller holds stateMu
+func (a *App) allFields() ([]string, error) {
 	if a.currentFile == nil || a.cache == nil {
 		return nil, &JSONLError{
 			Message: "No file currently loaded",
@@ -1540,10 +2219,14 @@ func (a *App) GetAllFields() ([]string, error) {
 	// Collect all unique field names
 	fieldSet := make(map[string]bool)
 
-	for _, record := range a.cache.records {
+	err := a.cache.forEach(func(record JSONRecord) bool {
 		for field := range record.Content {
 			fieldSet[field] = true
 		}
+		return true
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	// Convert to sorted slice
@@ -1657,7 +2340,7 @@ func (a *App) TestSearchFunctionality() (string, error) {
 	result += fmt.Sprintf("  Records returned: %d\n", len(searchResult5.Records))
 
 	// Test 6: Get common fields
-	commonFields, err := a.GetCommonFields()
+	commonFields, err := a.GetCommonFields(CommonFieldOptions{})
 	if err != nil {
 		return "", err
 	}
@@ -1718,6 +2401,7 @@ func (a *App) TestFileModificationDetection() (string, error) {
 	result += fmt.Sprintf("  Testing clipboard modification detection logic\n")
 
 	// Simulate clipboard content by creating a temporary JSONLFile
+	a.stateMu.Lock()
 	originalFile := a.currentFile
 	a.currentFile = &JSONLFile{
 		Name:       "Clipboard Content",
@@ -1727,6 +2411,7 @@ func (a *App) TestFileModificationDetection() (string, error) {
 		LoadedAt:   time.Now(),
 		ModifiedAt: time.Now(),
 	}
+	a.stateMu.Unlock()
 
 	clipboardModified, err := a.CheckFileModification()
 	if err != nil {
@@ -1743,31 +2428,29 @@ func (a *App) TestFileModificationDetection() (string, error) {
 	}
 
 	// Restore original file
+	a.stateMu.Lock()
 	a.currentFile = originalFile
+	a.stateMu.Unlock()
 
 	return result, nil
 }
 
 // ExportSearchResults exports all search results to a JSONL file
 func (a *App) ExportSearchResults(searchQuery string, shownFields []string, hiddenFields []string) (string, error) {
-	// Get user's downloads directory
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return "", fmt.Errorf("failed to get home directory: %w", err)
-	}
-
-	downloadsDir := filepath.Join(homeDir, "Downloads")
+	a.stateMu.RLock()
+	defer a.stateMu.RUnlock()
+	return a.exportRecords(searchQuery, shownFields, hiddenFields, nil)
+}
 
-	// Create downloads directory if it doesn't exist
-	if err := os.MkdirAll(downloadsDir, 0755); err != nil {
-		return "", fmt.Errorf("failed to create downloads directory: %w", err)
+// exportRecords writes all records matching searchQuery to a new JSONL file in the
+// Downloads directory, applying the optional transform before field visibility.
+// The caller holds stateMu.
+func (a *App) exportRecords(searchQuery string, shownFields []string, hiddenFields []string, transform func(JSONRecord) JSONRecord) (string, error) {
+	filepath, err := a.exportFilePath("jsonl-viewer-export", "jsonl")
+	if err != nil {
+		return "", err
 	}
 
-	// Generate filename with timestamp
-	timestamp := time.Now().Format("2006-01-02T15-04-05")
-	filename := fmt.Sprintf("jsonl-viewer-export-%s.jsonl", timestamp)
-	filepath := filepath.Join(downloadsDir, filename)
-
 	// Create the file
 	file, err := os.Create(filepath)
 	if err != nil {
@@ -1779,7 +2462,7 @@ func (a *App) ExportSearchResults(searchQuery string, shownFields []string, hidd
 	fmt.Printf("Export: searchQuery='%s', shownFields=%v, hiddenFields=%v\n", searchQuery, shownFields, hiddenFields)
 
 	// Get all records (not just current page)
-	allRecords, err := a.GetAllRecords(searchQuery)
+	allRecords, err := a.allRecords(searchQuery)
 	if err != nil {
 		return "", fmt.Errorf("failed to get all records: %w", err)
 	}
@@ -1789,6 +2472,10 @@ func (a *App) ExportSearchResults(searchQuery string, shownFields []string, hidd
 	// Process each record and write to file
 	exportedCount := 0
 	for _, record := range allRecords {
+		if transform != nil {
+			record = transform(record)
+		}
+
 		// Apply field visibility filtering
 		displayJSON := a.getDisplayJSON(record, shownFields, hiddenFields)
 		_, err := file.WriteString(displayJSON + "\n")
@@ -1799,75 +2486,162 @@ func (a *App) ExportSearchResults(searchQuery string, shownFields []string, hidd
 	}
 
 	fmt.Printf("Export: Successfully exported %d records to %s\n", exportedCount, filepath)
+	a.audit(AuditEntry{Action: AuditExportSaved, Path: a.auditPath(), Query: searchQuery, Records: exportedCount, Destination: filepath, Detail: "jsonl"})
 	return filepath, nil
 }
 
+// exportFilePath returns a timestamped file path in the export directory of the
+// settings, or the user's Downloads directory, creating the directory if needed.
+// The caller holds stateMu.
+func (a *App) exportFilePath(prefix, extension string) (string, error) {
+	downloadsDir := a.settings.ExportDirectory
+	if downloadsDir == "" {
+		// Get user's downloads directory
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to get home directory: %w", err)
+		}
+		downloadsDir = filepath.Join(homeDir, "Downloads")
+	}
+
+	// Create downloads directory if it doesn't exist
+	if err := os.MkdirAll(downloadsDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create downloads directory: %w", err)
+	}
+
+	// Generate filename with timestamp
+	timestamp := time.Now().Format("2006-01-02T15-04-05")
+	filename := fmt.Sprintf("%s-%s.%s", prefix, timestamp, extension)
+	return filepath.Join(downloadsDir, filename), nil
+}
+
+// exportMatcher returns the predicate selecting exported records: every record for
+// an empty query, otherwise those matching it as a Lucene query, falling back to
+// simple search when it does not parse. Case sensitivity follows the settings.
+// The caller holds stateMu.
+func (a *App) exportMatcher(searchQuery string) func(JSONRecord) bool {
+	if searchQuery == "" {
+		return func(JSONRecord) bool { return true }
+	}
+
+	luceneQuery := parseLuceneQuery(searchQuery)
+	if luceneQuery != nil {
+		return func(record JSONRecord) bool {
+			return a.evaluateLuceneQuery(luceneQuery, record, a.settings.CaseSensitive)
+		}
+	}
+	return func(record JSONRecord) bool {
+		return a.recordMatches(record, searchQuery, a.settings.CaseSensitive)
+	}
+}
+
 // GetAllRecords gets all records that match the search query
 func (a *App) GetAllRecords(searchQuery string) ([]JSONRecord, error) {
+	a.stateMu.RLock()
+	defer a.stateMu.RUnlock()
+	return a.allRecords(searchQuery)
+}
+
+// allRecords implements GetAllRecords; the caller holds stateMu
+func (a *App) allRecords(searchQuery string) ([]JSONRecord, error) {
 	if a.currentFile == nil {
 		return nil, fmt.Errorf("no file loaded")
 	}
 
 	fmt.Printf("GetAllRecords: Reading file %s with searchQuery='%s'\n", a.currentFile.Path, searchQuery)
 
-	// Read all records from file
+	matches := a.exportMatcher(searchQuery)
+
+	// Remote sources are not downloaded again, other formats are not read as lines
+	// and sorted records keep their order; the loaded records are exported
+	if a.cache != nil && (isRemotePath(a.currentFile.Path) || a.currentFile.Format != "" || a.cache.sortKeys != nil) {
+		var allRecords []JSONRecord
+		err := a.cache.forEach(func(record JSONRecord) bool {
+			if matches(record) {
+				allRecords = append(allRecords, record)
+			}
+			return true
+		})
+		if err != nil {
+			return nil, err
+		}
+		return allRecords, nil
+	}
+
+	// Read all records from file, or from each file of a merged dataset in turn
 	var allRecords []JSONRecord
 	lineNumber := 1
 	totalLines := 0
 	validLines := 0
 
-	file, err := os.Open(a.currentFile.Path)
-	if err != nil {
-		return nil, fmt.Errorf("failed to open file: %w", err)
+	paths := []string{a.currentFile.Path}
+	merged := len(a.currentFile.Sources) > 0
+	if merged {
+		paths = a.currentFile.Sources
 	}
-	defer file.Close()
 
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		totalLines++
-		line := strings.TrimSpace(scanner.Text())
-		if line == "" {
-			lineNumber++
-			continue
+	for _, path := range paths {
+		file, err := openJSONLSource(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open file: %w", err)
 		}
 
-		var jsonData map[string]interface{}
-		if err := json.Unmarshal([]byte(line), &jsonData); err != nil {
-			lineNumber++
-			continue
-		}
+		sourceLine := 0
+		lines := newLineReader(file, a.maxLineSize)
+		for {
+			raw, tooLong, err := lines.next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				file.Close()
+				return nil, fmt.Errorf("error reading file: %w", err)
+			}
 
-		validLines++
-		record := JSONRecord{
-			LineNumber: lineNumber,
-			Content:    jsonData,
-			RawJSON:    line,
-		}
+			totalLines++
+			sourceLine++
+			line := strings.TrimSpace(string(raw))
+			if line == "" || tooLong {
+				lineNumber++
+				continue
+			}
 
-		// If there's a search query, check if record matches using Lucene syntax
-		if searchQuery != "" {
-			// Parse Lucene query
-			luceneQuery := parseLuceneQuery(searchQuery)
-			if luceneQuery != nil {
-				if !a.evaluateLuceneQuery(luceneQuery, record, false) {
-					lineNumber++
-					continue
-				}
-			} else {
-				// Fallback to simple search if Lucene parsing fails
-				if !a.recordMatches(record, searchQuery, false) {
-					lineNumber++
-					continue
-				}
+			jsonData, value, err := parseRecordLine(line)
+			repaired := false
+			if err != nil && a.lenientParsing {
+				jsonData, value, line, err = parseLenientLine(line)
+				repaired = err == nil
+			}
+			if err != nil {
+				lineNumber++
+				continue
 			}
-		}
 
-		allRecords = append(allRecords, record)
-		lineNumber++
-	}
+			validLines++
+			a.deriveFields(jsonData)
+			record := JSONRecord{
+				LineNumber: lineNumber,
+				Content:    jsonData,
+				RawJSON:    line,
+				Value:      value,
+				Repaired:   repaired,
+			}
+			if merged {
+				record.SourceFile = path
+				record.SourceLine = sourceLine
+			}
+
+			// If there's a search query, check if record matches using Lucene syntax
+			if !matches(record) {
+				lineNumber++
+				continue
+			}
+
+			allRecords = append(allRecords, record)
+			lineNumber++
+		}
 
-	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("error reading file: %w", err)
+		file.Close()
 	}
 
 	fmt.Printf("GetAllRecords: Total lines=%d, valid lines=%d, matched lines=%d\n", totalLines, validLines, len(allRecords))
@@ -1876,8 +2650,9 @@ func (a *App) GetAllRecords(searchQuery string) ([]JSONRecord, error) {
 
 // getDisplayJSON applies field visibility filtering to a record
 func (a *App) getDisplayJSON(record JSONRecord, shownFields []string, hiddenFields []string) string {
-	// If no field visibility is set, return the original JSON
-	if len(shownFields) == 0 && len(hiddenFields) == 0 {
+	// If no field visibility is set and no virtual fields are defined, return the original
+	// JSON; non-object records have no fields to filter
+	if (len(shownFields) == 0 && len(hiddenFields) == 0 && !a.hasDerivedFields()) || record.Content == nil {
 		return record.RawJSON
 	}
 
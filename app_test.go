@@ -1,7 +1,10 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -206,6 +209,30 @@ func TestEvaluateLuceneQuery(t *testing.T) {
 			expected:      true,
 			description:   "Should match wildcard pattern in raw JSON",
 		},
+		{
+			name: "WildcardQueryGlobalValueMatch",
+			query: &LuceneQuery{
+				Type:  "wildcard",
+				Field: "",
+				Value: "jo?n.*@*.com",
+			},
+			record:        testRecord1,
+			caseSensitive: false,
+			expected:      true,
+			description:   "Should match wildcard pattern against a whole field value",
+		},
+		{
+			name: "WildcardQueryInteriorStarNoMatch",
+			query: &LuceneQuery{
+				Type:  "wildcard",
+				Field: "company",
+				Value: "T*X*Z",
+			},
+			record:        testRecord1,
+			caseSensitive: false,
+			expected:      false,
+			description:   "Should require every part of the pattern in order",
+		},
 		{
 			name: "WildcardQueryNoMatch",
 			query: &LuceneQuery{
@@ -553,6 +580,10 @@ func TestMatchFieldValue(t *testing.T) {
 		{"Nil field value", nil, "test", false, false},
 		{"Number field", 123, "23", false, true},
 		{"Boolean field", true, "true", false, true},
+		{"Nested object as JSON", map[string]interface{}{"b": json.Number("1")}, `"b":1`, false, true},
+		{"Nested key", map[string]interface{}{"user": map[string]interface{}{"Name": "x"}}, "name", false, true},
+		{"Nested array value", []interface{}{map[string]interface{}{"tag": "Admin"}}, "admin", false, true},
+		{"Go map syntax", map[string]interface{}{"b": "c"}, "map[b:c]", false, false},
 	}
 
 	for _, tt := range tests {
@@ -607,7 +638,14 @@ func TestMatchWildcard(t *testing.T) {
 	}{
 		{"Star wildcard", "hello.txt", "*.txt", false, true},
 		{"Star wildcard no match", "hello.doc", "*.txt", false, false},
-		{"Question mark wildcard", "test", "t?st", false, false}, // Current implementation doesn't support ? wildcards
+		{"Question mark wildcard", "test", "t?st", false, true},
+		{"Question mark is one character", "toast", "t?st", false, false},
+		{"Question mark on multibyte character", "tést", "t?st", false, true},
+		{"Interior stars", "alpha beta gamma", "a*b*a", false, true},
+		{"Interior stars out of order", "alpha gamma beta", "a*b*c", false, false},
+		{"Star needs backtracking", "abcbcd", "a*bcd", false, true},
+		{"Star matches nothing", "ab", "a*b", false, true},
+		{"Anchored at both ends", "xhello worldx", "hello*world", false, false},
 		{"Multiple stars", "hello world test", "*world*", false, true},
 		{"Star at end", "hello world", "hello*", false, true},
 		{"Star at beginning", "hello world", "*world", false, true},
@@ -1078,3 +1116,260 @@ func TestEndToEndMultiConditionQueries(t *testing.T) {
 		})
 	}
 }
+
+func TestGetRecordChunk(t *testing.T) {
+	app := newTestApp(t, `{"text":"héllo wörld"}`)
+
+	var data string
+	offset := 0
+	for {
+		chunk, err := app.GetRecordChunk(1, offset, 4)
+		if err != nil {
+			t.Fatalf("GetRecordChunk returned error: %v", err)
+		}
+		if chunk.Length > 4 || chunk.Length == 0 {
+			t.Fatalf("Unexpected chunk length %d", chunk.Length)
+		}
+		data += chunk.Data
+		offset = chunk.Offset + chunk.Length
+		if !chunk.HasMore {
+			break
+		}
+	}
+
+	if data != `{"text":"héllo wörld"}` {
+		t.Errorf("Reassembled chunks do not match record: %q", data)
+	}
+
+	if _, err := app.GetRecordChunk(99, 0, 10); err == nil {
+		t.Error("Expected error for unknown line number")
+	}
+}
+
+func TestSearchRecordsSelectedFields(t *testing.T) {
+	app := newTestApp(t, `{"message":"disk full","error":"none","host":"db-1"}
+{"message":"ok","error":"disk quota","host":"db-2"}
+{"message":"ok","error":"none","host":"disk-3"}`)
+
+	tests := []struct {
+		name     string
+		fields   []string
+		expected []int
+	}{
+		{"No selection searches all fields", nil, []int{1, 2, 3}},
+		{"All searches all fields", []string{"all"}, []int{1, 2, 3}},
+		{"Single field", []string{"message"}, []int{1}},
+		{"Multiple fields", []string{"message", "error"}, []int{1, 2}},
+		{"Unknown field", []string{"missing"}, []int{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := app.SearchRecords(SearchOptions{Query: "disk", SelectedFields: tt.fields})
+			if err != nil {
+				t.Fatalf("SearchRecords returned error: %v", err)
+			}
+			lines := []int{}
+			for _, record := range result.Records {
+				lines = append(lines, record.LineNumber)
+			}
+			if fmt.Sprint(lines) != fmt.Sprint(tt.expected) {
+				t.Errorf("Expected lines %v, got %v", tt.expected, lines)
+			}
+		})
+	}
+}
+
+func TestNonObjectLines(t *testing.T) {
+	useTempConfigDir(t)
+
+	path := filepath.Join(t.TempDir(), "mixed.jsonl")
+	os.WriteFile(path, []byte("{\"a\":1}\n[1,\"two\"]\n\"text\"\n42\nnull\n{broken\n"), 0644)
+
+	app := &App{}
+	if _, err := app.LoadJSONLFile(path); err != nil {
+		t.Fatalf("LoadJSONLFile returned error: %v", err)
+	}
+	stats := app.cache.stats
+	if stats.ValidRecords != 5 || len(stats.InvalidLines) != 1 || stats.InvalidLines[0] != 6 {
+		t.Fatalf("Expected 5 valid records and line 6 invalid, got %+v", stats)
+	}
+
+	record, _ := app.GetRecordByLineNumber(2)
+	if values, ok := record.Value.([]interface{}); !ok || record.Content != nil || len(values) != 2 {
+		t.Errorf("Expected the array line as Value, got %+v", record)
+	}
+	if record, _ := app.GetRecordByLineNumber(4); record.Value != json.Number("42") {
+		t.Errorf("Expected the number line as Value, got %+v", record.Value)
+	}
+
+	result, err := app.SearchRecords(SearchOptions{Query: "two", Limit: 10})
+	if err != nil {
+		t.Fatalf("SearchRecords returned error: %v", err)
+	}
+	if result.TotalMatches != 1 || result.Records[0].LineNumber != 2 {
+		t.Errorf("Expected the array line to be searchable, got %+v", result.Records)
+	}
+
+	exported, err := app.GetAllRecords("text")
+	if err != nil || len(exported) != 1 || exported[0].Value != "text" {
+		t.Errorf("Expected the string line to be exported, got %+v (%v)", exported, err)
+	}
+}
+
+func TestConcurrentAccessDuringReload(t *testing.T) {
+	useTempConfigDir(t)
+	path := filepath.Join(t.TempDir(), "app.jsonl")
+	var content string
+	for i := 1; i <= 200; i++ {
+		content += fmt.Sprintf("{\"n\":%d,\"level\":\"info\"}\n", i)
+	}
+	os.WriteFile(path, []byte(content), 0644)
+
+	app := &App{}
+	if _, err := app.LoadJSONLFile(path); err != nil {
+		t.Fatalf("LoadJSONLFile returned error: %v", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 20; i++ {
+			f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+			fmt.Fprintf(f, "{\"n\":%d,\"level\":\"error\"}\n", 1000+i)
+			f.Close()
+			app.ReloadIncremental()
+			if i%5 == 0 {
+				app.SortRecords([]SortKey{{Field: "n", Direction: "desc"}})
+			} else if i%5 == 2 {
+				app.ResetOrder()
+			}
+		}
+	}()
+
+	for reading := true; reading; {
+		select {
+		case <-done:
+			reading = false
+		default:
+		}
+		page, err := app.GetRecords(0, 50)
+		if err != nil || len(page.Records) != 50 {
+			t.Fatalf("Expected a full page during reloads, got %v", err)
+		}
+		if result, err := app.SearchRecords(SearchOptions{Query: "level:error", UseLucene: true}); err != nil || result.TotalMatches > 20 {
+			t.Fatalf("Unexpected search result during reloads: %+v (%v)", result, err)
+		}
+		if record, err := app.GetRecordByLineNumber(100); err != nil || record.Content["n"] != json.Number("100") {
+			t.Fatalf("Expected line 100 during reloads, got %+v (%v)", record, err)
+		}
+	}
+
+	if count, _ := app.GetTotalRecordCount(); count != 220 {
+		t.Errorf("Expected 220 records after the reloads, got %d", count)
+	}
+}
+
+func TestLargeNumberPrecision(t *testing.T) {
+	app := newTestApp(t, `{"id":12345678901234567890,"ts":1715000000123456789}
+{"id":12345678901234567891,"ts":1715000000123456790}
+{"id":1.5e3,"ts":0}
+`)
+
+	if id := app.records[0].Content["id"]; id != json.Number("12345678901234567890") {
+		t.Errorf("Expected id kept as json.Number, got %T %v", id, id)
+	}
+
+	// Re-serialized records keep every digit
+	display := app.getDisplayJSON(app.records[1], nil, []string{"ts"})
+	if display != `{"id":12345678901234567891}` {
+		t.Errorf("Expected exact digits after re-serializing, got %s", display)
+	}
+
+	lines := func(records []JSONRecord) string {
+		var numbers []int
+		for _, record := range records {
+			numbers = append(numbers, record.LineNumber)
+		}
+		return fmt.Sprint(numbers)
+	}
+	for _, options := range []SearchOptions{
+		{Query: "id:12345678901234567891", UseLucene: true, StrictTypes: true},
+		{Query: "id:[12345678901234567891 TO *]", UseLucene: true},
+		{Query: "ts:>1715000000123456789", UseLucene: true},
+	} {
+		result, err := app.SearchRecords(options)
+		if err != nil {
+			t.Fatalf("SearchRecords(%q) returned error: %v", options.Query, err)
+		}
+		if got := lines(result.Records); got != "[2]" {
+			t.Errorf("SearchRecords(%q): expected [2], got %s", options.Query, got)
+		}
+	}
+
+	result, err := app.RunSQL("SELECT id FROM records WHERE id > 12345678901234567890")
+	if err != nil {
+		t.Fatalf("RunSQL returned error: %v", err)
+	}
+	if len(result.Rows) != 1 || result.Rows[0][0] != json.Number("12345678901234567891") {
+		t.Errorf("Expected the larger id from SQL, got %v", result.Rows)
+	}
+
+	if err := app.SetComputedFields([]ComputedField{{Name: "second", Expression: "id == 12345678901234567891"}}); err != nil {
+		t.Fatalf("SetComputedFields returned error: %v", err)
+	}
+	derived, _ := app.cache.slice(0, 2)
+	if derived[0].Content["second"] != false || derived[1].Content["second"] != true {
+		t.Errorf("Expected expressions to tell the ids apart, got %v and %v", derived[0].Content["second"], derived[1].Content["second"])
+	}
+	if app.records[2].Content["id"] != json.Number("1.5e3") {
+		t.Errorf("Expected the number literal kept as written, got %v", app.records[2].Content["id"])
+	}
+}
+
+func TestNestedRecordMatching(t *testing.T) {
+	// Escaped characters only read as such once decoded
+	app := newTestApp(t, `{"msg":"caf\u00e9","user":{"name":"Zo\u00eb","roles":["admin","ops"]}}
+{"msg":"plain","user":{"name":"Ann"}}
+[{"note":"\u2603 snow"}]
+`)
+	lines := func(result *SearchResult) string {
+		var numbers []int
+		for _, record := range result.Records {
+			numbers = append(numbers, record.LineNumber)
+		}
+		return fmt.Sprint(numbers)
+	}
+
+	tests := []struct {
+		options  SearchOptions
+		expected string
+	}{
+		{SearchOptions{Query: "café"}, "[1]"},
+		{SearchOptions{Query: "zoë"}, "[1]"},
+		{SearchOptions{Query: "☃"}, "[3]"},
+		{SearchOptions{Query: "zoë", SelectedFields: []string{"user"}}, "[1]"},
+		{SearchOptions{Query: "user:zoë", UseLucene: true}, "[1]"},
+		{SearchOptions{Query: "user:roles", UseLucene: true}, "[1]"},
+		{SearchOptions{Query: `user:"[\"admin\",\"ops\"]"`, UseLucene: true}, "[1]"},
+		{SearchOptions{Query: "user:map", UseLucene: true}, "[]"},
+	}
+	for _, tt := range tests {
+		result, err := app.SearchRecords(tt.options)
+		if err != nil {
+			t.Fatalf("SearchRecords(%q) returned error: %v", tt.options.Query, err)
+		}
+		if got := lines(result); got != tt.expected {
+			t.Errorf("SearchRecords(%q): expected %s, got %s", tt.options.Query, tt.expected, got)
+		}
+	}
+
+	// The search index narrows to the same decoded text
+	if err := app.BuildSearchIndex(); err != nil {
+		t.Fatalf("BuildSearchIndex returned error: %v", err)
+	}
+	waitForSearchIndex(t, app)
+	if result, _ := app.SearchRecords(SearchOptions{Query: "zoë"}); lines(result) != "[1]" {
+		t.Errorf("Expected the indexed search to find nested decoded text, got %s", lines(result))
+	}
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Audit log storage
+const (
+	auditLogFile      = "audit-log.jsonl"
+	auditSettingsFile = "audit.json"
+)
+
+// Audited actions
+const (
+	AuditFileOpened  = "file.opened"
+	AuditSearchRun   = "search.run"
+	AuditExportSaved = "export.saved"
+	AuditEditSaved   = "edit.saved"
+)
+
+// AuditEntry is one significant action recorded in the audit log
+type AuditEntry struct {
+	Time        time.Time `json:"time"`
+	Action      string    `json:"action"`
+	Path        string    `json:"path,omitempty"`        // file the action applied to
+	Query       string    `json:"query,omitempty"`       // search or export query
+	Records     int       `json:"records"`               // records loaded, matched or exported, or changes saved
+	Destination string    `json:"destination,omitempty"` // file written by exports
+	Detail      string    `json:"detail,omitempty"`
+}
+
+// AuditLogPage is a page of audit entries, newest first
+type AuditLogPage struct {
+	Entries []AuditEntry `json:"entries"`
+	Offset  int          `json:"offset"`
+	Limit   int          `json:"limit"`
+	Total   int          `json:"total"`
+	HasMore bool         `json:"hasMore"`
+	Enabled bool         `json:"enabled"`
+}
+
+// auditSettings is the persisted audit configuration
+type auditSettings struct {
+	Enabled bool `json:"enabled"`
+}
+
+// auditLog appends entries to a JSONL file in the config directory when enabled
+type auditLog struct {
+	mu      sync.Mutex
+	enabled bool
+}
+
+// newAuditLog reads whether auditing was enabled in an earlier session
+func newAuditLog() *auditLog {
+	var settings auditSettings
+	readConfigFile(auditSettingsFile, &settings)
+	return &auditLog{enabled: settings.Enabled}
+}
+
+// logPath returns the location of the audit log file
+func (l *auditLog) logPath() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, auditLogFile), nil
+}
+
+// setEnabled turns recording on or off
+func (l *auditLog) setEnabled(enabled bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.enabled = enabled
+}
+
+// isEnabled reports whether entries are recorded
+func (l *auditLog) isEnabled() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.enabled
+}
+
+// record appends an entry when auditing is enabled; failures never interrupt the
+// audited action
+func (l *auditLog) record(entry AuditEntry) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !l.enabled {
+		return
+	}
+
+	path, err := l.logPath()
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	entry.Time = time.Now()
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	file.Write(append(data, '\n'))
+}
+
+// entries reads every entry of the audit log in file (chronological) order
+func (l *auditLog) entries() ([]AuditEntry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	path, err := l.logPath()
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return []AuditEntry{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer file.Close()
+
+	entries := []AuditEntry{}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var entry AuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err == nil {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, scanner.Err()
+}
+
+// audit records an action in the audit log
+func (a *App) audit(entry AuditEntry) {
+	a.auditLog.record(entry)
+}
+
+// auditPath returns the path of the current file for audit entries
+func (a *App) auditPath() string {
+	if a.currentFile == nil {
+		return ""
+	}
+	return a.currentFile.Path
+}
+
+// SetAuditLogEnabled turns the audit log on or off; the choice is remembered
+func (a *App) SetAuditLogEnabled(enabled bool) error {
+	a.auditLog.setEnabled(enabled)
+	return writeConfigFile(auditSettingsFile, auditSettings{Enabled: enabled})
+}
+
+// GetAuditLog returns a page of the audit log, newest entries first
+func (a *App) GetAuditLog(offset, limit int) (*AuditLogPage, error) {
+	// Validate parameters
+	if offset < 0 {
+		offset = 0
+	}
+	if limit <= 0 {
+		limit = 50
+	}
+	if limit > 1000 {
+		limit = 1000 // Cap maximum limit for performance
+	}
+
+	entries, err := a.auditLog.entries()
+	if err != nil {
+		return nil, err
+	}
+
+	page := &AuditLogPage{
+		Entries: []AuditEntry{},
+		Offset:  offset,
+		Limit:   limit,
+		Total:   len(entries),
+		Enabled: a.auditLog.isEnabled(),
+	}
+	for i := len(entries) - 1 - offset; i >= 0 && len(page.Entries) < limit; i-- {
+		page.Entries = append(page.Entries, entries[i])
+	}
+	page.HasMore = offset+len(page.Entries) < len(entries)
+	return page, nil
+}
+
+// ExportAuditLog copies the whole audit log to a JSONL file in Downloads
+func (a *App) ExportAuditLog() (string, error) {
+	entries, err := a.auditLog.entries()
+	if err != nil {
+		return "", err
+	}
+
+	a.stateMu.RLock()
+	filePath, err := a.exportFilePath("jsonl-viewer-audit", "jsonl")
+	a.stateMu.RUnlock()
+	if err != nil {
+		return "", err
+	}
+	file, err := os.Create(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to create export file: %w", err)
+	}
+	defer file.Close()
+
+	encoder := json.NewEncoder(file)
+	for _, entry := range entries {
+		if err := encoder.Encode(entry); err != nil {
+			return "", fmt.Errorf("failed to write audit export: %w", err)
+		}
+	}
+	return filePath, nil
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAuditLog(t *testing.T) {
+	useTempConfigDir(t)
+
+	path := filepath.Join(t.TempDir(), "data.jsonl")
+	os.WriteFile(path, []byte("{\"msg\":\"a\"}\n{\"msg\":\"b\"}\n"), 0644)
+
+	app := NewApp()
+
+	// Nothing is recorded until auditing is enabled
+	app.LoadJSONLFile(path)
+	if page, _ := app.GetAuditLog(0, 10); page.Total != 0 || page.Enabled {
+		t.Fatalf("Expected an empty, disabled audit log, got %+v", page)
+	}
+
+	if err := app.SetAuditLogEnabled(true); err != nil {
+		t.Fatalf("SetAuditLogEnabled returned error: %v", err)
+	}
+	app.LoadJSONLFile(path)
+	app.SearchRecords(SearchOptions{Query: "b"})
+
+	page, err := app.GetAuditLog(0, 10)
+	if err != nil {
+		t.Fatalf("GetAuditLog returned error: %v", err)
+	}
+	if page.Total != 2 || !page.Enabled {
+		t.Fatalf("Expected 2 entries, got %+v", page)
+	}
+
+	// Newest first
+	search, open := page.Entries[0], page.Entries[1]
+	if search.Action != AuditSearchRun || search.Query != "b" || search.Records != 1 || search.Path != path {
+		t.Errorf("Unexpected search entry: %+v", search)
+	}
+	if open.Action != AuditFileOpened || open.Records != 2 || open.Time.IsZero() {
+		t.Errorf("Unexpected open entry: %+v", open)
+	}
+
+	// The setting survives a restart
+	if !NewApp().auditLog.isEnabled() {
+		t.Errorf("Expected auditing to stay enabled for a new session")
+	}
+
+	if page, _ := app.GetAuditLog(1, 10); len(page.Entries) != 1 || page.HasMore {
+		t.Errorf("Unexpected second page: %+v", page)
+	}
+}
+
+func TestAuditEditSaved(t *testing.T) {
+	useTempConfigDir(t)
+
+	app := NewApp()
+	if err := app.SetAuditLogEnabled(true); err != nil {
+		t.Fatalf("SetAuditLogEnabled returned error: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "data.jsonl")
+	for _, kind := range []string{JournalEdit, JournalTag} {
+		if _, err := app.RecordJournalEntry(JournalEntry{Kind: kind, Path: path, LineNumber: 1}); err != nil {
+			t.Fatalf("RecordJournalEntry returned error: %v", err)
+		}
+	}
+	if err := app.MarkJournalSaved(path); err != nil {
+		t.Fatalf("MarkJournalSaved returned error: %v", err)
+	}
+
+	// Discarded changes are not saved edits
+	app.RecordJournalEntry(JournalEntry{Kind: JournalEdit, Path: path, LineNumber: 2})
+	app.DiscardJournal(path)
+
+	page, _ := app.GetAuditLog(0, 10)
+	if page.Total != 1 {
+		t.Fatalf("Expected 1 entry, got %+v", page)
+	}
+	if saved := page.Entries[0]; saved.Action != AuditEditSaved || saved.Path != path || saved.Records != 2 {
+		t.Errorf("Unexpected edit entry: %+v", saved)
+	}
+}
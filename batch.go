@@ -0,0 +1,137 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// BatchFileResult is the outcome of converting a single file in a batch
+type BatchFileResult struct {
+	InputPath      string `json:"inputPath"`
+	OutputPath     string `json:"outputPath"`
+	RecordsWritten int    `json:"recordsWritten"`
+	SkippedLines   int    `json:"skippedLines"`
+	Error          string `json:"error,omitempty"`
+}
+
+// BatchConversionReport summarizes a batch conversion
+type BatchConversionReport struct {
+	Pattern      string            `json:"pattern"`
+	OutputFormat string            `json:"outputFormat"`
+	Files        []BatchFileResult `json:"files"`
+	Succeeded    int               `json:"succeeded"`
+	Failed       int               `json:"failed"`
+}
+
+// BatchProgress is emitted as the "batch:progress" event for every file
+type BatchProgress struct {
+	Index  int    `json:"index"` // 1-based position of the file in the batch
+	Total  int    `json:"total"`
+	File   string `json:"file"`
+	Status string `json:"status"` // 'converting', 'done', 'failed'
+	Error  string `json:"error,omitempty"`
+}
+
+// batchOutputPath derives the output file name for an input file, stripping
+// compression and format extensions before adding the new one
+func batchOutputPath(inputPath, outputDir, outputFormat string) string {
+	base := filepath.Base(inputPath)
+	base = strings.TrimSuffix(base, ".gz")
+	for _, ext := range []string{".jsonl", ".jsonlines", ".ndjson", ".json", ".txt"} {
+		if strings.HasSuffix(strings.ToLower(base), ext) {
+			base = base[:len(base)-len(ext)]
+			break
+		}
+	}
+
+	if outputDir == "" {
+		outputDir = filepath.Dir(inputPath)
+	}
+	return filepath.Join(outputDir, base+"."+outputFormat)
+}
+
+// runBatchConversion converts every file matching pattern, reporting progress
+// through the given callback
+func runBatchConversion(pattern, outputFormat, outputDir string, progress func(BatchProgress)) (*BatchConversionReport, error) {
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, &JSONLError{
+			Message: fmt.Sprintf("Invalid file pattern %q", pattern),
+			Err:     err,
+		}
+	}
+	// Only regular files are converted; directories matching the pattern are skipped
+	var files []string
+	for _, match := range matches {
+		if info, err := os.Stat(match); err == nil && !info.IsDir() {
+			files = append(files, match)
+		}
+	}
+	if len(files) == 0 {
+		return nil, &JSONLError{
+			Message: fmt.Sprintf("No files match %q", pattern),
+			Err:     ErrFileNotFound,
+		}
+	}
+
+	if outputDir != "" {
+		if err := os.MkdirAll(outputDir, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create output directory: %w", err)
+		}
+	}
+
+	report := &BatchConversionReport{
+		Pattern:      pattern,
+		OutputFormat: outputFormat,
+		Files:        []BatchFileResult{},
+	}
+
+	for i, inputPath := range files {
+		outputPath := batchOutputPath(inputPath, outputDir, outputFormat)
+		progress(BatchProgress{Index: i + 1, Total: len(files), File: inputPath, Status: "converting"})
+
+		fileResult := BatchFileResult{InputPath: inputPath, OutputPath: outputPath}
+		var result *ConversionResult
+		if outputPath == inputPath {
+			err = &JSONLError{
+				Message: "Output would overwrite the input file",
+				Err:     ErrUnsupportedConversion,
+			}
+		} else {
+			result, err = convertFileToFormat(inputPath, outputPath, outputFormat)
+		}
+
+		if err != nil {
+			fileResult.Error = err.Error()
+			report.Failed++
+			progress(BatchProgress{Index: i + 1, Total: len(files), File: inputPath, Status: "failed", Error: err.Error()})
+		} else {
+			fileResult.RecordsWritten = result.RecordsWritten
+			fileResult.SkippedLines = len(result.SkippedLines)
+			report.Succeeded++
+			progress(BatchProgress{Index: i + 1, Total: len(files), File: inputPath, Status: "done"})
+		}
+		report.Files = append(report.Files, fileResult)
+	}
+
+	return report, nil
+}
+
+// BatchConvert converts every file matching a glob pattern to outputFormat
+// ('jsonl', 'json', 'csv' or 'parquet'), writing results to outputDir (or next to each input)
+// and emitting "batch:progress" events per file
+func (a *App) BatchConvert(pattern, outputFormat, outputDir string) (*BatchConversionReport, error) {
+	if strings.TrimSpace(pattern) == "" {
+		return nil, &JSONLError{
+			Message: "File pattern cannot be empty",
+			Err:     errors.New("empty pattern"),
+		}
+	}
+
+	return runBatchConversion(pattern, outputFormat, outputDir, func(p BatchProgress) {
+		a.emitEvent("batch:progress", p)
+	})
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRunBatchConversion(t *testing.T) {
+	dir := t.TempDir()
+	outDir := filepath.Join(dir, "out")
+
+	if err := os.WriteFile(filepath.Join(dir, "a.jsonl"), []byte("{\"id\":1,\"user\":{\"name\":\"x\"}}\n{\"id\":2,\"extra\":true}\n"), 0644); err != nil {
+		t.Fatalf("Failed to write input: %v", err)
+	}
+
+	gzFile, err := os.Create(filepath.Join(dir, "b.jsonl.gz"))
+	if err != nil {
+		t.Fatalf("Failed to create gzip input: %v", err)
+	}
+	gz := gzip.NewWriter(gzFile)
+	gz.Write([]byte("{\"id\":3}\nbroken\n"))
+	gz.Close()
+	gzFile.Close()
+
+	// Directories matching the pattern are neither converted nor counted
+	if err := os.Mkdir(filepath.Join(dir, "c.jsonl.d"), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+
+	var events []BatchProgress
+	report, err := runBatchConversion(filepath.Join(dir, "*.jsonl*"), FormatCSV, outDir, func(p BatchProgress) {
+		events = append(events, p)
+	})
+	if err != nil {
+		t.Fatalf("runBatchConversion returned error: %v", err)
+	}
+
+	if report.Succeeded != 2 || report.Failed != 0 {
+		t.Fatalf("Unexpected report: %+v", report)
+	}
+	if len(events) != 4 || events[3].Status != "done" || events[0].Total != 2 || events[3].Total != 2 || events[3].Index != 2 {
+		t.Errorf("Unexpected progress events: %+v", events)
+	}
+
+	csvA, _ := os.ReadFile(filepath.Join(outDir, "a.csv"))
+	if expected := "extra,id,user.name\n,1,x\ntrue,2,\n"; string(csvA) != expected {
+		t.Errorf("Expected CSV %q, got %q", expected, csvA)
+	}
+	csvB, _ := os.ReadFile(filepath.Join(outDir, "b.csv"))
+	if expected := "id\n3\n"; string(csvB) != expected {
+		t.Errorf("Expected CSV %q, got %q", expected, csvB)
+	}
+	if report.Files[1].SkippedLines != 1 {
+		t.Errorf("Expected 1 skipped line for b.jsonl.gz, got %d", report.Files[1].SkippedLines)
+	}
+
+	// Decompressing to plain JSONL
+	report, err = runBatchConversion(filepath.Join(dir, "*.gz"), FormatJSONL, outDir, func(BatchProgress) {})
+	if err != nil || report.Succeeded != 1 {
+		t.Fatalf("Unexpected gzip to JSONL result: %+v, %v", report, err)
+	}
+	plain, _ := os.ReadFile(filepath.Join(outDir, "b.jsonl"))
+	if string(plain) != "{\"id\":3}\n" {
+		t.Errorf("Unexpected decompressed output %q", plain)
+	}
+
+	// Unsupported formats fail per file rather than aborting the batch
+	report, err = runBatchConversion(filepath.Join(dir, "*.jsonl"), "xml", outDir, func(BatchProgress) {})
+	if err != nil || report.Failed != 1 || report.Files[0].Error == "" {
+		t.Errorf("Expected an unknown format to fail per file, got %+v, %v", report, err)
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+// runConvertCommand implements `jsonl-viewer convert [-format F] [-out DIR] PATTERN...`
+// and returns the process exit code
+func runConvertCommand(args []string) int {
+	flags := flag.NewFlagSet("convert", flag.ContinueOnError)
+	format := flags.String("format", FormatJSONL, "output format: jsonl, json, csv or parquet")
+	outputDir := flags.String("out", "", "output directory (default: next to each input file)")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: jsonl-viewer convert [-format jsonl|json|csv|parquet] [-out DIR] PATTERN...")
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return 2
+	}
+
+	failed := 0
+	for _, pattern := range flags.Args() {
+		report, err := runBatchConversion(pattern, *format, *outputDir, func(p BatchProgress) {
+			switch p.Status {
+			case "done":
+				fmt.Printf("[%d/%d] %s: done\n", p.Index, p.Total, p.File)
+			case "failed":
+				fmt.Printf("[%d/%d] %s: failed: %s\n", p.Index, p.Total, p.File, p.Error)
+			}
+		})
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			failed++
+			continue
+		}
+
+		fmt.Printf("%s: %d converted, %d failed\n", pattern, report.Succeeded, report.Failed)
+		for _, file := range report.Files {
+			if file.Error == "" {
+				fmt.Printf("  %s -> %s (%d records, %d skipped lines)\n", file.InputPath, file.OutputPath, file.RecordsWritten, file.SkippedLines)
+			}
+		}
+		failed += report.Failed
+	}
+
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
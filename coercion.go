@@ -0,0 +1,224 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Coercion types for FieldCoercion
+const (
+	CoerceNumber    = "number"
+	CoerceTimestamp = "timestamp"
+	CoerceString    = "string"
+)
+
+// ErrInvalidCoercion is returned for unknown coercion types
+var ErrInvalidCoercion = errors.New("invalid coercion")
+
+// FieldCoercion overrides how a field's values are interpreted when comparing and
+// sorting, e.g. numbers or dates that arrive as strings
+type FieldCoercion struct {
+	Field  string `json:"field"`
+	Type   string `json:"type"`   // 'number', 'timestamp', 'string'
+	Format string `json:"format"` // Go time layout for 'timestamp'; empty detects common formats
+}
+
+// coerce converts a field value to the override type: an exact *big.Rat for numbers,
+// time.Time for timestamps and text for strings, reporting false if it cannot be
+// converted
+func (c FieldCoercion) coerce(value interface{}) (interface{}, bool) {
+	if value == nil {
+		return nil, false
+	}
+
+	switch c.Type {
+	case CoerceNumber:
+		if number, ok := exactNumber(value); ok {
+			return number, true
+		}
+
+	case CoerceTimestamp:
+		if str, ok := value.(string); ok && c.Format != "" {
+			t, err := time.Parse(c.Format, strings.TrimSpace(str))
+			return t, err == nil
+		}
+		return parseTimestamp(value)
+
+	case CoerceString:
+		return valueToString(value), true
+	}
+	return nil, false
+}
+
+// compareCoerced orders two values coerced by the same FieldCoercion
+func compareCoerced(left, right interface{}) int {
+	switch l := left.(type) {
+	case *big.Rat:
+		return l.Cmp(right.(*big.Rat))
+	case time.Time:
+		return l.Compare(right.(time.Time))
+	default:
+		return strings.Compare(left.(string), right.(string))
+	}
+}
+
+// matchField checks a field value against a search value, honouring a coercion
+// override for the field: coerced numbers and timestamps must be equal, while
+// coerced strings and values that cannot be coerced fall back to text matching.
+// Without an override, StrictTypes compares booleans and numbers by value.
+func (o matchOptions) matchField(field string, fieldValue interface{}, searchValue string) bool {
+	coercion, exists := o.Coercions[field]
+	if !exists || fieldValue == nil {
+		if o.StrictTypes {
+			if matched, typed := matchTyped(fieldValue, searchValue); typed {
+				return matched
+			}
+		}
+		return o.matchFieldValue(fieldValue, searchValue)
+	}
+
+	if coercion.Type == CoerceString {
+		return o.matchFieldValue(valueToString(fieldValue), searchValue)
+	}
+
+	left, leftOK := coercion.coerce(fieldValue)
+	right, rightOK := coercion.coerce(searchValue)
+	if leftOK && rightOK {
+		return compareCoerced(left, right) == 0
+	}
+	return o.matchFieldValue(fieldValue, searchValue)
+}
+
+// matchTyped compares boolean and number field values by value: booleans equal
+// only "true" or "false" and numbers only numerically equal values, so `active:tru`
+// and `status:50` no longer match by substring (a wildcard still can). typed is
+// false for other values, which are matched as text.
+func matchTyped(fieldValue interface{}, searchValue string) (matched, typed bool) {
+	searchValue = strings.TrimSpace(searchValue)
+	if b, ok := fieldValue.(bool); ok {
+		return strings.EqualFold(searchValue, strconv.FormatBool(b)), true
+	}
+	if _, isString := fieldValue.(string); isString {
+		return false, false
+	}
+	if number, ok := exactNumber(fieldValue); ok {
+		search, ok := exactNumber(searchValue)
+		return ok && number.Cmp(search) == 0, true
+	}
+	return false, false
+}
+
+// compareField orders a field value against a query bound, honouring a coercion
+// override for the field. Numeric bounds compare numerically with numbers and
+// numeric strings, or as epochs with date strings. Date bounds compare as points
+// in time with values parsed as timestamps, other bounds compare as text; false
+// means the two cannot be compared.
+func (o matchOptions) compareField(field string, fieldValue interface{}, bound string) (int, bool) {
+	if fieldValue == nil {
+		return 0, false
+	}
+
+	if coercion, exists := o.Coercions[field]; exists && coercion.Type != CoerceString {
+		left, leftOK := coercion.coerce(fieldValue)
+		right, rightOK := coercion.coerce(bound)
+		if !leftOK || !rightOK {
+			return 0, false
+		}
+		return compareCoerced(left, right), true
+	}
+
+	if boundNumber, ok := exactNumber(bound); ok {
+		if number, ok := exactNumber(fieldValue); ok {
+			return number.Cmp(boundNumber), true
+		}
+		// An epoch bound against a date string
+		t, ok := parseTimestamp(fieldValue)
+		if !ok {
+			return 0, false
+		}
+		epoch, _ := boundNumber.Float64()
+		return t.Compare(epochToTime(epoch)), true
+	}
+
+	if boundTime, ok := parseTimestampString(strings.TrimSpace(bound)); ok {
+		t, ok := parseTimestamp(fieldValue)
+		if !ok {
+			return 0, false
+		}
+		return t.Compare(boundTime), true
+	}
+	return strings.Compare(o.normalize(valueToString(fieldValue)), o.normalize(bound)), true
+}
+
+// exactNumber converts a number, json.Number or numeric string to an exact rational,
+// so values beyond float64 precision still compare correctly
+func exactNumber(value interface{}) (*big.Rat, bool) {
+	var text string
+	switch v := value.(type) {
+	case json.Number:
+		text = v.String()
+	case string:
+		text = strings.TrimSpace(v)
+	default:
+		number, ok := toFloat64(value)
+		if !ok || math.IsNaN(number) || math.IsInf(number, 0) {
+			return nil, false
+		}
+		return new(big.Rat).SetFloat64(number), true
+	}
+
+	if _, err := strconv.ParseFloat(text, 64); err != nil && !errors.Is(err, strconv.ErrRange) {
+		return nil, false
+	}
+	return new(big.Rat).SetString(text)
+}
+
+// SetFieldCoercions replaces the per-field coercion overrides used by search
+// comparisons and sorting
+func (a *App) SetFieldCoercions(coercions []FieldCoercion) error {
+	byField := make(map[string]FieldCoercion, len(coercions))
+	for _, coercion := range coercions {
+		if strings.TrimSpace(coercion.Field) == "" {
+			return &JSONLError{
+				Message: "Coercion field cannot be empty",
+				Err:     ErrInvalidCoercion,
+			}
+		}
+		switch coercion.Type {
+		case CoerceNumber, CoerceTimestamp, CoerceString:
+		default:
+			return &JSONLError{
+				Message: fmt.Sprintf("Unknown coercion type %q for %s", coercion.Type, coercion.Field),
+				Err:     ErrInvalidCoercion,
+			}
+		}
+		byField[coercion.Field] = coercion
+	}
+
+	a.stateMu.Lock()
+	a.coercions = byField
+	a.stateMu.Unlock()
+	return nil
+}
+
+// GetFieldCoercions returns the coercion overrides sorted by field
+func (a *App) GetFieldCoercions() []FieldCoercion {
+	a.stateMu.RLock()
+	defer a.stateMu.RUnlock()
+
+	coercions := make([]FieldCoercion, 0, len(a.coercions))
+	for _, coercion := range a.coercions {
+		coercions = append(coercions, coercion)
+	}
+	sort.Slice(coercions, func(i, j int) bool {
+		return coercions[i].Field < coercions[j].Field
+	})
+	return coercions
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestFieldCoercion(t *testing.T) {
+	tests := []struct {
+		name     string
+		coercion FieldCoercion
+		value    interface{}
+		expected interface{}
+		ok       bool
+	}{
+		{"Numeric string", FieldCoercion{Type: CoerceNumber}, " 42.5 ", big.NewRat(85, 2), true},
+		{"Number stays number", FieldCoercion{Type: CoerceNumber}, float64(7), big.NewRat(7, 1), true},
+		{"Large integer keeps every digit", FieldCoercion{Type: CoerceNumber}, json.Number("9007199254740993"), new(big.Rat).SetInt64(9007199254740993), true},
+		{"Non-numeric string", FieldCoercion{Type: CoerceNumber}, "n/a", nil, false},
+		{"Timestamp with layout", FieldCoercion{Type: CoerceTimestamp, Format: "02/01/2006"}, "31/12/2024", time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC), true},
+		{"Timestamp detected", FieldCoercion{Type: CoerceTimestamp}, "2024-12-31T00:00:00Z", time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC), true},
+		{"Number as string", FieldCoercion{Type: CoerceString}, float64(1000000), "1000000", true},
+		{"Null cannot be coerced", FieldCoercion{Type: CoerceString}, nil, nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, ok := tt.coercion.coerce(tt.value)
+			if ok != tt.ok {
+				t.Fatalf("Expected ok=%v, got %v", tt.ok, ok)
+			}
+			if !ok {
+				return
+			}
+			if expectedTime, isTime := tt.expected.(time.Time); isTime {
+				if !expectedTime.Equal(result.(time.Time)) {
+					t.Errorf("Expected %v, got %v", expectedTime, result)
+				}
+			} else if expectedNumber, isNumber := tt.expected.(*big.Rat); isNumber {
+				if expectedNumber.Cmp(result.(*big.Rat)) != 0 {
+					t.Errorf("Expected %v, got %v", expectedNumber, result)
+				}
+			} else if result != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestSearchWithFieldCoercions(t *testing.T) {
+	app := newTestApp(t, `{"amount":"10.0","day":"01/02/2024","big":1000000}
+{"amount":"100","day":"02/02/2024","big":5}`)
+
+	err := app.SetFieldCoercions([]FieldCoercion{
+		{Field: "amount", Type: CoerceNumber},
+		{Field: "day", Type: CoerceTimestamp, Format: "02/01/2006"},
+		{Field: "big", Type: CoerceString},
+	})
+	if err != nil {
+		t.Fatalf("SetFieldCoercions returned error: %v", err)
+	}
+
+	tests := []struct {
+		query    string
+		expected int
+	}{
+		{"amount:10", 1},      // numeric equality instead of substring
+		{"day:01/02/2024", 1}, // compared as dates
+		{"big:1000000", 1},    // not rendered as 1e+06
+		{"amount:abc", 0},     // falls back to text matching
+		{"amount:10 OR big:5", 2},
+	}
+
+	for _, tt := range tests {
+		result, err := app.SearchRecords(SearchOptions{Query: tt.query, UseLucene: true})
+		if err != nil {
+			t.Fatalf("SearchRecords(%q) returned error: %v", tt.query, err)
+		}
+		if result.TotalMatches != tt.expected {
+			t.Errorf("Expected %d matches for %q, got %d", tt.expected, tt.query, result.TotalMatches)
+		}
+	}
+
+	if err := app.SetFieldCoercions([]FieldCoercion{{Field: "x", Type: "bool"}}); err == nil {
+		t.Errorf("Expected an error for an unknown coercion type")
+	}
+	if coercions := app.GetFieldCoercions(); len(coercions) != 3 || coercions[0].Field != "amount" {
+		t.Errorf("Expected previous coercions to be kept, got %+v", coercions)
+	}
+}
+
+func TestStrictTypeMatching(t *testing.T) {
+	app := newTestApp(t, `{"active":true,"status":500,"code":"500"}
+{"active":false,"status":503,"code":"5000"}
+{"active":"true","status":50.0}`)
+
+	tests := []struct {
+		query    string
+		strict   bool
+		expected int
+	}{
+		{"active:tru", false, 2},
+		{"active:tru", true, 1}, // only the string value still matches as text
+		{"active:true", true, 2},
+		{"active:TRUE", true, 2},
+		{"active:tru*", true, 2},
+		{"status:50", false, 3},
+		{"status:50", true, 1},
+		{"status:5e2", true, 1},
+		{"status:abc", true, 0},
+		{"code:500", true, 2}, // strings keep substring matching
+	}
+	for _, tt := range tests {
+		result, err := app.SearchRecords(SearchOptions{Query: tt.query, UseLucene: true, StrictTypes: tt.strict})
+		if err != nil {
+			t.Fatalf("SearchRecords returned error: %v", err)
+		}
+		if result.TotalMatches != tt.expected {
+			t.Errorf("%s (strict %v): expected %d matches, got %d", tt.query, tt.strict, tt.expected, result.TotalMatches)
+		}
+	}
+}
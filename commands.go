@@ -0,0 +1,255 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Command execution errors
+var (
+	ErrUnknownCommand  = errors.New("unknown command")
+	ErrMissingArgument = errors.New("missing command argument")
+)
+
+// CommandParam describes a parameter of a palette command
+type CommandParam struct {
+	Name        string   `json:"name"`
+	Type        string   `json:"type"` // 'string', 'number', 'boolean'
+	Required    bool     `json:"required"`
+	Description string   `json:"description"`
+	Enum        []string `json:"enum,omitempty"` // allowed values, when restricted
+}
+
+// CommandInfo describes a named backend action for the command palette
+type CommandInfo struct {
+	ID          string         `json:"id"`
+	Title       string         `json:"title"`
+	Category    string         `json:"category"`
+	Description string         `json:"description"`
+	Params      []CommandParam `json:"params"`
+	NeedsFile   bool           `json:"needsFile"` // only available while a file is loaded
+}
+
+// commandArgs are the arguments passed to a command by the frontend
+type commandArgs map[string]interface{}
+
+// string returns a string argument, or "" when absent
+func (args commandArgs) string(name string) string {
+	value, _ := args[name].(string)
+	return value
+}
+
+// int returns a numeric argument, or fallback when absent
+func (args commandArgs) int(name string, fallback int) int {
+	if number, ok := toFloat64(args[name]); ok {
+		return int(number)
+	}
+	return fallback
+}
+
+// bool returns a boolean argument, or fallback when absent
+func (args commandArgs) bool(name string, fallback bool) bool {
+	if value, ok := args[name].(bool); ok {
+		return value
+	}
+	return fallback
+}
+
+// command is a registered palette action
+type command struct {
+	info CommandInfo
+	run  func(a *App, args commandArgs) (interface{}, error)
+}
+
+// Parameters shared by several commands
+var (
+	queryParam  = CommandParam{Name: "query", Type: "string", Description: "Search query (Lucene syntax), empty for all records"}
+	formatParam = CommandParam{Name: "format", Type: "string", Required: true, Description: "Output format", Enum: []string{FormatCSV, FormatJSON}}
+)
+
+// commandRegistry lists the backend actions available to the command palette
+var commandRegistry = []command{
+	{
+		info: CommandInfo{ID: "file.open", Title: "Open File...", Category: "File", Description: "Choose a JSONL file and open it"},
+		run: func(a *App, args commandArgs) (interface{}, error) {
+			path, err := a.OpenFile()
+			if err != nil {
+				return nil, err
+			}
+			return a.LoadJSONLFile(path)
+		},
+	},
+	{
+		info: CommandInfo{ID: "file.openTabs", Title: "Open Files in Tabs...", Category: "File", Description: "Choose several files and open each in its own tab"},
+		run: func(a *App, args commandArgs) (interface{}, error) {
+			paths, err := a.OpenMultipleFiles()
+			if err != nil {
+				return nil, err
+			}
+			return a.OpenFilesInTabs(paths)
+		},
+	},
+	{
+		info: CommandInfo{ID: "file.openClipboard", Title: "Open from Clipboard", Category: "File", Description: "Load JSONL content from the clipboard"},
+		run: func(a *App, args commandArgs) (interface{}, error) {
+			return a.LoadJSONLFromClipboard()
+		},
+	},
+	{
+		info: CommandInfo{ID: "file.reload", Title: "Reload File", Category: "File", Description: "Reload the current file from disk", NeedsFile: true},
+		run: func(a *App, args commandArgs) (interface{}, error) {
+			return a.ReloadCurrentFile()
+		},
+	},
+	{
+		info: CommandInfo{
+			ID: "tab.switch", Title: "Switch Tab", Category: "Tabs", Description: "Activate an open document",
+			Params: []CommandParam{{Name: "id", Type: "string", Required: true, Description: "Document ID"}},
+		},
+		run: func(a *App, args commandArgs) (interface{}, error) {
+			return a.SwitchDocument(args.string("id"))
+		},
+	},
+	{
+		info: CommandInfo{
+			ID: "tab.close", Title: "Close Tab", Category: "Tabs", Description: "Close an open document",
+			Params: []CommandParam{{Name: "id", Type: "string", Required: true, Description: "Document ID"}},
+		},
+		run: func(a *App, args commandArgs) (interface{}, error) {
+			return nil, a.CloseDocument(args.string("id"))
+		},
+	},
+	{
+		info: CommandInfo{
+			ID: "navigate.line", Title: "Go to Line...", Category: "Navigate", Description: "Jump to the record at a line number", NeedsFile: true,
+			Params: []CommandParam{{Name: "line", Type: "number", Required: true, Description: "Line number"}},
+		},
+		run: func(a *App, args commandArgs) (interface{}, error) {
+			return a.GetRecordByLineNumber(args.int("line", 0))
+		},
+	},
+	{
+		info: CommandInfo{
+			ID: "search.run", Title: "Search", Category: "Search", Description: "Search the loaded records", NeedsFile: true,
+			Params: []CommandParam{
+				queryParam,
+				{Name: "caseSensitive", Type: "boolean", Description: "Match case, defaulting to the settings"},
+				{Name: "useLucene", Type: "boolean", Description: "Interpret the query as Lucene syntax"},
+				{Name: "useJSONPath", Type: "boolean", Description: "Interpret the query as a JSONPath expression"},
+			},
+		},
+		run: func(a *App, args commandArgs) (interface{}, error) {
+			return a.SearchRecords(SearchOptions{
+				Query:         args.string("query"),
+				CaseSensitive: args.bool("caseSensitive", a.GetSettings().CaseSensitive),
+				UseLucene:     args.bool("useLucene", false),
+				UseJSONPath:   args.bool("useJSONPath", false),
+			})
+		},
+	},
+	{
+		info: CommandInfo{
+			ID: "search.quickFilters", Title: "Show Quick Filters", Category: "Search", Description: "List enum-like fields as toggleable filters", NeedsFile: true,
+			Params: []CommandParam{{Name: "maxDistinct", Type: "number", Description: "Largest number of values per field"}},
+		},
+		run: func(a *App, args commandArgs) (interface{}, error) {
+			return a.GetQuickFilters(args.int("maxDistinct", 0), nil)
+		},
+	},
+	{
+		info: CommandInfo{
+			ID: "export.jsonl", Title: "Export Results as JSONL", Category: "Export", Description: "Save matching records to Downloads", NeedsFile: true,
+			Params: []CommandParam{queryParam},
+		},
+		run: func(a *App, args commandArgs) (interface{}, error) {
+			return a.ExportSearchResults(args.string("query"), nil, nil)
+		},
+	},
+	{
+		info: CommandInfo{
+			ID: "export.html", Title: "Export Results as HTML Viewer", Category: "Export", Description: "Save matching records as a standalone HTML page", NeedsFile: true,
+			Params: []CommandParam{queryParam},
+		},
+		run: func(a *App, args commandArgs) (interface{}, error) {
+			return a.ExportHTMLViewer(SearchOptions{Query: args.string("query"), UseLucene: true, CaseSensitive: a.GetSettings().CaseSensitive}, nil, nil)
+		},
+	},
+	{
+		info: CommandInfo{
+			ID: "export.fieldCatalog", Title: "Export Field Catalog", Category: "Export", Description: "Save the field catalog as CSV or JSON", NeedsFile: true,
+			Params: []CommandParam{formatParam},
+		},
+		run: func(a *App, args commandArgs) (interface{}, error) {
+			catalog, err := a.GetFieldCatalog(nil)
+			if err != nil {
+				return nil, err
+			}
+			return a.ExportStats("field-catalog", catalog, args.string("format"))
+		},
+	},
+	{
+		info: CommandInfo{
+			ID: "view.pageSize", Title: "Set Page Size...", Category: "View", Description: "Records per page; 0 switches to automatic sizing", NeedsFile: true,
+			Params: []CommandParam{{Name: "pageSize", Type: "number", Required: true, Description: "Records per page"}},
+		},
+		run: func(a *App, args commandArgs) (interface{}, error) {
+			if pageSize := args.int("pageSize", 0); pageSize > 0 {
+				return pageSize, a.SetPageSize(pageSize)
+			}
+			return a.SetAutoPageSize(0)
+		},
+	},
+	{
+		info: CommandInfo{ID: "analyze.quality", Title: "Show Data Quality", Category: "Analyze", Description: "Compute completeness, duplicates and timestamp gaps", NeedsFile: true},
+		run: func(a *App, args commandArgs) (interface{}, error) {
+			return a.GetQualityMetrics(nil)
+		},
+	},
+	{
+		info: CommandInfo{ID: "analyze.validate", Title: "Run Validation Rules", Category: "Analyze", Description: "Check records against the loaded validation rules", NeedsFile: true},
+		run: func(a *App, args commandArgs) (interface{}, error) {
+			return a.RunValidation()
+		},
+	},
+}
+
+// GetCommands returns the registered palette commands with their parameters
+func (a *App) GetCommands() []CommandInfo {
+	commands := make([]CommandInfo, 0, len(commandRegistry))
+	for _, cmd := range commandRegistry {
+		commands = append(commands, cmd.info)
+	}
+	return commands
+}
+
+// ExecuteCommand runs a registered command with the given arguments, checking
+// required parameters first, and returns the result of the underlying method
+func (a *App) ExecuteCommand(id string, args map[string]interface{}) (interface{}, error) {
+	for _, cmd := range commandRegistry {
+		if cmd.info.ID != id {
+			continue
+		}
+
+		if cmd.info.NeedsFile && a.loadedFile() == nil {
+			return nil, &JSONLError{
+				Message: "No file currently loaded",
+				Err:     ErrNoFileLoaded,
+			}
+		}
+		for _, param := range cmd.info.Params {
+			if _, exists := args[param.Name]; param.Required && !exists {
+				return nil, &JSONLError{
+					Message: fmt.Sprintf("Command %s requires parameter %s", id, param.Name),
+					Err:     ErrMissingArgument,
+				}
+			}
+		}
+
+		return cmd.run(a, commandArgs(args))
+	}
+
+	return nil, &JSONLError{
+		Message: fmt.Sprintf("Unknown command %q", id),
+		Err:     ErrUnknownCommand,
+	}
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestCommandRegistry(t *testing.T) {
+	seen := make(map[string]bool)
+	for _, cmd := range (&App{}).GetCommands() {
+		if seen[cmd.ID] {
+			t.Errorf("Duplicate command ID %s", cmd.ID)
+		}
+		seen[cmd.ID] = true
+		if cmd.Title == "" || cmd.Category == "" {
+			t.Errorf("Command %s is missing metadata", cmd.ID)
+		}
+	}
+}
+
+func TestExecuteCommand(t *testing.T) {
+	app := newTestApp(t, `{"msg":"a"}
+{"msg":"b"}`)
+
+	record, err := app.ExecuteCommand("navigate.line", map[string]interface{}{"line": float64(2)})
+	if err != nil {
+		t.Fatalf("ExecuteCommand returned error: %v", err)
+	}
+	if record.(*JSONRecord).Content["msg"] != "b" {
+		t.Errorf("Expected record at line 2, got %+v", record)
+	}
+
+	result, err := app.ExecuteCommand("search.run", map[string]interface{}{"query": "b"})
+	if err != nil || result.(*SearchResult).TotalMatches != 1 {
+		t.Errorf("Expected one search match, got %+v, %v", result, err)
+	}
+
+	if _, err := app.ExecuteCommand("navigate.line", nil); err == nil {
+		t.Errorf("Expected an error for a missing required parameter")
+	}
+	if _, err := app.ExecuteCommand("no.such.command", nil); err == nil {
+		t.Errorf("Expected an error for an unknown command")
+	}
+	if _, err := (&App{}).ExecuteCommand("file.reload", nil); err == nil {
+		t.Errorf("Expected an error for a file command without a file")
+	}
+}
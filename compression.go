@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"io"
+	"os"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// decompressor recognizes a compression format by the magic bytes at the start of
+// a file and wraps the file's reader in a decoder
+type decompressor struct {
+	name  string
+	magic []byte
+	open  func(r io.Reader) (io.ReadCloser, error)
+}
+
+// decompressors lists the compression formats detected when reading input files
+var decompressors = []decompressor{
+	{
+		name:  "gzip",
+		magic: []byte{0x1f, 0x8b},
+		open: func(r io.Reader) (io.ReadCloser, error) {
+			return gzip.NewReader(r)
+		},
+	},
+	{
+		name:  "zstd",
+		magic: []byte{0x28, 0xb5, 0x2f, 0xfd},
+		open: func(r io.Reader) (io.ReadCloser, error) {
+			decoder, err := zstd.NewReader(r)
+			if err != nil {
+				return nil, err
+			}
+			return decoder.IOReadCloser(), nil
+		},
+	},
+	{
+		name:  "bzip2",
+		magic: []byte("BZh"),
+		open: func(r io.Reader) (io.ReadCloser, error) {
+			return io.NopCloser(bzip2.NewReader(r)), nil
+		},
+	},
+}
+
+// maxMagicLength is the longest magic byte sequence of any decompressor
+const maxMagicLength = 4
+
+// detectDecompressor returns the decompressor whose magic bytes start header
+func detectDecompressor(header []byte) *decompressor {
+	for i := range decompressors {
+		if bytes.HasPrefix(header, decompressors[i].magic) {
+			return &decompressors[i]
+		}
+	}
+	return nil
+}
+
+// openJSONLSource opens a file for reading, transparently decompressing gzip,
+// zstd and bzip2 content and transcoding UTF-16 or BOM-prefixed text to UTF-8. Compression is detected from the content, so a renamed
+// compressed file still decodes and a plain file named .gz is read as is.
+func openJSONLSource(path string) (io.ReadCloser, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder, err := decompressReader(file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return readCloser{Reader: decodeText(decoder), Closer: multiCloser{decoder, file}}, nil
+}
+
+// decompressReader wraps r in the decoder of the compression format its content
+// starts with, or returns it buffered when it is not compressed. Closing the result
+// releases the decoder but not r.
+func decompressReader(r io.Reader) (io.ReadCloser, error) {
+	buffered := bufio.NewReader(r)
+	header, _ := buffered.Peek(maxMagicLength)
+	format := detectDecompressor(header)
+	if format == nil {
+		return io.NopCloser(buffered), nil
+	}
+	return format.open(buffered)
+}
+
+// compressionOf returns the compression format of a file, or "" for plain files
+func compressionOf(path string) string {
+	file, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	header := make([]byte, maxMagicLength)
+	n, _ := io.ReadFull(file, header)
+	if format := detectDecompressor(header[:n]); format != nil {
+		return format.name
+	}
+	return ""
+}
+
+// isCompressedFile reports whether a file holds compressed content
+func isCompressedFile(path string) bool {
+	return compressionOf(path) != ""
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// writeGzipFile writes content gzip-compressed to a file in a temporary directory
+func writeGzipFile(t *testing.T, name, content string) string {
+	t.Helper()
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	gz.Write([]byte(content))
+	gz.Close()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadGzipFile(t *testing.T) {
+	useTempConfigDir(t)
+
+	for _, name := range []string{"logs.jsonl.gz", "logs.jsonl"} {
+		path := writeGzipFile(t, name, "{\"level\":\"info\"}\nbad\n{\"level\":\"error\"}\n")
+
+		app := &App{}
+		file, err := app.LoadJSONLFile(path)
+		if err != nil {
+			t.Fatalf("%s: LoadJSONLFile returned error: %v", name, err)
+		}
+		if file.Records != 2 || len(app.cache.stats.InvalidLines) != 1 {
+			t.Errorf("%s: expected 2 records and 1 invalid line, got %d and %v", name, file.Records, app.cache.stats.InvalidLines)
+		}
+
+		result, err := app.SearchRecords(SearchOptions{Query: "level:error", UseLucene: true})
+		if err != nil || result.TotalMatches != 1 {
+			t.Errorf("%s: expected 1 match, got %+v (%v)", name, result, err)
+		}
+
+		records, err := app.GetAllRecords("error")
+		if err != nil || len(records) != 1 {
+			t.Errorf("%s: expected GetAllRecords to read the decompressed file, got %d (%v)", name, len(records), err)
+		}
+	}
+}
+
+func TestIsCompressedFile(t *testing.T) {
+	compressed := writeGzipFile(t, "data.gz", "{}\n")
+	plain := filepath.Join(t.TempDir(), "plain.gz")
+	os.WriteFile(plain, []byte("{}\n"), 0644)
+
+	if !isCompressedFile(compressed) {
+		t.Error("Expected gzip content to be detected")
+	}
+	if isCompressedFile(plain) {
+		t.Error("Expected a plain file named .gz to be read as is")
+	}
+
+	app := &App{}
+	if _, err := app.LoadJSONLFileIndexed(compressed); err == nil {
+		t.Error("Expected indexing a compressed file to fail")
+	}
+}
+
+// bzip2Records is `{"n":1}\n{"n":2}\n` compressed with bzip2
+var bzip2Records = []byte{
+	0x42, 0x5a, 0x68, 0x39, 0x31, 0x41, 0x59, 0x26, 0x53, 0x59, 0x5f, 0x3f, 0x93, 0xd7, 0x00, 0x00,
+	0x06, 0x59, 0x80, 0x00, 0x10, 0x10, 0x00, 0x30, 0x10, 0x00, 0x01, 0x00, 0x0a, 0x20, 0x00, 0x31,
+	0x0c, 0x08, 0x12, 0x80, 0x7a, 0x89, 0xc2, 0x26, 0x86, 0x8b, 0xe2, 0xee, 0x48, 0xa7, 0x0a, 0x12,
+	0x0b, 0xe7, 0xf2, 0x7a, 0xe0,
+}
+
+func TestLoadZstdAndBzip2Files(t *testing.T) {
+	useTempConfigDir(t)
+	dir := t.TempDir()
+
+	var zstdData bytes.Buffer
+	encoder, _ := zstd.NewWriter(&zstdData)
+	encoder.Write([]byte("{\"n\":1}\n{\"n\":2}\n"))
+	encoder.Close()
+
+	// Extensions deliberately say nothing about the compression
+	files := map[string][]byte{"zstd": zstdData.Bytes(), "bzip2": bzip2Records}
+	for format, data := range files {
+		path := filepath.Join(dir, format+".jsonl")
+		os.WriteFile(path, data, 0644)
+
+		if got := compressionOf(path); got != format {
+			t.Errorf("Expected %s to be detected, got %q", format, got)
+		}
+
+		app := &App{}
+		file, err := app.LoadJSONLFile(path)
+		if err != nil {
+			t.Fatalf("%s: LoadJSONLFile returned error: %v", format, err)
+		}
+		if file.Records != 2 {
+			t.Errorf("%s: expected 2 records, got %d", format, file.Records)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ComputedField is a user-defined field computed from an expression over the
+// record's fields, e.g. Name "duration_ms" with Expression "end_ts - start_ts"
+type ComputedField struct {
+	Name       string `json:"name"`
+	Expression string `json:"expression"`
+}
+
+// compiledComputed is a computed field with its expression parsed
+type compiledComputed struct {
+	field ComputedField
+	expr  exprNode
+}
+
+// apply evaluates the expression and adds the result to content; records where
+// the expression fails (e.g. arithmetic on a missing field) get no value
+func (c *compiledComputed) apply(content map[string]interface{}) {
+	value, err := c.expr.eval(content)
+	if err != nil {
+		return
+	}
+	setDerivedValue(content, c.field.Name, value)
+}
+
+// SetComputedFields replaces the computed fields. They are not stored in the loaded
+// records but added by the derive hook as records are read, see applyDerivedFields.
+// Fields are evaluated in order after regex extraction, so they may refer to
+// extracted fields and to computed fields defined before them.
+func (a *App) SetComputedFields(fields []ComputedField) error {
+	compiled := make([]*compiledComputed, 0, len(fields))
+	for _, field := range fields {
+		if strings.TrimSpace(field.Name) == "" {
+			return &JSONLError{
+				Message: "Computed field name cannot be empty",
+				Err:     ErrInvalidExpression,
+			}
+		}
+
+		expr, err := parseExpression(field.Expression)
+		if err != nil {
+			return &JSONLError{
+				Message: fmt.Sprintf("Invalid expression for %s: %v", field.Name, err),
+				Err:     ErrInvalidExpression,
+			}
+		}
+		compiled = append(compiled, &compiledComputed{field: field, expr: expr})
+	}
+
+	a.stateMu.Lock()
+	a.computedFields = compiled
+	a.applyDerivedFields()
+	a.bumpDataVersion()
+	a.stateMu.Unlock()
+	return nil
+}
+
+// GetComputedFields returns the active computed field definitions
+func (a *App) GetComputedFields() []ComputedField {
+	a.stateMu.RLock()
+	defer a.stateMu.RUnlock()
+
+	fields := make([]ComputedField, 0, len(a.computedFields))
+	for _, computed := range a.computedFields {
+		fields = append(fields, computed.field)
+	}
+	return fields
+}
@@ -0,0 +1,422 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Conversion directions
+const (
+	ConvertJSONLToJSON = "jsonl-to-json"
+	ConvertJSONToJSONL = "json-to-jsonl"
+)
+
+// ErrUnsupportedConversion is returned for unknown conversion directions or formats
+var ErrUnsupportedConversion = errors.New("unsupported conversion")
+
+// ConversionResult summarizes a completed file conversion
+type ConversionResult struct {
+	InputPath      string `json:"inputPath"`
+	OutputPath     string `json:"outputPath"`
+	Direction      string `json:"direction"`
+	RecordsWritten int    `json:"recordsWritten"`
+	SkippedLines   []int  `json:"skippedLines"` // invalid input lines left out of the output
+}
+
+// convertJSONLToJSONArray streams JSONL lines into a single JSON array, skipping invalid lines
+func convertJSONLToJSONArray(r io.Reader, w io.Writer, result *ConversionResult) error {
+	reader := bufio.NewReader(r)
+	writer := bufio.NewWriter(w)
+
+	if _, err := writer.WriteString("["); err != nil {
+		return err
+	}
+
+	lineNumber := 0
+	for {
+		line, readErr := reader.ReadString('\n')
+		if readErr != nil && readErr != io.EOF {
+			return readErr
+		}
+		if line != "" {
+			lineNumber++
+			trimmed := strings.TrimSpace(line)
+			if trimmed != "" {
+				if !json.Valid([]byte(trimmed)) {
+					result.SkippedLines = append(result.SkippedLines, lineNumber)
+				} else {
+					separator := "\n"
+					if result.RecordsWritten > 0 {
+						separator = ",\n"
+					}
+					if _, err := writer.WriteString(separator + trimmed); err != nil {
+						return err
+					}
+					result.RecordsWritten++
+				}
+			}
+		}
+		if readErr == io.EOF {
+			break
+		}
+	}
+
+	if _, err := writer.WriteString("\n]\n"); err != nil {
+		return err
+	}
+	return writer.Flush()
+}
+
+// convertJSONArrayToJSONL streams the elements of a top-level JSON array as JSONL lines
+func convertJSONArrayToJSONL(r io.Reader, w io.Writer, result *ConversionResult) error {
+	decoder := json.NewDecoder(bufio.NewReader(r))
+	writer := bufio.NewWriter(w)
+
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '[' {
+		return &JSONLError{
+			Message: "Input is not a JSON array",
+			Err:     ErrInvalidJSONL,
+		}
+	}
+
+	var compacted bytes.Buffer
+	for decoder.More() {
+		var element json.RawMessage
+		if err := decoder.Decode(&element); err != nil {
+			return err
+		}
+
+		compacted.Reset()
+		if err := json.Compact(&compacted, element); err != nil {
+			return err
+		}
+		compacted.WriteByte('\n')
+		if _, err := writer.Write(compacted.Bytes()); err != nil {
+			return err
+		}
+		result.RecordsWritten++
+	}
+
+	// Consume the closing bracket so truncated input is reported
+	if _, err := decoder.Token(); err != nil {
+		return err
+	}
+
+	return writer.Flush()
+}
+
+// ConvertFile converts between JSONL and a single JSON array file, streaming in both
+// directions so large files are never held in memory
+func (a *App) ConvertFile(inputPath, outputPath, direction string) (*ConversionResult, error) {
+	var convert func(io.Reader, io.Writer, *ConversionResult) error
+	switch direction {
+	case ConvertJSONLToJSON:
+		convert = convertJSONLToJSONArray
+	case ConvertJSONToJSONL:
+		convert = convertJSONArrayToJSONL
+	default:
+		return nil, &JSONLError{
+			Message: fmt.Sprintf("Unknown conversion direction %q", direction),
+			Err:     ErrUnsupportedConversion,
+		}
+	}
+
+	if inputPath == outputPath {
+		return nil, &JSONLError{
+			Message: "Input and output paths must differ",
+			Err:     ErrUnsupportedConversion,
+		}
+	}
+
+	input, err := os.Open(inputPath)
+	if err != nil {
+		return nil, &JSONLError{
+			Message: "Failed to open input file",
+			Err:     ErrFileNotFound,
+		}
+	}
+	defer input.Close()
+
+	output, err := os.Create(outputPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create output file: %w", err)
+	}
+	defer output.Close()
+
+	result := &ConversionResult{
+		InputPath:    inputPath,
+		OutputPath:   outputPath,
+		Direction:    direction,
+		SkippedLines: []int{},
+	}
+
+	if err := convert(input, output, result); err != nil {
+		return nil, &JSONLError{
+			Message: "Conversion failed",
+			Err:     err,
+		}
+	}
+
+	a.audit(AuditEntry{Action: AuditExportSaved, Path: inputPath, Records: result.RecordsWritten, Destination: outputPath, Detail: "convert " + direction})
+	return result, nil
+}
+
+// Output formats supported by batch conversion
+const (
+	FormatJSONL   = "jsonl"
+	FormatJSON    = "json"
+	FormatCSV     = "csv"
+	FormatParquet = "parquet"
+)
+
+// multiCloser closes several closers in order, returning the first error
+type multiCloser []io.Closer
+
+func (m multiCloser) Close() error {
+	var firstErr error
+	for _, c := range m {
+		if err := c.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// readCloser combines a reader with the closers of its underlying sources
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// openConversionInput opens an input file, transparently decompressing it, and
+// returns the input format implied by the extension before any compression suffix
+func openConversionInput(path string) (io.ReadCloser, string, error) {
+	reader, err := openJSONLSource(path)
+	if err != nil {
+		return nil, "", err
+	}
+
+	name := strings.ToLower(path)
+	for _, suffix := range []string{".gz", ".zst", ".bz2"} {
+		name = strings.TrimSuffix(name, suffix)
+	}
+
+	format := FormatJSONL
+	if strings.HasSuffix(name, ".json") {
+		format = FormatJSON
+	}
+	return reader, format, nil
+}
+
+// forEachJSONValue calls fn with every JSON value of a JSONL stream or JSON array,
+// recording invalid JSONL lines in result
+func forEachJSONValue(r io.Reader, format string, result *ConversionResult, fn func(raw []byte) error) error {
+	if format == FormatJSON {
+		decoder := json.NewDecoder(bufio.NewReader(r))
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		if delim, ok := token.(json.Delim); !ok || delim != '[' {
+			return &JSONLError{
+				Message: "Input is not a JSON array",
+				Err:     ErrInvalidJSONL,
+			}
+		}
+		for decoder.More() {
+			var element json.RawMessage
+			if err := decoder.Decode(&element); err != nil {
+				return err
+			}
+			if err := fn(element); err != nil {
+				return err
+			}
+		}
+		_, err = decoder.Token()
+		return err
+	}
+
+	reader := bufio.NewReader(r)
+	lineNumber := 0
+	for {
+		line, readErr := reader.ReadString('\n')
+		if readErr != nil && readErr != io.EOF {
+			return readErr
+		}
+		if line != "" {
+			lineNumber++
+			trimmed := strings.TrimSpace(line)
+			if trimmed != "" {
+				if !json.Valid([]byte(trimmed)) {
+					result.SkippedLines = append(result.SkippedLines, lineNumber)
+				} else if err := fn([]byte(trimmed)); err != nil {
+					return err
+				}
+			}
+		}
+		if readErr == io.EOF {
+			return nil
+		}
+	}
+}
+
+// csvRow flattens a JSON value into leaf field paths and their text values
+func csvRow(raw []byte) (map[string]string, error) {
+	content, value, err := parseRecordLine(string(raw))
+	if err != nil {
+		return nil, err
+	}
+
+	row := make(map[string]string)
+	if content == nil {
+		row["value"] = valueToString(value)
+		return row, nil
+	}
+
+	walkFields(content, "", func(path string, fieldValue interface{}) {
+		switch fieldValue.(type) {
+		case map[string]interface{}:
+			return
+		case nil:
+			row[path] = ""
+		default:
+			row[path] = valueToString(fieldValue)
+		}
+	})
+	return row, nil
+}
+
+// convertToCSV writes the values of an input file as CSV with one column per leaf
+// field path; the input is read twice so the header can be computed up front
+func convertToCSV(inputPath string, w io.Writer, result *ConversionResult) error {
+	columnSet := make(map[string]bool)
+	firstPass := &ConversionResult{}
+	if err := withConversionInput(inputPath, func(r io.Reader, format string) error {
+		return forEachJSONValue(r, format, firstPass, func(raw []byte) error {
+			row, err := csvRow(raw)
+			if err != nil {
+				return err
+			}
+			for column := range row {
+				columnSet[column] = true
+			}
+			return nil
+		})
+	}); err != nil {
+		return err
+	}
+
+	columns := make([]string, 0, len(columnSet))
+	for column := range columnSet {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(columns); err != nil {
+		return err
+	}
+
+	record := make([]string, len(columns))
+	err := withConversionInput(inputPath, func(r io.Reader, format string) error {
+		return forEachJSONValue(r, format, result, func(raw []byte) error {
+			row, err := csvRow(raw)
+			if err != nil {
+				return err
+			}
+			for i, column := range columns {
+				record[i] = row[column]
+			}
+			result.RecordsWritten++
+			return writer.Write(record)
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// withConversionInput opens an input file for the duration of fn
+func withConversionInput(path string, fn func(r io.Reader, format string) error) error {
+	reader, format, err := openConversionInput(path)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	return fn(reader, format)
+}
+
+// convertFileToFormat converts one input file (JSONL, JSON array, optionally gzipped)
+// to the requested output format
+func convertFileToFormat(inputPath, outputPath, outputFormat string) (*ConversionResult, error) {
+	result := &ConversionResult{
+		InputPath:    inputPath,
+		OutputPath:   outputPath,
+		Direction:    outputFormat,
+		SkippedLines: []int{},
+	}
+
+	if outputFormat != FormatJSONL && outputFormat != FormatJSON && outputFormat != FormatCSV && outputFormat != FormatParquet {
+		return nil, &JSONLError{
+			Message: fmt.Sprintf("Unknown output format %q", outputFormat),
+			Err:     ErrUnsupportedConversion,
+		}
+	}
+
+	output, err := os.Create(outputPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create output file: %w", err)
+	}
+	defer output.Close()
+
+	if outputFormat == FormatCSV {
+		return result, convertToCSV(inputPath, output, result)
+	}
+	if outputFormat == FormatParquet {
+		return result, convertToParquet(inputPath, output, result)
+	}
+
+	err = withConversionInput(inputPath, func(r io.Reader, format string) error {
+		switch {
+		case format == FormatJSONL && outputFormat == FormatJSON:
+			return convertJSONLToJSONArray(r, output, result)
+		case format == FormatJSON && outputFormat == FormatJSONL:
+			return convertJSONArrayToJSONL(r, output, result)
+		case format == FormatJSONL:
+			// Same format (e.g. decompressing .jsonl.gz): rewrite valid lines
+			writer := bufio.NewWriter(output)
+			err := forEachJSONValue(r, format, result, func(raw []byte) error {
+				result.RecordsWritten++
+				_, err := writer.Write(append(raw, '\n'))
+				return err
+			})
+			if err != nil {
+				return err
+			}
+			return writer.Flush()
+		default:
+			_, err := io.Copy(output, r)
+			return err
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestConvertFileRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	jsonlPath := filepath.Join(dir, "input.jsonl")
+	jsonPath := filepath.Join(dir, "output.json")
+	roundTripPath := filepath.Join(dir, "roundtrip.jsonl")
+
+	input := "{\"id\":1,\"name\":\"a\"}\n\nnot json\n{\"id\":2, \"tags\":[1,2]}\n[1,2]"
+	if err := os.WriteFile(jsonlPath, []byte(input), 0644); err != nil {
+		t.Fatalf("Failed to write input: %v", err)
+	}
+
+	app := &App{}
+	result, err := app.ConvertFile(jsonlPath, jsonPath, ConvertJSONLToJSON)
+	if err != nil {
+		t.Fatalf("ConvertFile to JSON returned error: %v", err)
+	}
+	if result.RecordsWritten != 3 || !reflect.DeepEqual(result.SkippedLines, []int{3}) {
+		t.Errorf("Unexpected JSONL to JSON result: %+v", result)
+	}
+
+	jsonOutput, _ := os.ReadFile(jsonPath)
+	expectedJSON := "[\n{\"id\":1,\"name\":\"a\"},\n{\"id\":2, \"tags\":[1,2]},\n[1,2]\n]\n"
+	if string(jsonOutput) != expectedJSON {
+		t.Errorf("Expected JSON output %q, got %q", expectedJSON, jsonOutput)
+	}
+
+	result, err = app.ConvertFile(jsonPath, roundTripPath, ConvertJSONToJSONL)
+	if err != nil {
+		t.Fatalf("ConvertFile to JSONL returned error: %v", err)
+	}
+	if result.RecordsWritten != 3 {
+		t.Errorf("Expected 3 records written, got %d", result.RecordsWritten)
+	}
+
+	jsonlOutput, _ := os.ReadFile(roundTripPath)
+	expectedJSONL := "{\"id\":1,\"name\":\"a\"}\n{\"id\":2,\"tags\":[1,2]}\n[1,2]\n"
+	if string(jsonlOutput) != expectedJSONL {
+		t.Errorf("Expected JSONL output %q, got %q", expectedJSONL, jsonlOutput)
+	}
+}
+
+func TestConvertFileErrors(t *testing.T) {
+	dir := t.TempDir()
+	objectPath := filepath.Join(dir, "object.json")
+	if err := os.WriteFile(objectPath, []byte(`{"not":"an array"}`), 0644); err != nil {
+		t.Fatalf("Failed to write input: %v", err)
+	}
+
+	app := &App{}
+	tests := []struct {
+		name      string
+		input     string
+		direction string
+	}{
+		{"Unknown direction", objectPath, "csv"},
+		{"Not an array", objectPath, ConvertJSONToJSONL},
+		{"Missing input", filepath.Join(dir, "missing.jsonl"), ConvertJSONLToJSON},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := app.ConvertFile(tt.input, filepath.Join(dir, "out"), tt.direction); err == nil {
+				t.Errorf("Expected error for %s", tt.name)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"sort"
+	"strings"
+)
+
+// MatchCount holds match totals for a search without any records
+type MatchCount struct {
+	Query        string       `json:"query"`
+	Total        int          `json:"total"`
+	TotalMatches int          `json:"totalMatches"`
+	FacetField   string       `json:"facetField,omitempty"`
+	Facets       []ValueCount `json:"facets,omitempty"` // matches per value of FacetField, most frequent first
+}
+
+// missingFacetValue labels matching records that lack the facet field
+const missingFacetValue = "(missing)"
+
+// CountMatches returns how many records match the search options without building
+// or serializing any result records. With facetField set, matches are also counted
+// per value of that field.
+func (a *App) CountMatches(options SearchOptions, facetField string) (*MatchCount, error) {
+	a.stateMu.RLock()
+	defer a.stateMu.RUnlock()
+	return a.countMatches(options, facetField)
+}
+
+// countMatches implements CountMatches; the caller holds stateMu
+func (a *App) countMatches(options SearchOptions, facetField string) (*MatchCount, error) {
+	if a.currentFile == nil || a.cache == nil {
+		return nil, &JSONLError{
+			Message: "No file currently loaded",
+			Err:     ErrNoFileLoaded,
+		}
+	}
+
+	result := &MatchCount{
+		Query:      options.Query,
+		Total:      a.cache.totalCount,
+		FacetField: facetField,
+	}
+
+	if strings.TrimSpace(options.Query) == "" {
+		return result, nil
+	}
+
+	var facetCounts map[string]int
+	if facetField != "" {
+		facetCounts = make(map[string]int)
+	}
+
+	matches, err := a.searchPredicate(options)
+	if err != nil {
+		return nil, err
+	}
+	err = a.cache.forEach(func(record JSONRecord) bool {
+		if !matches(record) {
+			return true
+		}
+		result.TotalMatches++
+
+		if facetCounts != nil {
+			value, exists := getFieldValue(record.Content, facetField)
+			if exists {
+				facetCounts[valueToString(value)]++
+			} else {
+				facetCounts[missingFacetValue]++
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if facetCounts != nil {
+		result.Facets = make([]ValueCount, 0, len(facetCounts))
+		for value, count := range facetCounts {
+			result.Facets = append(result.Facets, ValueCount{Value: value, Count: count})
+		}
+		sort.Slice(result.Facets, func(i, j int) bool {
+			if result.Facets[i].Count != result.Facets[j].Count {
+				return result.Facets[i].Count > result.Facets[j].Count
+			}
+			return result.Facets[i].Value < result.Facets[j].Value
+		})
+	}
+
+	return result, nil
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCountMatches(t *testing.T) {
+	app := newTestApp(t, `{"level":"error","msg":"timeout"}
+{"level":"warn","msg":"timeout soon"}
+{"level":"error","msg":"timeout again"}
+{"msg":"timeout unknown"}
+{"level":"info","msg":"ok"}`)
+
+	count, err := app.CountMatches(SearchOptions{Query: "timeout"}, "level")
+	if err != nil {
+		t.Fatalf("CountMatches returned error: %v", err)
+	}
+
+	if count.Total != 5 || count.TotalMatches != 4 {
+		t.Errorf("Unexpected totals: %+v", count)
+	}
+	expected := []ValueCount{{"error", 2}, {missingFacetValue, 1}, {"warn", 1}}
+	if !reflect.DeepEqual(count.Facets, expected) {
+		t.Errorf("Expected facets %v, got %v", expected, count.Facets)
+	}
+
+	search, err := app.SearchRecords(SearchOptions{Query: "level:error AND msg:again", UseLucene: true})
+	if err != nil {
+		t.Fatalf("SearchRecords returned error: %v", err)
+	}
+	count, err = app.CountMatches(SearchOptions{Query: "level:error AND msg:again", UseLucene: true}, "")
+	if err != nil {
+		t.Fatalf("CountMatches returned error: %v", err)
+	}
+	if count.TotalMatches != search.TotalMatches || count.Facets != nil {
+		t.Errorf("Expected count to agree with search (%d), got %+v", search.TotalMatches, count)
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+// setDerivedValue adds a derived field to content unless the record already has a
+// real field of that name
+func setDerivedValue(content map[string]interface{}, name string, value interface{}) {
+	if _, exists := content[name]; exists {
+		return
+	}
+	content[name] = value
+}
+
+// hasDerivedFields reports whether any virtual field rules are defined
+func (a *App) hasDerivedFields() bool {
+	return len(a.extractionRules) > 0 || len(a.computedFields) > 0
+}
+
+// applyDerivedFields installs the virtual fields of the defined rules on the loaded
+// records. They are computed as records are read, so search, facets, sorting and
+// export treat them as real fields while the loaded data itself is left unchanged.
+func (a *App) applyDerivedFields() {
+	if a.cache == nil {
+		return
+	}
+
+	var derive func(content map[string]interface{})
+	if a.hasDerivedFields() {
+		derive = a.deriveFields
+	}
+	a.cache.derive = derive
+
+	// Disk-backed records get their virtual fields as pages are decoded
+	if a.cache.diskBacked() {
+		a.cache.index.derive = derive
+		a.cache.index.pages.reset()
+	}
+}
+
+// deriveFields adds the virtual fields of the defined rules to a record's content
+func (a *App) deriveFields(content map[string]interface{}) {
+	// Non-object records have no fields to derive from
+	if content == nil {
+		return
+	}
+	for _, rule := range a.extractionRules {
+		rule.apply(content)
+	}
+	for _, computed := range a.computedFields {
+		computed.apply(content)
+	}
+}
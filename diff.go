@@ -0,0 +1,179 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+)
+
+// ErrNoBaseline is returned when diffing without a pinned baseline record
+var ErrNoBaseline = errors.New("no baseline record pinned")
+
+// Field change kinds reported by record diffs
+const (
+	FieldAdded   = "added"
+	FieldRemoved = "removed"
+	FieldChanged = "changed"
+)
+
+// FieldDiff is a single field-level difference between two records
+type FieldDiff struct {
+	Path   string      `json:"path"`
+	Change string      `json:"change"` // 'added', 'removed', 'changed'
+	Before interface{} `json:"before,omitempty"`
+	After  interface{} `json:"after,omitempty"`
+}
+
+// RecordDiff compares a record with the pinned baseline record
+type RecordDiff struct {
+	Record       JSONRecord  `json:"record"`
+	BaselineLine int         `json:"baselineLine"`
+	Identical    bool        `json:"identical"`
+	Diffs        []FieldDiff `json:"diffs"`
+}
+
+// diffContent returns the field-level differences from before to after, descending
+// into nested objects with dot paths and comparing arrays as whole values
+func diffContent(before, after map[string]interface{}) []FieldDiff {
+	diffs := []FieldDiff{}
+	diffObjects("", before, after, &diffs)
+	sort.Slice(diffs, func(i, j int) bool {
+		return diffs[i].Path < diffs[j].Path
+	})
+	return diffs
+}
+
+func diffObjects(prefix string, before, after map[string]interface{}, diffs *[]FieldDiff) {
+	for key, beforeValue := range before {
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+
+		afterValue, exists := after[key]
+		if !exists {
+			*diffs = append(*diffs, FieldDiff{Path: path, Change: FieldRemoved, Before: beforeValue})
+			continue
+		}
+
+		beforeObject, beforeIsObject := beforeValue.(map[string]interface{})
+		afterObject, afterIsObject := afterValue.(map[string]interface{})
+		if beforeIsObject && afterIsObject {
+			diffObjects(path, beforeObject, afterObject, diffs)
+			continue
+		}
+
+		if !reflect.DeepEqual(beforeValue, afterValue) {
+			*diffs = append(*diffs, FieldDiff{Path: path, Change: FieldChanged, Before: beforeValue, After: afterValue})
+		}
+	}
+
+	for key, afterValue := range after {
+		if _, exists := before[key]; exists {
+			continue
+		}
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+		*diffs = append(*diffs, FieldDiff{Path: path, Change: FieldAdded, After: afterValue})
+	}
+}
+
+// PinBaseline pins the record at lineNumber as the baseline for DiffAgainstBaseline.
+// While pinned, the records returned by GetRecords and GetRecordByLineNumber carry
+// their BaselineDiffs. The record is deep-copied, so later changes to the loaded
+// record do not move the baseline.
+func (a *App) PinBaseline(lineNumber int) (*JSONRecord, error) {
+	a.stateMu.Lock()
+	defer a.stateMu.Unlock()
+
+	record, err := a.recordByLineNumber(lineNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	baseline := *record
+	if record.Content != nil {
+		baseline.Content = deepCopyValue(record.Content).(map[string]interface{})
+	}
+	baseline.Value = deepCopyValue(record.Value)
+	if record.Duplicates != nil {
+		baseline.Duplicates = make(map[string][]interface{}, len(record.Duplicates))
+		for key, values := range record.Duplicates {
+			baseline.Duplicates[key] = deepCopyValue(values).([]interface{})
+		}
+	}
+	a.baseline = &baseline
+	return &baseline, nil
+}
+
+// withBaselineDiffs returns copies of records carrying their diffs against the
+// pinned baseline, or records unchanged when none is pinned. Lines that are not
+// JSON objects are left without diffs. The caller holds stateMu.
+func (a *App) withBaselineDiffs(records []JSONRecord) []JSONRecord {
+	if a.baseline == nil {
+		return records
+	}
+	annotated := make([]JSONRecord, len(records))
+	for i, record := range records {
+		if record.Content != nil && !record.Invalid {
+			record.BaselineDiffs = diffContent(a.baseline.Content, record.Content)
+		}
+		annotated[i] = record
+	}
+	return annotated
+}
+
+// ClearBaseline unpins the baseline record
+func (a *App) ClearBaseline() {
+	a.stateMu.Lock()
+	defer a.stateMu.Unlock()
+	a.baseline = nil
+}
+
+// GetBaseline returns the pinned baseline record, or nil when none is pinned
+func (a *App) GetBaseline() *JSONRecord {
+	a.stateMu.RLock()
+	defer a.stateMu.RUnlock()
+	return a.baseline
+}
+
+// DiffAgainstBaseline returns field-level diffs of the records at the given line
+// numbers against the pinned baseline; unknown line numbers are skipped
+func (a *App) DiffAgainstBaseline(lineNumbers []int) ([]RecordDiff, error) {
+	a.stateMu.RLock()
+	defer a.stateMu.RUnlock()
+
+	if a.currentFile == nil || a.cache == nil {
+		return nil, &JSONLError{
+			Message: "No file currently loaded",
+			Err:     ErrNoFileLoaded,
+		}
+	}
+
+	if a.baseline == nil {
+		return nil, &JSONLError{
+			Message: "No baseline record pinned",
+			Err:     ErrNoBaseline,
+		}
+	}
+
+	result := []RecordDiff{}
+	for _, lineNumber := range lineNumbers {
+		record, err := a.recordByLineNumber(lineNumber)
+		if err != nil {
+			continue
+		}
+
+		diffs := diffContent(a.baseline.Content, record.Content)
+		result = append(result, RecordDiff{
+			Record:       *record,
+			BaselineLine: a.baseline.LineNumber,
+			Identical:    len(diffs) == 0,
+			Diffs:        diffs,
+		})
+	}
+
+	return result, nil
+}
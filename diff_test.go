@@ -0,0 +1,93 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffContent(t *testing.T) {
+	before := map[string]interface{}{
+		"status": "ok",
+		"code":   float64(200),
+		"user":   map[string]interface{}{"name": "ann", "role": "admin"},
+		"tags":   []interface{}{"a"},
+		"debug":  true,
+	}
+	after := map[string]interface{}{
+		"status": "ok",
+		"code":   float64(500),
+		"user":   map[string]interface{}{"name": "ann", "role": "guest"},
+		"tags":   []interface{}{"a", "b"},
+		"error":  "timeout",
+	}
+
+	expected := []FieldDiff{
+		{Path: "code", Change: FieldChanged, Before: float64(200), After: float64(500)},
+		{Path: "debug", Change: FieldRemoved, Before: true},
+		{Path: "error", Change: FieldAdded, After: "timeout"},
+		{Path: "tags", Change: FieldChanged, Before: []interface{}{"a"}, After: []interface{}{"a", "b"}},
+		{Path: "user.role", Change: FieldChanged, Before: "admin", After: "guest"},
+	}
+
+	if result := diffContent(before, after); !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, result)
+	}
+}
+
+func TestDiffAgainstBaseline(t *testing.T) {
+	app := newTestApp(t, `{"status":"ok","code":200}
+{"status":"ok","code":200}
+{"status":"fail","code":500}`)
+
+	if _, err := app.DiffAgainstBaseline([]int{2}); err == nil {
+		t.Errorf("Expected an error without a pinned baseline")
+	}
+
+	if _, err := app.PinBaseline(1); err != nil {
+		t.Fatalf("PinBaseline returned error: %v", err)
+	}
+
+	diffs, err := app.DiffAgainstBaseline([]int{2, 3, 99})
+	if err != nil {
+		t.Fatalf("DiffAgainstBaseline returned error: %v", err)
+	}
+	if len(diffs) != 2 {
+		t.Fatalf("Expected 2 diffs, got %d", len(diffs))
+	}
+	if !diffs[0].Identical || diffs[0].BaselineLine != 1 {
+		t.Errorf("Expected line 2 to be identical to the baseline, got %+v", diffs[0])
+	}
+	if diffs[1].Identical || len(diffs[1].Diffs) != 2 {
+		t.Errorf("Expected two changed fields on line 3, got %+v", diffs[1])
+	}
+
+	// Pages and single records carry their diffs against the baseline
+	page, err := app.GetRecords(0, 10)
+	if err != nil {
+		t.Fatalf("GetRecords returned error: %v", err)
+	}
+	if len(page.Records[1].BaselineDiffs) != 0 || len(page.Records[2].BaselineDiffs) != 2 {
+		t.Errorf("Expected diffs on line 3 only, got %+v", page.Records)
+	}
+	record, err := app.GetRecordByLineNumber(3)
+	if err != nil || len(record.BaselineDiffs) != 2 || record.BaselineDiffs[0].Path != "code" {
+		t.Errorf("Expected line 3 with its diffs, got %+v (%v)", record, err)
+	}
+	if app.cache.records[2].BaselineDiffs != nil {
+		t.Error("Expected the loaded records to be left without diffs")
+	}
+
+	// The baseline is a copy, unaffected by changes to the loaded record
+	app.cache.records[0].Content["status"] = "changed"
+	if app.GetBaseline().Content["status"] != "ok" {
+		t.Errorf("Expected the pinned baseline to be unchanged, got %+v", app.GetBaseline())
+	}
+
+	app.ClearBaseline()
+	if app.GetBaseline() != nil {
+		t.Errorf("Expected baseline to be cleared")
+	}
+	if page, _ := app.GetRecords(0, 10); page.Records[2].BaselineDiffs != nil {
+		t.Errorf("Expected no diffs without a baseline, got %+v", page.Records[2])
+	}
+}
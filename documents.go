@@ -0,0 +1,517 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// ErrDocumentNotFound is returned for unknown document IDs
+var ErrDocumentNotFound = errors.New("document not found")
+
+// document is the state of one open file (tab). The active document's state lives
+// in the App fields; it is copied back into its document before switching. Methods
+// taking a document ID read any tab through readDocument; they cover the records,
+// search, fields, statistics, invalid lines and exports of a tab. Methods changing
+// a tab, such as sorting, filters or reloads, act on the active tab, and settings,
+// loaders and other app-wide methods take no document ID. Documents are guarded
+// by stateMu like the App's file state.
+type document struct {
+	id           string
+	state        fileState
+	activeSearch SearchOptions
+}
+
+// DocumentInfo describes an open document (tab)
+type DocumentInfo struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Path    string `json:"path"`
+	Records int    `json:"records"`
+	Active  bool   `json:"active"`
+}
+
+// FileLoadProgress is emitted as the "load:file" event while opening several files
+type FileLoadProgress struct {
+	Index    int    `json:"index"` // 1-based position in the batch
+	Total    int    `json:"total"`
+	Path     string `json:"path"`
+	Status   string `json:"status"` // 'loading', 'loaded', 'failed'
+	Error    string `json:"error,omitempty"`
+	Document string `json:"document,omitempty"` // ID of the opened document
+}
+
+// info returns the document description shown in tabs
+func (d *document) info(active bool) DocumentInfo {
+	info := DocumentInfo{ID: d.id, Active: active}
+	if file := d.state.currentFile; file != nil {
+		info.Name = file.Name
+		info.Path = file.Path
+		info.Records = file.Records
+	}
+	return info
+}
+
+// activeDocument returns the document whose state is in the App fields, if any.
+// The caller holds stateMu.
+func (a *App) activeDocument() *document {
+	for _, doc := range a.documents {
+		if doc.id == a.activeDocumentID {
+			return doc
+		}
+	}
+	return nil
+}
+
+// storeActiveDocument copies the App's file state into the active document,
+// creating a document for a file that was loaded before any tabs existed. The
+// caller holds stateMu exclusively.
+func (a *App) storeActiveDocument() {
+	if a.currentFile == nil {
+		return
+	}
+
+	doc := a.activeDocument()
+	if doc == nil {
+		a.nextDocumentID++
+		doc = &document{id: fmt.Sprintf("doc-%d", a.nextDocumentID)}
+		a.documents = append(a.documents, doc)
+		a.activeDocumentID = doc.id
+	}
+
+	a.saveDocumentState(doc)
+}
+
+// saveDocumentState copies the App's file state into doc
+func (a *App) saveDocumentState(doc *document) {
+	doc.state = a.fileState
+	doc.activeSearch = a.currentSearch()
+}
+
+// loadDocumentState copies doc's file state into the App
+func (a *App) loadDocumentState(doc *document) {
+	a.activeDocumentID = doc.id
+	a.fileState = doc.state
+	a.setActiveSearch(doc.activeSearch)
+}
+
+// restoreDocument makes doc the active document by loading its state into the App.
+// The caller holds stateMu exclusively.
+func (a *App) restoreDocument(doc *document) {
+	if cancel := a.swapProgressiveCancel(nil); cancel != nil {
+		cancel()
+	}
+	a.loadDocumentState(doc)
+}
+
+// documentByID returns the open document with the given ID. The caller holds stateMu.
+func (a *App) documentByID(id string) (*document, error) {
+	for _, doc := range a.documents {
+		if doc.id == id {
+			return doc, nil
+		}
+	}
+	return nil, &JSONLError{
+		Message: fmt.Sprintf("Document %s is not open", id),
+		Err:     ErrDocumentNotFound,
+	}
+}
+
+// inDocument runs fn with the state of document id loaded into the App, so any
+// tab can be queried without switching to it. The active tab is restored afterwards
+// and state changed by fn, such as the last search, is kept in the queried document.
+// fn runs with stateMu held exclusively, so it must not call methods locking it.
+func (a *App) inDocument(id string, fn func() error) error {
+	a.stateMu.Lock()
+	defer a.stateMu.Unlock()
+
+	a.storeActiveDocument()
+	doc, err := a.documentByID(id)
+	if err != nil {
+		return err
+	}
+
+	active := a.activeDocument()
+	if doc == active {
+		return fn()
+	}
+
+	// Leave the active tab's background work, e.g. a progressive count, running
+	progressiveCancel := a.swapProgressiveCancel(nil)
+	a.loadDocumentState(doc)
+	defer func() {
+		a.saveDocumentState(doc)
+		a.swapProgressiveCancel(progressiveCancel)
+		if active != nil {
+			a.loadDocumentState(active)
+		}
+	}()
+	return fn()
+}
+
+// readDocument runs fn, which only reads the file state, with the state of document
+// id loaded into the App. The active tab is read under a shared lock, other tabs
+// through inDocument. fn must not call methods locking stateMu.
+func (a *App) readDocument(id string, fn func() error) error {
+	a.stateMu.RLock()
+	if a.currentFile != nil && id == a.activeDocumentID {
+		defer a.stateMu.RUnlock()
+		return fn()
+	}
+	a.stateMu.RUnlock()
+	return a.inDocument(id, fn)
+}
+
+// openInNewDocument loads a file into a new tab and makes it active
+func (a *App) openInNewDocument(path string) (*document, error) {
+	// Activate the new tab before loading so the previous tab is not overwritten
+	a.stateMu.Lock()
+	a.storeActiveDocument()
+	previous := a.activeDocument()
+	a.nextDocumentID++
+	doc := &document{id: fmt.Sprintf("doc-%d", a.nextDocumentID)}
+	a.documents = append(a.documents, doc)
+	a.activeDocumentID = doc.id
+	a.stateMu.Unlock()
+
+	_, err := a.loadPath(path)
+
+	a.stateMu.Lock()
+	defer a.stateMu.Unlock()
+	if err != nil {
+		a.removeDocument(doc.id)
+		if previous != nil {
+			a.restoreDocument(previous)
+		} else {
+			a.activeDocumentID = ""
+		}
+		return nil, err
+	}
+	a.saveDocumentState(doc)
+	return doc, nil
+}
+
+// removeDocument drops the document with the given ID from the tabs. The caller
+// holds stateMu exclusively.
+func (a *App) removeDocument(id string) {
+	for i, doc := range a.documents {
+		if doc.id == id {
+			a.documents = append(a.documents[:i], a.documents[i+1:]...)
+			return
+		}
+	}
+}
+
+// OpenFilesInTabs opens each path in its own tab, emitting a "load:file" event per
+// file. Files that fail to load are reported in the events and skipped; the first
+// opened file becomes the active tab.
+func (a *App) OpenFilesInTabs(paths []string) ([]DocumentInfo, error) {
+	var first *document
+	var lastErr error
+
+	for i, path := range paths {
+		progress := FileLoadProgress{Index: i + 1, Total: len(paths), Path: path, Status: "loading"}
+		a.emitEvent("load:file", progress)
+
+		doc, err := a.openInNewDocument(path)
+		if err != nil {
+			lastErr = err
+			progress.Status = "failed"
+			progress.Error = err.Error()
+			a.emitEvent("load:file", progress)
+			continue
+		}
+
+		if first == nil {
+			first = doc
+		}
+		progress.Status = "loaded"
+		progress.Document = doc.id
+		a.emitEvent("load:file", progress)
+	}
+
+	if first == nil && lastErr != nil {
+		return nil, lastErr
+	}
+	a.stateMu.Lock()
+	defer a.stateMu.Unlock()
+	if first != nil {
+		a.storeActiveDocument()
+		a.restoreDocument(first)
+	}
+	return a.listDocuments(), nil
+}
+
+// ListDocuments returns the open documents in tab order
+func (a *App) ListDocuments() []DocumentInfo {
+	a.stateMu.RLock()
+	if a.currentFile == nil || a.activeDocument() != nil {
+		defer a.stateMu.RUnlock()
+		return a.documentInfos()
+	}
+	a.stateMu.RUnlock()
+
+	// A file loaded before any tabs existed needs its document created first
+	a.stateMu.Lock()
+	defer a.stateMu.Unlock()
+	return a.listDocuments()
+}
+
+// listDocuments stores the active document and describes the open documents. The
+// caller holds stateMu exclusively.
+func (a *App) listDocuments() []DocumentInfo {
+	a.storeActiveDocument()
+	return a.documentInfos()
+}
+
+// documentInfos describes the open documents, reading the active one from the App
+// fields. The caller holds stateMu.
+func (a *App) documentInfos() []DocumentInfo {
+	infos := make([]DocumentInfo, 0, len(a.documents))
+	for _, doc := range a.documents {
+		if doc.id == a.activeDocumentID {
+			active := document{id: doc.id, state: a.fileState}
+			infos = append(infos, active.info(true))
+		} else {
+			infos = append(infos, doc.info(false))
+		}
+	}
+	return infos
+}
+
+// SwitchDocument makes the document with the given ID the active tab
+func (a *App) SwitchDocument(id string) (*JSONLFile, error) {
+	a.stateMu.Lock()
+	defer a.stateMu.Unlock()
+
+	a.storeActiveDocument()
+	doc, err := a.documentByID(id)
+	if err != nil {
+		return nil, err
+	}
+	a.restoreDocument(doc)
+	return doc.state.currentFile, nil
+}
+
+// CloseDocument closes a tab; closing the active tab activates its neighbour or
+// leaves no file loaded when it was the last one
+func (a *App) CloseDocument(id string) error {
+	a.stateMu.Lock()
+	defer a.stateMu.Unlock()
+
+	a.storeActiveDocument()
+	for i, doc := range a.documents {
+		if doc.id != id {
+			continue
+		}
+
+		a.documents = append(a.documents[:i], a.documents[i+1:]...)
+		if id != a.activeDocumentID {
+			return nil
+		}
+
+		if len(a.documents) == 0 {
+			a.activeDocumentID = ""
+			a.fileState = fileState{}
+			a.setActiveSearch(SearchOptions{})
+			return nil
+		}
+		if i >= len(a.documents) {
+			i = len(a.documents) - 1
+		}
+		a.restoreDocument(a.documents[i])
+		return nil
+	}
+
+	return &JSONLError{
+		Message: fmt.Sprintf("Document %s is not open", id),
+		Err:     ErrDocumentNotFound,
+	}
+}
+
+// OpenMultipleFiles opens a native file dialog allowing several JSONL files to be
+// selected and returns their paths
+func (a *App) OpenMultipleFiles() ([]string, error) {
+	paths, err := runtime.OpenMultipleFilesDialog(a.ctx, jsonlDialogOptions("Select JSONL Files"))
+	if err != nil {
+		return nil, &JSONLError{
+			Message: "Failed to open file dialog",
+			Err:     err,
+		}
+	}
+
+	if len(paths) == 0 {
+		return nil, &JSONLError{
+			Message: "File selection cancelled",
+			Err:     errors.New("user cancelled file selection"),
+		}
+	}
+
+	return paths, nil
+}
+
+// GetDocumentRecords returns a page of records of an open document
+func (a *App) GetDocumentRecords(id string, offset, limit int) (page *PaginatedRecords, err error) {
+	err = a.readDocument(id, func() error {
+		page, err = a.getRecords(offset, limit)
+		return err
+	})
+	return page, err
+}
+
+// GetDocumentRecordByLineNumber retrieves a record of an open document by its line number
+func (a *App) GetDocumentRecordByLineNumber(id string, lineNumber int) (record *JSONRecord, err error) {
+	err = a.readDocument(id, func() error {
+		record, err = a.recordByLineNumber(lineNumber)
+		return err
+	})
+	return record, err
+}
+
+// GetDocumentRecordRange returns the records of an open document between two line numbers
+func (a *App) GetDocumentRecordRange(id string, startLine, endLine int) (records []JSONRecord, err error) {
+	err = a.readDocument(id, func() error {
+		records, err = a.recordRange(startLine, endLine)
+		return err
+	})
+	return records, err
+}
+
+// GetDocumentTotalRecordCount returns the number of records of an open document
+func (a *App) GetDocumentTotalRecordCount(id string) (count int, err error) {
+	err = a.readDocument(id, func() error {
+		count, err = a.totalRecordCount()
+		return err
+	})
+	return count, err
+}
+
+// GetDocumentAllRecords returns the records of an open document matching a query
+func (a *App) GetDocumentAllRecords(id, searchQuery string) (records []JSONRecord, err error) {
+	err = a.readDocument(id, func() error {
+		records, err = a.allRecords(searchQuery)
+		return err
+	})
+	return records, err
+}
+
+// SearchDocument searches the records of an open document. Progressive search is
+// only available in the active document.
+func (a *App) SearchDocument(id string, options SearchOptions) (result *SearchResult, err error) {
+	a.stateMu.RLock()
+	active := id == a.activeDocumentID
+	a.stateMu.RUnlock()
+	if !active {
+		options.Progressive = false
+	}
+	err = a.readDocument(id, func() error {
+		result, err = a.searchRecords(options)
+		return err
+	})
+	return result, err
+}
+
+// CountDocumentMatches counts the records of an open document matching a search,
+// see CountMatches
+func (a *App) CountDocumentMatches(id string, options SearchOptions, facetField string) (count *MatchCount, err error) {
+	err = a.readDocument(id, func() error {
+		count, err = a.countMatches(options, facetField)
+		return err
+	})
+	return count, err
+}
+
+// GetDocumentFilteredRecords returns a page of an open document's records passing
+// its filter stack
+func (a *App) GetDocumentFilteredRecords(id string, offset, limit int) (result *SearchResult, err error) {
+	err = a.readDocument(id, func() error {
+		result, err = a.filteredRecords(offset, limit)
+		return err
+	})
+	return result, err
+}
+
+// GetDocumentAllFields returns the field names of an open document
+func (a *App) GetDocumentAllFields(id string) (fields []string, err error) {
+	err = a.readDocument(id, func() error {
+		fields, err = a.allFields()
+		return err
+	})
+	return fields, err
+}
+
+// GetDocumentFieldCatalog returns the field catalog of an open document, see GetFieldCatalog
+func (a *App) GetDocumentFieldCatalog(id string, filter *SearchOptions) (catalog []FieldInfo, err error) {
+	err = a.readDocument(id, func() error {
+		catalog, err = a.fieldCatalog(filter)
+		return err
+	})
+	return catalog, err
+}
+
+// GetDocumentFieldFacets returns the most common values of a field of an open
+// document, see GetFieldFacets
+func (a *App) GetDocumentFieldFacets(id, field string, topN int, filter *SearchOptions) (facets *FieldFacets, err error) {
+	err = a.readDocument(id, func() error {
+		facets, err = a.fieldFacets(field, topN, filter)
+		return err
+	})
+	return facets, err
+}
+
+// GetDocumentStats returns the statistics of an open document
+func (a *App) GetDocumentStats(id string) (stats *FileStats, err error) {
+	err = a.readDocument(id, func() error {
+		stats, err = a.fileStats()
+		return err
+	})
+	return stats, err
+}
+
+// CheckDocumentModification reports whether the file of an open document changed
+// since it was loaded
+func (a *App) CheckDocumentModification(id string) (modified bool, err error) {
+	err = a.readDocument(id, func() error {
+		modified, err = a.checkFileModification()
+		return err
+	})
+	return modified, err
+}
+
+// GetDocumentInvalidLines returns a page of the invalid lines of an open document
+func (a *App) GetDocumentInvalidLines(id string, offset, limit int) (page *PaginatedRecords, err error) {
+	err = a.readDocument(id, func() error {
+		page, err = a.invalidLines(offset, limit)
+		return err
+	})
+	return page, err
+}
+
+// GetDocumentInvalidLineDetails explains the invalid lines of an open document
+func (a *App) GetDocumentInvalidLineDetails(id string, offset, limit int) (details *InvalidLineDetails, err error) {
+	err = a.readDocument(id, func() error {
+		details, err = a.invalidLineDetails(offset, limit)
+		return err
+	})
+	return details, err
+}
+
+// ExportDocumentResults exports the matching records of an open document to a JSONL file
+func (a *App) ExportDocumentResults(id, searchQuery string, shownFields, hiddenFields []string) (path string, err error) {
+	err = a.readDocument(id, func() error {
+		path, err = a.exportRecords(searchQuery, shownFields, hiddenFields, nil)
+		return err
+	})
+	return path, err
+}
+
+// ExportDocumentHTMLViewer exports the matching records of an open document as a
+// standalone HTML page, see ExportHTMLViewer
+func (a *App) ExportDocumentHTMLViewer(id string, options SearchOptions, shownFields, hiddenFields []string) (path string, err error) {
+	err = a.readDocument(id, func() error {
+		path, err = a.exportHTMLViewer(options, shownFields, hiddenFields)
+		return err
+	})
+	return path, err
+}
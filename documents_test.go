@@ -0,0 +1,229 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestOpenFilesInTabs(t *testing.T) {
+	useTempConfigDir(t)
+
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first.jsonl")
+	second := filepath.Join(dir, "second.jsonl")
+	os.WriteFile(first, []byte("{\"n\":1}\n"), 0644)
+	os.WriteFile(second, []byte("{\"n\":1}\n{\"n\":2}\n"), 0644)
+
+	app := &App{}
+	docs, err := app.OpenFilesInTabs([]string{first, filepath.Join(dir, "missing.jsonl"), second})
+	if err != nil {
+		t.Fatalf("OpenFilesInTabs returned error: %v", err)
+	}
+
+	if len(docs) != 2 || docs[0].Path != first || docs[1].Path != second {
+		t.Fatalf("Expected two tabs for the readable files, got %+v", docs)
+	}
+	if !docs[0].Active || app.currentFile.Path != first {
+		t.Errorf("Expected the first file to be active, got %+v", docs)
+	}
+
+	if _, err := app.SwitchDocument(docs[1].ID); err != nil {
+		t.Fatalf("SwitchDocument returned error: %v", err)
+	}
+	if count, _ := app.GetTotalRecordCount(); count != 2 {
+		t.Errorf("Expected 2 records in the second tab, got %d", count)
+	}
+
+	// State is kept per tab
+	if _, err := app.SearchRecords(SearchOptions{Query: "2"}); err != nil {
+		t.Fatalf("SearchRecords returned error: %v", err)
+	}
+	app.SwitchDocument(docs[0].ID)
+	if count, _ := app.GetTotalRecordCount(); count != 1 || app.activeSearch.Query != "" {
+		t.Errorf("Expected the first tab's own state, got %d records and search %q", count, app.activeSearch.Query)
+	}
+
+	if err := app.CloseDocument(docs[0].ID); err != nil {
+		t.Fatalf("CloseDocument returned error: %v", err)
+	}
+	if app.currentFile == nil || app.currentFile.Path != second || app.activeSearch.Query != "2" {
+		t.Errorf("Expected the remaining tab to become active with its search")
+	}
+	app.CloseDocument(docs[1].ID)
+	if app.currentFile != nil || len(app.ListDocuments()) != 0 {
+		t.Errorf("Expected no file loaded after closing every tab")
+	}
+
+	if _, err := app.SwitchDocument("doc-99"); err == nil {
+		t.Errorf("Expected an error for an unknown document")
+	}
+}
+
+func TestDocumentScopedMethods(t *testing.T) {
+	useTempConfigDir(t)
+
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first.jsonl")
+	second := filepath.Join(dir, "second.jsonl")
+	os.WriteFile(first, []byte("{\"n\":1}\n"), 0644)
+	os.WriteFile(second, []byte("{\"n\":1}\n{\"n\":2}\nbad\n{\"n\":22}\n"), 0644)
+
+	app := &App{}
+	docs, err := app.OpenFilesInTabs([]string{first, second})
+	if err != nil {
+		t.Fatalf("OpenFilesInTabs returned error: %v", err)
+	}
+
+	page, err := app.GetDocumentRecords(docs[1].ID, 0, 10)
+	if err != nil || page.Total != 3 {
+		t.Fatalf("Expected 3 records in the background tab, got %+v (%v)", page, err)
+	}
+
+	result, err := app.SearchDocument(docs[1].ID, SearchOptions{Query: "2"})
+	if err != nil || result.TotalMatches != 2 {
+		t.Fatalf("Expected 2 matches in the background tab, got %+v (%v)", result, err)
+	}
+
+	stats, err := app.GetDocumentStats(docs[0].ID)
+	if err != nil || stats.ValidRecords != 1 {
+		t.Errorf("Expected stats of the first tab, got %+v (%v)", stats, err)
+	}
+	stats, err = app.GetDocumentStats(docs[1].ID)
+	if err != nil || stats.ValidRecords != 3 || len(stats.InvalidLines) != 1 || stats.InvalidLines[0] != 3 {
+		t.Errorf("Expected stats of the background tab, got %+v (%v)", stats, err)
+	}
+
+	// Fields, counts, facets and invalid lines of any tab
+	if count, err := app.GetDocumentTotalRecordCount(docs[1].ID); err != nil || count != 3 {
+		t.Errorf("Expected 3 records in the background tab, got %d (%v)", count, err)
+	}
+	if fields, err := app.GetDocumentAllFields(docs[1].ID); err != nil || len(fields) != 1 || fields[0] != "n" {
+		t.Errorf("Expected the field n, got %v (%v)", fields, err)
+	}
+	if count, err := app.CountDocumentMatches(docs[1].ID, SearchOptions{Query: "n:2*", UseLucene: true}, ""); err != nil || count.TotalMatches != 2 {
+		t.Errorf("Expected 2 matching records, got %+v (%v)", count, err)
+	}
+	if facets, err := app.GetDocumentFieldFacets(docs[1].ID, "n", 10, nil); err != nil || len(facets.Values) != 3 {
+		t.Errorf("Expected 3 distinct values, got %+v (%v)", facets, err)
+	}
+	if invalid, err := app.GetDocumentInvalidLines(docs[1].ID, 0, 10); err != nil || invalid.Total != 1 {
+		t.Errorf("Expected 1 invalid line, got %+v (%v)", invalid, err)
+	}
+
+	// The active tab is untouched and the background tab kept its search
+	if app.activeDocumentID != docs[0].ID || app.currentFile.Path != first || app.activeSearch.Query != "" {
+		t.Errorf("Expected the first tab to stay active, got %s", app.activeDocumentID)
+	}
+	app.SwitchDocument(docs[1].ID)
+	if app.activeSearch.Query != "2" {
+		t.Errorf("Expected the background search to be kept, got %q", app.activeSearch.Query)
+	}
+
+	// The pinned baseline belongs to its tab
+	if _, err := app.PinBaseline(4); err != nil {
+		t.Fatalf("PinBaseline returned error: %v", err)
+	}
+	app.SwitchDocument(docs[0].ID)
+	if app.GetBaseline() != nil {
+		t.Errorf("Expected no baseline in the first tab, got %+v", app.GetBaseline())
+	}
+	app.SwitchDocument(docs[1].ID)
+	if baseline := app.GetBaseline(); baseline == nil || baseline.LineNumber != 4 {
+		t.Errorf("Expected the second tab's baseline, got %+v", baseline)
+	}
+
+	_, err = app.GetDocumentRecords("doc-missing", 0, 10)
+	var jsonlErr *JSONLError
+	if !errors.As(err, &jsonlErr) || !errors.Is(jsonlErr.Err, ErrDocumentNotFound) {
+		t.Errorf("Expected ErrDocumentNotFound, got %v", err)
+	}
+}
+
+func TestDocumentsConcurrentAccess(t *testing.T) {
+	useTempConfigDir(t)
+
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first.jsonl")
+	second := filepath.Join(dir, "second.jsonl")
+	os.WriteFile(first, []byte("{\"n\":1}\n"), 0644)
+	os.WriteFile(second, []byte("{\"n\":1}\n{\"n\":2}\n"), 0644)
+
+	app := &App{}
+	docs, err := app.OpenFilesInTabs([]string{first, second})
+	if err != nil {
+		t.Fatalf("OpenFilesInTabs returned error: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				app.SwitchDocument(docs[(i+j)%2].ID)
+				app.ListDocuments()
+				app.GetDocumentRecords(docs[j%2].ID, 0, 10)
+				app.GetRecords(0, 10)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if infos := app.ListDocuments(); len(infos) != 2 {
+		t.Errorf("Expected both tabs to stay open, got %+v", infos)
+	}
+
+	// Reading the active tab and listing tabs only need a shared lock
+	active := app.ListDocuments()[0].ID
+	if !app.ListDocuments()[0].Active {
+		active = app.ListDocuments()[1].ID
+	}
+	app.stateMu.RLock()
+	done := make(chan struct{})
+	go func() {
+		app.ListDocuments()
+		app.GetDocumentRecords(active, 0, 10)
+		app.GetDocumentStats(active)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Error("Expected reads of the active tab not to wait for other readers")
+	}
+	app.stateMu.RUnlock()
+	<-done
+}
+
+func TestHandleDroppedFiles(t *testing.T) {
+	useTempConfigDir(t)
+
+	dir := t.TempDir()
+	jsonl := filepath.Join(dir, "events.jsonl")
+	array := filepath.Join(dir, "dump.json")
+	image := filepath.Join(dir, "photo.png")
+	os.WriteFile(jsonl, []byte("{\"n\":1}\n"), 0644)
+	os.WriteFile(array, []byte("[{\"n\":1},{\"n\":2}]"), 0644)
+	os.WriteFile(image, []byte("\x89PNG"), 0644)
+
+	app := &App{}
+	var jsonErr *JSONLError
+	if _, err := app.HandleDroppedFiles([]string{image}); !errors.As(err, &jsonErr) || !errors.Is(jsonErr.Err, ErrUnsupportedFileType) {
+		t.Errorf("Expected ErrUnsupportedFileType, got %v", err)
+	}
+
+	docs, err := app.HandleDroppedFiles([]string{image, jsonl, array})
+	if err != nil {
+		t.Fatalf("HandleDroppedFiles returned error: %v", err)
+	}
+	if len(docs) != 2 || app.currentFile.Path != jsonl {
+		t.Fatalf("Expected the two supported files in tabs, got %+v", docs)
+	}
+	if docs[1].Records != 2 {
+		t.Errorf("Expected the JSON array to be loaded as 2 records, got %+v", docs[1])
+	}
+}
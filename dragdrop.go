@@ -0,0 +1,77 @@
+package main
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+)
+
+// ErrUnsupportedFileType is returned when none of the dropped files can be opened
+var ErrUnsupportedFileType = errors.New("unsupported file type")
+
+// droppableExtensions are the file extensions accepted besides JSONL ones
+var droppableExtensions = []string{".json", ".log", ".txt"}
+
+// isSupportedFile reports whether a file name has an extension the viewer opens,
+// optionally followed by a compression suffix
+func isSupportedFile(name string) bool {
+	if isJSONLFileName(name) {
+		return true
+	}
+	ext := strings.ToLower(filepath.Ext(trimCompressionSuffix(name)))
+	for _, supported := range droppableExtensions {
+		if ext == supported {
+			return true
+		}
+	}
+	return false
+}
+
+// trimCompressionSuffix removes a .gz, .zst or .bz2 suffix from a file name
+func trimCompressionSuffix(name string) string {
+	for _, suffix := range []string{".gz", ".zst", ".bz2"} {
+		if strings.HasSuffix(strings.ToLower(name), suffix) {
+			return name[:len(name)-len(suffix)]
+		}
+	}
+	return name
+}
+
+// loadPath loads a file in the format its extension suggests: .json files holding
+// an array through LoadJSONArrayFile, .log files through LoadLogFile with a detected
+// format, and anything else as JSONL
+func (a *App) loadPath(path string) (*JSONLFile, error) {
+	switch strings.ToLower(filepath.Ext(trimCompressionSuffix(path))) {
+	case ".json":
+		if file, err := a.LoadJSONArrayFile(path); err == nil {
+			return file, nil
+		}
+	case ".log":
+		return a.LoadLogFile(path, "")
+	}
+	return a.LoadJSONLFile(path)
+}
+
+// HandleDroppedFiles opens the files dropped on the window in tabs, skipping those
+// without a supported extension, and emits a "file:loaded" event with the active file
+func (a *App) HandleDroppedFiles(paths []string) ([]DocumentInfo, error) {
+	var supported []string
+	for _, path := range paths {
+		if isSupportedFile(path) {
+			supported = append(supported, path)
+		}
+	}
+	if len(supported) == 0 {
+		return nil, &JSONLError{
+			Message: "None of the dropped files is a JSONL, JSON or log file",
+			Err:     ErrUnsupportedFileType,
+		}
+	}
+
+	docs, err := a.OpenFilesInTabs(supported)
+	if err != nil {
+		return nil, err
+	}
+	a.emitEvent("file:loaded", a.loadedFile())
+	return docs, nil
+}
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// DuplicateKeyLine lists the keys repeated within one object on a line. JSON
+// decoding keeps only the last value of a repeated key.
+type DuplicateKeyLine struct {
+	Line int      `json:"line"`
+	Keys []string `json:"keys"` // field paths such as "user.id" or "items.0.sku"
+}
+
+// SetKeepDuplicateKeys keeps every value of keys repeated within an object, for
+// files loaded afterwards. The field still holds the last value; the record's
+// Duplicates map lists all of them in line order. Lines with repeated keys are
+// reported in FileStats.DuplicateKeys either way.
+func (a *App) SetKeepDuplicateKeys(enabled bool) {
+	a.stateMu.Lock()
+	defer a.stateMu.Unlock()
+	a.keepDuplicateKeys = enabled
+}
+
+// GetKeepDuplicateKeys reports whether every value of repeated keys is kept
+func (a *App) GetKeepDuplicateKeys() bool {
+	a.stateMu.RLock()
+	defer a.stateMu.RUnlock()
+	return a.keepDuplicateKeys
+}
+
+// duplicateKey is a key repeated within an object, with the byte range of each
+// of its values in the line
+type duplicateKey struct {
+	path   string
+	values [][2]int
+}
+
+// keyEntry is a key of an object being scanned and the byte range of its value
+type keyEntry struct {
+	name       string
+	start, end int
+}
+
+// keyFrame is an object or array open at the scanner's position
+type keyFrame struct {
+	object    bool
+	path      string
+	expectKey bool       // the next string in an object is a key
+	keys      []keyEntry // object keys seen so far
+	element   int        // index of the current array element
+}
+
+// findDuplicateKeys returns the keys repeated within any object of a valid JSON
+// line, in the order their objects close. The byte ranges of their values are
+// only tracked with withValues set, for duplicateValues.
+func findDuplicateKeys(line string, withValues bool) []duplicateKey {
+	// Keys can only repeat in an object with at least two of them
+	if strings.Count(line, ":") < 2 {
+		return nil
+	}
+
+	var stack []keyFrame
+	var duplicates []duplicateKey
+	closeValue := func(frame *keyFrame, end int) {
+		if withValues && frame.object && len(frame.keys) > 0 && frame.keys[len(frame.keys)-1].end == 0 {
+			frame.keys[len(frame.keys)-1].end = end
+		}
+	}
+
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; c {
+		case '"':
+			end := stringEnd(line, i)
+			if n := len(stack); n > 0 && stack[n-1].object && stack[n-1].expectKey {
+				stack[n-1].keys = append(stack[n-1].keys, keyEntry{name: unquoteKey(line[i : end+1])})
+				stack[n-1].expectKey = false
+			}
+			i = end
+
+		case ':':
+			if n := len(stack); withValues && n > 0 && stack[n-1].object {
+				stack[n-1].keys[len(stack[n-1].keys)-1].start = i + 1
+			}
+
+		case ',':
+			if n := len(stack); n > 0 {
+				frame := &stack[n-1]
+				if frame.object {
+					closeValue(frame, i)
+					frame.expectKey = true
+				} else {
+					frame.element++
+				}
+			}
+
+		case '{', '[':
+			path := ""
+			if n := len(stack); n > 0 {
+				parent := &stack[n-1]
+				if parent.object {
+					path = joinFieldPath(parent.path, parent.keys[len(parent.keys)-1].name)
+				} else {
+					path = joinFieldPath(parent.path, strconv.Itoa(parent.element))
+				}
+			}
+			stack = append(stack, keyFrame{object: c == '{', path: path, expectKey: c == '{'})
+
+		case '}', ']':
+			if len(stack) == 0 {
+				return duplicates
+			}
+			frame := &stack[len(stack)-1]
+			if frame.object {
+				closeValue(frame, i)
+				duplicates = append(duplicates, repeatedKeys(frame, withValues)...)
+			}
+			stack = stack[:len(stack)-1]
+		}
+	}
+	return duplicates
+}
+
+// repeatedKeys returns the keys of a closed object that appear more than once,
+// with the byte ranges of their values when withValues is set
+func repeatedKeys(frame *keyFrame, withValues bool) []duplicateKey {
+	if len(frame.keys) < 2 {
+		return nil
+	}
+
+	counts := make(map[string]int, len(frame.keys))
+	for _, key := range frame.keys {
+		counts[key.name]++
+	}
+	var duplicates []duplicateKey
+	positions := make(map[string]int)
+	for _, key := range frame.keys {
+		if counts[key.name] < 2 {
+			continue
+		}
+		position, exists := positions[key.name]
+		if !exists {
+			position = len(duplicates)
+			positions[key.name] = position
+			duplicates = append(duplicates, duplicateKey{path: joinFieldPath(frame.path, key.name)})
+		}
+		if withValues {
+			duplicates[position].values = append(duplicates[position].values, [2]int{key.start, key.end})
+		}
+	}
+	return duplicates
+}
+
+// stringEnd returns the index of the quote closing the string that starts at line[start]
+func stringEnd(line string, start int) int {
+	for i := start + 1; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return len(line) - 1
+}
+
+// unquoteKey decodes a quoted object key
+func unquoteKey(quoted string) string {
+	if !strings.Contains(quoted, `\`) {
+		return quoted[1 : len(quoted)-1]
+	}
+	var key string
+	if err := json.Unmarshal([]byte(quoted), &key); err != nil {
+		return quoted[1 : len(quoted)-1]
+	}
+	return key
+}
+
+// joinFieldPath appends a segment to a dot-separated field path
+func joinFieldPath(path, segment string) string {
+	if path == "" {
+		return segment
+	}
+	return path + "." + segment
+}
+
+// detectDuplicateKeys reports the keys repeated within the objects of a parsed
+// record's line and, with keep set, lists every value of them in its Duplicates
+func detectDuplicateKeys(record *JSONRecord, keep bool) (DuplicateKeyLine, bool) {
+	duplicates := findDuplicateKeys(record.RawJSON, keep)
+	if len(duplicates) == 0 {
+		return DuplicateKeyLine{}, false
+	}
+	if keep {
+		record.Duplicates = duplicateValues(record.RawJSON, duplicates)
+	}
+	return DuplicateKeyLine{Line: record.LineNumber, Keys: duplicateKeyPaths(duplicates)}, true
+}
+
+// duplicateKeyPaths returns the field paths of the duplicate keys
+func duplicateKeyPaths(duplicates []duplicateKey) []string {
+	paths := make([]string, len(duplicates))
+	for i, duplicate := range duplicates {
+		paths[i] = duplicate.path
+	}
+	return paths
+}
+
+// duplicateValues decodes every value of the duplicate keys, by field path
+func duplicateValues(line string, duplicates []duplicateKey) map[string][]interface{} {
+	values := make(map[string][]interface{}, len(duplicates))
+	for _, duplicate := range duplicates {
+		for _, span := range duplicate.values {
+			content, value, err := parseRecordLine(strings.TrimSpace(line[span[0]:span[1]]))
+			if err != nil {
+				continue
+			}
+			if content != nil {
+				values[duplicate.path] = append(values[duplicate.path], content)
+			} else {
+				values[duplicate.path] = append(values[duplicate.path], value)
+			}
+		}
+	}
+	return values
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFindDuplicateKeys(t *testing.T) {
+	tests := []struct {
+		line     string
+		expected string
+	}{
+		{`{"a":1,"b":2}`, `[]`},
+		{`{"a":1,"b":"x:y","a":2}`, `[a]`},
+		{`{"a":1,"a":{"b":1,"b":2},"a":3}`, `[a.b a]`},
+		{`{"items":[{"sku":1},{"sku":2,"sku":3}]}`, `[items.1.sku]`},
+		{`{"k\u0065y":1,"key":2}`, `[key]`},
+		{`[{"x":1,"x":2}]`, `[0.x]`},
+		{`{"s":"\"a\":1,\"a\":2"}`, `[]`},
+	}
+	for _, tt := range tests {
+		paths := duplicateKeyPaths(findDuplicateKeys(tt.line, false))
+		if got := fmt.Sprint(paths); got != tt.expected {
+			t.Errorf("findDuplicateKeys(%s) = %s, expected %s", tt.line, got, tt.expected)
+		}
+	}
+
+	line := `{"a": 1, "a": {"b": [true]} , "a": "x"}`
+	values, _ := json.Marshal(duplicateValues(line, findDuplicateKeys(line, true)))
+	if string(values) != `{"a":[1,{"b":[true]},"x"]}` {
+		t.Errorf("Unexpected duplicate values %s", values)
+	}
+}
+
+func TestLoadDuplicateKeys(t *testing.T) {
+	useTempConfigDir(t)
+
+	path := filepath.Join(t.TempDir(), "dupes.jsonl")
+	os.WriteFile(path, []byte("{\"id\":1}\n{\"id\":2,\"level\":\"info\",\"level\":\"error\"}\n{\"user\":{\"id\":1,\"id\":2}}\n"), 0644)
+
+	app := &App{}
+	if _, err := app.LoadJSONLFile(path); err != nil {
+		t.Fatalf("LoadJSONLFile returned error: %v", err)
+	}
+	duplicates := app.cache.stats.DuplicateKeys
+	if fmt.Sprint(duplicates) != "[{2 [level]} {3 [user.id]}]" {
+		t.Fatalf("Expected duplicates on lines 2 and 3, got %v", duplicates)
+	}
+	record, _ := app.GetRecordByLineNumber(2)
+	if record.Content["level"] != "error" || record.Duplicates != nil {
+		t.Errorf("Expected the last value without annotations by default, got %+v", record)
+	}
+
+	app.SetKeepDuplicateKeys(true)
+	if _, err := app.LoadJSONLFile(path); err != nil {
+		t.Fatalf("LoadJSONLFile returned error: %v", err)
+	}
+	record, _ = app.GetRecordByLineNumber(3)
+	if fmt.Sprint(record.Duplicates) != "map[user.id:[1 2]]" {
+		t.Errorf("Expected both values of user.id, got %v", record.Duplicates)
+	}
+	if first, _ := app.GetRecordByLineNumber(1); first.Duplicates != nil {
+		t.Errorf("Expected no annotations without repeated keys, got %v", first.Duplicates)
+	}
+
+	// Appended lines are checked on reload
+	f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	f.WriteString(`{"x":1,"x":1}` + "\n")
+	f.Close()
+	if _, err := app.ReloadIncremental(); err != nil {
+		t.Fatalf("ReloadIncremental returned error: %v", err)
+	}
+	if duplicates := app.cache.stats.DuplicateKeys; len(duplicates) != 3 || duplicates[2].Line != 4 {
+		t.Errorf("Expected the appended line to be reported, got %v", duplicates)
+	}
+}
+
+func TestDuplicateKeysAcrossLoaders(t *testing.T) {
+	useTempConfigDir(t)
+
+	content := "{\"id\":1}\n{\"id\":2,\"id\":3}\n"
+	records, stats, _ := ParseJSONLFromString(content)
+	if fmt.Sprint(stats.DuplicateKeys) != "[{2 [id]}]" || records[1].Duplicates != nil {
+		t.Errorf("Expected line 2 reported without values, got %v and %v", stats.DuplicateKeys, records[1].Duplicates)
+	}
+	records, _, _ = parseJSONLString(content, true)
+	if fmt.Sprint(records[1].Duplicates) != "map[id:[2 3]]" {
+		t.Errorf("Expected both values of id from a string, got %v", records[1].Duplicates)
+	}
+
+	dir := t.TempDir()
+	multiline := filepath.Join(dir, "pretty.json")
+	os.WriteFile(multiline, []byte("{\"id\": 1}\n{\n  \"id\": 2,\n  \"id\": 3\n}\n"), 0644)
+	array := filepath.Join(dir, "array.json")
+	os.WriteFile(array, []byte(`[{"id":1},{"id":2,"id":3}]`), 0644)
+
+	app := &App{}
+	app.SetKeepDuplicateKeys(true)
+	app.SetParseMode(ParseModeMultiline)
+	if _, err := app.LoadJSONLFile(multiline); err != nil {
+		t.Fatalf("LoadJSONLFile returned error: %v", err)
+	}
+	record, _ := app.GetRecordByLineNumber(2)
+	if fmt.Sprint(app.cache.stats.DuplicateKeys) != "[{2 [id]}]" || fmt.Sprint(record.Duplicates) != "map[id:[2 3]]" {
+		t.Errorf("Expected the concatenated record's duplicates, got %v and %+v", app.cache.stats.DuplicateKeys, record)
+	}
+
+	app.SetParseMode(ParseModeJSONL)
+	if _, err := app.LoadJSONArrayFile(array); err != nil {
+		t.Fatalf("LoadJSONArrayFile returned error: %v", err)
+	}
+	record, _ = app.GetRecordByLineNumber(2)
+	if fmt.Sprint(app.cache.stats.DuplicateKeys) != "[{2 [id]}]" || fmt.Sprint(record.Duplicates) != "map[id:[2 3]]" {
+		t.Errorf("Expected the array element's duplicates, got %v and %+v", app.cache.stats.DuplicateKeys, record)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(content))
+	}))
+	defer server.Close()
+	if _, err := app.LoadJSONLFromURL(server.URL + "/export.jsonl"); err != nil {
+		t.Fatalf("LoadJSONLFromURL returned error: %v", err)
+	}
+	record, _ = app.GetRecordByLineNumber(2)
+	if fmt.Sprint(app.cache.stats.DuplicateKeys) != "[{2 [id]}]" || fmt.Sprint(record.Duplicates) != "map[id:[2 3]]" {
+		t.Errorf("Expected the downloaded record's duplicates, got %v and %+v", app.cache.stats.DuplicateKeys, record)
+	}
+}
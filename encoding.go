@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+// Text encodings recognized besides plain UTF-8
+const (
+	encodingUTF8BOM = "utf-8-bom"
+	encodingUTF16LE = "utf-16le"
+	encodingUTF16BE = "utf-16be"
+)
+
+// detectTextEncoding sniffs the encoding of content from its first bytes and
+// returns it with the length of its byte order mark. UTF-16 without a BOM is
+// recognized from the zero byte paired with the leading ASCII character of a
+// JSON line. Plain UTF-8 returns "".
+func detectTextEncoding(header []byte) (encoding string, bomLength int) {
+	switch {
+	case bytes.HasPrefix(header, []byte{0xef, 0xbb, 0xbf}):
+		return encodingUTF8BOM, 3
+	case bytes.HasPrefix(header, []byte{0xff, 0xfe}):
+		return encodingUTF16LE, 2
+	case bytes.HasPrefix(header, []byte{0xfe, 0xff}):
+		return encodingUTF16BE, 2
+	case len(header) >= 2 && header[0] != 0 && header[1] == 0:
+		return encodingUTF16LE, 0
+	case len(header) >= 2 && header[0] == 0 && header[1] != 0:
+		return encodingUTF16BE, 0
+	}
+	return "", 0
+}
+
+// decodeText wraps r so that it yields UTF-8 without byte order mark, transcoding
+// UTF-16 content
+func decodeText(r io.Reader) io.Reader {
+	buffered := bufio.NewReader(r)
+	header, _ := buffered.Peek(4)
+	encoding, bomLength := detectTextEncoding(header)
+	buffered.Discard(bomLength)
+
+	switch encoding {
+	case encodingUTF16LE:
+		return transform.NewReader(buffered, unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM).NewDecoder())
+	case encodingUTF16BE:
+		return transform.NewReader(buffered, unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM).NewDecoder())
+	}
+	return buffered
+}
+
+// decodeTextString returns content as UTF-8 without byte order mark
+func decodeTextString(content string) string {
+	if encoding, _ := detectTextEncoding([]byte(content[:min(len(content), 4)])); encoding == "" {
+		return content
+	}
+	decoded, err := io.ReadAll(decodeText(strings.NewReader(content)))
+	if err != nil {
+		return content
+	}
+	return string(decoded)
+}
+
+// isTranscodedFile reports whether a file's bytes differ from the UTF-8 text read
+// from it, so that byte offsets into the file cannot be used directly
+func isTranscodedFile(path string) bool {
+	file, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	header := make([]byte, 4)
+	n, _ := io.ReadFull(file, header)
+	encoding, _ := detectTextEncoding(header[:n])
+	return encoding != ""
+}
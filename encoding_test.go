@@ -0,0 +1,75 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"unicode/utf16"
+)
+
+// utf16Bytes encodes content as UTF-16 with an optional byte order mark
+func utf16Bytes(content string, bigEndian, bom bool) []byte {
+	units := utf16.Encode([]rune(content))
+	if bom {
+		units = append([]uint16{0xfeff}, units...)
+	}
+	data := make([]byte, 0, len(units)*2)
+	for _, u := range units {
+		if bigEndian {
+			data = append(data, byte(u>>8), byte(u))
+		} else {
+			data = append(data, byte(u), byte(u>>8))
+		}
+	}
+	return data
+}
+
+func TestLoadEncodedFiles(t *testing.T) {
+	useTempConfigDir(t)
+
+	content := "{\"name\":\"Zoë\"}\r\n{\"name\":\"日本\"}\r\n"
+	files := map[string][]byte{
+		"utf8-bom.jsonl":    append([]byte{0xef, 0xbb, 0xbf}, content...),
+		"utf16le-bom.jsonl": utf16Bytes(content, false, true),
+		"utf16be-bom.jsonl": utf16Bytes(content, true, true),
+		"utf16le.jsonl":     utf16Bytes(content, false, false),
+	}
+
+	dir := t.TempDir()
+	for name, data := range files {
+		path := filepath.Join(dir, name)
+		os.WriteFile(path, data, 0644)
+
+		app := &App{}
+		if _, err := app.LoadJSONLFile(path); err != nil {
+			t.Fatalf("%s: LoadJSONLFile returned error: %v", name, err)
+		}
+		stats := app.cache.stats
+		if stats.ValidRecords != 2 || len(stats.InvalidLines) != 0 {
+			t.Errorf("%s: expected 2 valid records, got %+v", name, stats)
+			continue
+		}
+		if record, _ := app.GetRecordByLineNumber(2); record.Content["name"] != "日本" {
+			t.Errorf("%s: unexpected record %+v", name, record.Content)
+		}
+		if !isTranscodedFile(path) {
+			t.Errorf("%s: expected the file to be reported as transcoded", name)
+		}
+	}
+}
+
+func TestParseJSONLFromStringWithBOM(t *testing.T) {
+	records, stats, err := ParseJSONLFromString("\ufeff{\"a\":1}\n{\"b\":2}")
+	if err != nil {
+		t.Fatalf("ParseJSONLFromString returned error: %v", err)
+	}
+	if len(records) != 2 || len(stats.InvalidLines) != 0 {
+		t.Errorf("Expected the BOM to be ignored, got %d records and invalid lines %v", len(records), stats.InvalidLines)
+	}
+
+	records, _, _ = ParseJSONLFromString(string(utf16Bytes("{\"a\":1}\n", false, true)))
+	if len(records) != 1 || records[0].Content["a"] != json.Number("1") {
+		t.Errorf("Expected UTF-16 content to be transcoded, got %+v", records)
+	}
+}
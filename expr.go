@@ -0,0 +1,417 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ErrInvalidExpression is returned for computed field expressions that cannot be parsed
+var ErrInvalidExpression = errors.New("invalid expression")
+
+// exprNode is a parsed expression evaluated against a record's content
+type exprNode interface {
+	eval(content map[string]interface{}) (interface{}, error)
+}
+
+type literalNode struct{ value interface{} }
+
+type fieldNode struct{ path string }
+
+type unaryNode struct {
+	op      string
+	operand exprNode
+}
+
+type binaryNode struct {
+	op          string
+	left, right exprNode
+}
+
+func (n literalNode) eval(map[string]interface{}) (interface{}, error) {
+	return n.value, nil
+}
+
+// Missing fields evaluate to null
+func (n fieldNode) eval(content map[string]interface{}) (interface{}, error) {
+	value, _ := getFieldValue(content, n.path)
+	return value, nil
+}
+
+func (n unaryNode) eval(content map[string]interface{}) (interface{}, error) {
+	value, err := n.operand.eval(content)
+	if err != nil {
+		return nil, err
+	}
+
+	if n.op == "!" {
+		return !truthy(value), nil
+	}
+	number, ok := toFloat64(value)
+	if !ok {
+		return nil, fmt.Errorf("cannot negate %s", jsonTypeOf(value))
+	}
+	return -number, nil
+}
+
+func (n binaryNode) eval(content map[string]interface{}) (interface{}, error) {
+	left, err := n.left.eval(content)
+	if err != nil {
+		return nil, err
+	}
+
+	// Logical operators short-circuit
+	switch n.op {
+	case "&&":
+		if !truthy(left) {
+			return false, nil
+		}
+		right, err := n.right.eval(content)
+		return truthy(right), err
+	case "||":
+		if truthy(left) {
+			return true, nil
+		}
+		right, err := n.right.eval(content)
+		return truthy(right), err
+	}
+
+	right, err := n.right.eval(content)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.op {
+	case "==":
+		return exprEqual(left, right), nil
+	case "!=":
+		return !exprEqual(left, right), nil
+	case "<", "<=", ">", ">=":
+		cmp, ok := exprCompare(left, right)
+		if !ok {
+			return false, nil
+		}
+		switch n.op {
+		case "<":
+			return cmp < 0, nil
+		case "<=":
+			return cmp <= 0, nil
+		case ">":
+			return cmp > 0, nil
+		default:
+			return cmp >= 0, nil
+		}
+	}
+
+	return exprArithmetic(n.op, left, right)
+}
+
+// truthy converts a value to a boolean: null, false, 0 and "" are false
+func truthy(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case string:
+		return v != ""
+	}
+	if number, ok := toFloat64(value); ok {
+		return number != 0
+	}
+	return true
+}
+
+// exprEqual compares numbers numerically and everything else by its text form
+func exprEqual(left, right interface{}) bool {
+	if cmp, ok := compareNumbers(left, right); ok {
+		return cmp == 0
+	}
+	if left == nil || right == nil {
+		return left == nil && right == nil
+	}
+	return valueToString(left) == valueToString(right)
+}
+
+// exprCompare orders two values as numbers, timestamps or strings, reporting
+// false when they cannot be compared
+func exprCompare(left, right interface{}) (int, bool) {
+	if left == nil || right == nil {
+		return 0, false
+	}
+
+	if cmp, ok := compareNumbers(left, right); ok {
+		return cmp, true
+	}
+
+	leftString, leftIsString := left.(string)
+	rightString, rightIsString := right.(string)
+	if !leftIsString || !rightIsString {
+		return 0, false
+	}
+	if leftTime, ok := parseTimestampString(leftString); ok {
+		if rightTime, ok := parseTimestampString(rightString); ok {
+			return leftTime.Compare(rightTime), true
+		}
+	}
+	return strings.Compare(leftString, rightString), true
+}
+
+// exprArithmetic applies an arithmetic operator. Subtracting two timestamps yields
+// milliseconds and + concatenates when either side is a string.
+func exprArithmetic(op string, left, right interface{}) (interface{}, error) {
+	leftNumber, leftOK := toFloat64(left)
+	rightNumber, rightOK := toFloat64(right)
+
+	if !leftOK || !rightOK {
+		leftString, leftIsString := left.(string)
+		rightString, rightIsString := right.(string)
+
+		if op == "-" && leftIsString && rightIsString {
+			leftTime, leftParsed := parseTimestampString(leftString)
+			rightTime, rightParsed := parseTimestampString(rightString)
+			if leftParsed && rightParsed {
+				return float64(leftTime.Sub(rightTime).Microseconds()) / 1000, nil
+			}
+		}
+		if op == "+" && (leftIsString || rightIsString) && left != nil && right != nil {
+			return valueToString(left) + valueToString(right), nil
+		}
+		return nil, fmt.Errorf("cannot apply %s to %s and %s", op, jsonTypeOf(left), jsonTypeOf(right))
+	}
+
+	switch op {
+	case "+":
+		return leftNumber + rightNumber, nil
+	case "-":
+		return leftNumber - rightNumber, nil
+	case "*":
+		return leftNumber * rightNumber, nil
+	case "/":
+		if rightNumber == 0 {
+			return nil, errors.New("division by zero")
+		}
+		return leftNumber / rightNumber, nil
+	case "%":
+		if rightNumber == 0 {
+			return nil, errors.New("division by zero")
+		}
+		return math.Mod(leftNumber, rightNumber), nil
+	}
+	return nil, fmt.Errorf("unknown operator %s", op)
+}
+
+// exprToken is a lexical token of an expression
+type exprToken struct {
+	kind  string // 'number', 'string', 'ident', 'op', 'eof'
+	text  string
+	value interface{}
+}
+
+// tokenizeExpression splits an expression into tokens. Field names may contain dots;
+// names with other characters can be quoted with backticks.
+func tokenizeExpression(input string) ([]exprToken, error) {
+	var tokens []exprToken
+	runes := []rune(input)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+
+		case unicode.IsDigit(r) || (r == '.' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.' || runes[i] == 'e' || runes[i] == 'E' ||
+				((runes[i] == '+' || runes[i] == '-') && (runes[i-1] == 'e' || runes[i-1] == 'E'))) {
+				i++
+			}
+			text := string(runes[start:i])
+			if _, err := strconv.ParseFloat(text, 64); err != nil {
+				return nil, fmt.Errorf("invalid number %q", text)
+			}
+			tokens = append(tokens, exprToken{kind: "number", text: text, value: json.Number(text)})
+
+		case r == '"' || r == '\'' || r == '`':
+			quote := r
+			var sb strings.Builder
+			i++
+			for i < len(runes) && runes[i] != quote {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+				}
+				sb.WriteRune(runes[i])
+				i++
+			}
+			if i >= len(runes) {
+				return nil, errors.New("unterminated quote")
+			}
+			i++
+			if quote == '`' {
+				tokens = append(tokens, exprToken{kind: "ident", text: sb.String()})
+			} else {
+				tokens = append(tokens, exprToken{kind: "string", text: sb.String(), value: sb.String()})
+			}
+
+		case unicode.IsLetter(r) || r == '_' || r == '@' || r == '$':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) ||
+				runes[i] == '_' || runes[i] == '.' || runes[i] == '@' || runes[i] == '$') {
+				i++
+			}
+			tokens = append(tokens, exprToken{kind: "ident", text: string(runes[start:i])})
+
+		default:
+			op := string(r)
+			if i+1 < len(runes) {
+				switch two := string(runes[i : i+2]); two {
+				case "==", "!=", "<=", ">=", "&&", "||":
+					op = two
+				}
+			}
+			if !strings.Contains("+-*/%()<>!", op) && len(op) == 1 {
+				return nil, fmt.Errorf("unexpected character %q", r)
+			}
+			tokens = append(tokens, exprToken{kind: "op", text: op})
+			i += len(op)
+		}
+	}
+
+	return append(tokens, exprToken{kind: "eof"}), nil
+}
+
+// exprParser is a recursive descent parser over expression tokens
+type exprParser struct {
+	tokens []exprToken
+	pos    int
+}
+
+// parseExpression parses an expression such as `end_ts - start_ts` or `status >= 500`
+func parseExpression(input string) (exprNode, error) {
+	tokens, err := tokenizeExpression(input)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &exprParser{tokens: tokens}
+	node, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != "eof" {
+		return nil, fmt.Errorf("unexpected %q", p.peek().text)
+	}
+	return node, nil
+}
+
+func (p *exprParser) peek() exprToken {
+	return p.tokens[p.pos]
+}
+
+// acceptOp consumes the next token if it is one of the given operators
+func (p *exprParser) acceptOp(ops ...string) (string, bool) {
+	token := p.peek()
+	if token.kind != "op" {
+		return "", false
+	}
+	for _, op := range ops {
+		if token.text == op {
+			p.pos++
+			return op, true
+		}
+	}
+	return "", false
+}
+
+// parseBinary parses a left-associative chain of operators at one precedence level
+func (p *exprParser) parseBinary(next func() (exprNode, error), ops ...string) (exprNode, error) {
+	left, err := next()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.acceptOp(ops...)
+		if !ok {
+			return left, nil
+		}
+		right, err := next()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryNode{op: op, left: left, right: right}
+	}
+}
+
+func (p *exprParser) parseOr() (exprNode, error) {
+	return p.parseBinary(p.parseAnd, "||")
+}
+
+func (p *exprParser) parseAnd() (exprNode, error) {
+	return p.parseBinary(p.parseComparison, "&&")
+}
+
+func (p *exprParser) parseComparison() (exprNode, error) {
+	return p.parseBinary(p.parseAdditive, "==", "!=", "<=", ">=", "<", ">")
+}
+
+func (p *exprParser) parseAdditive() (exprNode, error) {
+	return p.parseBinary(p.parseMultiplicative, "+", "-")
+}
+
+func (p *exprParser) parseMultiplicative() (exprNode, error) {
+	return p.parseBinary(p.parseUnary, "*", "/", "%")
+}
+
+func (p *exprParser) parseUnary() (exprNode, error) {
+	if op, ok := p.acceptOp("!", "-"); ok {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return unaryNode{op: op, operand: operand}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *exprParser) parsePrimary() (exprNode, error) {
+	token := p.peek()
+	switch token.kind {
+	case "number", "string":
+		p.pos++
+		return literalNode{value: token.value}, nil
+
+	case "ident":
+		p.pos++
+		switch token.text {
+		case "true":
+			return literalNode{value: true}, nil
+		case "false":
+			return literalNode{value: false}, nil
+		case "null":
+			return literalNode{value: nil}, nil
+		}
+		return fieldNode{path: token.text}, nil
+
+	case "op":
+		if token.text == "(" {
+			p.pos++
+			node, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := p.acceptOp(")"); !ok {
+				return nil, errors.New("missing closing parenthesis")
+			}
+			return node, nil
+		}
+	}
+
+	if token.kind == "eof" {
+		return nil, errors.New("unexpected end of expression")
+	}
+	return nil, fmt.Errorf("unexpected %q", token.text)
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseExpression(t *testing.T) {
+	content := map[string]interface{}{
+		"status":   float64(503),
+		"start_ts": "2024-01-01T10:00:00Z",
+		"end_ts":   "2024-01-01T10:00:01.5Z",
+		"user":     map[string]interface{}{"name": "ann"},
+		"bytes":    float64(2048),
+		"my-field": "x",
+	}
+
+	tests := []struct {
+		name       string
+		expression string
+		expected   interface{}
+		wantErr    bool
+	}{
+		{"Comparison", "status >= 500", true, false},
+		{"Precedence", "1 + 2 * 3 - 4 / 2", float64(5), false},
+		{"Parentheses", "(1 + 2) * 3", float64(9), false},
+		{"Timestamp difference in milliseconds", "end_ts - start_ts", float64(1500), false},
+		{"Nested field and string concatenation", `user.name + "@example.com"`, "ann@example.com", false},
+		{"Logical operators", `status >= 500 && !(user.name == "bob")`, true, false},
+		{"Missing field compares as null", "missing == null", true, false},
+		{"Missing field in arithmetic", "missing * 2", nil, true},
+		{"Backtick field name", "`my-field` == 'x'", true, false},
+		{"Unary minus and modulo", "-bytes % 1000", float64(-48), false},
+		{"Division by zero", "bytes / 0", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node, err := parseExpression(tt.expression)
+			if err != nil {
+				t.Fatalf("parseExpression(%q) returned error: %v", tt.expression, err)
+			}
+			result, err := node.eval(content)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Expected error=%v, got %v", tt.wantErr, err)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("Expected %v, got %v for %q", tt.expected, result, tt.expression)
+			}
+		})
+	}
+
+	for _, invalid := range []string{"", "status >=", "(1 + 2", "status = 5", "a & b", `"open`} {
+		if _, err := parseExpression(invalid); err == nil {
+			t.Errorf("Expected parse error for %q", invalid)
+		}
+	}
+}
+
+func TestSetComputedFields(t *testing.T) {
+	app := newTestApp(t, `{"status":200,"msg":"took 120ms"}
+{"status":503,"msg":"took 900ms"}`)
+
+	if _, err := app.SetExtractionRules([]ExtractionRule{{SourceField: "msg", Pattern: `took (?P<took>\d+)ms`}}); err != nil {
+		t.Fatalf("SetExtractionRules returned error: %v", err)
+	}
+	err := app.SetComputedFields([]ComputedField{
+		{Name: "is_error", Expression: "status >= 500"},
+		{Name: "slow", Expression: "is_error && took > '500'"},
+	})
+	if err != nil {
+		t.Fatalf("SetComputedFields returned error: %v", err)
+	}
+
+	record, err := app.GetRecordByLineNumber(2)
+	if err != nil {
+		t.Fatalf("GetRecordByLineNumber returned error: %v", err)
+	}
+	if value := record.Content["is_error"]; value != true {
+		t.Errorf("Expected is_error true, got %v", value)
+	}
+	if value := record.Content["slow"]; value != true {
+		t.Errorf("Expected slow to use extracted and computed fields, got %v", value)
+	}
+
+	result, err := app.SearchRecords(SearchOptions{Query: "is_error:true", UseLucene: true})
+	if err != nil || result.TotalMatches != 1 {
+		t.Errorf("Expected computed field to be searchable, got %+v, %v", result, err)
+	}
+
+	if err := app.SetComputedFields([]ComputedField{{Name: "bad", Expression: "status >"}}); err == nil {
+		t.Errorf("Expected an error for an invalid expression")
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ErrInvalidExtraction is returned for extraction rules that cannot be compiled
+var ErrInvalidExtraction = errors.New("invalid extraction rule")
+
+// ExtractionRule derives virtual fields from a source field using a regular
+// expression; every named group becomes a field of the same name
+type ExtractionRule struct {
+	SourceField string `json:"sourceField"`
+	Pattern     string `json:"pattern"` // e.g. `order (?P<order_id>\d+)`
+}
+
+// compiledExtraction is an extraction rule with its pattern compiled
+type compiledExtraction struct {
+	rule    ExtractionRule
+	pattern *regexp.Regexp
+	fields  []string // named groups, by submatch index
+}
+
+// compileExtractionRule validates a rule and compiles its pattern
+func compileExtractionRule(rule ExtractionRule) (*compiledExtraction, error) {
+	if strings.TrimSpace(rule.SourceField) == "" {
+		return nil, &JSONLError{
+			Message: "Extraction rule needs a source field",
+			Err:     ErrInvalidExtraction,
+		}
+	}
+
+	pattern, err := regexp.Compile(rule.Pattern)
+	if err != nil {
+		return nil, &JSONLError{
+			Message: fmt.Sprintf("Invalid pattern for %s: %v", rule.SourceField, err),
+			Err:     ErrInvalidExtraction,
+		}
+	}
+
+	fields := pattern.SubexpNames()
+	named := false
+	for _, name := range fields {
+		if name != "" {
+			named = true
+		}
+	}
+	if !named {
+		return nil, &JSONLError{
+			Message: fmt.Sprintf("Pattern for %s has no named groups such as (?P<name>...)", rule.SourceField),
+			Err:     ErrInvalidExtraction,
+		}
+	}
+
+	return &compiledExtraction{rule: rule, pattern: pattern, fields: fields}, nil
+}
+
+// apply adds the named groups matched in the source field to content
+func (e *compiledExtraction) apply(content map[string]interface{}) {
+	value, exists := getFieldValue(content, e.rule.SourceField)
+	if !exists || value == nil {
+		return
+	}
+
+	match := e.pattern.FindStringSubmatch(valueToString(value))
+	if match == nil {
+		return
+	}
+	for i, name := range e.fields {
+		if name != "" && match[i] != "" {
+			setDerivedValue(content, name, match[i])
+		}
+	}
+}
+
+// SetExtractionRules replaces the regex extraction rules. Their fields are not
+// stored in the loaded records but added by the derive hook as records are read,
+// see applyDerivedFields. It returns the names of the derived fields.
+func (a *App) SetExtractionRules(rules []ExtractionRule) ([]string, error) {
+	compiled := make([]*compiledExtraction, 0, len(rules))
+	names := []string{}
+	for _, rule := range rules {
+		extraction, err := compileExtractionRule(rule)
+		if err != nil {
+			return nil, err
+		}
+		compiled = append(compiled, extraction)
+		for _, name := range extraction.fields {
+			if name != "" {
+				names = append(names, name)
+			}
+		}
+	}
+
+	a.stateMu.Lock()
+	a.extractionRules = compiled
+	a.applyDerivedFields()
+	a.bumpDataVersion()
+	a.stateMu.Unlock()
+	return names, nil
+}
+
+// GetExtractionRules returns the active extraction rules
+func (a *App) GetExtractionRules() []ExtractionRule {
+	a.stateMu.RLock()
+	defer a.stateMu.RUnlock()
+
+	rules := make([]ExtractionRule, 0, len(a.extractionRules))
+	for _, extraction := range a.extractionRules {
+		rules = append(rules, extraction.rule)
+	}
+	return rules
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCompileExtractionRule(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    ExtractionRule
+		wantErr bool
+	}{
+		{"Named groups", ExtractionRule{SourceField: "msg", Pattern: `order (?P<order_id>\d+)`}, false},
+		{"Missing source field", ExtractionRule{Pattern: `(?P<id>\d+)`}, true},
+		{"No named groups", ExtractionRule{SourceField: "msg", Pattern: `order (\d+)`}, true},
+		{"Invalid pattern", ExtractionRule{SourceField: "msg", Pattern: `(?P<id>`}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := compileExtractionRule(tt.rule)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Expected error=%v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestSetExtractionRules(t *testing.T) {
+	app := newTestApp(t, `{"msg":"created order 42 for user ann"}
+{"msg":"no order here"}
+{"msg":"created order 7 for user bob","order_id":"real"}`)
+
+	names, err := app.SetExtractionRules([]ExtractionRule{
+		{SourceField: "msg", Pattern: `order (?P<order_id>\d+) for user (?P<user>\w+)`},
+	})
+	if err != nil {
+		t.Fatalf("SetExtractionRules returned error: %v", err)
+	}
+	if strings.Join(names, ",") != "order_id,user" {
+		t.Errorf("Expected derived fields order_id,user, got %v", names)
+	}
+
+	records, _ := app.cache.slice(0, 3)
+	if value := records[0].Content["order_id"]; value != "42" {
+		t.Errorf("Expected order_id 42, got %v", value)
+	}
+	if _, exists := records[1].Content["order_id"]; exists {
+		t.Errorf("Expected no order_id for a non-matching record")
+	}
+	if value := records[2].Content["order_id"]; value != "real" {
+		t.Errorf("Expected real field to win over derived value, got %v", value)
+	}
+	if _, exists := app.cache.records[0].Content["order_id"]; exists {
+		t.Errorf("Expected the loaded records to be left unchanged")
+	}
+
+	// Derived fields are searchable and exported like real ones
+	result, err := app.SearchRecords(SearchOptions{Query: "user:bob", UseLucene: true})
+	if err != nil || result.TotalMatches != 1 {
+		t.Errorf("Expected one match for the derived field, got %+v, %v", result, err)
+	}
+	if display := app.getDisplayJSON(records[0], nil, nil); !strings.Contains(display, `"order_id":"42"`) {
+		t.Errorf("Expected export to include derived fields, got %s", display)
+	}
+
+	// Clearing the rules restores the original records
+	if _, err := app.SetExtractionRules(nil); err != nil {
+		t.Fatalf("SetExtractionRules returned error: %v", err)
+	}
+	records, _ = app.cache.slice(0, 3)
+	if _, exists := records[0].Content["order_id"]; exists {
+		t.Errorf("Expected derived fields to be removed")
+	}
+	if value := records[2].Content["order_id"]; value != "real" {
+		t.Errorf("Expected real field to be kept, got %v", value)
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"errors"
+	"sort"
+	"strings"
+)
+
+// maxFacetValues caps the values returned by GetFieldFacets
+const maxFacetValues = 100
+
+// FieldFacet is one of the most frequent values of a field
+type FieldFacet struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+	Query string `json:"query"` // Lucene query selecting exactly the records counted
+}
+
+// FieldFacets lists the most frequent values of a field among the records in scope
+type FieldFacets struct {
+	Field   string       `json:"field"`
+	Values  []FieldFacet `json:"values"`  // most frequent first
+	Records int          `json:"records"` // records in scope
+	Missing int          `json:"missing"` // records in scope without the field
+	// MissingQuery selects exactly the records without the field
+	MissingQuery string `json:"missingQuery"`
+	Distinct     int    `json:"distinct"` // distinct values in scope
+	Other        int    `json:"other"`    // records holding a value not listed
+}
+
+// GetFieldFacets returns the topN most frequent values of a field with counts and
+// a ready-made query for each, for clickable facet filters. Counts cover the
+// records left by the filter stack, narrowed to those matching filter when it
+// carries a query. topN defaults to 10 and is capped at 100.
+func (a *App) GetFieldFacets(field string, topN int, filter *SearchOptions) (*FieldFacets, error) {
+	a.stateMu.RLock()
+	defer a.stateMu.RUnlock()
+	return a.fieldFacets(field, topN, filter)
+}
+
+// fieldFacets implements GetFieldFacets; the caller holds stateMu
+func (a *App) fieldFacets(field string, topN int, filter *SearchOptions) (*FieldFacets, error) {
+	if a.currentFile == nil || a.cache == nil {
+		return nil, &JSONLError{
+			Message: "No file currently loaded",
+			Err:     ErrNoFileLoaded,
+		}
+	}
+
+	field = strings.TrimSpace(field)
+	if field == "" {
+		return nil, &JSONLError{
+			Message: "Field name cannot be empty",
+			Err:     errors.New("empty field name"),
+		}
+	}
+
+	if topN <= 0 {
+		topN = 10
+	}
+	if topN > maxFacetValues {
+		topN = maxFacetValues
+	}
+
+	// _missing_ also matches null values, which are a facet of their own
+	facets := &FieldFacets{Field: field, Values: []FieldFacet{}, MissingQuery: formatLuceneQuery(&LuceneQuery{
+		Type:  "and",
+		Left:  &LuceneQuery{Type: "not", Query: &LuceneQuery{Type: "exists", Field: field}},
+		Right: &LuceneQuery{Type: "not", Query: &LuceneQuery{Type: "exact", Field: field, Value: "null"}},
+	})}
+	// Values are counted by type as well, so 200 and "200" are separate facets
+	counts := make(map[string]int)
+	err := a.forEachInScope(filter, func(record JSONRecord) {
+		facets.Records++
+		if value, exists := getFieldValue(record.Content, field); exists {
+			counts[exactText(value)]++
+		} else {
+			facets.Missing++
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]ValueCount, 0, len(counts))
+	for text, count := range counts {
+		values = append(values, ValueCount{Value: text, Count: count})
+	}
+	sort.Slice(values, func(i, j int) bool {
+		if values[i].Count != values[j].Count {
+			return values[i].Count > values[j].Count
+		}
+		return values[i].Value < values[j].Value
+	})
+
+	facets.Distinct = len(values)
+	facets.Other = facets.Records - facets.Missing
+	for _, value := range values {
+		if len(facets.Values) == topN {
+			break
+		}
+		facets.Values = append(facets.Values, FieldFacet{
+			Value: exactDisplayText(value.Value),
+			Count: value.Count,
+			Query: formatLuceneQuery(&LuceneQuery{Type: "exact", Field: field, Value: value.Value}),
+		})
+		facets.Other -= value.Count
+	}
+	return facets, nil
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestGetFieldFacets(t *testing.T) {
+	app := newTestApp(t, `{"level":"error","service":"api"}
+{"level":"info","service":"api"}
+{"level":"error","service":"web"}
+{"level":"warn","service":"api"}
+{"level":"error","service":"api"}
+{"service":"web"}
+`)
+
+	facets, err := app.GetFieldFacets("level", 2, nil)
+	if err != nil {
+		t.Fatalf("GetFieldFacets returned error: %v", err)
+	}
+	if facets.Records != 6 || facets.Missing != 1 || facets.Distinct != 3 || facets.Other != 1 {
+		t.Errorf("Unexpected totals: %+v", facets)
+	}
+	if len(facets.Values) != 2 || facets.Values[0].Value != "error" || facets.Values[0].Count != 3 ||
+		facets.Values[1].Value != "info" || facets.Values[1].Count != 1 {
+		t.Fatalf("Expected error (3) then info (1), got %+v", facets.Values)
+	}
+	if facets.Values[0].Query != `level:="error"` {
+		t.Errorf(`Expected query level:="error", got %q`, facets.Values[0].Query)
+	}
+
+	// The query of a facet selects the records it counted
+	result, err := app.SearchRecords(SearchOptions{Query: facets.Values[0].Query, UseLucene: true})
+	if err != nil || result.TotalMatches != 3 {
+		t.Errorf("Expected the facet query to match 3 records, got %v (%v)", result, err)
+	}
+
+	result, err = app.SearchRecords(SearchOptions{Query: facets.MissingQuery, UseLucene: true})
+	if err != nil || result.TotalMatches != facets.Missing {
+		t.Errorf("Expected the missing query to match %d records, got %v (%v)", facets.Missing, result, err)
+	}
+
+	filtered, _ := app.GetFieldFacets("level", 0, &SearchOptions{Query: "service:api", UseLucene: true})
+	if filtered.Records != 4 || len(filtered.Values) != 3 || filtered.Values[0].Count != 2 || filtered.Other != 0 {
+		t.Errorf("Unexpected facets within service:api: %+v", filtered)
+	}
+
+	if _, err := app.PushFilter(SearchOptions{Query: "service:web", UseLucene: true}); err != nil {
+		t.Fatalf("PushFilter returned error: %v", err)
+	}
+	stacked, _ := app.GetFieldFacets("level", 0, nil)
+	if stacked.Records != 2 || stacked.Missing != 1 || len(stacked.Values) != 1 {
+		t.Errorf("Expected facets within the filter stack, got %+v", stacked)
+	}
+
+	var jsonlErr *JSONLError
+	if _, err := app.GetFieldFacets("", 5, nil); err == nil {
+		t.Error("Expected an error for an empty field name")
+	}
+	if _, err := (&App{}).GetFieldFacets("level", 5, nil); !errors.As(err, &jsonlErr) || !errors.Is(jsonlErr.Err, ErrNoFileLoaded) {
+		t.Errorf("Expected ErrNoFileLoaded, got %v", err)
+	}
+}
+
+func TestFieldFacetQueriesAreExact(t *testing.T) {
+	app := newTestApp(t, `{"level":"error"}
+{"level":"error: disk full"}
+{"level":"Error"}
+{"level":"error"}
+{"level":null}
+{"level":"null"}
+{"level":200}
+{"level":"200"}
+{"other":1}
+`)
+
+	facets, err := app.GetFieldFacets("level", 0, nil)
+	if err != nil {
+		t.Fatalf("GetFieldFacets returned error: %v", err)
+	}
+	if facets.Distinct != 7 || facets.Missing != 1 {
+		t.Errorf("Expected 7 distinct values and 1 missing, got %+v", facets)
+	}
+
+	// Clicking any facet selects exactly the records it counted
+	for _, facet := range facets.Values {
+		result, err := app.SearchRecords(SearchOptions{Query: facet.Query, UseLucene: true})
+		if err != nil || result.TotalMatches != facet.Count {
+			t.Errorf("Facet %q (%s): expected %d matches, got %v (%v)", facet.Value, facet.Query, facet.Count, result, err)
+		}
+	}
+	result, err := app.SearchRecords(SearchOptions{Query: facets.MissingQuery, UseLucene: true})
+	if err != nil || result.TotalMatches != 1 || result.Records[0].LineNumber != 9 {
+		t.Errorf("Expected the missing query (%s) to match line 9 only, got %v (%v)", facets.MissingQuery, result, err)
+	}
+}
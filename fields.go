@@ -0,0 +1,782 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Common field selection strategies
+const (
+	CommonFieldsThreshold = "threshold" // fields present in at least Threshold of records
+	CommonFieldsAll       = "all"       // fields present in every record
+	CommonFieldsTopN      = "top"       // the TopN fields with the highest coverage
+)
+
+// defaultCommonFieldThreshold is the fraction of records a field must appear in to be common
+const defaultCommonFieldThreshold = 0.5
+
+// CommonFieldOptions controls how common fields are selected from field occurrence counts
+type CommonFieldOptions struct {
+	Strategy  string  `json:"strategy"`  // 'threshold', 'all', 'top'
+	Threshold float64 `json:"threshold"` // fraction of records (0-1), used by 'threshold'
+	TopN      int     `json:"topN"`      // number of fields to return, used by 'top'
+}
+
+// normalize fills in defaults for unset or out-of-range options
+func (o CommonFieldOptions) normalize() CommonFieldOptions {
+	if o.Strategy == "" {
+		o.Strategy = CommonFieldsThreshold
+	}
+	if o.Threshold <= 0 || o.Threshold > 1 {
+		o.Threshold = defaultCommonFieldThreshold
+	}
+	if o.TopN <= 0 {
+		o.TopN = 10
+	}
+	return o
+}
+
+// selectCommonFields picks common fields from per-field occurrence counts
+func selectCommonFields(fieldCounts map[string]int, totalRecords int, options CommonFieldOptions) []string {
+	options = options.normalize()
+	commonFields := []string{}
+
+	if totalRecords == 0 {
+		return commonFields
+	}
+
+	switch options.Strategy {
+	case CommonFieldsAll:
+		for field, count := range fieldCounts {
+			if count == totalRecords {
+				commonFields = append(commonFields, field)
+			}
+		}
+		sort.Strings(commonFields)
+
+	case CommonFieldsTopN:
+		for field := range fieldCounts {
+			commonFields = append(commonFields, field)
+		}
+		// Highest coverage first, alphabetical among ties
+		sort.Slice(commonFields, func(i, j int) bool {
+			ci, cj := fieldCounts[commonFields[i]], fieldCounts[commonFields[j]]
+			if ci != cj {
+				return ci > cj
+			}
+			return commonFields[i] < commonFields[j]
+		})
+		if len(commonFields) > options.TopN {
+			commonFields = commonFields[:options.TopN]
+		}
+
+	default:
+		// Compare as fractions so odd record counts are not truncated
+		minCount := options.Threshold * float64(totalRecords)
+		for field, count := range fieldCounts {
+			if float64(count) >= minCount {
+				commonFields = append(commonFields, field)
+			}
+		}
+		sort.Strings(commonFields)
+	}
+
+	return commonFields
+}
+
+// FieldInfo describes how a field path is used across the loaded records
+type FieldInfo struct {
+	Path           string         `json:"path"`
+	Count          int            `json:"count"`
+	DominantType   string         `json:"dominantType"`
+	Types          map[string]int `json:"types"`
+	SampleValues   []string       `json:"sampleValues"`
+	AvgValueLength float64        `json:"avgValueLength"`
+}
+
+// Limits for the sample values kept per field in the catalog
+const (
+	maxCatalogSamples     = 5
+	maxCatalogSampleBytes = 100
+)
+
+// jsonTypeOf returns the JSON type name of a decoded value
+func jsonTypeOf(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case float64, float32, int, int64, int32, json.Number:
+		return "number"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	default:
+		return "unknown"
+	}
+}
+
+// valueToString renders a field value as text, using JSON for non-string values
+func valueToString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return v
+	}
+
+	jsonBytes, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(jsonBytes)
+}
+
+// scalarText renders a string, number or boolean value as searched text; null is empty
+func scalarText(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return string(v)
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// matchValueText reports whether match accepts any text of a value: objects and
+// arrays as JSON and then every nested key and value in turn, scalars as they read
+func matchValueText(value interface{}, match func(text string) bool) bool {
+	switch value.(type) {
+	case map[string]interface{}, []interface{}:
+		return match(valueToString(value)) || matchNestedText(value, match)
+	}
+	return match(scalarText(value))
+}
+
+// recordText reports whether match accepts the decoded text of a record's values,
+// see matchNestedText; top-level keys are left to its raw JSON
+func recordText(record JSONRecord, match func(text string) bool) bool {
+	if record.Content == nil {
+		return matchNestedText(record.Value, match)
+	}
+	for _, value := range record.Content {
+		if matchNestedText(value, match) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchNestedText reports whether match accepts a key or scalar value anywhere in
+// a value, descending into nested objects and arrays
+func matchNestedText(value interface{}, match func(text string) bool) bool {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, nested := range v {
+			if match(key) || matchNestedText(nested, match) {
+				return true
+			}
+		}
+		return false
+	case []interface{}:
+		for _, nested := range v {
+			if matchNestedText(nested, match) {
+				return true
+			}
+		}
+		return false
+	case nil:
+		return false
+	}
+	return match(scalarText(value))
+}
+
+// truncateString shortens s to at most maxBytes without splitting a UTF-8 sequence
+func truncateString(s string, maxBytes int) string {
+	if len(s) <= maxBytes {
+		return s
+	}
+	cut := maxBytes
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut] + "..."
+}
+
+// walkFields calls fn for every field path in content, descending into nested objects
+// using dot-separated paths (arrays are reported as a single value)
+func walkFields(content map[string]interface{}, prefix string, fn func(path string, value interface{})) {
+	for key, value := range content {
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+
+		fn(path, value)
+
+		if nested, ok := value.(map[string]interface{}); ok {
+			walkFields(nested, path, fn)
+		}
+	}
+}
+
+// GetFieldCatalog returns every field path with occurrence count, dominant type,
+// sample values and average value length, sorted by path. A non-nil filter with a
+// query restricts the catalog to matching records.
+func (a *App) GetFieldCatalog(filter *SearchOptions) ([]FieldInfo, error) {
+	a.stateMu.RLock()
+	defer a.stateMu.RUnlock()
+	return a.fieldCatalog(filter)
+}
+
+// fieldCatalog implements GetFieldCatalog; the caller holds stateMu
+func (a *App) fieldCatalog(filter *SearchOptions) ([]FieldInfo, error) {
+	if a.currentFile == nil || a.cache == nil {
+		return nil, &JSONLError{
+			Message: "No file currently loaded",
+			Err:     ErrNoFileLoaded,
+		}
+	}
+
+	catalog := newFieldCatalog()
+	if err := a.forEachScoped(filter, catalog.add); err != nil {
+		return nil, err
+	}
+	return catalog.fields(), nil
+}
+
+// fieldCatalog accumulates the field paths used by records
+type fieldCatalog struct {
+	catalog      map[string]*FieldInfo
+	totalLengths map[string]int
+	leafCounts   map[string]int
+}
+
+func newFieldCatalog() *fieldCatalog {
+	return &fieldCatalog{
+		catalog:      make(map[string]*FieldInfo),
+		totalLengths: make(map[string]int),
+		leafCounts:   make(map[string]int),
+	}
+}
+
+// add records the field paths and values of one record
+func (c *fieldCatalog) add(record JSONRecord) {
+	walkFields(record.Content, "", func(path string, value interface{}) {
+		info, exists := c.catalog[path]
+		if !exists {
+			info = &FieldInfo{
+				Path:         path,
+				Types:        make(map[string]int),
+				SampleValues: []string{},
+			}
+			c.catalog[path] = info
+		}
+
+		valueType := jsonTypeOf(value)
+		info.Count++
+		info.Types[valueType]++
+
+		// Objects are described by their children, so only measure leaf values
+		if valueType == "object" {
+			return
+		}
+
+		valueStr := valueToString(value)
+		c.totalLengths[path] += len(valueStr)
+		c.leafCounts[path]++
+
+		if len(info.SampleValues) < maxCatalogSamples {
+			valueStr = truncateString(valueStr, maxCatalogSampleBytes)
+			for _, sample := range info.SampleValues {
+				if sample == valueStr {
+					return
+				}
+			}
+			info.SampleValues = append(info.SampleValues, valueStr)
+		}
+	})
+}
+
+// fields describes every field path seen, sorted by path
+func (c *fieldCatalog) fields() []FieldInfo {
+	result := make([]FieldInfo, 0, len(c.catalog))
+	for path, info := range c.catalog {
+		// Pick the most frequent type, alphabetical among ties for stable output
+		for valueType, count := range info.Types {
+			if count > info.Types[info.DominantType] ||
+				(count == info.Types[info.DominantType] && valueType < info.DominantType) {
+				info.DominantType = valueType
+			}
+		}
+		if c.leafCounts[path] > 0 {
+			info.AvgValueLength = float64(c.totalLengths[path]) / float64(c.leafCounts[path])
+		}
+		result = append(result, *info)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Path < result[j].Path
+	})
+
+	return result
+}
+
+// ValueCount is a distinct field value and the number of records containing it
+type ValueCount struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// FieldValuePage is a paginated slice of the distinct values of a field
+type FieldValuePage struct {
+	Field   string       `json:"field"`
+	Values  []ValueCount `json:"values"`
+	Offset  int          `json:"offset"`
+	Limit   int          `json:"limit"`
+	Total   int          `json:"total"`
+	HasMore bool         `json:"hasMore"`
+}
+
+// getFieldValue looks up a dot-separated field path in a record's content
+func getFieldValue(content map[string]interface{}, path string) (interface{}, bool) {
+	if value, exists := content[path]; exists {
+		return value, true
+	}
+
+	current := content
+	parts := strings.Split(path, ".")
+	for i, part := range parts {
+		value, exists := current[part]
+		if !exists {
+			return nil, false
+		}
+		if i == len(parts)-1 {
+			return value, true
+		}
+		nested, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		current = nested
+	}
+
+	return nil, false
+}
+
+// fieldPathValues returns every value a dot-separated field path reaches in a
+// record's content. Arrays along the path fan out to each element unless the next
+// segment is an index, so `items.sku` collects the sku of every item while
+// `items.0.sku` picks the first. Keys that themselves contain dots are matched too.
+func fieldPathValues(content map[string]interface{}, path string) []interface{} {
+	if value, exists := content[path]; exists {
+		return []interface{}{value}
+	}
+	return collectPathValues(content, strings.Split(path, "."), nil)
+}
+
+func collectPathValues(value interface{}, parts []string, values []interface{}) []interface{} {
+	if len(parts) == 0 {
+		return append(values, value)
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for i := len(parts); i > 0; i-- {
+			if nested, exists := v[strings.Join(parts[:i], ".")]; exists {
+				values = collectPathValues(nested, parts[i:], values)
+			}
+		}
+	case []interface{}:
+		if index, err := strconv.Atoi(parts[0]); err == nil {
+			if index >= 0 && index < len(v) {
+				values = collectPathValues(v[index], parts[1:], values)
+			}
+			return values
+		}
+		for _, element := range v {
+			values = collectPathValues(element, parts, values)
+		}
+	}
+	return values
+}
+
+// anyFieldValue reports whether match accepts any value the field path reaches
+func anyFieldValue(content map[string]interface{}, path string, match func(value interface{}) bool) bool {
+	for _, value := range fieldPathValues(content, path) {
+		if match(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldDistinctValues returns the distinct values of a field sorted by value,
+// computing them once per loaded file
+func (c *RecordCache) fieldDistinctValues(field string) ([]ValueCount, error) {
+	return c.cachedFieldValues(&c.distinctValues, field, valueToString)
+}
+
+// fieldExactValues returns the distinct values of a field rendered by exactText, so
+// 200 and "200" are separate, computing them once per loaded file
+func (c *RecordCache) fieldExactValues(field string) ([]ValueCount, error) {
+	return c.cachedFieldValues(&c.exactValues, field, exactText)
+}
+
+// cachedFieldValues counts the values of field by their text, caching the sorted
+// result in cache
+func (c *RecordCache) cachedFieldValues(cache *map[string][]ValueCount, field string, text func(value interface{}) string) ([]ValueCount, error) {
+	c.distinctValuesMu.Lock()
+	defer c.distinctValuesMu.Unlock()
+
+	if values, exists := (*cache)[field]; exists {
+		return values, nil
+	}
+
+	counts := make(fieldValueCounts)
+	err := c.forEach(func(record JSONRecord) bool {
+		if value, exists := getFieldValue(record.Content, field); exists {
+			counts[text(value)]++
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	values := counts.sorted()
+	if *cache == nil {
+		*cache = make(map[string][]ValueCount)
+	}
+	(*cache)[field] = values
+	return values, nil
+}
+
+// fieldValueCounts counts the records holding each distinct value of a field
+type fieldValueCounts map[string]int
+
+// add counts the value of field in record, if it has one
+func (counts fieldValueCounts) add(record JSONRecord, field string) {
+	if value, exists := getFieldValue(record.Content, field); exists {
+		counts[valueToString(value)]++
+	}
+}
+
+// sorted returns the counted values sorted by value
+func (counts fieldValueCounts) sorted() []ValueCount {
+	values := make([]ValueCount, 0, len(counts))
+	for value, count := range counts {
+		values = append(values, ValueCount{Value: value, Count: count})
+	}
+	sort.Slice(values, func(i, j int) bool {
+		return values[i].Value < values[j].Value
+	})
+	return values
+}
+
+// BrowseFieldValues returns one page of the distinct values of a field, optionally
+// restricted to values starting with prefixFilter (case-insensitive) and to records
+// matching filter
+func (a *App) BrowseFieldValues(field string, offset, limit int, prefixFilter string, filter *SearchOptions) (*FieldValuePage, error) {
+	a.stateMu.RLock()
+	defer a.stateMu.RUnlock()
+
+	if a.currentFile == nil || a.cache == nil {
+		return nil, &JSONLError{
+			Message: "No file currently loaded",
+			Err:     ErrNoFileLoaded,
+		}
+	}
+
+	if strings.TrimSpace(field) == "" {
+		return nil, &JSONLError{
+			Message: "Field name cannot be empty",
+			Err:     errors.New("empty field name"),
+		}
+	}
+
+	// Validate parameters
+	if offset < 0 {
+		offset = 0
+	}
+	if limit <= 0 {
+		limit = a.cache.pageSize
+	}
+	if limit > 1000 {
+		limit = 1000 // Cap maximum limit for performance
+	}
+
+	// Only whole-file values are cached; filtered subsets change with every query
+	var values []ValueCount
+	if isFiltered(filter) {
+		counts := make(fieldValueCounts)
+		err := a.forEachScoped(filter, func(record JSONRecord) {
+			counts.add(record, field)
+		})
+		if err != nil {
+			return nil, err
+		}
+		values = counts.sorted()
+	} else {
+		var err error
+		if values, err = a.cache.fieldDistinctValues(field); err != nil {
+			return nil, err
+		}
+	}
+
+	// Count matches and collect only the requested page
+	prefix := foldCase(prefixFilter)
+	page := []ValueCount{}
+	total := 0
+	for _, value := range values {
+		if prefix != "" && !strings.HasPrefix(foldCase(value.Value), prefix) {
+			continue
+		}
+		if total >= offset && len(page) < limit {
+			page = append(page, value)
+		}
+		total++
+	}
+
+	return &FieldValuePage{
+		Field:   field,
+		Values:  page,
+		Offset:  offset,
+		Limit:   limit,
+		Total:   total,
+		HasMore: offset+len(page) < total,
+	}, nil
+}
+
+// FieldValueSuggestion is a completion for a field value typed in the search box
+type FieldValueSuggestion struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+	Query string `json:"query"` // Lucene query to insert, e.g. status:=200
+}
+
+// SuggestFieldValues returns the most frequent values of a field starting with
+// prefix (case-insensitive), so `status:` can complete to status:=200, status:=404
+// or status:=500. Each suggestion matches its value exactly and by type; values
+// come from the distinct values cached per loaded file. limit defaults to 10.
+func (a *App) SuggestFieldValues(field, prefix string, limit int) ([]FieldValueSuggestion, error) {
+	a.stateMu.RLock()
+	defer a.stateMu.RUnlock()
+
+	if a.currentFile == nil || a.cache == nil {
+		return nil, &JSONLError{
+			Message: "No file currently loaded",
+			Err:     ErrNoFileLoaded,
+		}
+	}
+
+	if strings.TrimSpace(field) == "" {
+		return nil, &JSONLError{
+			Message: "Field name cannot be empty",
+			Err:     errors.New("empty field name"),
+		}
+	}
+
+	if limit <= 0 {
+		limit = 10
+	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	values, err := a.cache.fieldExactValues(field)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix = foldCase(prefix)
+	var matches []ValueCount
+	for _, value := range values {
+		if strings.HasPrefix(foldCase(exactDisplayText(value.Value)), prefix) {
+			matches = append(matches, value)
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Count > matches[j].Count
+	})
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+
+	suggestions := make([]FieldValueSuggestion, len(matches))
+	for i, value := range matches {
+		suggestions[i] = FieldValueSuggestion{
+			Value: exactDisplayText(value.Value),
+			Count: value.Count,
+			Query: formatLuceneQuery(&LuceneQuery{Type: "exact", Field: field, Value: value.Value}),
+		}
+	}
+	return suggestions, nil
+}
+
+// defaultMaxEnumValues is the largest number of distinct values an enum-like field may have
+const defaultMaxEnumValues = 20
+
+// QuickFilterValue is a single toggleable value of an enum-like field
+type QuickFilterValue struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+	Query string `json:"query"` // Lucene query selecting records with this value
+}
+
+// QuickFilter is an enum-like field exposed as a set of value chips
+type QuickFilter struct {
+	Field  string             `json:"field"`
+	Values []QuickFilterValue `json:"values"`
+}
+
+// quickFilterQuery builds an exact Lucene query (`field:=value`) matching a value
+// rendered by exactText, escaping both
+func quickFilterQuery(field, exact string) string {
+	return formatLuceneQuery(&LuceneQuery{Type: "exact", Field: field, Value: exact})
+}
+
+// GetQuickFilters detects low-cardinality fields (at most maxDistinct values, at least two)
+// and returns them with value counts as ready-made filters, sorted by field path.
+// A non-nil filter with a query restricts detection and counts to matching records.
+func (a *App) GetQuickFilters(maxDistinct int, filter *SearchOptions) ([]QuickFilter, error) {
+	a.stateMu.RLock()
+	defer a.stateMu.RUnlock()
+
+	if a.currentFile == nil || a.cache == nil {
+		return nil, &JSONLError{
+			Message: "No file currently loaded",
+			Err:     ErrNoFileLoaded,
+		}
+	}
+
+	if maxDistinct <= 0 {
+		maxDistinct = defaultMaxEnumValues
+	}
+
+	valueCounts := make(map[string]map[string]int)
+	rejected := make(map[string]bool)
+
+	err := a.forEachScoped(filter, func(record JSONRecord) {
+		walkFields(record.Content, "", func(path string, value interface{}) {
+			if rejected[path] {
+				return
+			}
+
+			// Containers cannot be toggled as a single value
+			valueType := jsonTypeOf(value)
+			if valueType == "object" || valueType == "array" {
+				rejected[path] = true
+				delete(valueCounts, path)
+				return
+			}
+
+			counts, exists := valueCounts[path]
+			if !exists {
+				counts = make(map[string]int)
+				valueCounts[path] = counts
+			}
+			// Counted by type as well, as facets are, so 200 and "200" are separate values
+			counts[exactText(value)]++
+
+			// Stop tracking fields as soon as they exceed the cardinality limit
+			if len(counts) > maxDistinct {
+				rejected[path] = true
+				delete(valueCounts, path)
+			}
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	filters := []QuickFilter{}
+	for field, counts := range valueCounts {
+		if len(counts) < 2 {
+			continue
+		}
+
+		values := make([]QuickFilterValue, 0, len(counts))
+		for exact, count := range counts {
+			values = append(values, QuickFilterValue{
+				Value: exactDisplayText(exact),
+				Count: count,
+				Query: quickFilterQuery(field, exact),
+			})
+		}
+		// Most frequent values first
+		sort.Slice(values, func(i, j int) bool {
+			if values[i].Count != values[j].Count {
+				return values[i].Count > values[j].Count
+			}
+			return values[i].Value < values[j].Value
+		})
+
+		filters = append(filters, QuickFilter{Field: field, Values: values})
+	}
+
+	sort.Slice(filters, func(i, j int) bool {
+		return filters[i].Field < filters[j].Field
+	})
+
+	return filters, nil
+}
+
+// toFloat64 converts a numeric field value to float64
+func toFloat64(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// compareNumbers orders two numeric values, reporting false when either is not a
+// number. json.Number values that only differ beyond float64 precision, such as
+// large IDs, are still ordered exactly.
+func compareNumbers(left, right interface{}) (int, bool) {
+	leftNumber, leftOK := toFloat64(left)
+	rightNumber, rightOK := toFloat64(right)
+	if !leftOK || !rightOK {
+		return 0, false
+	}
+	switch {
+	case leftNumber < rightNumber:
+		return -1, true
+	case leftNumber > rightNumber:
+		return 1, true
+	}
+
+	_, leftText := left.(json.Number)
+	_, rightText := right.(json.Number)
+	if leftText || rightText {
+		if leftExact, ok := exactNumber(left); ok {
+			if rightExact, ok := exactNumber(right); ok {
+				return leftExact.Cmp(rightExact), true
+			}
+		}
+	}
+	return 0, true
+}
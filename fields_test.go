@@ -0,0 +1,315 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+// newTestApp returns an App with the given JSONL content loaded as the current file
+func newTestApp(t *testing.T, content string) *App {
+	t.Helper()
+
+	records, stats, err := ParseJSONLFromString(content)
+	if err != nil {
+		t.Fatalf("Failed to parse test data: %v", err)
+	}
+
+	return &App{fileState: fileState{
+		currentFile: &JSONLFile{Name: "test", Path: "<clipboard>", Records: len(records)},
+		records:     records,
+		cache:       &RecordCache{records: records, pageSize: 50, totalCount: len(records), stats: stats},
+	}}
+}
+
+func TestSelectCommonFields(t *testing.T) {
+	fieldCounts := map[string]int{
+		"id":      3,
+		"name":    3,
+		"email":   2,
+		"address": 1,
+	}
+
+	tests := []struct {
+		name     string
+		total    int
+		options  CommonFieldOptions
+		expected []string
+	}{
+		{"Default threshold is half of the records", 3, CommonFieldOptions{}, []string{"email", "id", "name"}},
+		{"Custom threshold", 3, CommonFieldOptions{Threshold: 0.9}, []string{"id", "name"}},
+		{"Low threshold includes rare fields", 3, CommonFieldOptions{Threshold: 0.3}, []string{"address", "email", "id", "name"}},
+		{"All strategy", 3, CommonFieldOptions{Strategy: CommonFieldsAll}, []string{"id", "name"}},
+		{"Top N by coverage", 3, CommonFieldOptions{Strategy: CommonFieldsTopN, TopN: 3}, []string{"id", "name", "email"}},
+		{"No records", 0, CommonFieldOptions{}, []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := selectCommonFields(fieldCounts, tt.total, tt.options)
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("Expected %v, got %v for options %+v", tt.expected, result, tt.options)
+			}
+		})
+	}
+}
+
+func TestGetFieldCatalog(t *testing.T) {
+	app := newTestApp(t, `{"id":1,"user":{"name":"Ann","tags":["a"]},"note":"x"}
+{"id":2,"user":{"name":"Bob"},"note":null}
+{"id":"3","user":{"name":"Ann"}}`)
+
+	catalog, err := app.GetFieldCatalog(nil)
+	if err != nil {
+		t.Fatalf("GetFieldCatalog returned error: %v", err)
+	}
+
+	byPath := make(map[string]FieldInfo)
+	var paths []string
+	for _, info := range catalog {
+		byPath[info.Path] = info
+		paths = append(paths, info.Path)
+	}
+
+	expectedPaths := []string{"id", "note", "user", "user.name", "user.tags"}
+	if !reflect.DeepEqual(paths, expectedPaths) {
+		t.Fatalf("Expected paths %v, got %v", expectedPaths, paths)
+	}
+
+	if info := byPath["id"]; info.Count != 3 || info.DominantType != "number" {
+		t.Errorf("Unexpected id info: %+v", info)
+	}
+	if info := byPath["user"]; info.DominantType != "object" || len(info.SampleValues) != 0 {
+		t.Errorf("Unexpected user info: %+v", info)
+	}
+	if info := byPath["user.name"]; !reflect.DeepEqual(info.SampleValues, []string{"Ann", "Bob"}) || info.AvgValueLength != 3 {
+		t.Errorf("Unexpected user.name info: %+v", info)
+	}
+	if info := byPath["note"]; info.Count != 2 || info.Types["null"] != 1 {
+		t.Errorf("Unexpected note info: %+v", info)
+	}
+}
+
+func TestBrowseFieldValues(t *testing.T) {
+	app := newTestApp(t, `{"user":"alice"}
+{"user":"bob"}
+{"user":"alice"}
+{"user":"Albert"}
+{"user":"carol"}
+{"other":1}`)
+
+	tests := []struct {
+		name          string
+		offset, limit int
+		prefix        string
+		expected      []ValueCount
+		total         int
+		hasMore       bool
+	}{
+		{"First page", 0, 2, "", []ValueCount{{"Albert", 1}, {"alice", 2}}, 4, true},
+		{"Second page", 2, 2, "", []ValueCount{{"bob", 1}, {"carol", 1}}, 4, false},
+		{"Prefix filter is case-insensitive", 0, 10, "AL", []ValueCount{{"Albert", 1}, {"alice", 2}}, 2, false},
+		{"Offset beyond values", 10, 2, "", []ValueCount{}, 4, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := app.BrowseFieldValues("user", tt.offset, tt.limit, tt.prefix, nil)
+			if err != nil {
+				t.Fatalf("BrowseFieldValues returned error: %v", err)
+			}
+			if !reflect.DeepEqual(page.Values, tt.expected) || page.Total != tt.total || page.HasMore != tt.hasMore {
+				t.Errorf("Unexpected page: %+v", page)
+			}
+		})
+	}
+}
+
+func TestGetQuickFilters(t *testing.T) {
+	app := newTestApp(t, `{"id":1,"level":"info","region":"eu","meta":{"env":"prod"}}
+{"id":2,"level":"error","region":"eu","meta":{"env":"dev"}}
+{"id":3,"level":"info","region":"eu","meta":{"env":"prod"},"tags":["a"]}`)
+
+	filters, err := app.GetQuickFilters(2, nil)
+	if err != nil {
+		t.Fatalf("GetQuickFilters returned error: %v", err)
+	}
+
+	var fields []string
+	for _, filter := range filters {
+		fields = append(fields, filter.Field)
+	}
+
+	// id has too many values, region is constant, meta and tags are containers
+	expectedFields := []string{"level", "meta.env"}
+	if !reflect.DeepEqual(fields, expectedFields) {
+		t.Fatalf("Expected fields %v, got %v", expectedFields, fields)
+	}
+
+	expectedLevel := []QuickFilterValue{
+		{Value: "info", Count: 2, Query: `level:="info"`},
+		{Value: "error", Count: 1, Query: `level:="error"`},
+	}
+	if !reflect.DeepEqual(filters[0].Values, expectedLevel) {
+		t.Errorf("Expected level values %v, got %v", expectedLevel, filters[0].Values)
+	}
+
+	// Queries escape quotes, backslashes and field names, and match their value
+	app = newTestApp(t, `{"user name":"say \"hi\"\\"}
+{"user name":"other"}`)
+	filters, _ = app.GetQuickFilters(2, nil)
+	if len(filters) != 1 || len(filters[0].Values) != 2 {
+		t.Fatalf("Expected one quick filter with 2 values, got %+v", filters)
+	}
+	quoted := filters[0].Values[1]
+	if quoted.Query != `user\ name:="say \"hi\"\\"` {
+		t.Errorf("Unexpected query %s", quoted.Query)
+	}
+	result, err := app.SearchRecords(SearchOptions{Query: quoted.Query, UseLucene: true})
+	if err != nil || result.TotalMatches != 1 || result.Records[0].LineNumber != 1 {
+		t.Errorf("Expected the query to match line 1, got %+v (%v)", result, err)
+	}
+
+	// Values match exactly and by type: "info" does not select "info-x", and 200
+	// and "200" are separate values
+	app = newTestApp(t, `{"level":"info","code":200}
+{"level":"info-x","code":"200"}
+{"level":"info","code":200}`)
+	filters, _ = app.GetQuickFilters(2, nil)
+	expected := []QuickFilter{
+		{Field: "code", Values: []QuickFilterValue{
+			{Value: "200", Count: 2, Query: `code:=200`},
+			{Value: "200", Count: 1, Query: `code:="200"`},
+		}},
+		{Field: "level", Values: []QuickFilterValue{
+			{Value: "info", Count: 2, Query: `level:="info"`},
+			{Value: "info-x", Count: 1, Query: `level:="info-x"`},
+		}},
+	}
+	if !reflect.DeepEqual(filters, expected) {
+		t.Fatalf("Expected %+v, got %+v", expected, filters)
+	}
+	for _, filter := range filters {
+		for _, value := range filter.Values {
+			result, err := app.SearchRecords(SearchOptions{Query: value.Query, UseLucene: true})
+			if err != nil || result.TotalMatches != value.Count {
+				t.Errorf("Expected %s to match %d records, got %+v (%v)", value.Query, value.Count, result, err)
+			}
+		}
+	}
+}
+
+func TestFieldPathValues(t *testing.T) {
+	records, _, _ := ParseJSONLFromString(`{"request":{"headers":{"user-agent":"curl/8.0"}},"items":[{"sku":"a"},{"sku":"b"},{"qty":1}],"k8s.pod":"web-1","meta":{"app.name":"api"}}`)
+	content := records[0].Content
+
+	tests := []struct {
+		path     string
+		expected []interface{}
+	}{
+		{"request.headers.user-agent", []interface{}{"curl/8.0"}},
+		{"items.sku", []interface{}{"a", "b"}},
+		{"items.1.sku", []interface{}{"b"}},
+		{"items.7.sku", nil},
+		{"k8s.pod", []interface{}{"web-1"}},
+		{"meta.app.name", []interface{}{"api"}},
+		{"request.missing", nil},
+	}
+	for _, tt := range tests {
+		if got := fieldPathValues(content, tt.path); !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("fieldPathValues(%q) = %v, expected %v", tt.path, got, tt.expected)
+		}
+	}
+
+	app := newTestApp(t, `{"request":{"headers":{"user-agent":"curl/8.0"}},"items":[{"sku":"a"}]}
+{"request":{"headers":{"user-agent":"Mozilla/5.0"}},"items":[{"sku":"b"},{"sku":"c"}]}`)
+	for _, options := range []SearchOptions{
+		{Query: "request.headers.user-agent:curl", UseLucene: true},
+		{Query: "curl", SelectedFields: []string{"request.headers.user-agent"}},
+		{Query: "items.sku:a OR items.1.sku:a", UseLucene: true},
+		{Query: "_exists_:items.0.sku AND NOT _exists_:items.1", UseLucene: true},
+	} {
+		result, err := app.SearchRecords(options)
+		if err != nil {
+			t.Fatalf("SearchRecords returned error: %v", err)
+		}
+		if result.TotalMatches != 1 || result.Records[0].LineNumber != 1 {
+			t.Errorf("Expected only line 1 to match %+v, got %+v", options, result.Records)
+		}
+	}
+}
+
+func TestSuggestFieldValues(t *testing.T) {
+	app := newTestApp(t, `{"status":200,"path":"/api"}
+{"status":404,"path":"/api v2"}
+{"status":200}
+{"status":500}
+{"status":200}
+{"status":500}`)
+
+	suggestions, err := app.SuggestFieldValues("status", "", 2)
+	if err != nil {
+		t.Fatalf("SuggestFieldValues returned error: %v", err)
+	}
+	expected := []FieldValueSuggestion{
+		{Value: "200", Count: 3, Query: "status:=200"},
+		{Value: "500", Count: 2, Query: "status:=500"},
+	}
+	if !reflect.DeepEqual(suggestions, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, suggestions)
+	}
+
+	suggestions, _ = app.SuggestFieldValues("status", "4", 0)
+	if len(suggestions) != 1 || suggestions[0].Value != "404" {
+		t.Errorf("Expected only 404 for prefix 4, got %+v", suggestions)
+	}
+
+	// Suggested queries are escaped for the search box
+	suggestions, _ = app.SuggestFieldValues("path", "/API ", 0)
+	if len(suggestions) != 1 || suggestions[0].Query != `path:="/api v2"` {
+		t.Errorf("Expected an escaped query for /api v2, got %+v", suggestions)
+	}
+	if result, _ := app.SearchRecords(SearchOptions{Query: suggestions[0].Query, UseLucene: true}); result.TotalMatches != 1 {
+		t.Errorf("Expected the suggested query to match one record, got %d", result.TotalMatches)
+	}
+
+	// Suggestions select their value exactly and by type
+	suggestions, _ = app.SuggestFieldValues("path", "/api", 0)
+	if len(suggestions) != 2 {
+		t.Fatalf("Expected both paths, got %+v", suggestions)
+	}
+	for _, suggestion := range suggestions {
+		if result, _ := app.SearchRecords(SearchOptions{Query: suggestion.Query, UseLucene: true}); result.TotalMatches != 1 {
+			t.Errorf("Expected %s to match only %s, got %d records", suggestion.Query, suggestion.Value, result.TotalMatches)
+		}
+	}
+	mixed := newTestApp(t, `{"code":200}
+{"code":"200"}
+{"code":200}`)
+	suggestions, _ = mixed.SuggestFieldValues("code", "2", 0)
+	expected = []FieldValueSuggestion{
+		{Value: "200", Count: 2, Query: "code:=200"},
+		{Value: "200", Count: 1, Query: `code:="200"`},
+	}
+	if !reflect.DeepEqual(suggestions, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, suggestions)
+	}
+
+	// Prefixes match with full case folding
+	cities := newTestApp(t, `{"city":"Straße 1"}
+{"city":"İstanbul"}
+{"city":"ΣΊΣΥΦΟΣ"}`)
+	for prefix, expected := range map[string]string{"STRASSE": "Straße 1", "istan": "İstanbul", "σίσυφος": "ΣΊΣΥΦΟΣ"} {
+		suggestions, _ := cities.SuggestFieldValues("city", prefix, 0)
+		if len(suggestions) != 1 || suggestions[0].Value != expected {
+			t.Errorf("Expected %s for prefix %s, got %+v", expected, prefix, suggestions)
+		}
+		page, _ := cities.BrowseFieldValues("city", 0, 10, prefix, nil)
+		if page == nil || len(page.Values) != 1 || page.Values[0].Value != expected {
+			t.Errorf("Expected to browse %s for prefix %s, got %+v", expected, prefix, page)
+		}
+	}
+
+	if _, err := app.SuggestFieldValues(" ", "", 0); err == nil {
+		t.Error("Expected an error for an empty field name")
+	}
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"hash/maphash"
+	"math"
+	"math/big"
+	"math/bits"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Distinct values are counted exactly up to exactDistinctLimit, then estimated with
+// a HyperLogLog sketch of 2^sketchPrecision registers (about 0.8% standard error)
+const (
+	exactDistinctLimit = 100000
+	sketchPrecision    = 14
+)
+
+// FieldStats summarizes the values of one field across the loaded records
+type FieldStats struct {
+	Field               string         `json:"field"`
+	Records             int            `json:"records"` // records examined
+	Present             int            `json:"present"` // records containing the field, null included
+	Missing             int            `json:"missing"`
+	Null                int            `json:"null"`
+	Types               map[string]int `json:"types"` // JSON type name to count
+	Distinct            int            `json:"distinct"`
+	DistinctApproximate bool           `json:"distinctApproximate"` // Distinct is an estimate
+	MinNumber           json.Number    `json:"minNumber,omitempty"`
+	MaxNumber           json.Number    `json:"maxNumber,omitempty"`
+	MinDate             *time.Time     `json:"minDate,omitempty"` // over strings parsed as timestamps
+	MaxDate             *time.Time     `json:"maxDate,omitempty"`
+	AvgStringLength     float64        `json:"avgStringLength"` // in characters, over string values
+}
+
+// cardinalitySketch estimates the number of distinct strings added to it
+type cardinalitySketch struct {
+	seed      maphash.Seed
+	registers []uint8
+}
+
+func newCardinalitySketch() *cardinalitySketch {
+	return &cardinalitySketch{
+		seed:      maphash.MakeSeed(),
+		registers: make([]uint8, 1<<sketchPrecision),
+	}
+}
+
+// add records a value; the top bits of its hash pick a register, which keeps the
+// longest run of leading zeros seen in the remaining bits
+func (s *cardinalitySketch) add(value string) {
+	hash := maphash.String(s.seed, value)
+	register := hash >> (64 - sketchPrecision)
+	rank := uint8(bits.LeadingZeros64(hash<<sketchPrecision|1<<(sketchPrecision-1))) + 1
+	if rank > s.registers[register] {
+		s.registers[register] = rank
+	}
+}
+
+// estimate returns the approximate number of distinct values added
+func (s *cardinalitySketch) estimate() int {
+	m := float64(len(s.registers))
+	sum := 0.0
+	empty := 0
+	for _, rank := range s.registers {
+		sum += math.Ldexp(1, -int(rank))
+		if rank == 0 {
+			empty++
+		}
+	}
+	estimate := 0.7213 / (1 + 1.079/m) * m * m / sum
+	// Small cardinalities are estimated better by linear counting
+	if estimate <= 2.5*m && empty > 0 {
+		estimate = m * math.Log(m/float64(empty))
+	}
+	return int(estimate + 0.5)
+}
+
+// distinctCounter counts distinct values exactly until there are too many to keep,
+// then switches to a sketch
+type distinctCounter struct {
+	seen   map[string]struct{}
+	sketch *cardinalitySketch
+}
+
+func (c *distinctCounter) add(value string) {
+	if c.sketch != nil {
+		c.sketch.add(value)
+		return
+	}
+	if c.seen == nil {
+		c.seen = make(map[string]struct{})
+	}
+	c.seen[value] = struct{}{}
+	if len(c.seen) > exactDistinctLimit {
+		c.sketch = newCardinalitySketch()
+		for seen := range c.seen {
+			c.sketch.add(seen)
+		}
+		c.seen = nil
+	}
+}
+
+// count returns the number of distinct values and whether it is an estimate
+func (c *distinctCounter) count() (int, bool) {
+	if c.sketch != nil {
+		return c.sketch.estimate(), true
+	}
+	return len(c.seen), false
+}
+
+// computeFieldStats scans every record for the values of field
+func computeFieldStats(cache *RecordCache, field string) (*FieldStats, error) {
+	stats := &FieldStats{Field: field, Types: make(map[string]int)}
+	var distinct distinctCounter
+	var minNumber, maxNumber *big.Rat
+	var minDate, maxDate time.Time
+	stringCount, stringLength := 0, 0
+
+	err := cache.forEach(func(record JSONRecord) bool {
+		stats.Records++
+		value, exists := getFieldValue(record.Content, field)
+		if !exists {
+			stats.Missing++
+			return true
+		}
+		stats.Present++
+		stats.Types[jsonTypeOf(value)]++
+		distinct.add(valueToString(value))
+
+		switch v := value.(type) {
+		case nil:
+			stats.Null++
+		case string:
+			stringCount++
+			stringLength += utf8.RuneCountInString(v)
+			if t, ok := parseTimestampString(strings.TrimSpace(v)); ok {
+				if minDate.IsZero() || t.Before(minDate) {
+					minDate = t
+				}
+				if maxDate.IsZero() || t.After(maxDate) {
+					maxDate = t
+				}
+			}
+		case bool, map[string]interface{}, []interface{}:
+		default:
+			// Compare exactly so large integers keep their precision
+			number, ok := exactNumber(v)
+			if !ok {
+				break
+			}
+			if minNumber == nil || number.Cmp(minNumber) < 0 {
+				minNumber = number
+				stats.MinNumber = json.Number(valueToString(v))
+			}
+			if maxNumber == nil || number.Cmp(maxNumber) > 0 {
+				maxNumber = number
+				stats.MaxNumber = json.Number(valueToString(v))
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	stats.Distinct, stats.DistinctApproximate = distinct.count()
+	if !minDate.IsZero() {
+		stats.MinDate = &minDate
+		stats.MaxDate = &maxDate
+	}
+	if stringCount > 0 {
+		stats.AvgStringLength = float64(stringLength) / float64(stringCount)
+	}
+	return stats, nil
+}
+
+// GetFieldStats returns the type distribution, null and missing counts, distinct
+// cardinality, numeric and date ranges and average string length of a field over
+// all loaded records. Statistics are computed on first request and cached until
+// the records change.
+func (a *App) GetFieldStats(field string) (*FieldStats, error) {
+	a.stateMu.RLock()
+	defer a.stateMu.RUnlock()
+
+	if a.currentFile == nil || a.cache == nil {
+		return nil, &JSONLError{
+			Message: "No file currently loaded",
+			Err:     ErrNoFileLoaded,
+		}
+	}
+
+	field = strings.TrimSpace(field)
+	if field == "" {
+		return nil, &JSONLError{
+			Message: "Field name cannot be empty",
+			Err:     errors.New("empty field name"),
+		}
+	}
+
+	a.cache.fieldStatsMu.Lock()
+	defer a.cache.fieldStatsMu.Unlock()
+	if stats, exists := a.cache.fieldStats[field]; exists {
+		return stats, nil
+	}
+	stats, err := computeFieldStats(a.cache, field)
+	if err != nil {
+		return nil, err
+	}
+	if a.cache.fieldStats == nil {
+		a.cache.fieldStats = make(map[string]*FieldStats)
+	}
+	a.cache.fieldStats[field] = stats
+	return stats, nil
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestGetFieldStats(t *testing.T) {
+	app := newTestApp(t, `{"v":3,"name":"héllo","at":"2024-01-02T00:00:00Z"}
+{"v":12345678901234567890,"name":"ab","at":"2023-06-01"}
+{"v":-1.5,"name":null}
+{"v":"text","name":"ab","at":"not a date"}
+{"other":true}
+`)
+
+	stats, err := app.GetFieldStats("v")
+	if err != nil {
+		t.Fatalf("GetFieldStats returned error: %v", err)
+	}
+	if stats.Records != 5 || stats.Present != 4 || stats.Missing != 1 || stats.Null != 0 {
+		t.Errorf("Unexpected counts: %+v", stats)
+	}
+	if stats.Types["number"] != 3 || stats.Types["string"] != 1 {
+		t.Errorf("Unexpected types: %v", stats.Types)
+	}
+	if stats.MinNumber != "-1.5" || stats.MaxNumber != "12345678901234567890" {
+		t.Errorf("Expected range -1.5 to 12345678901234567890, got %s to %s", stats.MinNumber, stats.MaxNumber)
+	}
+	if stats.Distinct != 4 || stats.DistinctApproximate {
+		t.Errorf("Expected 4 exact distinct values, got %d (approximate %t)", stats.Distinct, stats.DistinctApproximate)
+	}
+
+	names, _ := app.GetFieldStats("name")
+	if names.Null != 1 || names.Missing != 1 || names.Distinct != 3 {
+		t.Errorf("Unexpected name counts: %+v", names)
+	}
+	if names.AvgStringLength != 3 {
+		t.Errorf("Expected an average of 3 characters, got %v", names.AvgStringLength)
+	}
+	if names.MinNumber != "" || names.MinDate != nil {
+		t.Errorf("Expected no numeric or date range for names, got %+v", names)
+	}
+
+	dates, _ := app.GetFieldStats("at")
+	if dates.MinDate == nil || !dates.MinDate.Equal(time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)) ||
+		!dates.MaxDate.Equal(time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected date range %v to %v", dates.MinDate, dates.MaxDate)
+	}
+
+	// Cached until the records change
+	if again, _ := app.GetFieldStats("v"); again != stats {
+		t.Error("Expected cached statistics on the second request")
+	}
+	app.bumpDataVersion()
+	if again, _ := app.GetFieldStats("v"); again == stats {
+		t.Error("Expected statistics to be recomputed after the data changed")
+	}
+
+	var jsonlErr *JSONLError
+	if _, err := app.GetFieldStats(" "); err == nil {
+		t.Error("Expected an error for an empty field name")
+	}
+	if _, err := (&App{}).GetFieldStats("v"); !errors.As(err, &jsonlErr) || !errors.Is(jsonlErr.Err, ErrNoFileLoaded) {
+		t.Errorf("Expected ErrNoFileLoaded, got %v", err)
+	}
+}
+
+func TestDistinctCounterApproximation(t *testing.T) {
+	var counter distinctCounter
+	for i := 0; i < exactDistinctLimit; i++ {
+		counter.add(strconv.Itoa(i))
+	}
+	if count, approximate := counter.count(); count != exactDistinctLimit || approximate {
+		t.Fatalf("Expected %d exact distinct values, got %d (approximate %t)", exactDistinctLimit, count, approximate)
+	}
+
+	const total = 3 * exactDistinctLimit
+	for i := 0; i < total; i++ {
+		counter.add(strconv.Itoa(i))
+	}
+	count, approximate := counter.count()
+	if !approximate {
+		t.Fatal("Expected an estimate past the exact limit")
+	}
+	if count < total*97/100 || count > total*103/100 {
+		t.Errorf("Expected about %d distinct values, got %d", total, count)
+	}
+}
@@ -0,0 +1,262 @@
+package main
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrEmptyFilter is returned when pushing a filter without a query
+var ErrEmptyFilter = errors.New("empty filter")
+
+// FilterLevel describes one filter of the filter stack
+type FilterLevel struct {
+	Query     string `json:"query"`
+	UseLucene bool   `json:"useLucene"`
+	Matches   int    `json:"matches"` // records left after this and all earlier filters
+}
+
+// stackedFilter is a filter of the stack with the positions of the records left
+// after it, computed for one data version
+type stackedFilter struct {
+	options   SearchOptions
+	positions []int32
+	version   uint64
+}
+
+// filterPositions returns the positions of the records matching options among the
+// given positions, or among all records when within is nil
+func (a *App) filterPositions(options SearchOptions, within []int32) ([]int32, error) {
+	matches, err := a.searchPredicate(options)
+	if err != nil {
+		return nil, err
+	}
+	positions := []int32{}
+	if within == nil {
+		position := int32(0)
+		err := a.cache.forEach(func(record JSONRecord) bool {
+			if matches(record) {
+				positions = append(positions, position)
+			}
+			position++
+			return true
+		})
+		if err != nil {
+			return nil, err
+		}
+		return positions, nil
+	}
+
+	for _, position := range within {
+		records, err := a.cache.slice(int(position), int(position)+1)
+		if err != nil {
+			return nil, err
+		}
+		if len(records) == 1 && matches(records[0]) {
+			positions = append(positions, position)
+		}
+	}
+	return positions, nil
+}
+
+// refreshFilters re-applies the stack after the records changed, so each filter
+// again narrows the current records. A filter that cannot be re-applied stays stale.
+func (a *App) refreshFilters() error {
+	var within []int32
+	for _, filter := range a.cache.filters {
+		if filter.version != a.cache.version {
+			positions, err := a.filterPositions(filter.options, within)
+			if err != nil {
+				return err
+			}
+			filter.positions = positions
+			filter.version = a.cache.version
+		}
+		within = filter.positions
+	}
+	return nil
+}
+
+// filterScope returns the records left by the filter stack, or false when no
+// filter is stacked
+func (a *App) filterScope() ([]JSONRecord, bool, error) {
+	if a.cache == nil || len(a.cache.filters) == 0 {
+		return nil, false, nil
+	}
+	a.cache.filterMu.Lock()
+	defer a.cache.filterMu.Unlock()
+	if err := a.refreshFilters(); err != nil {
+		return nil, true, err
+	}
+	positions := a.cache.filters[len(a.cache.filters)-1].positions
+
+	records := make([]JSONRecord, 0, len(positions))
+	for _, position := range positions {
+		page, err := a.cache.slice(int(position), int(position)+1)
+		if err != nil {
+			return nil, true, &JSONLError{
+				Message: "Failed to read records",
+				Err:     err,
+			}
+		}
+		records = append(records, page...)
+	}
+	return records, true, nil
+}
+
+// PushFilter narrows the current results: the filter applies only to the records
+// left by the filters pushed before it, and SearchRecords searches within them too.
+// It returns the page of remaining records selected by options.Offset and Limit.
+func (a *App) PushFilter(options SearchOptions) (*SearchResult, error) {
+	a.stateMu.Lock()
+	defer a.stateMu.Unlock()
+
+	if a.currentFile == nil || a.cache == nil {
+		return nil, &JSONLError{
+			Message: "No file currently loaded",
+			Err:     ErrNoFileLoaded,
+		}
+	}
+	if strings.TrimSpace(options.Query) == "" {
+		return nil, &JSONLError{
+			Message: "Filter query cannot be empty",
+			Err:     ErrEmptyFilter,
+		}
+	}
+
+	if err := a.refreshFilters(); err != nil {
+		return nil, err
+	}
+	var within []int32
+	if len(a.cache.filters) > 0 {
+		within = a.cache.filters[len(a.cache.filters)-1].positions
+	}
+	positions, err := a.filterPositions(options, within)
+	if err != nil {
+		return nil, err
+	}
+	a.cache.filters = append(a.cache.filters, &stackedFilter{
+		options:   options,
+		positions: positions,
+		version:   a.cache.version,
+	})
+	return a.filteredRecords(options.Offset, options.Limit)
+}
+
+// PopFilter removes the most recently pushed filter, widening the results to those
+// of the filter before it, and returns the remaining stack
+func (a *App) PopFilter() []FilterLevel {
+	a.stateMu.Lock()
+	defer a.stateMu.Unlock()
+
+	if a.cache != nil && len(a.cache.filters) > 0 {
+		a.cache.filters = a.cache.filters[:len(a.cache.filters)-1]
+	}
+	return a.listFilters()
+}
+
+// ClearFilters removes every stacked filter
+func (a *App) ClearFilters() {
+	a.stateMu.Lock()
+	defer a.stateMu.Unlock()
+
+	if a.cache != nil {
+		a.cache.filters = nil
+	}
+}
+
+// ListFilters returns the filter stack from the first pushed filter to the last
+func (a *App) ListFilters() []FilterLevel {
+	a.stateMu.Lock()
+	defer a.stateMu.Unlock()
+	return a.listFilters()
+}
+
+// listFilters implements ListFilters; the caller holds stateMu exclusively
+func (a *App) listFilters() []FilterLevel {
+	levels := []FilterLevel{}
+	if a.cache == nil {
+		return levels
+	}
+	// Stale counts are still the best available for a filter that cannot be re-applied
+	a.refreshFilters()
+	for _, filter := range a.cache.filters {
+		levels = append(levels, FilterLevel{
+			Query:     filter.options.Query,
+			UseLucene: filter.options.UseLucene,
+			Matches:   len(filter.positions),
+		})
+	}
+	return levels
+}
+
+// GetFilteredRecords returns a page of the records left by the filter stack, or of
+// all records when it is empty
+func (a *App) GetFilteredRecords(offset, limit int) (*SearchResult, error) {
+	a.stateMu.RLock()
+	defer a.stateMu.RUnlock()
+	return a.filteredRecords(offset, limit)
+}
+
+// filteredRecords implements GetFilteredRecords; the caller holds stateMu
+func (a *App) filteredRecords(offset, limit int) (*SearchResult, error) {
+	if a.currentFile == nil || a.cache == nil {
+		return nil, &JSONLError{
+			Message: "No file currently loaded",
+			Err:     ErrNoFileLoaded,
+		}
+	}
+
+	if offset < 0 {
+		offset = 0
+	}
+	if limit <= 0 {
+		limit = 50
+	}
+	if limit > 1000 {
+		limit = 1000
+	}
+
+	records, scoped, err := a.filterScope()
+	if err != nil {
+		return nil, err
+	}
+	total := a.cache.totalCount
+	query := ""
+	if scoped {
+		total = len(records)
+		query = a.cache.filters[len(a.cache.filters)-1].options.Query
+	}
+
+	start := offset
+	if start > total {
+		start = total
+	}
+	end := start + limit
+	if end > total {
+		end = total
+	}
+
+	var page []JSONRecord
+	if scoped {
+		page = records[start:end]
+	} else {
+		// Only the requested page is read, so disk-backed files stay on disk
+		var err error
+		if page, err = a.cache.slice(start, end); err != nil {
+			return nil, &JSONLError{
+				Message: "Failed to read records",
+				Err:     err,
+			}
+		}
+	}
+	return &SearchResult{
+		Records:      page,
+		Offset:       offset,
+		Limit:        limit,
+		Total:        a.cache.totalCount,
+		TotalMatches: total,
+		HasMore:      end < total,
+		Query:        query,
+		Version:      a.cache.versionToken(),
+	}, nil
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestFilterStack(t *testing.T) {
+	app := newTestApp(t, `{"level":"error","svc":"api","msg":"timeout"}
+{"level":"info","svc":"api","msg":"ok"}
+{"level":"error","svc":"db","msg":"timeout"}
+{"level":"error","svc":"api","msg":"refused"}
+{"level":"warn","svc":"api","msg":"timeout"}
+`)
+	lines := func(result *SearchResult) string {
+		var numbers []int
+		for _, record := range result.Records {
+			numbers = append(numbers, record.LineNumber)
+		}
+		return fmt.Sprint(numbers)
+	}
+
+	result, err := app.PushFilter(SearchOptions{Query: "level:error", UseLucene: true})
+	if err != nil {
+		t.Fatalf("PushFilter returned error: %v", err)
+	}
+	if lines(result) != "[1 3 4]" || result.TotalMatches != 3 {
+		t.Errorf("Expected lines [1 3 4], got %s", lines(result))
+	}
+
+	result, err = app.PushFilter(SearchOptions{Query: "api"})
+	if err != nil {
+		t.Fatalf("PushFilter returned error: %v", err)
+	}
+	if lines(result) != "[1 4]" {
+		t.Errorf("Expected the second filter to narrow to [1 4], got %s", lines(result))
+	}
+
+	// Searches run within the filtered records
+	search, err := app.SearchRecords(SearchOptions{Query: "timeout"})
+	if err != nil || lines(search) != "[1]" {
+		t.Errorf("Expected search within filters to find [1], got %v (%v)", search, err)
+	}
+
+	levels := app.ListFilters()
+	if len(levels) != 2 || levels[0].Query != "level:error" || levels[0].Matches != 3 || levels[1].Matches != 2 {
+		t.Errorf("Unexpected filter stack: %+v", levels)
+	}
+
+	// Derived field changes re-apply the stack to the updated records
+	app.records[1].Content["level"] = "error"
+	app.bumpDataVersion()
+	if page, _ := app.GetFilteredRecords(0, 10); lines(page) != "[1 2 4]" {
+		t.Errorf("Expected the stack to be re-applied, got %s", lines(page))
+	}
+
+	if levels := app.PopFilter(); len(levels) != 1 {
+		t.Errorf("Expected one filter after pop, got %+v", levels)
+	}
+	if page, _ := app.GetFilteredRecords(1, 2); lines(page) != "[2 3]" || !page.HasMore {
+		t.Errorf("Expected page [2 3] of the first filter, got %s", lines(page))
+	}
+
+	app.ClearFilters()
+	if page, _ := app.GetFilteredRecords(0, 10); page.TotalMatches != 5 {
+		t.Errorf("Expected all records without filters, got %d", page.TotalMatches)
+	}
+
+	_, err = app.PushFilter(SearchOptions{Query: " "})
+	var jsonlErr *JSONLError
+	if !errors.As(err, &jsonlErr) || !errors.Is(jsonlErr.Err, ErrEmptyFilter) {
+		t.Errorf("Expected ErrEmptyFilter, got %v", err)
+	}
+}
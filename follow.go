@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"time"
+)
+
+// followState is the polling of a followed file, see StartFollow
+type followState struct {
+	path   string
+	cancel context.CancelFunc
+}
+
+// StartFollow follows the current file like tail -f: every FollowIntervalMs of the
+// settings, lines appended to it are merged as by ReloadIncremental and, when
+// records were added, a "follow:appended" event carries the updated file. Polling
+// stops on StopFollow or once another file becomes current.
+func (a *App) StartFollow() error {
+	a.stateMu.Lock()
+	defer a.stateMu.Unlock()
+
+	if a.currentFile == nil || a.cache == nil {
+		return &JSONLError{
+			Message: "No file currently loaded",
+			Err:     ErrNoFileLoaded,
+		}
+	}
+
+	if a.following != nil {
+		a.following.cancel()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	state := &followState{path: a.currentFile.Path, cancel: cancel}
+	a.following = state
+	go a.follow(ctx, state, a.currentFile.Records)
+	return nil
+}
+
+// StopFollow stops following the current file
+func (a *App) StopFollow() {
+	a.stateMu.Lock()
+	defer a.stateMu.Unlock()
+	if a.following != nil {
+		a.following.cancel()
+		a.following = nil
+	}
+}
+
+// IsFollowing reports whether the current file is being followed
+func (a *App) IsFollowing() bool {
+	a.stateMu.RLock()
+	defer a.stateMu.RUnlock()
+	return a.following != nil
+}
+
+// follow polls the followed file until ctx is cancelled or another file is current
+func (a *App) follow(ctx context.Context, state *followState, records int) {
+	defer func() {
+		a.stateMu.Lock()
+		if a.following == state {
+			a.following = nil
+		}
+		a.stateMu.Unlock()
+		state.cancel()
+	}()
+
+	for {
+		// The interval is read on every poll so updated settings apply right away
+		a.stateMu.RLock()
+		interval := time.Duration(a.settings.withDefaults().FollowIntervalMs) * time.Millisecond
+		followed := a.currentFile != nil && a.currentFile.Path == state.path
+		a.stateMu.RUnlock()
+		if !followed {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+
+		file, err := a.ReloadIncremental()
+		if err != nil {
+			a.emitEvent("follow:error", err.Error())
+			continue
+		}
+		if file.Path == state.path && file.Records != records {
+			records = file.Records
+			a.emitEvent("follow:appended", file)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFollow(t *testing.T) {
+	useTempConfigDir(t)
+
+	app := &App{settings: Settings{FollowIntervalMs: minFollowInterval}}
+	var jsonErr *JSONLError
+	if err := app.StartFollow(); !errors.As(err, &jsonErr) || !errors.Is(jsonErr.Err, ErrNoFileLoaded) {
+		t.Fatalf("Expected ErrNoFileLoaded without a file, got %v", err)
+	}
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log.jsonl")
+	os.WriteFile(path, []byte("{\"n\":1}\n"), 0644)
+	if _, err := app.LoadJSONLFile(path); err != nil {
+		t.Fatalf("LoadJSONLFile returned error: %v", err)
+	}
+	if err := app.StartFollow(); err != nil {
+		t.Fatalf("StartFollow returned error: %v", err)
+	}
+	if !app.IsFollowing() {
+		t.Fatal("Expected the file to be followed")
+	}
+
+	f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	f.WriteString("{\"n\":2}\n{\"n\":3}\n")
+	f.Close()
+
+	count := func() int {
+		total, _ := app.GetTotalRecordCount()
+		return total
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for count() != 3 && time.Now().Before(deadline) {
+		time.Sleep(20 * time.Millisecond)
+	}
+	if total := count(); total != 3 {
+		t.Fatalf("Expected the appended records to be merged, got %d records", total)
+	}
+
+	app.StopFollow()
+	if app.IsFollowing() {
+		t.Error("Expected StopFollow to stop following")
+	}
+
+	// Following ends once another file is loaded
+	if err := app.StartFollow(); err != nil {
+		t.Fatalf("StartFollow returned error: %v", err)
+	}
+	other := filepath.Join(dir, "other.jsonl")
+	os.WriteFile(other, []byte("{\"n\":1}\n"), 0644)
+	if _, err := app.LoadJSONLFile(other); err != nil {
+		t.Fatalf("LoadJSONLFile returned error: %v", err)
+	}
+	deadline = time.Now().Add(5 * time.Second)
+	for app.IsFollowing() && time.Now().Before(deadline) {
+		time.Sleep(20 * time.Millisecond)
+	}
+	if app.IsFollowing() {
+		t.Error("Expected following to stop when another file is loaded")
+	}
+}
@@ -34,7 +34,7 @@ export interface SearchOptions {
   query: string;
   caseSensitive: boolean;
   useLucene: boolean;
-  selectedField: string;
+  selectedFields: string[];
   offset: number;
   limit: number;
 }
@@ -10,7 +10,7 @@ export function GetAllFields():Promise<Array<string>>;
 
 export function GetAllRecords(arg1:string):Promise<Array<main.JSONRecord>>;
 
-export function GetCommonFields():Promise<Array<string>>;
+export function GetCommonFields(arg1:main.CommonFieldOptions):Promise<Array<string>>;
 
 export function GetFileModificationInfo():Promise<{[key: string]: any}>;
 
@@ -26,6 +26,8 @@ export function GetRecords(arg1:number,arg2:number):Promise<main.PaginatedRecord
 
 export function GetSearchHighlights(arg1:main.JSONRecord,arg2:string,arg3:boolean):Promise<Array<main.HighlightMatch>>;
 
+export function GetSettings():Promise<main.Settings>;
+
 export function GetTotalRecordCount():Promise<number>;
 
 export function Greet(arg1:string):Promise<string>;
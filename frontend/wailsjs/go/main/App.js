@@ -18,8 +18,8 @@ export function GetAllRecords(arg1) {
   return window['go']['main']['App']['GetAllRecords'](arg1);
 }
 
-export function GetCommonFields() {
-  return window['go']['main']['App']['GetCommonFields']();
+export function GetCommonFields(arg1) {
+  return window['go']['main']['App']['GetCommonFields'](arg1);
 }
 
 export function GetFileModificationInfo() {
@@ -50,6 +50,10 @@ export function GetSearchHighlights(arg1, arg2, arg3) {
   return window['go']['main']['App']['GetSearchHighlights'](arg1, arg2, arg3);
 }
 
+export function GetSettings() {
+  return window['go']['main']['App']['GetSettings']();
+}
+
 export function GetTotalRecordCount() {
   return window['go']['main']['App']['GetTotalRecordCount']();
 }
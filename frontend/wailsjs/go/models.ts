@@ -1,5 +1,21 @@
 export namespace main {
 	
+	export class CommonFieldOptions {
+	    strategy: string;
+	    threshold: number;
+	    topN: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new CommonFieldOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.strategy = source["strategy"];
+	        this.threshold = source["threshold"];
+	        this.topN = source["topN"];
+	    }
+	}
 	export class FileStats {
 	    totalLines: number;
 	    validRecords: number;
@@ -138,7 +154,7 @@ export namespace main {
 	    query: string;
 	    caseSensitive: boolean;
 	    useLucene: boolean;
-	    selectedField: string;
+	    selectedFields: string[];
 	    offset: number;
 	    limit: number;
 	
@@ -151,7 +167,7 @@ export namespace main {
 	        this.query = source["query"];
 	        this.caseSensitive = source["caseSensitive"];
 	        this.useLucene = source["useLucene"];
-	        this.selectedField = source["selectedField"];
+	        this.selectedFields = source["selectedFields"];
 	        this.offset = source["offset"];
 	        this.limit = source["limit"];
 	    }
@@ -198,6 +214,26 @@ export namespace main {
 		    return a;
 		}
 	}
+	export class Settings {
+	    defaultPageSize: number;
+	    caseSensitive: boolean;
+	    exportDirectory: string;
+	    followIntervalMs: number;
+	    maxLineSize: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Settings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.defaultPageSize = source["defaultPageSize"];
+	        this.caseSensitive = source["caseSensitive"];
+	        this.exportDirectory = source["exportDirectory"];
+	        this.followIntervalMs = source["followIntervalMs"];
+	        this.maxLineSize = source["maxLineSize"];
+	    }
+	}
 
 }
 
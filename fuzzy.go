@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// maxFuzzyDistance is the largest edit distance accepted by `term~N`, as in Lucene
+const maxFuzzyDistance = 2
+
+// cutFuzzy splits a term ending in ~ or ~N, e.g. jonh~ or jonh~1, into the term and
+// the distance. A tilde anywhere else, as in a~b, is part of the term.
+func cutFuzzy(text string) (term, distance string, found bool) {
+	at := strings.LastIndexByte(text, '~')
+	if at <= 0 {
+		return text, "", false
+	}
+	for _, r := range text[at+1:] {
+		if r < '0' || r > '9' {
+			return text, "", false
+		}
+	}
+	return text[:at], text[at+1:], true
+}
+
+// parseFuzzy parses the term and optional distance of a fuzzy query such as `jonh~`
+// or `jonh~1`. Without a distance the maximum is used.
+func parseFuzzy(term, distance string) (*LuceneQuery, error) {
+	query := &LuceneQuery{Type: "fuzzy", Value: term, Distance: maxFuzzyDistance}
+	if distance != "" {
+		n, err := strconv.Atoi(distance)
+		if err != nil || n < 0 || n > maxFuzzyDistance {
+			return nil, fmt.Errorf("fuzzy distance must be between 0 and %d, got %q", maxFuzzyDistance, distance)
+		}
+		query.Distance = n
+	}
+	return query, nil
+}
+
+// matchFuzzy checks whether text, or one of the words in it, is within distance
+// edits of term. Words are runs of letters, digits, '_' and '-', so identifiers
+// such as "order-4821" are compared whole.
+func (o matchOptions) matchFuzzy(text, term string, distance int) bool {
+	if text == "" {
+		return false
+	}
+
+	text, target := o.normalize(text), []rune(o.normalize(term))
+	if levenshtein([]rune(text), target, distance) <= distance {
+		return true
+	}
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '-'
+	})
+	for _, word := range words {
+		if levenshtein([]rune(word), target, distance) <= distance {
+			return true
+		}
+	}
+	return false
+}
+
+// levenshtein returns the edit distance between a and b, or max+1 once it is known
+// to exceed max
+func levenshtein(a, b []rune, max int) int {
+	if diff := len(a) - len(b); diff > max || -diff > max {
+		return max + 1
+	}
+
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		rowMin := curr[0]
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			rowMin = min(rowMin, curr[j])
+		}
+		if rowMin > max {
+			return max + 1
+		}
+		prev, curr = curr, prev
+	}
+	return min(prev[len(b)], max+1)
+}
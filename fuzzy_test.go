@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		max      int
+		expected int
+	}{
+		{"john", "jonh", 2, 2},
+		{"kitten", "sitting", 3, 3},
+		{"same", "same", 2, 0},
+		{"short", "muchlonger", 2, 3},
+		{"abcdef", "uvwxyz", 2, 3},
+		{"", "ab", 2, 2},
+	}
+	for _, tt := range tests {
+		if got := levenshtein([]rune(tt.a), []rune(tt.b), tt.max); got != tt.expected {
+			t.Errorf("levenshtein(%q, %q, %d) = %d, expected %d", tt.a, tt.b, tt.max, got, tt.expected)
+		}
+	}
+}
+
+func TestFuzzySearch(t *testing.T) {
+	app := newTestApp(t, `{"name":"John","msg":"order-4821 shipped"}
+{"name":"Joan","msg":"order-4812 pending"}
+{"name":"Jonathan","msg":"refund"}
+`)
+	tests := []struct {
+		query    string
+		expected []int
+	}{
+		{"name:jonh~", []int{1, 2}},
+		{"name:jonh~1", []int{}},
+		{"name:jon~1", []int{1, 2}},
+		{"ordr-4821~1", []int{1}},
+		{"msg:pendng~ OR refnd~1", []int{2, 3}},
+	}
+	for _, tt := range tests {
+		result, err := app.SearchRecords(SearchOptions{Query: tt.query, UseLucene: true, Limit: 10})
+		if err != nil {
+			t.Fatalf("SearchRecords(%q) returned error: %v", tt.query, err)
+		}
+		lines := []int{}
+		for _, record := range result.Records {
+			lines = append(lines, record.LineNumber)
+		}
+		if fmt.Sprint(lines) != fmt.Sprint(tt.expected) {
+			t.Errorf("%s: expected lines %v, got %v", tt.query, tt.expected, lines)
+		}
+	}
+
+	if _, err := parseLucene("name:jonh~3"); err == nil {
+		t.Error("Expected a distance over the maximum to fail")
+	}
+
+	// A tilde that does not end the term is part of it
+	for _, text := range []string{"a~b", "jonh~x", "~home"} {
+		query, err := parseLucene("path:" + text)
+		if err != nil || query.Type != "field" || query.Value != text {
+			t.Errorf("Expected %s as a plain term, got %+v (%v)", text, query, err)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// jsonlExtensions are the file extensions picked up when a directory is loaded
+var jsonlExtensions = []string{".jsonl", ".jsonlines", ".ndjson"}
+
+// isJSONLFileName reports whether a file name has a JSONL extension, optionally
+// followed by a compression suffix
+func isJSONLFileName(name string) bool {
+	name = strings.ToLower(trimCompressionSuffix(name))
+	for _, ext := range jsonlExtensions {
+		if strings.HasSuffix(name, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveGlob returns the sorted files matched by pattern. A directory matches the
+// JSONL files directly inside it.
+func resolveGlob(pattern string) ([]string, error) {
+	if info, err := os.Stat(pattern); err == nil && info.IsDir() {
+		entries, err := os.ReadDir(pattern)
+		if err != nil {
+			return nil, err
+		}
+		var paths []string
+		for _, entry := range entries {
+			if !entry.IsDir() && isJSONLFileName(entry.Name()) {
+				paths = append(paths, filepath.Join(pattern, entry.Name()))
+			}
+		}
+		return paths, nil
+	}
+
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, match := range matches {
+		if info, err := os.Stat(match); err == nil && !info.IsDir() {
+			paths = append(paths, match)
+		}
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// LoadJSONLGlob loads every file matching pattern (e.g. logs/*.jsonl) or every JSONL
+// file in a directory as one dataset. Files are concatenated in name order: line
+// numbers run on across files and each record carries its SourceFile and SourceLine.
+func (a *App) LoadJSONLGlob(pattern string) (*JSONLFile, error) {
+	if strings.TrimSpace(pattern) == "" {
+		return nil, &JSONLError{
+			Message: "File pattern cannot be empty",
+			Err:     ErrFileNotFound,
+		}
+	}
+
+	paths, err := resolveGlob(pattern)
+	if err != nil {
+		return nil, &JSONLError{
+			Message: "Invalid file pattern",
+			Err:     err,
+		}
+	}
+	if len(paths) == 0 {
+		return nil, &JSONLError{
+			Message: fmt.Sprintf("No files match %s", pattern),
+			Err:     ErrFileNotFound,
+		}
+	}
+
+	var records []JSONRecord
+	stats := &FileStats{InvalidLines: []int{}, invalidDetails: make(map[int]InvalidLineDetail)}
+	fieldCounts := make(map[string]int)
+	var modifiedAt time.Time
+
+	ctx, done := a.beginLoad()
+	defer done()
+
+	options := a.loadOptions()
+	for _, path := range paths {
+		parser, err := NewJSONLParser(path)
+		if err != nil {
+			return nil, err
+		}
+		parser.ctx = ctx
+		parser.mode = options.mode
+		parser.applyOptions(options)
+		fileRecords, fileStats, err := parser.ParseJSONL()
+		parser.Close()
+		if err != nil {
+			return nil, &JSONLError{
+				Message: fmt.Sprintf("Failed to parse %s", path),
+				Err:     err,
+			}
+		}
+
+		lineOffset := stats.TotalLines
+		for _, record := range fileRecords {
+			record.SourceFile = path
+			record.SourceLine = record.LineNumber
+			record.LineNumber += lineOffset
+			for field := range record.Content {
+				fieldCounts[field]++
+			}
+			records = append(records, record)
+		}
+		for _, line := range fileStats.InvalidLines {
+			stats.InvalidLines = append(stats.InvalidLines, line+lineOffset)
+		}
+		for _, detail := range fileStats.invalidDetails {
+			detail.LineNumber += lineOffset
+			stats.invalidDetails[detail.LineNumber] = detail
+		}
+		for _, line := range fileStats.LongLines {
+			stats.LongLines = append(stats.LongLines, line+lineOffset)
+		}
+		for _, line := range fileStats.RepairedLines {
+			stats.RepairedLines = append(stats.RepairedLines, line+lineOffset)
+		}
+		// Merged datasets are not reloaded incrementally, so a truncated final line
+		// stays invalid
+		if fileStats.TruncatedLine > 0 {
+			stats.InvalidLines = append(stats.InvalidLines, fileStats.TruncatedLine+lineOffset)
+		}
+		for _, duplicate := range fileStats.DuplicateKeys {
+			duplicate.Line += lineOffset
+			stats.DuplicateKeys = append(stats.DuplicateKeys, duplicate)
+		}
+
+		stats.TotalLines += fileStats.TotalLines
+		stats.ValidRecords += fileStats.ValidRecords
+		stats.FileSize += fileStats.FileSize
+		if info, err := os.Stat(path); err == nil && info.ModTime().After(modifiedAt) {
+			modifiedAt = info.ModTime()
+		}
+	}
+	stats.CommonFields = selectCommonFields(fieldCounts, stats.ValidRecords, CommonFieldOptions{})
+	stats.RecordSizes = measureRecordSizes(recordSizes(records))
+
+	jsonlFile := &JSONLFile{
+		Name:       fmt.Sprintf("%s (%d files)", filepath.Base(pattern), len(paths)),
+		Path:       pattern,
+		Size:       stats.FileSize,
+		Records:    stats.ValidRecords,
+		LoadedAt:   time.Now(),
+		ModifiedAt: modifiedAt,
+		Sources:    paths,
+		Format:     options.mode,
+	}
+	a.storeFile(jsonlFile, records, stats, nil)
+	return jsonlFile, nil
+}
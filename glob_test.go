@@ -0,0 +1,54 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadJSONLGlob(t *testing.T) {
+	useTempConfigDir(t)
+
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "b.jsonl"), []byte("{\"n\":3}\nbad\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "a.jsonl"), []byte("{\"n\":1}\n{\"n\":2}\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("{\"n\":9}\n"), 0644)
+
+	for _, pattern := range []string{filepath.Join(dir, "*.jsonl"), dir} {
+		app := &App{}
+		file, err := app.LoadJSONLGlob(pattern)
+		if err != nil {
+			t.Fatalf("LoadJSONLGlob(%s) returned error: %v", pattern, err)
+		}
+		if file.Records != 3 || len(file.Sources) != 2 {
+			t.Fatalf("Expected 3 records from 2 files, got %+v", file)
+		}
+
+		record, err := app.GetRecordByLineNumber(3)
+		if err != nil {
+			t.Fatalf("GetRecordByLineNumber returned error: %v", err)
+		}
+		if record.Content["n"] != json.Number("3") || filepath.Base(record.SourceFile) != "b.jsonl" || record.SourceLine != 1 {
+			t.Errorf("Expected the first record of b.jsonl at line 3, got %+v", record)
+		}
+		if stats, err := app.GetFileStats(); err != nil || len(stats.InvalidLines) != 1 || stats.InvalidLines[0] != 4 || stats.TotalLines != 4 {
+			t.Errorf("Unexpected merged stats: %+v (%v)", stats, err)
+		}
+
+		// Export reads every source file
+		all, err := app.GetAllRecords("")
+		if err != nil || len(all) != 3 || all[2].LineNumber != 3 || all[2].SourceLine != 1 {
+			t.Errorf("Expected GetAllRecords to span the files, got %+v (%v)", all, err)
+		}
+
+		if modified, err := app.CheckFileModification(); err != nil || modified {
+			t.Errorf("Expected an unmodified dataset, got %v (%v)", modified, err)
+		}
+	}
+
+	app := &App{}
+	if _, err := app.LoadJSONLGlob(filepath.Join(dir, "*.ndjson")); err == nil {
+		t.Error("Expected an error when no files match")
+	}
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// jsonToken is a key or scalar value in a record's raw JSON
+type jsonToken struct {
+	start, end int    // byte range in the raw JSON, quotes included
+	path       string // JSON pointer of the member or element, such as "/user/name"
+	field      string // top-level key the token belongs to, "" outside an object record
+	key        bool   // the token is an object key rather than a value
+}
+
+// tokenFrame is an object or array open at the tokenizer's position
+type tokenFrame struct {
+	object    bool
+	path      string
+	expectKey bool   // the next string in an object is a key
+	key       string // decoded key of the current member
+	element   int    // index of the current array element
+}
+
+// memberPath returns the JSON pointer of the frame's current member or element
+func (f *tokenFrame) memberPath() string {
+	if f.object {
+		return f.path + "/" + escapePointer(f.key)
+	}
+	return f.path + "/" + strconv.Itoa(f.element)
+}
+
+// escapePointer escapes a key for use as a JSON pointer segment
+func escapePointer(key string) string {
+	if !strings.ContainsAny(key, "~/") {
+		return key
+	}
+	return strings.ReplaceAll(strings.ReplaceAll(key, "~", "~0"), "/", "~1")
+}
+
+// scanJSONTokens returns the keys and scalar values of valid JSON in order, with
+// their exact byte ranges and paths
+func scanJSONTokens(raw string) []jsonToken {
+	var tokens []jsonToken
+	var stack []tokenFrame
+	field := func() string {
+		if len(stack) > 0 && stack[0].object {
+			return stack[0].key
+		}
+		return ""
+	}
+	valuePath := func() string {
+		if len(stack) == 0 {
+			return ""
+		}
+		return stack[len(stack)-1].memberPath()
+	}
+
+	for i := 0; i < len(raw); i++ {
+		switch c := raw[i]; c {
+		case '"':
+			end := stringEnd(raw, i) + 1
+			if n := len(stack); n > 0 && stack[n-1].object && stack[n-1].expectKey {
+				stack[n-1].key = unquoteKey(raw[i:end])
+				stack[n-1].expectKey = false
+				tokens = append(tokens, jsonToken{start: i, end: end, path: valuePath(), field: field(), key: true})
+			} else {
+				tokens = append(tokens, jsonToken{start: i, end: end, path: valuePath(), field: field()})
+			}
+			i = end - 1
+
+		case '{', '[':
+			stack = append(stack, tokenFrame{object: c == '{', path: valuePath(), expectKey: c == '{'})
+
+		case '}', ']':
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+
+		case ',':
+			if n := len(stack); n > 0 {
+				if stack[n-1].object {
+					stack[n-1].expectKey = true
+				} else {
+					stack[n-1].element++
+				}
+			}
+
+		case ':', ' ', '\t', '\r', '\n':
+
+		default:
+			// Numbers, true, false and null run to the next delimiter
+			end := i
+			for end < len(raw) && !strings.ContainsRune(",}] \t\r\n", rune(raw[end])) {
+				end++
+			}
+			tokens = append(tokens, jsonToken{start: i, end: end, path: valuePath(), field: field()})
+			i = end - 1
+		}
+	}
+	return tokens
+}
+
+// tokenText returns the decoded text of a token: strings unquoted, other scalars
+// as written and null as empty
+func tokenText(raw string, token jsonToken) string {
+	text, _ := decodeToken(raw, token)
+	return text
+}
+
+// decodeToken returns the decoded text of a token and a function mapping a byte
+// range of the text back to the range of raw JSON it was decoded from
+func decodeToken(raw string, token jsonToken) (string, func(start, end int) (int, int)) {
+	text := raw[token.start:token.end]
+	if text == "null" {
+		return "", nil
+	}
+	if !strings.HasPrefix(text, `"`) {
+		return text, func(start, end int) (int, int) {
+			return token.start + start, token.start + end
+		}
+	}
+	if !strings.Contains(text, `\`) {
+		return text[1 : len(text)-1], func(start, end int) (int, int) {
+			return token.start + 1 + start, token.start + 1 + end
+		}
+	}
+
+	// Escape sequences decode to fewer bytes; keep the raw range of each decoded byte
+	var decoded strings.Builder
+	var starts, ends []int
+	write := func(s string, rawStart, rawEnd int) {
+		decoded.WriteString(s)
+		for j := 0; j < len(s); j++ {
+			starts = append(starts, token.start+rawStart)
+			ends = append(ends, token.start+rawEnd)
+		}
+	}
+	for i := 1; i < len(text)-1; {
+		if text[i] != '\\' {
+			_, size := utf8.DecodeRuneInString(text[i:])
+			write(text[i:i+size], i, i+size)
+			i += size
+			continue
+		}
+		size := 2
+		if text[i+1] == 'u' {
+			size = 6
+			// A surrogate pair decodes to one character
+			if i+12 <= len(text)-1 && text[i+6] == '\\' && text[i+7] == 'u' {
+				if r, _ := strconv.ParseUint(text[i+2:i+6], 16, 16); r >= 0xD800 && r < 0xDC00 {
+					size = 12
+				}
+			}
+		}
+		if i+size > len(text)-1 {
+			size = len(text) - 1 - i
+		}
+		var s string
+		if err := json.Unmarshal([]byte(`"`+text[i:i+size]+`"`), &s); err != nil {
+			s = text[i : i+size]
+		}
+		write(s, i, i+size)
+		i += size
+	}
+	return decoded.String(), func(start, end int) (int, int) {
+		return starts[start], ends[end-1]
+	}
+}
+
+// tokenAt returns the token containing byte position pos, if any
+func tokenAt(tokens []jsonToken, pos int) (jsonToken, bool) {
+	i := sort.Search(len(tokens), func(i int) bool { return tokens[i].start > pos }) - 1
+	if i >= 0 && pos < tokens[i].end {
+		return tokens[i], true
+	}
+	return jsonToken{}, false
+}
+
+// findMatches returns the byte ranges of text holding the non-overlapping
+// occurrences of term, compared after normalizing both as the options do.
+// Normalizing can change lengths, such as folding "ß" to "ss", so text is
+// normalized a character at a time, keeping each character's original range.
+func (o matchOptions) findMatches(text, term string) [][2]int {
+	term = o.normalize(term)
+	if term == "" {
+		return nil
+	}
+
+	// ASCII text keeps its length
+	var haystack string
+	var starts, ends []int // original range of the character each normalized byte came from
+	if isASCII(text) {
+		haystack = o.normalize(text)
+	} else {
+		var normalized strings.Builder
+		for i := 0; i < len(text); {
+			_, size := utf8.DecodeRuneInString(text[i:])
+			folded := o.normalize(text[i : i+size])
+			normalized.WriteString(folded)
+			for j := 0; j < len(folded); j++ {
+				starts = append(starts, i)
+				ends = append(ends, i+size)
+			}
+			i += size
+		}
+		haystack = normalized.String()
+	}
+
+	var matches [][2]int
+	for offset := 0; ; {
+		index := strings.Index(haystack[offset:], term)
+		if index < 0 {
+			return matches
+		}
+		start, end := offset+index, offset+index+len(term)
+		offset = end
+		if starts != nil {
+			start, end = starts[start], ends[end-1]
+		}
+		matches = append(matches, [2]int{start, end})
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestScanJSONTokens(t *testing.T) {
+	raw := `{"a":"x","b":{"c/d":[1,true,null]},"e~":"q\"t"}`
+	var got []string
+	for _, token := range scanJSONTokens(raw) {
+		got = append(got, fmt.Sprintf("%s=%s", token.path, raw[token.start:token.end]))
+	}
+	expected := `[/a="a" /a="x" /b="b" /b/c~1d="c/d" /b/c~1d/0=1 /b/c~1d/1=true /b/c~1d/2=null /e~0="e~" /e~0="q\"t"]`
+	if fmt.Sprint(got) != expected {
+		t.Errorf("Unexpected tokens\n got %v\nwant %s", got, expected)
+	}
+}
+
+func TestGetSearchHighlights(t *testing.T) {
+	app := &App{}
+	record := JSONRecord{
+		RawJSON: `{"msg":"error: disk error","error":"ERROR","user":{"note":"Straße error"},"tags":["x","error"]}`,
+	}
+
+	highlights, err := app.GetSearchHighlights(record, "error", false)
+	if err != nil {
+		t.Fatalf("GetSearchHighlights returned error: %v", err)
+	}
+	var raw, fields []string
+	for _, h := range highlights {
+		if record.RawJSON[h.StartPos:h.EndPos] != h.Text {
+			t.Errorf("Highlight text %q does not match its range %d-%d", h.Text, h.StartPos, h.EndPos)
+		}
+		if h.FieldName == "raw" {
+			raw = append(raw, fmt.Sprintf("%d:%s", h.StartPos, h.Path))
+		} else {
+			fields = append(fields, fmt.Sprintf("%s:%d-%d", h.Path, h.ValueStart, h.ValueEnd))
+		}
+	}
+	if fmt.Sprint(raw) != "[8:/msg 20:/msg 28:/error 36:/error 67:/user/note 88:/tags/1]" {
+		t.Errorf("Unexpected raw highlights %v", raw)
+	}
+	// Every occurrence within a value, with its range in the decoded value
+	expected := `[/msg:0-5 /msg:12-17 /error:0-5 /user/note:8-13 /tags/1:0-5]`
+	if fmt.Sprint(fields) != expected {
+		t.Errorf("Unexpected field highlights\n got %v\nwant %s", fields, expected)
+	}
+
+	// Ranges in escaped values map back to the raw JSON
+	escaped := JSONRecord{RawJSON: `{"msg":"say \"hi\" \u00e9t\u00e9 hi"}`}
+	highlights, _ = app.GetSearchHighlights(escaped, "hi", false)
+	var ranges []string
+	for _, h := range highlights {
+		if h.FieldName != "raw" {
+			ranges = append(ranges, fmt.Sprintf("%d-%d %s", h.ValueStart, h.ValueEnd, escaped.RawJSON[h.StartPos:h.EndPos]))
+		}
+	}
+	if fmt.Sprint(ranges) != "[5-7 hi 15-17 hi]" {
+		t.Errorf("Unexpected ranges in the escaped value %v", ranges)
+	}
+	highlights, _ = app.GetSearchHighlights(escaped, "\"hi\" é", false)
+	if len(highlights) != 1 || highlights[0].Text != "\"hi\" é" || escaped.RawJSON[highlights[0].StartPos:highlights[0].EndPos] != `\"hi\" \u00e9` {
+		t.Errorf("Expected the decoded match to span the escapes, got %+v", highlights)
+	}
+
+	// Case folding can change lengths; ranges stay on the original text
+	folded := JSONRecord{RawJSON: `{"street":"Großstraße 1"}`}
+	highlights, _ = app.GetSearchHighlights(folded, "STRASSE", false)
+	if len(highlights) != 2 || folded.RawJSON[highlights[0].StartPos:highlights[0].EndPos] != "straße" {
+		t.Errorf("Expected the folded match to cover straße, got %+v", highlights)
+	}
+}
@@ -0,0 +1,336 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// Bucket limits for histograms
+const (
+	defaultHistogramBuckets = 20
+	maxHistogramBuckets     = 1000
+)
+
+// ErrInvalidHistogram is returned for histogram parameters that cannot be bucketed
+var ErrInvalidHistogram = errors.New("invalid histogram")
+
+// HistogramBucket counts the values in [Start, End); the last bucket includes End
+type HistogramBucket struct {
+	Start float64 `json:"start"`
+	End   float64 `json:"end"`
+	Count int     `json:"count"`
+}
+
+// NumericHistogram is the distribution of a numeric field's values
+type NumericHistogram struct {
+	Field       string            `json:"field"`
+	Buckets     []HistogramBucket `json:"buckets"`
+	BucketWidth float64           `json:"bucketWidth"`
+	Values      int               `json:"values"`  // records with a numeric value for the field
+	Skipped     int               `json:"skipped"` // records in scope without one
+	Min         float64           `json:"min"`
+	Max         float64           `json:"max"`
+}
+
+// bucketNumbers spreads values over bucketCount equal buckets between their minimum
+// and maximum or, when bucketWidth is set, over buckets of that width aligned to
+// its multiples
+func bucketNumbers(field string, values []float64, bucketCount int, bucketWidth float64) (*NumericHistogram, error) {
+	histogram := &NumericHistogram{Field: field, Buckets: []HistogramBucket{}, Values: len(values)}
+	if len(values) == 0 {
+		return histogram, nil
+	}
+
+	histogram.Min, histogram.Max = values[0], values[0]
+	for _, value := range values {
+		histogram.Min = math.Min(histogram.Min, value)
+		histogram.Max = math.Max(histogram.Max, value)
+	}
+
+	start := histogram.Min
+	if bucketWidth > 0 {
+		start = math.Floor(histogram.Min/bucketWidth) * bucketWidth
+		span := math.Floor(histogram.Max/bucketWidth)*bucketWidth - start
+		if buckets := span/bucketWidth + 1; buckets > maxHistogramBuckets {
+			return nil, fmt.Errorf("%w: width %v needs %.0f buckets, at most %d are allowed",
+				ErrInvalidHistogram, bucketWidth, buckets, maxHistogramBuckets)
+		}
+		bucketCount = int(math.Round(span/bucketWidth)) + 1
+	} else {
+		if bucketCount <= 0 {
+			bucketCount = defaultHistogramBuckets
+		}
+		if bucketCount > maxHistogramBuckets {
+			bucketCount = maxHistogramBuckets
+		}
+		if histogram.Max == histogram.Min {
+			bucketCount = 1
+		}
+		bucketWidth = (histogram.Max - histogram.Min) / float64(bucketCount)
+	}
+
+	histogram.BucketWidth = bucketWidth
+	histogram.Buckets = make([]HistogramBucket, bucketCount)
+	for i := range histogram.Buckets {
+		histogram.Buckets[i].Start = start + float64(i)*bucketWidth
+		histogram.Buckets[i].End = start + float64(i+1)*bucketWidth
+	}
+	for _, value := range values {
+		i := bucketCount - 1
+		if bucketWidth > 0 {
+			i = int(math.Floor((value - start) / bucketWidth))
+		}
+		// Rounding can push values at the edges one bucket out
+		if i < 0 {
+			i = 0
+		}
+		if i >= bucketCount {
+			i = bucketCount - 1
+		}
+		histogram.Buckets[i].Count++
+	}
+	return histogram, nil
+}
+
+// GetNumericHistogram buckets the numeric values of a field, such as latency_ms,
+// for charting its distribution. Either bucketCount equal buckets span the values
+// (20 by default, at most 1000) or, when bucketWidth is positive, buckets of that
+// width aligned to its multiples. Counts cover the records left by the filter
+// stack, narrowed to those matching filter when it carries a query.
+func (a *App) GetNumericHistogram(field string, bucketCount int, bucketWidth float64, filter *SearchOptions) (*NumericHistogram, error) {
+	a.stateMu.RLock()
+	defer a.stateMu.RUnlock()
+
+	if a.currentFile == nil || a.cache == nil {
+		return nil, &JSONLError{
+			Message: "No file currently loaded",
+			Err:     ErrNoFileLoaded,
+		}
+	}
+
+	field = strings.TrimSpace(field)
+	if field == "" {
+		return nil, &JSONLError{
+			Message: "Field name cannot be empty",
+			Err:     errors.New("empty field name"),
+		}
+	}
+	if bucketWidth < 0 || math.IsNaN(bucketWidth) || math.IsInf(bucketWidth, 0) {
+		return nil, &JSONLError{
+			Message: fmt.Sprintf("Bucket width must be a positive number, got %v", bucketWidth),
+			Err:     ErrInvalidHistogram,
+		}
+	}
+
+	var values []float64
+	skipped := 0
+	err := a.forEachInScope(filter, func(record JSONRecord) {
+		value, _ := getFieldValue(record.Content, field)
+		if number, ok := toFloat64(value); ok && !math.IsNaN(number) && !math.IsInf(number, 0) {
+			values = append(values, number)
+		} else {
+			skipped++
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	histogram, err := bucketNumbers(field, values, bucketCount, bucketWidth)
+	if err != nil {
+		return nil, &JSONLError{
+			Message: err.Error(),
+			Err:     ErrInvalidHistogram,
+		}
+	}
+	histogram.Skipped = skipped
+	return histogram, nil
+}
+
+// namedIntervals are the calendar names accepted as time histogram intervals
+var namedIntervals = map[string]time.Duration{
+	"second": time.Second,
+	"minute": time.Minute,
+	"hour":   time.Hour,
+	"day":    24 * time.Hour,
+	"week":   7 * 24 * time.Hour,
+}
+
+// autoIntervals are tried in order for an automatic interval, the first giving at
+// most defaultTimeBuckets buckets winning. Months and years are 30 and 365 days;
+// longer spans get a computed multiple of a year, see autoInterval.
+var autoIntervals = []time.Duration{
+	time.Second, 5 * time.Second, 15 * time.Second, 30 * time.Second,
+	time.Minute, 5 * time.Minute, 15 * time.Minute, 30 * time.Minute,
+	time.Hour, 3 * time.Hour, 6 * time.Hour, 12 * time.Hour,
+	24 * time.Hour, 7 * 24 * time.Hour, 30 * 24 * time.Hour, 90 * 24 * time.Hour,
+	365 * 24 * time.Hour,
+}
+
+// defaultTimeBuckets is the most buckets an automatic interval aims for
+const defaultTimeBuckets = 100
+
+// autoInterval picks the first of autoIntervals laying out elapsed time in at most
+// defaultTimeBuckets buckets, as counted by span, or else the smallest multiple of
+// a year that does. Aligning to the interval adds at most one bucket at each end.
+func autoInterval(elapsed time.Duration, span func(interval time.Duration) int64) time.Duration {
+	for _, candidate := range autoIntervals {
+		if span(candidate) <= defaultTimeBuckets {
+			return candidate
+		}
+	}
+	year := autoIntervals[len(autoIntervals)-1]
+	years := elapsed/(defaultTimeBuckets-2)/year + 1
+	return years * year
+}
+
+// TimeBucket counts the records timestamped in [Start, End)
+type TimeBucket struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+	Count int       `json:"count"`
+}
+
+// TimeHistogram is the number of records per time interval
+type TimeHistogram struct {
+	Field           string       `json:"field"`
+	Interval        string       `json:"interval"` // bucket length, e.g. "1h0m0s"
+	IntervalSeconds float64      `json:"intervalSeconds"`
+	Buckets         []TimeBucket `json:"buckets"` // consecutive, empty intervals included
+	Values          int          `json:"values"`  // records with a parseable timestamp
+	Skipped         int          `json:"skipped"` // records in scope without one
+}
+
+// parseInterval reads a time histogram interval: "minute", "hour", "day" and the
+// like, a duration such as "15m", or "" and "auto" for automatic
+func parseInterval(interval string) (time.Duration, error) {
+	interval = strings.ToLower(strings.TrimSpace(interval))
+	if interval == "" || interval == "auto" {
+		return 0, nil
+	}
+	if duration, exists := namedIntervals[interval]; exists {
+		return duration, nil
+	}
+	duration, err := time.ParseDuration(interval)
+	if err != nil || duration <= 0 {
+		return 0, fmt.Errorf("%w: unknown interval %q", ErrInvalidHistogram, interval)
+	}
+	return duration, nil
+}
+
+// bucketTimes counts times per interval between the first and last, aligned to
+// multiples of the interval in UTC. A zero interval is picked automatically.
+func bucketTimes(field string, times []time.Time, interval time.Duration) (*TimeHistogram, error) {
+	histogram := &TimeHistogram{Field: field, Buckets: []TimeBucket{}, Values: len(times)}
+	if len(times) == 0 {
+		if interval > 0 {
+			histogram.Interval = interval.String()
+			histogram.IntervalSeconds = interval.Seconds()
+		}
+		return histogram, nil
+	}
+
+	first, last := times[0], times[0]
+	for _, t := range times {
+		if t.Before(first) {
+			first = t
+		}
+		if t.After(last) {
+			last = t
+		}
+	}
+
+	span := func(interval time.Duration) int64 {
+		return int64(last.UTC().Truncate(interval).Sub(first.UTC().Truncate(interval))/interval) + 1
+	}
+	// Durations saturate at about 292 years, beyond which buckets cannot be laid out
+	if last.Sub(first) == time.Duration(math.MaxInt64) {
+		return nil, fmt.Errorf("%w: timestamps from %s to %s span too long a time",
+			ErrInvalidHistogram, first.UTC().Format(time.RFC3339), last.UTC().Format(time.RFC3339))
+	}
+	if interval == 0 {
+		interval = autoInterval(last.Sub(first), span)
+	}
+	count := span(interval)
+	if count > maxHistogramBuckets {
+		return nil, fmt.Errorf("%w: interval %s needs %d buckets, at most %d are allowed",
+			ErrInvalidHistogram, interval, count, maxHistogramBuckets)
+	}
+
+	start := first.UTC().Truncate(interval)
+	histogram.Interval = interval.String()
+	histogram.IntervalSeconds = interval.Seconds()
+	histogram.Buckets = make([]TimeBucket, count)
+	for i := range histogram.Buckets {
+		histogram.Buckets[i].Start = start.Add(time.Duration(i) * interval)
+		histogram.Buckets[i].End = start.Add(time.Duration(i+1) * interval)
+	}
+	for _, t := range times {
+		histogram.Buckets[t.UTC().Truncate(interval).Sub(start)/interval].Count++
+	}
+	return histogram, nil
+}
+
+// GetTimeHistogram counts records per time interval of their timestamp field, to
+// show volume over time and spot spikes. interval is "second", "minute", "hour",
+// "day", "week", a duration such as "15m", or empty to pick one giving at most 100
+// buckets. An empty timestampField is detected from the records. Counts cover the
+// records left by the filter stack, narrowed to those matching filter when it
+// carries a query.
+func (a *App) GetTimeHistogram(timestampField, interval string, filter *SearchOptions) (*TimeHistogram, error) {
+	a.stateMu.RLock()
+	defer a.stateMu.RUnlock()
+
+	if a.currentFile == nil || a.cache == nil {
+		return nil, &JSONLError{
+			Message: "No file currently loaded",
+			Err:     ErrNoFileLoaded,
+		}
+	}
+
+	duration, err := parseInterval(interval)
+	if err != nil {
+		return nil, &JSONLError{
+			Message: err.Error(),
+			Err:     ErrInvalidHistogram,
+		}
+	}
+
+	field := strings.TrimSpace(timestampField)
+	if field == "" {
+		sample, _ := a.cache.slice(0, min(a.cache.totalCount, 100))
+		if field = detectTimestampField(sample); field == "" {
+			return nil, &JSONLError{
+				Message: "No timestamp field found",
+				Err:     ErrInvalidHistogram,
+			}
+		}
+	}
+
+	var times []time.Time
+	skipped := 0
+	err = a.forEachInScope(filter, func(record JSONRecord) {
+		value, _ := getFieldValue(record.Content, field)
+		if t, ok := parseTimestamp(value); ok {
+			times = append(times, t)
+		} else {
+			skipped++
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	histogram, err := bucketTimes(field, times, duration)
+	if err != nil {
+		return nil, &JSONLError{
+			Message: err.Error(),
+			Err:     ErrInvalidHistogram,
+		}
+	}
+	histogram.Skipped = skipped
+	return histogram, nil
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGetNumericHistogram(t *testing.T) {
+	var content strings.Builder
+	for i := 0; i < 100; i++ {
+		fmt.Fprintf(&content, "{\"latency_ms\":%d,\"slow\":%t}\n", i, i >= 90)
+	}
+	content.WriteString("{\"latency_ms\":\"n/a\"}\n{\"other\":1}\n")
+	app := newTestApp(t, content.String())
+
+	histogram, err := app.GetNumericHistogram("latency_ms", 4, 0, nil)
+	if err != nil {
+		t.Fatalf("GetNumericHistogram returned error: %v", err)
+	}
+	if histogram.Values != 100 || histogram.Skipped != 2 || histogram.Min != 0 || histogram.Max != 99 {
+		t.Errorf("Unexpected totals: %+v", histogram)
+	}
+	if len(histogram.Buckets) != 4 || histogram.BucketWidth != 24.75 {
+		t.Fatalf("Expected 4 buckets of width 24.75, got %+v", histogram)
+	}
+	counts := []int{25, 25, 25, 25}
+	for i, bucket := range histogram.Buckets {
+		if bucket.Count != counts[i] {
+			t.Errorf("Bucket %d [%v, %v): expected %d values, got %d", i, bucket.Start, bucket.End, counts[i], bucket.Count)
+		}
+	}
+	if last := histogram.Buckets[3]; last.End != 99 {
+		t.Errorf("Expected the last bucket to end at the maximum, got %v", last.End)
+	}
+
+	byWidth, err := app.GetNumericHistogram("latency_ms", 0, 30, nil)
+	if err != nil {
+		t.Fatalf("GetNumericHistogram returned error: %v", err)
+	}
+	if len(byWidth.Buckets) != 4 || byWidth.Buckets[3].Start != 90 || byWidth.Buckets[3].Count != 10 || byWidth.Buckets[0].Count != 30 {
+		t.Errorf("Unexpected buckets of width 30: %+v", byWidth.Buckets)
+	}
+
+	filtered, _ := app.GetNumericHistogram("latency_ms", 0, 0, &SearchOptions{Query: "slow:true", UseLucene: true})
+	if filtered.Values != 10 || filtered.Min != 90 || len(filtered.Buckets) != defaultHistogramBuckets {
+		t.Errorf("Unexpected filtered histogram: %+v", filtered)
+	}
+
+	single, _ := app.GetNumericHistogram("latency_ms", 5, 0, &SearchOptions{Query: "latency_ms:99", UseLucene: true})
+	if len(single.Buckets) != 1 || single.Buckets[0].Count != 1 {
+		t.Errorf("Expected one bucket for a single value, got %+v", single.Buckets)
+	}
+
+	var jsonlErr *JSONLError
+	if _, err := app.GetNumericHistogram("latency_ms", 0, 0.01, nil); !errors.As(err, &jsonlErr) || !errors.Is(jsonlErr.Err, ErrInvalidHistogram) {
+		t.Errorf("Expected ErrInvalidHistogram for too many buckets, got %v", err)
+	}
+	if _, err := app.GetNumericHistogram("latency_ms", 0, -1, nil); !errors.As(err, &jsonlErr) || !errors.Is(jsonlErr.Err, ErrInvalidHistogram) {
+		t.Errorf("Expected ErrInvalidHistogram for a negative width, got %v", err)
+	}
+	if _, err := (&App{}).GetNumericHistogram("latency_ms", 0, 0, nil); !errors.As(err, &jsonlErr) || !errors.Is(jsonlErr.Err, ErrNoFileLoaded) {
+		t.Errorf("Expected ErrNoFileLoaded, got %v", err)
+	}
+}
+
+func TestGetTimeHistogram(t *testing.T) {
+	app := newTestApp(t, `{"timestamp":"2024-03-01T10:05:00Z","level":"info"}
+{"timestamp":"2024-03-01T10:40:00Z","level":"error"}
+{"timestamp":"2024-03-01T10:59:59Z","level":"error"}
+{"timestamp":"2024-03-01T13:00:00+01:00","level":"info"}
+{"timestamp":"2024-03-01T13:30:00Z","level":"error"}
+{"timestamp":"garbage","level":"info"}
+`)
+
+	histogram, err := app.GetTimeHistogram("", "hour", nil)
+	if err != nil {
+		t.Fatalf("GetTimeHistogram returned error: %v", err)
+	}
+	if histogram.Field != "timestamp" || histogram.Values != 5 || histogram.Skipped != 1 || histogram.IntervalSeconds != 3600 {
+		t.Errorf("Unexpected totals: %+v", histogram)
+	}
+	counts := []int{3, 0, 1, 1}
+	if len(histogram.Buckets) != len(counts) {
+		t.Fatalf("Expected %d hourly buckets, got %+v", len(counts), histogram.Buckets)
+	}
+	for i, bucket := range histogram.Buckets {
+		if bucket.Count != counts[i] {
+			t.Errorf("Bucket %s: expected %d records, got %d", bucket.Start, counts[i], bucket.Count)
+		}
+	}
+	if start := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC); !histogram.Buckets[0].Start.Equal(start) || !histogram.Buckets[0].End.Equal(start.Add(time.Hour)) {
+		t.Errorf("Expected the first bucket to start at %s, got %+v", start, histogram.Buckets[0])
+	}
+
+	auto, _ := app.GetTimeHistogram("timestamp", "", nil)
+	if auto.Interval != "5m0s" || len(auto.Buckets) != 42 {
+		t.Errorf("Expected 42 automatic 5 minute buckets, got %d of %s", len(auto.Buckets), auto.Interval)
+	}
+
+	filtered, _ := app.GetTimeHistogram("timestamp", "15m", &SearchOptions{Query: "level:error", UseLucene: true})
+	if filtered.Values != 3 || filtered.Buckets[0].Count != 1 || filtered.Buckets[1].Count != 1 {
+		t.Errorf("Unexpected filtered histogram: %+v", filtered)
+	}
+
+	var jsonlErr *JSONLError
+	if _, err := app.GetTimeHistogram("timestamp", "fortnightly", nil); !errors.As(err, &jsonlErr) || !errors.Is(jsonlErr.Err, ErrInvalidHistogram) {
+		t.Errorf("Expected ErrInvalidHistogram for an unknown interval, got %v", err)
+	}
+	if _, err := app.GetTimeHistogram("timestamp", "1ms", nil); !errors.As(err, &jsonlErr) || !errors.Is(jsonlErr.Err, ErrInvalidHistogram) {
+		t.Errorf("Expected ErrInvalidHistogram for too many buckets, got %v", err)
+	}
+	if _, err := newTestApp(t, `{"a":1}`).GetTimeHistogram("", "hour", nil); !errors.As(err, &jsonlErr) || !errors.Is(jsonlErr.Err, ErrInvalidHistogram) {
+		t.Errorf("Expected ErrInvalidHistogram without a timestamp field, got %v", err)
+	}
+	if _, err := (&App{}).GetTimeHistogram("", "", nil); !errors.As(err, &jsonlErr) || !errors.Is(jsonlErr.Err, ErrNoFileLoaded) {
+		t.Errorf("Expected ErrNoFileLoaded, got %v", err)
+	}
+}
+
+func TestAutomaticIntervalForLongSpans(t *testing.T) {
+	tests := []struct {
+		first, last time.Time
+		interval    string
+	}{
+		{time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC), "720h0m0s"},
+		{time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC), "8760h0m0s"},
+		{time.Date(1800, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC), "26280h0m0s"},
+	}
+	for _, tt := range tests {
+		histogram, err := bucketTimes("ts", []time.Time{tt.first, tt.last}, 0)
+		if err != nil {
+			t.Errorf("%s to %s: bucketTimes returned error: %v", tt.first, tt.last, err)
+			continue
+		}
+		if histogram.Interval != tt.interval || len(histogram.Buckets) > defaultTimeBuckets {
+			t.Errorf("%s to %s: expected %s buckets, got %d of %s", tt.first, tt.last, tt.interval, len(histogram.Buckets), histogram.Interval)
+		}
+	}
+
+	if _, err := bucketTimes("ts", []time.Time{time.Date(1, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}, 0); !errors.Is(err, ErrInvalidHistogram) {
+		t.Errorf("Expected ErrInvalidHistogram for a span beyond durations, got %v", err)
+	}
+}
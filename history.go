@@ -0,0 +1,108 @@
+package main
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"time"
+)
+
+// HistoryEntry is one state of an entity and the fields changed since the previous state
+type HistoryEntry struct {
+	Record    JSONRecord  `json:"record"`
+	Timestamp *time.Time  `json:"timestamp"` // nil when the timestamp field is missing or unparseable
+	Changes   []FieldDiff `json:"changes"`   // empty for the first entry
+}
+
+// KeyHistory is the time-ordered history of the records sharing one key value
+type KeyHistory struct {
+	KeyField       string         `json:"keyField"`
+	KeyValue       string         `json:"keyValue"`
+	TimestampField string         `json:"timestampField"`
+	Entries        []HistoryEntry `json:"entries"`
+	ChangedFields  map[string]int `json:"changedFields"` // number of transitions changing each field
+}
+
+// GetKeyHistory walks the records whose keyField equals keyValue in time order and
+// reports which fields changed between consecutive records. An empty timestampField
+// is detected automatically; without one the records stay in file order, as do
+// records whose timestamp cannot be parsed (after the timed ones).
+func (a *App) GetKeyHistory(keyField, keyValue, timestampField string) (*KeyHistory, error) {
+	a.stateMu.RLock()
+	defer a.stateMu.RUnlock()
+
+	if a.currentFile == nil || a.cache == nil {
+		return nil, &JSONLError{
+			Message: "No file currently loaded",
+			Err:     ErrNoFileLoaded,
+		}
+	}
+
+	if strings.TrimSpace(keyField) == "" {
+		return nil, &JSONLError{
+			Message: "Key field cannot be empty",
+			Err:     errors.New("empty key field"),
+		}
+	}
+
+	var entries []HistoryEntry
+	err := a.cache.forEach(func(record JSONRecord) bool {
+		if value, exists := getFieldValue(record.Content, keyField); exists && valueToString(value) == keyValue {
+			entries = append(entries, HistoryEntry{Record: record})
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if timestampField == "" {
+		records := make([]JSONRecord, len(entries))
+		for i, entry := range entries {
+			records[i] = entry.Record
+		}
+		timestampField = detectTimestampField(records)
+	}
+
+	if timestampField != "" {
+		for i := range entries {
+			if value, exists := getFieldValue(entries[i].Record.Content, timestampField); exists {
+				if t, ok := parseTimestamp(value); ok {
+					entries[i].Timestamp = &t
+				}
+			}
+		}
+		sort.SliceStable(entries, func(i, j int) bool {
+			ti, tj := entries[i].Timestamp, entries[j].Timestamp
+			if ti == nil || tj == nil {
+				return ti != nil && tj == nil
+			}
+			return ti.Before(*tj)
+		})
+	}
+
+	history := &KeyHistory{
+		KeyField:       keyField,
+		KeyValue:       keyValue,
+		TimestampField: timestampField,
+		Entries:        []HistoryEntry{},
+		ChangedFields:  make(map[string]int),
+	}
+
+	for i := range entries {
+		entries[i].Changes = []FieldDiff{}
+		if i > 0 {
+			// The timestamp changes on every event, so it is not reported as a change
+			for _, diff := range diffContent(entries[i-1].Record.Content, entries[i].Record.Content) {
+				if diff.Path == timestampField {
+					continue
+				}
+				entries[i].Changes = append(entries[i].Changes, diff)
+				history.ChangedFields[diff.Path]++
+			}
+		}
+		history.Entries = append(history.Entries, entries[i])
+	}
+
+	return history, nil
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGetKeyHistory(t *testing.T) {
+	app := newTestApp(t, `{"id":"o1","ts":"2024-01-01T10:05:00Z","status":"paid","total":10}
+{"id":"o2","ts":"2024-01-01T10:00:00Z","status":"new"}
+{"id":"o1","ts":"2024-01-01T10:00:00Z","status":"new","total":10}
+{"id":"o1","ts":"2024-01-01T10:09:00Z","status":"shipped","total":12,"carrier":"ups"}`)
+
+	history, err := app.GetKeyHistory("id", "o1", "")
+	if err != nil {
+		t.Fatalf("GetKeyHistory returned error: %v", err)
+	}
+
+	if history.TimestampField != "ts" {
+		t.Errorf("Expected detected timestamp field ts, got %q", history.TimestampField)
+	}
+
+	var lines []int
+	for _, entry := range history.Entries {
+		lines = append(lines, entry.Record.LineNumber)
+	}
+	if expected := []int{3, 1, 4}; !reflect.DeepEqual(lines, expected) {
+		t.Fatalf("Expected entries in time order %v, got %v", expected, lines)
+	}
+
+	if len(history.Entries[0].Changes) != 0 {
+		t.Errorf("Expected no changes for the first entry, got %+v", history.Entries[0].Changes)
+	}
+	expected := []FieldDiff{{Path: "status", Change: FieldChanged, Before: "new", After: "paid"}}
+	if !reflect.DeepEqual(history.Entries[1].Changes, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, history.Entries[1].Changes)
+	}
+
+	expectedCounts := map[string]int{"status": 2, "total": 1, "carrier": 1}
+	if !reflect.DeepEqual(history.ChangedFields, expectedCounts) {
+		t.Errorf("Expected changed field counts %v, got %v", expectedCounts, history.ChangedFields)
+	}
+
+	if _, err := app.GetKeyHistory("", "o1", ""); err == nil {
+		t.Errorf("Expected an error for an empty key field")
+	}
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"os"
+)
+
+// maxHTMLExportRecords keeps standalone viewers small enough for a browser to open
+const maxHTMLExportRecords = 50000
+
+// htmlViewerRecord is a record as embedded in the standalone HTML viewer
+type htmlViewerRecord struct {
+	Line int    `json:"line"`
+	JSON string `json:"json"`
+}
+
+// htmlViewerData is the data rendered into htmlViewerTemplate
+type htmlViewerData struct {
+	Title     string
+	Query     string
+	Total     int // matching records before truncation
+	Records   []htmlViewerRecord
+	Truncated bool
+}
+
+// htmlViewerTemplate is a single-file viewer with client-side search and paging
+var htmlViewerTemplate = template.Must(template.New("viewer").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; margin: 0; background: #1b2636; color: #e6e6e6; }
+header { position: sticky; top: 0; padding: 12px 16px; background: #243447; display: flex; gap: 12px; align-items: center; flex-wrap: wrap; }
+header h1 { font-size: 16px; margin: 0; }
+input { flex: 1; min-width: 200px; padding: 6px 8px; border-radius: 4px; border: 1px solid #3d5068; background: #1b2636; color: inherit; }
+button { padding: 6px 12px; border-radius: 4px; border: 1px solid #3d5068; background: #2f4560; color: inherit; cursor: pointer; }
+button:disabled { opacity: 0.4; cursor: default; }
+.record { display: flex; gap: 12px; padding: 6px 16px; border-bottom: 1px solid #2a3a4f; }
+.line { color: #7f93ab; min-width: 60px; text-align: right; font-family: monospace; }
+pre { margin: 0; white-space: pre-wrap; word-break: break-all; font-size: 12px; }
+.note { color: #f0b35a; }
+</style>
+</head>
+<body>
+<header>
+  <h1>{{.Title}}</h1>
+  <input id="search" type="search" placeholder="Filter records...">
+  <button id="prev">Previous</button>
+  <span id="status"></span>
+  <button id="next">Next</button>
+  {{if .Query}}<span>Exported with query: <code>{{.Query}}</code></span>{{end}}
+  {{if .Truncated}}<span class="note">Showing the first {{len .Records}} of {{.Total}} matching records</span>{{end}}
+</header>
+<main id="records"></main>
+<script>
+const records = {{.Records}} || [];
+const pageSize = 100;
+let filtered = records;
+let page = 0;
+
+function render() {
+  const pages = Math.max(1, Math.ceil(filtered.length / pageSize));
+  page = Math.min(page, pages - 1);
+  const container = document.getElementById('records');
+  container.textContent = '';
+  for (const record of filtered.slice(page * pageSize, (page + 1) * pageSize)) {
+    const row = document.createElement('div');
+    row.className = 'record';
+    const line = document.createElement('span');
+    line.className = 'line';
+    line.textContent = record.line;
+    const body = document.createElement('pre');
+    try {
+      body.textContent = JSON.stringify(JSON.parse(record.json), null, 2);
+    } catch (e) {
+      body.textContent = record.json;
+    }
+    row.append(line, body);
+    container.append(row);
+  }
+  document.getElementById('status').textContent =
+    filtered.length + ' records, page ' + (page + 1) + ' of ' + pages;
+  document.getElementById('prev').disabled = page === 0;
+  document.getElementById('next').disabled = page >= pages - 1;
+}
+
+document.getElementById('search').addEventListener('input', (event) => {
+  const term = event.target.value.toLowerCase();
+  filtered = term ? records.filter((record) => record.json.toLowerCase().includes(term)) : records;
+  page = 0;
+  render();
+});
+document.getElementById('prev').addEventListener('click', () => { page--; render(); });
+document.getElementById('next').addEventListener('click', () => { page++; render(); });
+render();
+</script>
+</body>
+</html>
+`))
+
+// buildHTMLViewerData collects the records matching options for the HTML viewer,
+// applying field visibility like the JSONL export
+func (a *App) buildHTMLViewerData(options SearchOptions, shownFields []string, hiddenFields []string) (htmlViewerData, error) {
+	data := htmlViewerData{
+		Title:   a.currentFile.Name,
+		Query:   options.Query,
+		Records: []htmlViewerRecord{},
+	}
+
+	err := a.forEachScoped(&options, func(record JSONRecord) {
+		data.Total++
+		if len(data.Records) == maxHTMLExportRecords {
+			data.Truncated = true
+			return
+		}
+		data.Records = append(data.Records, htmlViewerRecord{
+			Line: record.LineNumber,
+			JSON: a.getDisplayJSON(record, shownFields, hiddenFields),
+		})
+	})
+	return data, err
+}
+
+// ExportHTMLViewer writes the records matching options (all records for an empty
+// query) into a standalone HTML file with built-in search and paging, so results can
+// be shared with people who do not have the app installed
+func (a *App) ExportHTMLViewer(options SearchOptions, shownFields []string, hiddenFields []string) (string, error) {
+	a.stateMu.RLock()
+	defer a.stateMu.RUnlock()
+	return a.exportHTMLViewer(options, shownFields, hiddenFields)
+}
+
+// exportHTMLViewer implements ExportHTMLViewer; the caller holds stateMu
+func (a *App) exportHTMLViewer(options SearchOptions, shownFields []string, hiddenFields []string) (string, error) {
+	if a.currentFile == nil || a.cache == nil {
+		return "", &JSONLError{
+			Message: "No file currently loaded",
+			Err:     ErrNoFileLoaded,
+		}
+	}
+
+	filePath, err := a.exportFilePath("jsonl-viewer-export", "html")
+	if err != nil {
+		return "", err
+	}
+
+	data, err := a.buildHTMLViewerData(options, shownFields, hiddenFields)
+	if err != nil {
+		return "", err
+	}
+
+	file, err := os.Create(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to create export file: %w", err)
+	}
+	defer file.Close()
+
+	if err := htmlViewerTemplate.Execute(file, data); err != nil {
+		return "", fmt.Errorf("failed to write HTML export: %w", err)
+	}
+
+	a.audit(AuditEntry{Action: AuditExportSaved, Path: a.auditPath(), Query: options.Query, Records: len(data.Records), Destination: filePath, Detail: "html"})
+	return filePath, nil
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHTMLViewerTemplate(t *testing.T) {
+	app := newTestApp(t, `{"level":"error","msg":"</script><script>alert(1)</script>"}
+{"level":"info","msg":"ok"}
+{"level":"error","msg":"disk full","secret":"x"}`)
+
+	data, err := app.buildHTMLViewerData(SearchOptions{Query: "level:error", UseLucene: true}, nil, []string{"secret"})
+	if err != nil {
+		t.Fatalf("buildHTMLViewerData returned error: %v", err)
+	}
+	if data.Total != 2 || len(data.Records) != 2 || data.Records[1].Line != 3 {
+		t.Fatalf("Unexpected viewer data: %+v", data)
+	}
+	if strings.Contains(data.Records[1].JSON, "secret") {
+		t.Errorf("Expected hidden fields to be removed, got %s", data.Records[1].JSON)
+	}
+
+	var buf strings.Builder
+	if err := htmlViewerTemplate.Execute(&buf, data); err != nil {
+		t.Fatalf("Template execution failed: %v", err)
+	}
+	html := buf.String()
+
+	// Record content must not be able to break out of the embedded script
+	if strings.Contains(html, "</script><script>alert(1)") {
+		t.Errorf("Record content was not escaped in the HTML output")
+	}
+	if !strings.Contains(html, "disk full") || !strings.Contains(html, "level:error") {
+		t.Errorf("Expected records and query in the HTML output")
+	}
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"io"
+	"os"
+)
+
+// canReloadIncrementally reports whether the current file's records can be extended
+// from its last read position: a plain UTF-8 local file held in memory
+func (a *App) canReloadIncrementally() bool {
+	path := a.currentFile.Path
+	return path != "<clipboard>" && !isRemotePath(path) && len(a.currentFile.Sources) == 0 && a.currentFile.Format == "" &&
+		!a.cache.diskBacked() && !isCompressedFile(path) && !isTranscodedFile(path)
+}
+
+// ReloadIncremental parses only the lines appended to the current file since it was
+// loaded or last reloaded and merges them into the loaded records. A final line
+// without newline is read again once it is complete. Files that cannot be extended
+// this way, or that shrank since, are reloaded in full.
+func (a *App) ReloadIncremental() (*JSONLFile, error) {
+	a.stateMu.Lock()
+	file, reload, err := a.mergeAppendedLines()
+	a.stateMu.Unlock()
+	if reload != nil {
+		return reload()
+	}
+	return file, err
+}
+
+// mergeAppendedLines implements ReloadIncremental with stateMu held exclusively.
+// When the file has to be reloaded in full it returns the function doing so, to be
+// called once stateMu is released.
+func (a *App) mergeAppendedLines() (*JSONLFile, func() (*JSONLFile, error), error) {
+	if a.currentFile == nil || a.cache == nil {
+		return nil, nil, &JSONLError{
+			Message: "No file currently loaded",
+			Err:     ErrNoFileLoaded,
+		}
+	}
+
+	if !a.canReloadIncrementally() {
+		return nil, a.ReloadCurrentFile, nil
+	}
+
+	path := a.currentFile.Path
+	fileInfo, err := os.Stat(path)
+	if err != nil {
+		return nil, nil, &JSONLError{
+			Message: "File not found or cannot be accessed",
+			Err:     ErrFileNotFound,
+		}
+	}
+	if fileInfo.Size() < a.cache.readOffset {
+		return nil, func() (*JSONLFile, error) { return a.LoadJSONLFile(path) }, nil
+	}
+	if fileInfo.Size() == a.cache.readOffset {
+		return a.currentFile, nil, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, &JSONLError{
+			Message: "Failed to open file",
+			Err:     ErrFileNotFound,
+		}
+	}
+	defer file.Close()
+	if _, err := file.Seek(a.cache.readOffset, io.SeekStart); err != nil {
+		return nil, nil, &JSONLError{
+			Message: "Failed to seek to appended lines",
+			Err:     err,
+		}
+	}
+
+	parser := NewJSONLReaderParser(file)
+	parser.lineCount = a.cache.readLines
+	parser.lenient = a.lenientParsing
+	parser.keepDuplicates = a.keepDuplicateKeys
+	parser.setMaxLineSize(a.maxLineSize)
+	tail, tailStats, err := parser.ParseJSONL()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cache := a.cache
+	if cache.fieldCounts == nil {
+		cache.fieldCounts = make(map[string]int)
+		for _, record := range cache.records {
+			for field := range record.Content {
+				cache.fieldCounts[field]++
+			}
+		}
+	}
+
+	// Merge in file order and sort the merged records again afterwards
+	sortKeys := cache.sortKeys
+	a.restoreFileOrder()
+
+	// Drop what was parsed from an incomplete final line; the tail includes it in full
+	records := cache.records
+	for len(records) > 0 && records[len(records)-1].LineNumber > cache.readLines {
+		for field := range records[len(records)-1].Content {
+			cache.fieldCounts[field]--
+		}
+		records = records[:len(records)-1]
+	}
+	invalidLines := []int{}
+	for _, line := range cache.stats.InvalidLines {
+		if line <= cache.readLines {
+			invalidLines = append(invalidLines, line)
+		}
+	}
+	invalidDetails := make(map[int]InvalidLineDetail)
+	for line, detail := range cache.stats.invalidDetails {
+		if line <= cache.readLines {
+			invalidDetails[line] = detail
+		}
+	}
+	for line, detail := range tailStats.invalidDetails {
+		invalidDetails[line] = detail
+	}
+	var longLines []int
+	for _, line := range cache.stats.LongLines {
+		if line <= cache.readLines {
+			longLines = append(longLines, line)
+		}
+	}
+	var repairedLines []int
+	for _, line := range cache.stats.RepairedLines {
+		if line <= cache.readLines {
+			repairedLines = append(repairedLines, line)
+		}
+	}
+	var duplicateKeys []DuplicateKeyLine
+	for _, duplicate := range cache.stats.DuplicateKeys {
+		if duplicate.Line <= cache.readLines {
+			duplicateKeys = append(duplicateKeys, duplicate)
+		}
+	}
+
+	for _, record := range tail {
+		for field := range record.Content {
+			cache.fieldCounts[field]++
+		}
+	}
+	records = append(records[:len(records):len(records)], tail...)
+
+	stats := &FileStats{
+		TotalLines:    tailStats.TotalLines,
+		ValidRecords:  len(records),
+		InvalidLines:  append(invalidLines, tailStats.InvalidLines...),
+		CommonFields:  selectCommonFields(cache.fieldCounts, len(records), CommonFieldOptions{}),
+		FileSize:      fileInfo.Size(),
+		LongLines:     append(longLines, tailStats.LongLines...),
+		RepairedLines: append(repairedLines, tailStats.RepairedLines...),
+		DuplicateKeys: append(duplicateKeys, tailStats.DuplicateKeys...),
+		TruncatedLine: tailStats.TruncatedLine,
+		RecordSizes:   measureRecordSizes(recordSizes(records)),
+
+		invalidDetails: invalidDetails,
+	}
+
+	if parser.completeOffset > 0 {
+		cache.readOffset += parser.completeOffset
+		cache.readLines = parser.completeLines
+	}
+	cache.records = records
+	cache.totalCount = len(records)
+	cache.stats = stats
+	a.records = records
+	if sortKeys != nil {
+		a.applyRecordOrder(sortKeys)
+	}
+
+	a.currentFile.Size = fileInfo.Size()
+	a.currentFile.Records = len(records)
+	a.currentFile.ModifiedAt = fileInfo.ModTime()
+
+	a.validationReport = nil
+	a.applyPageSizeMode()
+	a.bumpDataVersion()
+	return a.currentFile, nil, nil
+}
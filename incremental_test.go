@@ -0,0 +1,75 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReloadIncremental(t *testing.T) {
+	useTempConfigDir(t)
+
+	path := filepath.Join(t.TempDir(), "app.log.jsonl")
+	os.WriteFile(path, []byte("{\"n\":1}\nbad\n{\"n\":2"), 0644)
+
+	app := &App{}
+	if _, err := app.LoadJSONLFile(path); err != nil {
+		t.Fatalf("LoadJSONLFile returned error: %v", err)
+	}
+	if stats := app.cache.stats; app.cache.totalCount != 1 || len(stats.InvalidLines) != 1 || stats.TruncatedLine != 3 {
+		t.Fatalf("Expected 1 record and the partial line to be truncated rather than invalid, got %+v", stats)
+	}
+
+	// Complete the partial final line and append more
+	appendFile := func(data string) {
+		f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+		f.WriteString(data)
+		f.Close()
+	}
+	appendFile("0}\n{\"n\":3,\"extra\":true}\n")
+
+	file, err := app.ReloadIncremental()
+	if err != nil {
+		t.Fatalf("ReloadIncremental returned error: %v", err)
+	}
+	if file.Records != 3 {
+		t.Fatalf("Expected 3 records, got %d", file.Records)
+	}
+
+	record, _ := app.GetRecordByLineNumber(3)
+	if record == nil || record.Content["n"] != json.Number("20") {
+		t.Errorf("Expected the completed line to be re-read, got %+v", record)
+	}
+	record, _ = app.GetRecordByLineNumber(4)
+	if record == nil || record.Content["extra"] != true {
+		t.Errorf("Expected the appended record at line 4, got %+v", record)
+	}
+	if stats := app.cache.stats; stats.TotalLines != 4 || len(stats.InvalidLines) != 1 || stats.InvalidLines[0] != 2 || stats.TruncatedLine != 0 {
+		t.Errorf("Unexpected stats after reload: %+v", stats)
+	}
+
+	// A line still incomplete after a reload stays truncated
+	appendFile(`{"n":`)
+	if _, err := app.ReloadIncremental(); err != nil {
+		t.Fatalf("ReloadIncremental returned error: %v", err)
+	}
+	if stats := app.cache.stats; stats.TruncatedLine != 5 || len(stats.InvalidLines) != 1 {
+		t.Errorf("Expected line 5 to be truncated, got %+v", stats)
+	}
+	appendFile("4}\n")
+	if file, _ := app.ReloadIncremental(); file.Records != 4 || app.cache.stats.TruncatedLine != 0 {
+		t.Errorf("Expected the completed line to load, got %d records (%+v)", file.Records, app.cache.stats)
+	}
+
+	// Nothing appended
+	if file, _ := app.ReloadIncremental(); file.Records != 4 {
+		t.Errorf("Expected no change, got %d records", file.Records)
+	}
+
+	// A truncated file is reloaded in full
+	os.WriteFile(path, []byte("{\"n\":9}\n"), 0644)
+	if file, err := app.ReloadIncremental(); err != nil || file.Records != 1 {
+		t.Errorf("Expected a full reload of the truncated file, got %+v (%v)", file, err)
+	}
+}
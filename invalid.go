@@ -0,0 +1,366 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+)
+
+// InvalidLineAnnotation describes why an exported line failed to parse
+type InvalidLineAnnotation struct {
+	LineNumber int    `json:"lineNumber"` // line number in the source file
+	OutputLine int    `json:"outputLine"` // line number in the exported file
+	Error      string `json:"error"`
+}
+
+// InvalidLinesExport summarizes an invalid-lines export
+type InvalidLinesExport struct {
+	Path            string `json:"path"`
+	AnnotationsPath string `json:"annotationsPath,omitempty"`
+	LineCount       int    `json:"lineCount"`
+}
+
+// InvalidLineDetail explains why a line of the loaded file is not a valid record
+type InvalidLineDetail struct {
+	LineNumber int    `json:"lineNumber"`
+	Error      string `json:"error"`
+	Offset     int    `json:"offset"`     // byte offset of the error in the line, -1 when unknown
+	Column     int    `json:"column"`     // 1-based character column of the error, 0 when unknown
+	Text       string `json:"text"`       // the line, cut to about 200 bytes around the error
+	TextOffset int    `json:"textOffset"` // byte offset of Text in the line
+	Length     int    `json:"length"`     // byte length of the whole line, -1 when over the max line size
+}
+
+// InvalidLineDetails is a page of invalid line diagnostics
+type InvalidLineDetails struct {
+	Lines   []InvalidLineDetail `json:"lines"`
+	Offset  int                 `json:"offset"`
+	Limit   int                 `json:"limit"`
+	Total   int                 `json:"total"`
+	HasMore bool                `json:"hasMore"`
+}
+
+// invalidLineContext is how many bytes of an invalid line are kept on either side
+// of the error
+const invalidLineContext = 100
+
+// parseRecordLine parses a trimmed, non-empty line the way the JSONL parser does.
+// An object is returned as content; any other JSON value (array, string, number,
+// boolean or null) is a valid record too and is returned as value.
+func parseRecordLine(line string) (content map[string]interface{}, value interface{}, err error) {
+	// Numbers stay json.Number so large integers such as IDs keep every digit
+	decoder := json.NewDecoder(strings.NewReader(line))
+	decoder.UseNumber()
+	var parsed interface{}
+	if err := decoder.Decode(&parsed); err != nil || strings.TrimSpace(line[decoder.InputOffset():]) != "" {
+		// Report the error, including trailing data, the way json.Unmarshal does
+		return nil, nil, json.Unmarshal([]byte(line), &parsed)
+	}
+	if object, ok := parsed.(map[string]interface{}); ok {
+		return object, nil, nil
+	}
+	return nil, parsed, nil
+}
+
+// scanInvalidLines calls fn for every non-empty line of r that is not a valid record
+func scanInvalidLines(r io.Reader, fn func(lineNumber int, line string, parseErr error) error) error {
+	reader := bufio.NewReader(r)
+	lineNumber := 0
+	for {
+		line, readErr := reader.ReadString('\n')
+		if readErr != nil && readErr != io.EOF {
+			return readErr
+		}
+		if line != "" {
+			lineNumber++
+			trimmed := strings.TrimSpace(line)
+			if trimmed != "" {
+				if _, _, err := parseRecordLine(trimmed); err != nil {
+					if err := fn(lineNumber, strings.TrimRight(line, "\r\n"), err); err != nil {
+						return err
+					}
+				}
+			}
+		}
+		if readErr == io.EOF {
+			return nil
+		}
+	}
+}
+
+// GetInvalidLineDetails returns a page of the invalid lines of the loaded file,
+// in line order, with the parse error, where in the line it occurred and the text
+// around it, as recorded when the file was parsed.
+func (a *App) GetInvalidLineDetails(offset, limit int) (*InvalidLineDetails, error) {
+	a.stateMu.RLock()
+	defer a.stateMu.RUnlock()
+	return a.invalidLineDetails(offset, limit)
+}
+
+// invalidLineDetails implements GetInvalidLineDetails; the caller holds stateMu
+func (a *App) invalidLineDetails(offset, limit int) (*InvalidLineDetails, error) {
+	page, lineNumbers, err := a.invalidLinePage(offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	details := &InvalidLineDetails{
+		Lines:   make([]InvalidLineDetail, 0, len(lineNumbers)),
+		Offset:  page.offset,
+		Limit:   page.limit,
+		Total:   page.total,
+		HasMore: page.hasMore,
+	}
+	for _, lineNumber := range lineNumbers {
+		details.Lines = append(details.Lines, a.cache.stats.invalidDetail(lineNumber))
+	}
+	return details, nil
+}
+
+// GetInvalidLines returns a page of the invalid lines of the loaded file as
+// pseudo-records flagged Invalid, with no content and the line's text in RawJSON,
+// so they can be browsed and copied like records. RawJSON holds the text kept
+// around the error, the whole line unless it is over 200 bytes; lines over the max
+// line size have no text.
+func (a *App) GetInvalidLines(offset, limit int) (*PaginatedRecords, error) {
+	a.stateMu.RLock()
+	defer a.stateMu.RUnlock()
+	return a.invalidLines(offset, limit)
+}
+
+// invalidLines implements GetInvalidLines; the caller holds stateMu
+func (a *App) invalidLines(offset, limit int) (*PaginatedRecords, error) {
+	page, lineNumbers, err := a.invalidLinePage(offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	records := make([]JSONRecord, 0, len(lineNumbers))
+	for _, lineNumber := range lineNumbers {
+		detail := a.cache.stats.invalidDetail(lineNumber)
+		records = append(records, JSONRecord{LineNumber: lineNumber, RawJSON: detail.Text, Invalid: true})
+	}
+
+	return &PaginatedRecords{
+		Records: records,
+		Offset:  page.offset,
+		Limit:   page.limit,
+		Total:   page.total,
+		HasMore: page.hasMore,
+		Version: a.cache.versionToken(),
+	}, nil
+}
+
+// invalidPage holds the bounds of a page of invalid lines
+type invalidPage struct {
+	offset, limit, total int
+	hasMore              bool
+}
+
+// invalidLinePage validates paging through the invalid lines of the loaded file and
+// returns the page bounds with the line numbers on the page. The caller holds stateMu.
+func (a *App) invalidLinePage(offset, limit int) (invalidPage, []int, error) {
+	if a.currentFile == nil || a.cache == nil {
+		return invalidPage{}, nil, &JSONLError{
+			Message: "No file currently loaded",
+			Err:     ErrNoFileLoaded,
+		}
+	}
+
+	if offset < 0 {
+		offset = 0
+	}
+	if limit <= 0 {
+		limit = a.cache.pageSize
+	}
+	if limit > 1000 {
+		limit = 1000
+	}
+
+	invalidLines := a.cache.stats.InvalidLines
+	page := invalidPage{offset: offset, limit: limit, total: len(invalidLines)}
+	if offset >= len(invalidLines) {
+		return page, nil, nil
+	}
+	end := offset + limit
+	if end > len(invalidLines) {
+		end = len(invalidLines)
+	}
+	page.hasMore = end < len(invalidLines)
+	return page, invalidLines[offset:end], nil
+}
+
+// invalidLineDetail returns the diagnostic recorded for an invalid line
+func (stats *FileStats) invalidDetail(lineNumber int) InvalidLineDetail {
+	if detail, exists := stats.invalidDetails[lineNumber]; exists {
+		return detail
+	}
+	return InvalidLineDetail{LineNumber: lineNumber, Error: "no diagnostic was recorded for this line", Offset: -1, Length: -1}
+}
+
+// longLineDetail describes a line skipped for exceeding the max line size
+func longLineDetail(lineNumber, maxLineSize int) InvalidLineDetail {
+	return InvalidLineDetail{
+		LineNumber: lineNumber,
+		Error:      fmt.Sprintf("line exceeds the maximum line size of %d bytes", maxLineSize),
+		Offset:     -1,
+		Length:     -1,
+	}
+}
+
+// jsonErrorOffset returns how many bytes were read when a JSON syntax or type error
+// occurred, or -1 for other errors
+func jsonErrorOffset(err error) int64 {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &syntaxErr) {
+		return syntaxErr.Offset
+	}
+	if errors.As(err, &typeErr) {
+		return typeErr.Offset
+	}
+	return -1
+}
+
+// invalidLineDetail describes why a line failed to parse. errOffset is the byte
+// offset of parseErr in the trimmed line, -1 when unknown.
+func invalidLineDetail(lineNumber int, line string, parseErr error, errOffset int64) InvalidLineDetail {
+	detail := InvalidLineDetail{LineNumber: lineNumber, Error: parseErr.Error(), Offset: -1, Length: len(line)}
+	if errOffset >= 0 {
+		leading := len(line) - len(strings.TrimLeft(line, " \t\r\n"))
+		offset := leading + int(errOffset) - 1
+		if offset < leading {
+			offset = leading
+		}
+		if offset > len(line) {
+			offset = len(line)
+		}
+		detail.Offset = offset
+		detail.Column = utf8.RuneCountInString(line[:offset]) + 1
+	}
+
+	// Keep the text around the error, cut at character boundaries
+	start, end := 0, len(line)
+	if len(line) > 2*invalidLineContext {
+		center := detail.Offset
+		if center < 0 {
+			center = 0
+		}
+		start = center - invalidLineContext
+		if start < 0 {
+			start = 0
+		}
+		end = start + 2*invalidLineContext
+		if end > len(line) {
+			end, start = len(line), len(line)-2*invalidLineContext
+		}
+		for start < end && !utf8.RuneStart(line[start]) {
+			start++
+		}
+		for end < len(line) && !utf8.RuneStart(line[end]) {
+			end--
+		}
+	}
+	// Copy the text so the diagnostic does not hold on to the whole line
+	detail.Text = strings.Clone(line[start:end])
+	detail.TextOffset = start
+	return detail
+}
+
+// ExportInvalidLines writes the raw invalid lines of the current file to outputPath
+// (or a timestamped file in Downloads when empty). With annotate set, a companion
+// "<path>.errors.jsonl" file records the source line number and parse error of each.
+func (a *App) ExportInvalidLines(outputPath string, annotate bool) (*InvalidLinesExport, error) {
+	a.stateMu.RLock()
+	defer a.stateMu.RUnlock()
+
+	if a.currentFile == nil {
+		return nil, &JSONLError{
+			Message: "No file currently loaded",
+			Err:     ErrNoFileLoaded,
+		}
+	}
+
+	if a.currentFile.Path == "<clipboard>" {
+		return nil, &JSONLError{
+			Message: "Cannot export invalid lines of clipboard content",
+			Err:     errors.New("clipboard content has no source file"),
+		}
+	}
+
+	if outputPath == "" {
+		var err error
+		outputPath, err = a.exportFilePath("jsonl-viewer-invalid-lines", "jsonl")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	source, err := openJSONLSource(a.currentFile.Path)
+	if err != nil {
+		return nil, &JSONLError{
+			Message: "Failed to open source file",
+			Err:     ErrFileNotFound,
+		}
+	}
+	defer source.Close()
+
+	output, err := os.Create(outputPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create export file: %w", err)
+	}
+	defer output.Close()
+	writer := bufio.NewWriter(output)
+
+	result := &InvalidLinesExport{Path: outputPath}
+
+	var annotations *bufio.Writer
+	if annotate {
+		result.AnnotationsPath = outputPath + ".errors.jsonl"
+		annotationsFile, err := os.Create(result.AnnotationsPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create annotations file: %w", err)
+		}
+		defer annotationsFile.Close()
+		annotations = bufio.NewWriter(annotationsFile)
+	}
+
+	err = scanInvalidLines(source, func(lineNumber int, line string, parseErr error) error {
+		result.LineCount++
+		if _, err := writer.WriteString(line + "\n"); err != nil {
+			return err
+		}
+		if annotations == nil {
+			return nil
+		}
+
+		jsonBytes, err := json.Marshal(InvalidLineAnnotation{
+			LineNumber: lineNumber,
+			OutputLine: result.LineCount,
+			Error:      parseErr.Error(),
+		})
+		if err != nil {
+			return err
+		}
+		_, err = annotations.Write(append(jsonBytes, '\n'))
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to export invalid lines: %w", err)
+	}
+
+	if err := writer.Flush(); err != nil {
+		return nil, fmt.Errorf("failed to write export file: %w", err)
+	}
+	if annotations != nil {
+		if err := annotations.Flush(); err != nil {
+			return nil, fmt.Errorf("failed to write annotations file: %w", err)
+		}
+	}
+
+	a.audit(AuditEntry{Action: AuditExportSaved, Path: a.auditPath(), Records: result.LineCount, Destination: result.Path, Detail: "invalid lines"})
+	return result, nil
+}
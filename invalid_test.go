@@ -0,0 +1,164 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExportInvalidLines(t *testing.T) {
+	dir := t.TempDir()
+	sourcePath := filepath.Join(dir, "source.jsonl")
+	content := "{\"ok\":1}\n{bad json}\n\n[1,2\n{\"ok\":2}\n{\"trailing\":\n"
+	if err := os.WriteFile(sourcePath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write source: %v", err)
+	}
+
+	app := &App{}
+	if _, err := app.LoadJSONLFile(sourcePath); err != nil {
+		t.Fatalf("LoadJSONLFile returned error: %v", err)
+	}
+
+	outputPath := filepath.Join(dir, "invalid.jsonl")
+	result, err := app.ExportInvalidLines(outputPath, true)
+	if err != nil {
+		t.Fatalf("ExportInvalidLines returned error: %v", err)
+	}
+	if result.LineCount != 3 {
+		t.Errorf("Expected 3 invalid lines, got %d", result.LineCount)
+	}
+
+	exported, _ := os.ReadFile(outputPath)
+	if expected := "{bad json}\n[1,2\n{\"trailing\":\n"; string(exported) != expected {
+		t.Errorf("Expected exported lines %q, got %q", expected, exported)
+	}
+
+	annotations, _ := os.ReadFile(result.AnnotationsPath)
+	lines := strings.Split(strings.TrimSpace(string(annotations)), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], `{"lineNumber":2,"outputLine":1,"error":`) ||
+		!strings.HasPrefix(lines[2], `{"lineNumber":6,"outputLine":3,`) {
+		t.Errorf("Unexpected annotations:\n%s", annotations)
+	}
+}
+
+func TestGetInvalidLineDetails(t *testing.T) {
+	useTempConfigDir(t)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "source.jsonl")
+	long := `{"msg":"` + strings.Repeat("é", 150) + `",oops}`
+	content := "{\"ok\":1}\n  {\"a\": tru}\n{\"b\":1} x\n" + long + "\n{\"c\":\n"
+	os.WriteFile(path, []byte(content), 0644)
+
+	app := &App{}
+	if _, err := app.LoadJSONLFile(path); err != nil {
+		t.Fatalf("LoadJSONLFile returned error: %v", err)
+	}
+
+	details, err := app.GetInvalidLineDetails(0, 2)
+	if err != nil {
+		t.Fatalf("GetInvalidLineDetails returned error: %v", err)
+	}
+	if details.Total != 4 || !details.HasMore || len(details.Lines) != 2 {
+		t.Fatalf("Expected the first 2 of 4 invalid lines, got %+v", details)
+	}
+	first := details.Lines[0]
+	if first.LineNumber != 2 || first.Offset != 11 || first.Column != 12 || first.Text != `  {"a": tru}` ||
+		!strings.Contains(first.Error, "invalid character '}'") {
+		t.Errorf("Unexpected detail for line 2: %+v", first)
+	}
+	if second := details.Lines[1]; second.LineNumber != 3 || second.Offset != 8 || !strings.Contains(second.Error, "after top-level value") {
+		t.Errorf("Unexpected detail for line 3: %+v", second)
+	}
+
+	details, _ = app.GetInvalidLineDetails(2, 10)
+	if details.HasMore || len(details.Lines) != 2 {
+		t.Fatalf("Expected the last 2 invalid lines, got %+v", details)
+	}
+	cut := details.Lines[0]
+	if cut.Length != len(long) || len(cut.Text) > 200 || !strings.Contains(cut.Text, ",oops}") ||
+		long[cut.TextOffset:cut.TextOffset+len(cut.Text)] != cut.Text || cut.Column != 161 {
+		t.Errorf("Expected the text around the error of the long line, got %+v", cut)
+	}
+	if last := details.Lines[1]; last.LineNumber != 5 || !strings.Contains(last.Error, "unexpected end of JSON input") {
+		t.Errorf("Unexpected detail for line 5: %+v", last)
+	}
+
+	if details, _ := app.GetInvalidLineDetails(10, 10); len(details.Lines) != 0 || details.Total != 4 {
+		t.Errorf("Expected an empty page past the end, got %+v", details)
+	}
+}
+
+func TestGetInvalidLines(t *testing.T) {
+	useTempConfigDir(t)
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "a.jsonl"), []byte("{\"ok\":1}\n{bad}\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "b.jsonl"), []byte("{\"ok\":2}\n\n[1,\n{\"ok\":3}\n"), 0644)
+
+	app := &App{}
+	if _, err := app.LoadJSONLGlob(filepath.Join(dir, "*.jsonl")); err != nil {
+		t.Fatalf("LoadJSONLGlob returned error: %v", err)
+	}
+
+	page, err := app.GetInvalidLines(0, 1)
+	if err != nil {
+		t.Fatalf("GetInvalidLines returned error: %v", err)
+	}
+	if page.Total != 2 || !page.HasMore || len(page.Records) != 1 {
+		t.Fatalf("Expected the first of 2 invalid lines, got %+v", page)
+	}
+	if record := page.Records[0]; record.LineNumber != 2 || record.RawJSON != "{bad}" || !record.Invalid || record.Content != nil {
+		t.Errorf("Unexpected pseudo-record %+v", record)
+	}
+
+	// Line numbers continue across the files of a merged dataset
+	page, _ = app.GetInvalidLines(1, 1)
+	if len(page.Records) != 1 || page.Records[0].LineNumber != 5 || page.Records[0].RawJSON != "[1," || page.HasMore {
+		t.Errorf("Unexpected second page %+v", page)
+	}
+
+	// Content without a source file keeps its invalid lines too
+	clipboard := newTestApp(t, "{\"ok\":1}\n {bad}\n")
+	page, err = clipboard.GetInvalidLines(0, 10)
+	if err != nil || len(page.Records) != 1 || page.Records[0].LineNumber != 2 || page.Records[0].RawJSON != " {bad}" {
+		t.Errorf("Expected the invalid clipboard line, got %+v (%v)", page, err)
+	}
+
+	var jsonlErr *JSONLError
+	if _, err := (&App{}).GetInvalidLines(0, 10); !errors.As(err, &jsonlErr) || !errors.Is(jsonlErr.Err, ErrNoFileLoaded) {
+		t.Errorf("Expected ErrNoFileLoaded, got %v", err)
+	}
+}
+
+func TestInvalidLineDetailsRecordedWhileParsing(t *testing.T) {
+	useTempConfigDir(t)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	os.WriteFile(path, []byte("level=info msg=ok\nlevel=\"oops\n"), 0644)
+
+	app := &App{}
+	if _, err := app.LoadLogFile(path, ParseModeLogfmt); err != nil {
+		t.Fatalf("LoadLogFile returned error: %v", err)
+	}
+	details, err := app.GetInvalidLineDetails(0, 10)
+	if err != nil || len(details.Lines) != 1 {
+		t.Fatalf("Expected 1 invalid line, got %+v (%v)", details, err)
+	}
+	if detail := details.Lines[0]; detail.LineNumber != 2 || detail.Text != `level="oops` ||
+		!strings.Contains(detail.Error, "unterminated quoted value") || detail.Offset != -1 {
+		t.Errorf("Expected the logfmt error of line 2, got %+v", detail)
+	}
+
+	// The diagnostics describe the file as it was loaded
+	path = filepath.Join(dir, "source.jsonl")
+	os.WriteFile(path, []byte("{\"ok\":1}\n{bad}\n"), 0644)
+	if _, err := app.LoadJSONLFile(path); err != nil {
+		t.Fatalf("LoadJSONLFile returned error: %v", err)
+	}
+	os.WriteFile(path, []byte("{\"ok\":1}\n{\"ok\":2}\n"), 0644)
+	details, _ = app.GetInvalidLineDetails(0, 10)
+	if len(details.Lines) != 1 || details.Lines[0].Text != "{bad}" || !strings.Contains(details.Lines[0].Error, "invalid character 'b'") {
+		t.Errorf("Expected the error recorded at load, got %+v", details)
+	}
+}
@@ -0,0 +1,252 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// journalFile is the write-ahead journal in the config directory
+const journalFile = "journal.jsonl"
+
+// Kinds of journaled changes
+const (
+	JournalEdit       = "edit"
+	JournalAnnotation = "annotation"
+	JournalTag        = "tag"
+	JournalBookmark   = "bookmark"
+)
+
+// ErrInvalidJournalEntry is returned for journal entries that cannot be recorded
+var ErrInvalidJournalEntry = errors.New("invalid journal entry")
+
+// JournalEntry is an unsaved change kept in the journal until it is saved or discarded
+type JournalEntry struct {
+	Seq        int64       `json:"seq"`
+	Time       time.Time   `json:"time"`
+	Kind       string      `json:"kind"` // 'edit', 'annotation', 'tag', 'bookmark'
+	Path       string      `json:"path"` // file the change belongs to
+	LineNumber int         `json:"lineNumber"`
+	Payload    interface{} `json:"payload"` // kind-specific data, e.g. new record content or tag names
+}
+
+// journal appends entries to the journal file, syncing each write so changes
+// survive an app or machine crash. The zero value is ready to use.
+type journal struct {
+	mu      sync.Mutex
+	lastSeq int64
+}
+
+func (j *journal) path() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, journalFile), nil
+}
+
+// readEntries returns the journaled entries in order. A torn final line left by a
+// crash mid-write is ignored.
+func (j *journal) readEntries() ([]JournalEntry, error) {
+	path, err := j.path()
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return []JournalEntry{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open journal: %w", err)
+	}
+	defer file.Close()
+
+	entries := []JournalEntry{}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var entry JournalEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		entries = append(entries, entry)
+		if entry.Seq > j.lastSeq {
+			j.lastSeq = entry.Seq
+		}
+	}
+	return entries, scanner.Err()
+}
+
+// append writes an entry and syncs it to disk before returning
+func (j *journal) append(entry JournalEntry) (JournalEntry, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	// Continue numbering after entries left by an earlier session
+	if j.lastSeq == 0 {
+		if _, err := j.readEntries(); err != nil {
+			return entry, err
+		}
+	}
+
+	path, err := j.path()
+	if err != nil {
+		return entry, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return entry, fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	j.lastSeq++
+	entry.Seq = j.lastSeq
+	entry.Time = time.Now()
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return entry, err
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0600)
+	if err != nil {
+		return entry, fmt.Errorf("failed to open journal: %w", err)
+	}
+	defer file.Close()
+
+	// Start on a fresh line if a crash left a torn final line behind
+	if info, err := file.Stat(); err == nil && info.Size() > 0 {
+		last := make([]byte, 1)
+		if _, err := file.ReadAt(last, info.Size()-1); err == nil && last[0] != '\n' {
+			data = append([]byte{'\n'}, data...)
+		}
+	}
+
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return entry, fmt.Errorf("failed to write journal: %w", err)
+	}
+	if err := file.Sync(); err != nil {
+		return entry, fmt.Errorf("failed to sync journal: %w", err)
+	}
+	return entry, nil
+}
+
+// remove rewrites the journal without the entries of path (every entry when path
+// is empty), replacing the file atomically, and returns the number of entries removed
+func (j *journal) remove(path string) (int, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	entries, err := j.readEntries()
+	if err != nil {
+		return 0, err
+	}
+	journalPath, err := j.path()
+	if err != nil {
+		return 0, err
+	}
+
+	var kept []byte
+	removed := 0
+	for _, entry := range entries {
+		if path == "" || entry.Path == path {
+			removed++
+			continue
+		}
+		data, err := json.Marshal(entry)
+		if err != nil {
+			return 0, err
+		}
+		kept = append(append(kept, data...), '\n')
+	}
+
+	if len(kept) == 0 {
+		if err := os.Remove(journalPath); err != nil && !os.IsNotExist(err) {
+			return 0, fmt.Errorf("failed to remove journal: %w", err)
+		}
+		return removed, nil
+	}
+
+	tmpPath := journalPath + ".tmp"
+	if err := os.WriteFile(tmpPath, kept, 0600); err != nil {
+		return 0, fmt.Errorf("failed to write journal: %w", err)
+	}
+	if err := os.Rename(tmpPath, journalPath); err != nil {
+		return 0, err
+	}
+	return removed, nil
+}
+
+// RecordJournalEntry durably records an unsaved change before it is applied; an
+// empty Path refers to the current file. It returns the entry with its sequence number.
+func (a *App) RecordJournalEntry(entry JournalEntry) (*JournalEntry, error) {
+	switch entry.Kind {
+	case JournalEdit, JournalAnnotation, JournalTag, JournalBookmark:
+	default:
+		return nil, &JSONLError{
+			Message: fmt.Sprintf("Unknown journal entry kind %q", entry.Kind),
+			Err:     ErrInvalidJournalEntry,
+		}
+	}
+
+	if entry.Path == "" {
+		file := a.loadedFile()
+		if file == nil {
+			return nil, &JSONLError{
+				Message: "No file currently loaded",
+				Err:     ErrNoFileLoaded,
+			}
+		}
+		entry.Path = file.Path
+	}
+
+	recorded, err := a.journal.append(entry)
+	if err != nil {
+		return nil, err
+	}
+	return &recorded, nil
+}
+
+// GetRecoverableChanges returns the journaled changes that were never saved or
+// discarded, e.g. after a crash, oldest first
+func (a *App) GetRecoverableChanges() ([]JournalEntry, error) {
+	a.journal.mu.Lock()
+	defer a.journal.mu.Unlock()
+	return a.journal.readEntries()
+}
+
+// MarkJournalSaved drops the journaled changes of a file once they have been saved,
+// recording the save in the audit log
+func (a *App) MarkJournalSaved(path string) error {
+	if path == "" {
+		return &JSONLError{
+			Message: "File path cannot be empty",
+			Err:     ErrInvalidJournalEntry,
+		}
+	}
+	saved, err := a.journal.remove(path)
+	if err != nil {
+		return err
+	}
+	a.audit(AuditEntry{Action: AuditEditSaved, Path: path, Records: saved})
+	return nil
+}
+
+// DiscardJournal drops the journaled changes of a file, or of every file when path is empty
+func (a *App) DiscardJournal(path string) error {
+	_, err := a.journal.remove(path)
+	return err
+}
+
+// offerJournalRecovery emits "journal:recovery" with the pending changes when an
+// earlier session ended without saving them
+func (a *App) offerJournalRecovery() {
+	entries, err := a.GetRecoverableChanges()
+	if err != nil || len(entries) == 0 {
+		return
+	}
+	a.emitEvent("journal:recovery", entries)
+}
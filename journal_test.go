@@ -0,0 +1,63 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestJournal(t *testing.T) {
+	dir := useTempConfigDir(t)
+	app := newTestApp(t, `{"msg":"a"}`)
+
+	if _, err := app.RecordJournalEntry(JournalEntry{Kind: "comment"}); err == nil {
+		t.Errorf("Expected an error for an unknown kind")
+	}
+
+	first, err := app.RecordJournalEntry(JournalEntry{Kind: JournalTag, LineNumber: 1, Payload: []string{"suspicious"}})
+	if err != nil {
+		t.Fatalf("RecordJournalEntry returned error: %v", err)
+	}
+	if first.Seq != 1 || first.Path != "<clipboard>" {
+		t.Errorf("Unexpected recorded entry: %+v", first)
+	}
+	app.RecordJournalEntry(JournalEntry{Kind: JournalBookmark, Path: "/data/other.jsonl", LineNumber: 7})
+
+	// Simulate a crash that tore the last write
+	journalPath := filepath.Join(dir, journalFile)
+	file, _ := os.OpenFile(journalPath, os.O_APPEND|os.O_WRONLY, 0600)
+	file.WriteString(`{"seq":3,"kind":"ed`)
+	file.Close()
+
+	// A new session sees the pending changes and continues the numbering
+	restarted := newTestApp(t, `{"msg":"a"}`)
+	entries, err := restarted.GetRecoverableChanges()
+	if err != nil {
+		t.Fatalf("GetRecoverableChanges returned error: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Kind != JournalTag || entries[1].LineNumber != 7 {
+		t.Fatalf("Unexpected recoverable entries: %+v", entries)
+	}
+	next, _ := restarted.RecordJournalEntry(JournalEntry{Kind: JournalAnnotation, Payload: "check this"})
+	if next.Seq != 3 {
+		t.Errorf("Expected sequence to continue at 3, got %d", next.Seq)
+	}
+	if entries, _ = restarted.GetRecoverableChanges(); len(entries) != 3 || entries[2].Kind != JournalAnnotation {
+		t.Errorf("Expected the new entry after the torn line to be readable, got %+v", entries)
+	}
+
+	if err := restarted.MarkJournalSaved("<clipboard>"); err != nil {
+		t.Fatalf("MarkJournalSaved returned error: %v", err)
+	}
+	entries, _ = restarted.GetRecoverableChanges()
+	if len(entries) != 1 || entries[0].Path != "/data/other.jsonl" {
+		t.Errorf("Expected only the other file's entry to remain, got %+v", entries)
+	}
+
+	if err := restarted.DiscardJournal(""); err != nil {
+		t.Fatalf("DiscardJournal returned error: %v", err)
+	}
+	if _, err := os.Stat(journalPath); !os.IsNotExist(err) {
+		t.Errorf("Expected the journal file to be removed")
+	}
+}
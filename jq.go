@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/itchyny/gojq"
+)
+
+// ErrInvalidJQ is returned when a jq expression fails to parse or compile
+var ErrInvalidJQ = errors.New("invalid jq expression")
+
+// ErrJQTimeout is returned when a jq expression runs longer than jqTimeout
+var ErrJQTimeout = errors.New("jq expression timed out")
+
+// jqTimeout bounds a FilterWithJQ run
+const jqTimeout = 30 * time.Second
+
+// compileJQ parses and compiles a jq expression
+func compileJQ(expr string) (*gojq.Code, error) {
+	query, err := gojq.Parse(expr)
+	if err != nil {
+		return nil, &JSONLError{
+			Message: fmt.Sprintf("Invalid jq expression: %v", err),
+			Err:     ErrInvalidJQ,
+		}
+	}
+	code, err := gojq.Compile(query)
+	if err != nil {
+		return nil, &JSONLError{
+			Message: fmt.Sprintf("Invalid jq expression: %v", err),
+			Err:     ErrInvalidJQ,
+		}
+	}
+	return code, nil
+}
+
+// jqOutputRecord wraps a value produced by a jq expression as a record of the line
+// it was computed from
+func jqOutputRecord(lineNumber int, value interface{}) (JSONRecord, error) {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return JSONRecord{}, err
+	}
+	// Decode the output again so its numbers are json.Number like loaded records
+	content, decoded, err := parseRecordLine(string(raw))
+	if err != nil {
+		return JSONRecord{}, err
+	}
+	return JSONRecord{LineNumber: lineNumber, Content: content, RawJSON: string(raw), Value: decoded}, nil
+}
+
+// FilterWithJQ runs a jq expression (e.g. `select(.latency_ms > 500) | {path, latency_ms}`)
+// against every record, computed and extracted fields included, and returns a page of
+// its outputs. Each output keeps the line number of the record it came from; an
+// expression may drop records or produce several outputs per record. The run can be
+// aborted with CancelSearch(token), and stops with ErrJQTimeout after jqTimeout.
+func (a *App) FilterWithJQ(expr string, offset, limit int, token string) (*SearchResult, error) {
+	return a.filterWithJQ(expr, offset, limit, token, jqTimeout)
+}
+
+// filterWithJQ implements FilterWithJQ, stopping with ErrJQTimeout after timeout
+func (a *App) filterWithJQ(expr string, offset, limit int, token string, timeout time.Duration) (*SearchResult, error) {
+	a.stateMu.RLock()
+	defer a.stateMu.RUnlock()
+
+	if a.currentFile == nil || a.cache == nil {
+		return nil, &JSONLError{
+			Message: "No file currently loaded",
+			Err:     ErrNoFileLoaded,
+		}
+	}
+
+	code, err := compileJQ(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	if offset < 0 {
+		offset = 0
+	}
+	if limit <= 0 {
+		limit = 50
+	}
+	if limit > 1000 {
+		limit = 1000
+	}
+
+	scan, done := a.beginSearch(token, a.cache.totalCount)
+	defer done()
+	ctx, cancel := context.WithTimeout(scan.ctx, timeout)
+	defer cancel()
+
+	// Count every output but only encode the ones on the requested page
+	var page []JSONRecord
+	totalMatches := 0
+	var runErr error
+	readErr := a.cache.forEach(func(record JSONRecord) bool {
+		// gojq normalizes numbers in place, so run on a private copy of the record's
+		// content with its virtual fields; json.Number values keep large integers exact
+		input := record.Value
+		if record.Content != nil {
+			input = record.Content
+		}
+		input = deepCopyValue(input)
+
+		outputs := 0
+		iter := code.RunWithContext(ctx, input)
+		for {
+			value, ok := iter.Next()
+			if !ok {
+				break
+			}
+			if err, isErr := value.(error); isErr {
+				if ctx.Err() != nil {
+					return false
+				}
+				runErr = &JSONLError{
+					Message:    fmt.Sprintf("jq error: %v", err),
+					LineNumber: record.LineNumber,
+					Err:        err,
+				}
+				return false
+			}
+			outputs++
+			totalMatches++
+			if totalMatches <= offset || len(page) >= limit {
+				continue
+			}
+			output, err := jqOutputRecord(record.LineNumber, value)
+			if err != nil {
+				runErr = &JSONLError{
+					Message:    "Failed to encode jq output",
+					LineNumber: record.LineNumber,
+					Err:        err,
+				}
+				return false
+			}
+			page = append(page, output)
+		}
+		return scan.step(1, outputs) && ctx.Err() == nil
+	})
+	if readErr != nil {
+		return nil, readErr
+	}
+	if runErr != nil {
+		return nil, runErr
+	}
+	switch {
+	case scan.ctx.Err() != nil:
+		return nil, &JSONLError{
+			Message: "Search was cancelled",
+			Err:     ErrSearchCancelled,
+		}
+	case ctx.Err() != nil:
+		return nil, &JSONLError{
+			Message: fmt.Sprintf("jq expression did not finish within %v", timeout),
+			Err:     ErrJQTimeout,
+		}
+	}
+	if page == nil {
+		page = []JSONRecord{}
+	}
+
+	return &SearchResult{
+		Records:      page,
+		Offset:       offset,
+		Limit:        limit,
+		Total:        a.cache.totalCount,
+		TotalMatches: totalMatches,
+		HasMore:      offset+len(page) < totalMatches,
+		Query:        expr,
+		Version:      a.cache.versionToken(),
+	}, nil
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestFilterWithJQ(t *testing.T) {
+	app := newTestApp(t, `{"path":"/a","latency_ms":120,"status":200}
+{"path":"/b","latency_ms":900,"status":500}
+{"path":"/c","latency_ms":650,"status":200}
+{"path":"/d","latency_ms":30,"status":404}
+`)
+
+	result, err := app.FilterWithJQ("select(.latency_ms > 500) | {path, latency_ms}", 0, 10, "")
+	if err != nil {
+		t.Fatalf("FilterWithJQ returned error: %v", err)
+	}
+	if result.TotalMatches != 2 || len(result.Records) != 2 {
+		t.Fatalf("Expected 2 outputs, got %+v", result)
+	}
+	first := result.Records[0]
+	if first.LineNumber != 2 || first.Content["path"] != "/b" || len(first.Content) != 2 {
+		t.Errorf("Unexpected first output: %+v", first)
+	}
+	if first.RawJSON != `{"latency_ms":900,"path":"/b"}` {
+		t.Errorf("Unexpected raw JSON: %s", first.RawJSON)
+	}
+
+	// Scalars and multiple outputs per record, paginated
+	result, err = app.FilterWithJQ(".path, .status", 3, 2, "")
+	if err != nil {
+		t.Fatalf("FilterWithJQ returned error: %v", err)
+	}
+	if result.TotalMatches != 8 || len(result.Records) != 2 || !result.HasMore {
+		t.Fatalf("Unexpected page: %+v", result)
+	}
+	if result.Records[0].LineNumber != 2 || result.Records[0].Value != json.Number("500") || result.Records[1].Value != "/c" {
+		t.Errorf("Unexpected page records: %+v", result.Records)
+	}
+
+	// The cached records are left untouched
+	record, err := app.GetRecordByLineNumber(1)
+	if err != nil || record.Content["latency_ms"] != json.Number("120") {
+		t.Errorf("Expected cached record to be unchanged, got %+v", record)
+	}
+
+	// Computed and extracted fields are part of the input
+	if err := app.SetComputedFields([]ComputedField{{Name: "latency_s", Expression: "latency_ms / 1000"}}); err != nil {
+		t.Fatalf("SetComputedFields returned error: %v", err)
+	}
+	if _, err := app.SetExtractionRules([]ExtractionRule{{SourceField: "path", Pattern: `^/(?P<section>\w)`}}); err != nil {
+		t.Fatalf("SetExtractionRules returned error: %v", err)
+	}
+	result, err = app.FilterWithJQ("select(.latency_s > 0.5) | .section", 0, 10, "")
+	if err != nil || result.TotalMatches != 2 || result.Records[0].Value != "b" || result.Records[1].Value != "c" {
+		t.Errorf("Expected the sections of the slow records, got %+v (%v)", result, err)
+	}
+}
+
+func TestFilterWithJQErrors(t *testing.T) {
+	app := newTestApp(t, "{\"n\":1}\n{\"n\":\"x\"}\n")
+
+	_, err := app.FilterWithJQ("select(.n >", 0, 10, "")
+	var jsonlErr *JSONLError
+	if !errors.As(err, &jsonlErr) || !errors.Is(jsonlErr.Err, ErrInvalidJQ) {
+		t.Errorf("Expected ErrInvalidJQ, got %v", err)
+	}
+
+	_, err = app.FilterWithJQ(".n + 1", 0, 10, "")
+	if !errors.As(err, &jsonlErr) || jsonlErr.LineNumber != 2 {
+		t.Errorf("Expected a runtime error on line 2, got %v", err)
+	}
+
+	empty := &App{}
+	if _, err := empty.FilterWithJQ(".", 0, 10, ""); !errors.As(err, &jsonlErr) || !errors.Is(jsonlErr.Err, ErrNoFileLoaded) {
+		t.Errorf("Expected ErrNoFileLoaded, got %v", err)
+	}
+}
+
+func TestFilterWithJQCancellation(t *testing.T) {
+	app := newTestApp(t, "{\"n\":1}\n{\"n\":2}\n")
+
+	var jsonlErr *JSONLError
+	_, err := app.filterWithJQ("last(range(1e12))", 0, 10, "", 50*time.Millisecond)
+	if !errors.As(err, &jsonlErr) || !errors.Is(jsonlErr.Err, ErrJQTimeout) {
+		t.Errorf("Expected ErrJQTimeout, got %v", err)
+	}
+
+	result := make(chan error, 1)
+	go func() {
+		_, err := app.FilterWithJQ("repeat(.)", 0, 10, "jq-run")
+		result <- err
+	}()
+	for !app.CancelSearch("jq-run") {
+		time.Sleep(time.Millisecond)
+	}
+	select {
+	case err := <-result:
+		if !errors.As(err, &jsonlErr) || !errors.Is(jsonlErr.Err, ErrSearchCancelled) {
+			t.Errorf("Expected ErrSearchCancelled, got %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("FilterWithJQ did not stop after CancelSearch")
+	}
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadJSONArrayFile(t *testing.T) {
+	useTempConfigDir(t)
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "dump.json")
+	os.WriteFile(path, []byte("[\n  {\"id\": 1, \"name\": \"a\"},\n  {\"id\": 2},\n  [1, 2],\n  \"text\"\n]\n"), 0644)
+
+	app := &App{}
+	file, err := app.LoadJSONArrayFile(path)
+	if err != nil {
+		t.Fatalf("LoadJSONArrayFile returned error: %v", err)
+	}
+	if file.Records != 4 || file.Format != FormatJSON {
+		t.Fatalf("Expected 4 records in JSON format, got %+v", file)
+	}
+
+	record, _ := app.GetRecordByLineNumber(1)
+	if record.RawJSON != `{"id":1,"name":"a"}` || record.Content["name"] != "a" {
+		t.Errorf("Unexpected first record %+v", record)
+	}
+	if record, _ := app.GetRecordByLineNumber(4); record.Value != "text" {
+		t.Errorf("Expected the string element as Value, got %+v", record)
+	}
+	if fields := app.cache.stats.CommonFields; len(fields) != 1 || fields[0] != "id" {
+		t.Errorf("Expected id as common field, got %v", fields)
+	}
+
+	exported, err := app.GetAllRecords("id")
+	if err != nil || len(exported) != 2 {
+		t.Errorf("Expected the loaded records to be exported, got %d (%v)", len(exported), err)
+	}
+
+	for name, content := range map[string]string{
+		"object.json":    `{"id": 1}`,
+		"truncated.json": `[{"id": 1}, {"id":`,
+	} {
+		path := filepath.Join(dir, name)
+		os.WriteFile(path, []byte(content), 0644)
+		var jsonErr *JSONLError
+		if _, err := app.LoadJSONArrayFile(path); !errors.As(err, &jsonErr) {
+			t.Errorf("%s: expected a JSONLError, got %v", name, err)
+		}
+	}
+	if app.currentFile.Path != filepath.Join(dir, "dump.json") {
+		t.Errorf("Expected failed loads to keep the current file, got %s", app.currentFile.Path)
+	}
+}
@@ -0,0 +1,620 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidJSONPath is returned when a JSONPath search query fails to parse
+var ErrInvalidJSONPath = errors.New("invalid JSONPath expression")
+
+// jsonPath is a compiled JSONPath expression, e.g. $.items[?(@.price > 10)].name
+type jsonPath []jsonPathSelector
+
+// jsonPathSelector selects children of the current values: named members, all
+// members, array indexes, an array slice or the elements passing a filter
+type jsonPathSelector struct {
+	kind      string // "name", "wildcard", "index", "slice", "filter"
+	recursive bool   // applies to the current values and all their descendants (..)
+	names     []string
+	indexes   []int
+	start     *int // slice bounds, nil when omitted
+	end       *int
+	step      int
+	filter    *jsonPathFilter
+}
+
+// jsonPathFilter is a node of a filter expression: a boolean combination of
+// comparisons, or the existence of a path
+type jsonPathFilter struct {
+	op          string // "or", "and", "not", "exists", or a comparison operator
+	left, right *jsonPathFilter
+	lhs, rhs    jsonPathOperand
+}
+
+// jsonPathOperand is a path relative to the current element (@) or the root ($),
+// or a literal value
+type jsonPathOperand struct {
+	path     jsonPath
+	fromRoot bool
+	isPath   bool
+	literal  interface{}
+}
+
+// jsonPathComparisons are the filter comparison operators, longest first
+var jsonPathComparisons = []string{"==", "!=", "<=", ">=", "<", ">"}
+
+// parseJSONPath compiles a JSONPath expression starting at the root ($)
+func parseJSONPath(expr string) (jsonPath, error) {
+	p := &jsonPathParser{input: strings.TrimSpace(expr)}
+	if !p.consume("$") {
+		return nil, p.errorf("expected $")
+	}
+	path, err := p.parseSelectors()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.input) {
+		return nil, p.errorf("unexpected %q", p.input[p.pos:])
+	}
+	return path, nil
+}
+
+type jsonPathParser struct {
+	input string
+	pos   int
+}
+
+func (p *jsonPathParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("%s at position %d", fmt.Sprintf(format, args...), p.pos)
+}
+
+func (p *jsonPathParser) peek(s string) bool {
+	return strings.HasPrefix(p.input[p.pos:], s)
+}
+
+func (p *jsonPathParser) consume(s string) bool {
+	if p.peek(s) {
+		p.pos += len(s)
+		return true
+	}
+	return false
+}
+
+func (p *jsonPathParser) skipSpaces() {
+	for p.pos < len(p.input) && (p.input[p.pos] == ' ' || p.input[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+// parseSelectors parses the selectors following $ or @, up to the first character
+// that cannot continue the path
+func (p *jsonPathParser) parseSelectors() (jsonPath, error) {
+	var path jsonPath
+	for p.pos < len(p.input) {
+		recursive := false
+		switch {
+		case p.consume(".."):
+			recursive = true
+			if !p.peek("[") {
+				selector, err := p.parseDotted()
+				if err != nil {
+					return nil, err
+				}
+				selector.recursive = true
+				path = append(path, selector)
+				continue
+			}
+		case p.consume("."):
+			selector, err := p.parseDotted()
+			if err != nil {
+				return nil, err
+			}
+			path = append(path, selector)
+			continue
+		case !p.peek("["):
+			return path, nil
+		}
+
+		p.pos++ // [
+		selector, err := p.parseBracket()
+		if err != nil {
+			return nil, err
+		}
+		selector.recursive = recursive
+		path = append(path, selector)
+	}
+	return path, nil
+}
+
+// parseDotted parses the member name or * following a dot
+func (p *jsonPathParser) parseDotted() (jsonPathSelector, error) {
+	if p.consume("*") {
+		return jsonPathSelector{kind: "wildcard"}, nil
+	}
+	start := p.pos
+	for p.pos < len(p.input) && !strings.ContainsRune(".[]()=!<>&|,'\" \t", rune(p.input[p.pos])) {
+		p.pos++
+	}
+	if p.pos == start {
+		return jsonPathSelector{}, p.errorf("expected a member name")
+	}
+	return jsonPathSelector{kind: "name", names: []string{p.input[start:p.pos]}}, nil
+}
+
+// parseBracket parses the contents of [...] after the opening bracket
+func (p *jsonPathParser) parseBracket() (jsonPathSelector, error) {
+	var selector jsonPathSelector
+	p.skipSpaces()
+	switch {
+	case p.consume("*"):
+		selector.kind = "wildcard"
+
+	case p.consume("?"):
+		p.skipSpaces()
+		if !p.consume("(") {
+			return selector, p.errorf("expected ( after ?")
+		}
+		filter, err := p.parseOr()
+		if err != nil {
+			return selector, err
+		}
+		p.skipSpaces()
+		if !p.consume(")") {
+			return selector, p.errorf("expected ) to close the filter")
+		}
+		selector.kind = "filter"
+		selector.filter = filter
+
+	case p.peek("'") || p.peek(`"`):
+		selector.kind = "name"
+		for {
+			name, err := p.parseString()
+			if err != nil {
+				return selector, err
+			}
+			selector.names = append(selector.names, name)
+			p.skipSpaces()
+			if !p.consume(",") {
+				break
+			}
+			p.skipSpaces()
+		}
+
+	default:
+		if err := p.parseIndexes(&selector); err != nil {
+			return selector, err
+		}
+	}
+
+	p.skipSpaces()
+	if !p.consume("]") {
+		return selector, p.errorf("expected ]")
+	}
+	return selector, nil
+}
+
+// parseIndexes parses a list of indexes ([0,2]) or a slice ([1:-1], [::2])
+func (p *jsonPathParser) parseIndexes(selector *jsonPathSelector) error {
+	var bounds []*int
+	separator := ""
+	for {
+		p.skipSpaces()
+		start := p.pos
+		if p.pos < len(p.input) && p.input[p.pos] == '-' {
+			p.pos++
+		}
+		for p.pos < len(p.input) && p.input[p.pos] >= '0' && p.input[p.pos] <= '9' {
+			p.pos++
+		}
+		var bound *int
+		if p.pos > start {
+			n, err := strconv.Atoi(p.input[start:p.pos])
+			if err != nil {
+				return p.errorf("invalid index %q", p.input[start:p.pos])
+			}
+			bound = &n
+		}
+		bounds = append(bounds, bound)
+
+		p.skipSpaces()
+		switch {
+		case (separator == "" || separator == ",") && p.consume(","):
+			separator = ","
+			continue
+		case (separator == "" || separator == ":") && p.consume(":"):
+			separator = ":"
+			continue
+		}
+		break
+	}
+
+	if separator == ":" {
+		if len(bounds) > 3 {
+			return p.errorf("a slice takes at most three parts")
+		}
+		selector.kind = "slice"
+		selector.start, selector.end, selector.step = bounds[0], bounds[1], 1
+		if len(bounds) == 3 && bounds[2] != nil {
+			selector.step = *bounds[2]
+		}
+		if selector.step == 0 {
+			return p.errorf("slice step cannot be zero")
+		}
+		return nil
+	}
+
+	selector.kind = "index"
+	for _, bound := range bounds {
+		if bound == nil {
+			return p.errorf("expected an index, name, * or filter")
+		}
+		selector.indexes = append(selector.indexes, *bound)
+	}
+	return nil
+}
+
+// parseString parses a single- or double-quoted string with backslash escapes
+func (p *jsonPathParser) parseString() (string, error) {
+	quote := p.input[p.pos]
+	p.pos++
+	var sb strings.Builder
+	for p.pos < len(p.input) {
+		c := p.input[p.pos]
+		switch {
+		case c == '\\' && p.pos+1 < len(p.input):
+			p.pos++
+			sb.WriteByte(p.input[p.pos])
+		case c == quote:
+			p.pos++
+			return sb.String(), nil
+		default:
+			sb.WriteByte(c)
+		}
+		p.pos++
+	}
+	return "", p.errorf("unterminated string")
+}
+
+func (p *jsonPathParser) parseOr() (*jsonPathFilter, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		p.skipSpaces()
+		if !p.consume("||") {
+			return left, nil
+		}
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &jsonPathFilter{op: "or", left: left, right: right}
+	}
+}
+
+func (p *jsonPathParser) parseAnd() (*jsonPathFilter, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		p.skipSpaces()
+		if !p.consume("&&") {
+			return left, nil
+		}
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &jsonPathFilter{op: "and", left: left, right: right}
+	}
+}
+
+func (p *jsonPathParser) parseUnary() (*jsonPathFilter, error) {
+	p.skipSpaces()
+	switch {
+	case p.peek("!") && !p.peek("!="):
+		p.pos++
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &jsonPathFilter{op: "not", left: operand}, nil
+
+	case p.consume("("):
+		filter, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpaces()
+		if !p.consume(")") {
+			return nil, p.errorf("expected )")
+		}
+		return filter, nil
+	}
+
+	lhs, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpaces()
+	for _, op := range jsonPathComparisons {
+		if p.consume(op) {
+			rhs, err := p.parseOperand()
+			if err != nil {
+				return nil, err
+			}
+			return &jsonPathFilter{op: op, lhs: lhs, rhs: rhs}, nil
+		}
+	}
+	if !lhs.isPath {
+		return nil, p.errorf("expected a comparison")
+	}
+	return &jsonPathFilter{op: "exists", lhs: lhs}, nil
+}
+
+// parseOperand parses a path or a literal string, number, boolean or null
+func (p *jsonPathParser) parseOperand() (jsonPathOperand, error) {
+	p.skipSpaces()
+	if p.pos >= len(p.input) {
+		return jsonPathOperand{}, p.errorf("expected a value")
+	}
+
+	switch c := p.input[p.pos]; {
+	case c == '@' || c == '$':
+		p.pos++
+		path, err := p.parseSelectors()
+		if err != nil {
+			return jsonPathOperand{}, err
+		}
+		return jsonPathOperand{path: path, fromRoot: c == '$', isPath: true}, nil
+
+	case c == '\'' || c == '"':
+		s, err := p.parseString()
+		return jsonPathOperand{literal: s}, err
+	}
+
+	switch {
+	case p.consume("true"):
+		return jsonPathOperand{literal: true}, nil
+	case p.consume("false"):
+		return jsonPathOperand{literal: false}, nil
+	case p.consume("null"):
+		return jsonPathOperand{literal: nil}, nil
+	}
+
+	start := p.pos
+	for p.pos < len(p.input) && strings.ContainsRune("+-.0123456789eE", rune(p.input[p.pos])) {
+		p.pos++
+	}
+	text := p.input[start:p.pos]
+	if _, err := strconv.ParseFloat(text, 64); err != nil {
+		p.pos = start
+		return jsonPathOperand{}, p.errorf("expected a value")
+	}
+	return jsonPathOperand{literal: json.Number(text)}, nil
+}
+
+// evaluate returns the values the path selects from root, in document order
+func (path jsonPath) evaluate(root interface{}) []interface{} {
+	return path.evaluateFrom(root, root)
+}
+
+func (path jsonPath) evaluateFrom(current, root interface{}) []interface{} {
+	values := []interface{}{current}
+	for _, selector := range path {
+		if selector.recursive {
+			var all []interface{}
+			for _, value := range values {
+				all = appendDescendants(all, value)
+			}
+			values = all
+		}
+		var next []interface{}
+		for _, value := range values {
+			next = selector.apply(next, value, root)
+		}
+		values = next
+	}
+	return values
+}
+
+// apply appends the children of value picked by the selector
+func (s jsonPathSelector) apply(out []interface{}, value, root interface{}) []interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		switch s.kind {
+		case "name":
+			for _, name := range s.names {
+				if child, ok := v[name]; ok {
+					out = append(out, child)
+				}
+			}
+		case "wildcard", "filter":
+			for _, key := range sortedKeys(v) {
+				if s.kind == "wildcard" || s.filter.matches(v[key], root) {
+					out = append(out, v[key])
+				}
+			}
+		}
+
+	case []interface{}:
+		switch s.kind {
+		case "wildcard", "filter":
+			for _, child := range v {
+				if s.kind == "wildcard" || s.filter.matches(child, root) {
+					out = append(out, child)
+				}
+			}
+		case "index":
+			for _, i := range s.indexes {
+				if i < 0 {
+					i += len(v)
+				}
+				if i >= 0 && i < len(v) {
+					out = append(out, v[i])
+				}
+			}
+		case "slice":
+			start, end := sliceBounds(s.start, s.end, s.step, len(v))
+			for i := start; (s.step > 0 && i < end) || (s.step < 0 && i > end); i += s.step {
+				out = append(out, v[i])
+			}
+		}
+	}
+	return out
+}
+
+// sliceBounds resolves slice bounds against an array length, following Python
+func sliceBounds(start, end *int, step, length int) (int, int) {
+	resolve := func(bound *int, fallback, low, high int) int {
+		if bound == nil {
+			return fallback
+		}
+		i := *bound
+		if i < 0 {
+			i += length
+		}
+		if i < low {
+			return low
+		}
+		if i > high {
+			return high
+		}
+		return i
+	}
+	if step > 0 {
+		return resolve(start, 0, 0, length), resolve(end, length, 0, length)
+	}
+	return resolve(start, length-1, -1, length-1), resolve(end, -1, -1, length-1)
+}
+
+// appendDescendants appends value and everything nested in it, depth first
+func appendDescendants(out []interface{}, value interface{}) []interface{} {
+	out = append(out, value)
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, key := range sortedKeys(v) {
+			out = appendDescendants(out, v[key])
+		}
+	case []interface{}:
+		for _, child := range v {
+			out = appendDescendants(out, child)
+		}
+	}
+	return out
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// matches evaluates the filter against the current element
+func (f *jsonPathFilter) matches(current, root interface{}) bool {
+	switch f.op {
+	case "or":
+		return f.left.matches(current, root) || f.right.matches(current, root)
+	case "and":
+		return f.left.matches(current, root) && f.right.matches(current, root)
+	case "not":
+		return !f.left.matches(current, root)
+	case "exists":
+		return len(f.lhs.values(current, root)) > 0
+	}
+
+	left, right := f.lhs.values(current, root), f.rhs.values(current, root)
+	if len(left) == 0 || len(right) == 0 {
+		return f.op == "!=" && len(left) != len(right)
+	}
+	return compareJSONPathValues(left[0], right[0], f.op)
+}
+
+func (o jsonPathOperand) values(current, root interface{}) []interface{} {
+	if !o.isPath {
+		return []interface{}{o.literal}
+	}
+	if o.fromRoot {
+		return o.path.evaluateFrom(root, root)
+	}
+	return o.path.evaluateFrom(current, root)
+}
+
+// compareJSONPathValues compares numbers numerically and strings lexically; other
+// values only support equality
+func compareJSONPathValues(a, b interface{}, op string) bool {
+	cmp, ordered := compareNumbers(a, b)
+	if x, ok := a.(string); ok {
+		if y, ok := b.(string); ok {
+			cmp, ordered = strings.Compare(x, y), true
+		}
+	}
+
+	if !ordered {
+		equal := reflect.DeepEqual(a, b)
+		switch op {
+		case "==":
+			return equal
+		case "!=":
+			return !equal
+		}
+		return false
+	}
+
+	switch op {
+	case "==":
+		return cmp == 0
+	case "!=":
+		return cmp != 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	}
+	return false
+}
+
+// jsonPathInput is the value a JSONPath expression is evaluated against: the
+// record's object, or its top-level array or scalar
+func jsonPathInput(record JSONRecord) interface{} {
+	if record.Content != nil {
+		return record.Content
+	}
+	return record.Value
+}
+
+// projectJSONPath replaces each record's content with the sub-values the path
+// selects, keeping its line number and source
+func projectJSONPath(path jsonPath, records []JSONRecord) []JSONRecord {
+	projected := make([]JSONRecord, len(records))
+	for i, record := range records {
+		values := path.evaluate(jsonPathInput(record))
+		raw, err := json.Marshal(values)
+		if err != nil {
+			raw = []byte("[]")
+		}
+		projected[i] = JSONRecord{
+			LineNumber: record.LineNumber,
+			RawJSON:    string(raw),
+			Value:      values,
+			SourceFile: record.SourceFile,
+			SourceLine: record.SourceLine,
+		}
+	}
+	return projected
+}
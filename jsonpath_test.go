@@ -0,0 +1,88 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestJSONPathEvaluate(t *testing.T) {
+	var doc interface{}
+	if err := json.Unmarshal([]byte(`{
+		"store": "main",
+		"items": [
+			{"name": "pen", "price": 2, "tags": ["office"]},
+			{"name": "lamp", "price": 35, "tags": ["home", "light"]},
+			{"name": "desk", "price": 120, "discount": {"price": 99}}
+		],
+		"limit": 30
+	}`), &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{"$.store", `["main"]`},
+		{"$.items[?(@.price>10)].name", `["lamp","desk"]`},
+		{"$.items[?(@.price > 10 && @.price < 100)].name", `["lamp"]`},
+		{"$.items[?(@.discount || @.name == 'pen')].name", `["pen","desk"]`},
+		{"$.items[?(!@.tags)].name", `["desk"]`},
+		{"$.items[?(@.price < $.limit)].name", `["pen"]`},
+		{"$.items[0,-1].name", `["pen","desk"]`},
+		{"$.items[1:].name", `["lamp","desk"]`},
+		{"$.items[::-2].name", `["desk","pen"]`},
+		{"$.items[*].tags[0]", `["office","home"]`},
+		{"$['items'][1]['name','price']", `["lamp",35]`},
+		{"$..price", `[2,35,120,99]`},
+		{"$.missing", `null`},
+	}
+	for _, tt := range tests {
+		path, err := parseJSONPath(tt.path)
+		if err != nil {
+			t.Errorf("parseJSONPath(%q) returned error: %v", tt.path, err)
+			continue
+		}
+		got, _ := json.Marshal(path.evaluate(doc))
+		if string(got) != tt.expected {
+			t.Errorf("%s = %s, expected %s", tt.path, got, tt.expected)
+		}
+	}
+
+	for _, invalid := range []string{"", "items", "$.items[", "$.items[?(@.price >)]", "$.items[::0]", "$.items[?(@.a == 'x)]", "$."} {
+		if _, err := parseJSONPath(invalid); err == nil {
+			t.Errorf("Expected parseJSONPath(%q) to fail", invalid)
+		}
+	}
+}
+
+func TestJSONPathSearch(t *testing.T) {
+	app := newTestApp(t, `{"order":1,"items":[{"sku":"a","price":5},{"sku":"b","price":25}]}
+{"order":2,"items":[{"sku":"c","price":3}]}
+{"order":3,"items":[{"sku":"d","price":12},{"sku":"e","price":40}]}
+`)
+
+	result, err := app.SearchRecords(SearchOptions{Query: "$.items[?(@.price>10)]", UseJSONPath: true, Limit: 10})
+	if err != nil {
+		t.Fatalf("SearchRecords returned error: %v", err)
+	}
+	if result.TotalMatches != 2 || len(result.Records) != 2 {
+		t.Fatalf("Expected 2 matching records, got %+v", result)
+	}
+	if result.Records[0].LineNumber != 1 || result.Records[0].RawJSON != `[{"price":25,"sku":"b"}]` {
+		t.Errorf("Unexpected first projection: %+v", result.Records[0])
+	}
+	if values, ok := result.Records[1].Value.([]interface{}); !ok || len(values) != 2 {
+		t.Errorf("Expected two projected values on line 3, got %+v", result.Records[1])
+	}
+
+	_, err = app.SearchRecords(SearchOptions{Query: "$.items[?(@.price>", UseJSONPath: true})
+	var jsonlErr *JSONLError
+	if !errors.As(err, &jsonlErr) || !errors.Is(jsonlErr.Err, ErrInvalidJSONPath) {
+		t.Errorf("Expected ErrInvalidJSONPath for an invalid path, got %v", err)
+	}
+	if _, err := app.CountMatches(SearchOptions{Query: "items", UseJSONPath: true}, ""); !errors.As(err, &jsonlErr) || !errors.Is(jsonlErr.Err, ErrInvalidJSONPath) {
+		t.Errorf("Expected ErrInvalidJSONPath when counting, got %v", err)
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/wailsapp/wails/v2/pkg/options"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// launchFiles returns the file arguments of a command line, resolved against the
+// directory it was run from. Flags, such as the -psn_ argument macOS adds, are skipped.
+func launchFiles(args []string, workingDir string) []string {
+	var paths []string
+	for _, arg := range args {
+		if arg == "" || strings.HasPrefix(arg, "-") {
+			continue
+		}
+		if !filepath.IsAbs(arg) && workingDir != "" {
+			arg = filepath.Join(workingDir, arg)
+		}
+		paths = append(paths, arg)
+	}
+	return paths
+}
+
+// openLaunchFiles opens files the app was asked to open by the OS: in tabs with a
+// "file:loaded" event once the UI is ready, or at domReady before that
+func (a *App) openLaunchFiles(paths []string) {
+	if len(paths) == 0 {
+		return
+	}
+	a.stateMu.Lock()
+	if !a.uiReady {
+		a.startupFiles = append(a.startupFiles, paths...)
+		a.stateMu.Unlock()
+		return
+	}
+	a.stateMu.Unlock()
+	if _, err := a.OpenFilesInTabs(paths); err == nil {
+		a.emitEvent("file:loaded", a.currentFile)
+	}
+}
+
+// onFileOpen handles a file opened from Finder ("Open With" or a double-click on
+// an associated file) on macOS
+func (a *App) onFileOpen(path string) {
+	a.openLaunchFiles([]string{path})
+}
+
+// onSecondInstanceLaunch handles a launch while the app is already running, as
+// "Open With" does on Windows and Linux: the files are opened in this instance,
+// which is brought to the front
+func (a *App) onSecondInstanceLaunch(data options.SecondInstanceData) {
+	a.openLaunchFiles(launchFiles(data.Args, data.WorkingDirectory))
+	if a.ctx != nil {
+		runtime.WindowUnminimise(a.ctx)
+		runtime.Show(a.ctx)
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/wailsapp/wails/v2/pkg/options"
+)
+
+func TestLaunchFiles(t *testing.T) {
+	dir := t.TempDir()
+	absolute := filepath.Join(dir, "b.jsonl")
+
+	got := launchFiles([]string{"-psn_0_12345", "a.jsonl", absolute, ""}, dir)
+	expected := []string{filepath.Join(dir, "a.jsonl"), absolute}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
+
+func TestOpenLaunchFiles(t *testing.T) {
+	useTempConfigDir(t)
+
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first.jsonl")
+	second := filepath.Join(dir, "second.jsonl")
+	os.WriteFile(first, []byte("{\"n\":1}\n"), 0644)
+	os.WriteFile(second, []byte("{\"n\":2}\n"), 0644)
+
+	// Files opened before the UI is ready wait for domReady
+	app := &App{}
+	app.onFileOpen(first)
+	if app.currentFile != nil || len(app.startupFiles) != 1 {
+		t.Fatalf("Expected the file to be queued, got %v", app.startupFiles)
+	}
+	app.domReady(nil)
+	if app.currentFile == nil || app.currentFile.Path != first || app.startupFiles != nil {
+		t.Fatalf("Expected the queued file to open at domReady, got %+v", app.currentFile)
+	}
+
+	// A second launch opens its arguments in the running instance
+	app.onSecondInstanceLaunch(options.SecondInstanceData{Args: []string{"second.jsonl"}, WorkingDirectory: dir})
+	if docs := app.ListDocuments(); len(docs) != 2 || app.currentFile.Path != second {
+		t.Errorf("Expected the second file in a new active tab, got %+v", docs)
+	}
+}
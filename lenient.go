@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+)
+
+// SetLenientParsing enables repairing almost-JSON lines, as found in hand-edited
+// fixtures, for files loaded afterwards: trailing commas, single-quoted strings,
+// unquoted keys and // or /* */ comments are accepted. Repaired lines are listed
+// in FileStats.RepairedLines and their records are flagged Repaired.
+func (a *App) SetLenientParsing(enabled bool) {
+	a.stateMu.Lock()
+	defer a.stateMu.Unlock()
+	a.lenientParsing = enabled
+}
+
+// GetLenientParsing reports whether almost-JSON lines are repaired while loading
+func (a *App) GetLenientParsing() bool {
+	a.stateMu.RLock()
+	defer a.stateMu.RUnlock()
+	return a.lenientParsing
+}
+
+// parseLenientLine parses a line that is not valid JSON after repairing it,
+// returning the repaired JSON
+func parseLenientLine(line string) (content map[string]interface{}, value interface{}, repaired string, err error) {
+	repaired = repairJSON(line)
+	content, value, err = parseRecordLine(repaired)
+	return content, value, repaired, err
+}
+
+// repairJSON rewrites the lenient syntax accepted by SetLenientParsing as JSON. The
+// result is not guaranteed to be valid; other mistakes are left as they are.
+func repairJSON(line string) string {
+	out := make([]byte, 0, len(line)+8)
+
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case c == '"' || c == '\'':
+			out, i = appendString(out, line, i)
+
+		case c == '/' && i+1 < len(line) && line[i+1] == '/':
+			// A line comment runs to the end of the line
+			i = len(line)
+
+		case c == '/' && i+1 < len(line) && line[i+1] == '*':
+			end := strings.Index(line[i+2:], "*/")
+			if end < 0 {
+				i = len(line)
+			} else {
+				i += end + 3
+			}
+
+		case c == '}' || c == ']':
+			// Drop a trailing comma before the closing bracket
+			if trimmed := bytes.TrimRight(out, " \t"); bytes.HasSuffix(trimmed, []byte(",")) {
+				out = trimmed[:len(trimmed)-1]
+			}
+			out = append(out, c)
+
+		case isIdentifierStart(c):
+			end := i
+			for end < len(line) && isIdentifierPart(line[end]) {
+				end++
+			}
+			word := line[i:end]
+			if next := strings.TrimLeft(line[end:], " \t"); strings.HasPrefix(next, ":") {
+				// Unquoted key
+				out = append(append(append(out, '"'), word...), '"')
+			} else {
+				out = append(out, word...)
+			}
+			i = end - 1
+
+		default:
+			out = append(out, c)
+		}
+	}
+	return string(out)
+}
+
+// appendString appends the string literal starting at line[start] to out as a
+// double-quoted JSON string and returns the index of its closing quote
+func appendString(out []byte, line string, start int) ([]byte, int) {
+	quote := line[start]
+	out = append(out, '"')
+	for i := start + 1; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case c == '\\' && i+1 < len(line):
+			if quote == '\'' && line[i+1] == '\'' {
+				// \' needs no escape in a double-quoted string
+				out = append(out, '\'')
+			} else {
+				out = append(out, c, line[i+1])
+			}
+			i++
+		case c == quote:
+			return append(out, '"'), i
+		case c == '"':
+			// Only reached inside single quotes
+			out = append(out, '\\', '"')
+		default:
+			out = append(out, c)
+		}
+	}
+	return out, len(line)
+}
+
+func isIdentifierStart(c byte) bool {
+	return c == '_' || c == '$' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isIdentifierPart(c byte) bool {
+	return isIdentifierStart(c) || (c >= '0' && c <= '9')
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestRepairJSON(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`{"a": 1, "b": [1, 2,],}`, `{"a": 1, "b": [1, 2]}`},
+		{`{'name': 'it\'s "ok"'}`, `{"name": "it's \"ok\""}`},
+		{`{name: "x", _id: 1e5, ok: true}`, `{"name": "x", "_id": 1e5, "ok": true}`},
+		{`{"a": 1} // trailing comment`, `{"a": 1} `},
+		{`{"a": /* inline */ 1}`, `{"a":  1}`},
+		{`{"url": "http://example.com"}`, `{"url": "http://example.com"}`},
+	}
+
+	for _, test := range tests {
+		if got := repairJSON(test.input); got != test.expected {
+			t.Errorf("repairJSON(%s) = %s, expected %s", test.input, got, test.expected)
+		}
+	}
+}
+
+func TestLoadLenientFile(t *testing.T) {
+	useTempConfigDir(t)
+
+	path := filepath.Join(t.TempDir(), "fixtures.jsonl")
+	os.WriteFile(path, []byte("{\"id\": 1}\n{id: 2, tags: ['a',],}\n{broken\n"), 0644)
+
+	app := &App{}
+	if _, err := app.LoadJSONLFile(path); err != nil {
+		t.Fatalf("LoadJSONLFile returned error: %v", err)
+	}
+	if stats, _ := app.GetFileStats(); stats.ValidRecords != 1 {
+		t.Fatalf("Expected almost-JSON to be invalid by default, got %+v", stats)
+	}
+
+	app.SetLenientParsing(true)
+	if _, err := app.LoadJSONLFile(path); err != nil {
+		t.Fatalf("LoadJSONLFile returned error: %v", err)
+	}
+	stats, err := app.GetFileStats()
+	if err != nil {
+		t.Fatalf("GetFileStats returned error: %v", err)
+	}
+	if stats.ValidRecords != 2 || len(stats.RepairedLines) != 1 || stats.RepairedLines[0] != 2 || len(stats.InvalidLines) != 1 {
+		t.Fatalf("Expected line 2 to be repaired and line 3 invalid, got %+v", stats)
+	}
+
+	record, _ := app.GetRecordByLineNumber(2)
+	if !record.Repaired || record.RawJSON != `{"id": 2, "tags": ["a"]}` || record.Content["id"] != json.Number("2") {
+		t.Errorf("Unexpected repaired record %+v", record)
+	}
+	if first, _ := app.GetRecordByLineNumber(1); first.Repaired {
+		t.Error("Expected valid lines not to be flagged")
+	}
+
+	exported, err := app.GetAllRecords("")
+	if err != nil || len(exported) != 2 || !exported[1].Repaired {
+		t.Errorf("Expected the repaired record to be exported, got %+v (%v)", exported, err)
+	}
+}
+
+func TestLoaderSettingsConcurrentWithLoads(t *testing.T) {
+	useTempConfigDir(t)
+
+	path := filepath.Join(t.TempDir(), "data.jsonl")
+	os.WriteFile(path, []byte("{\"n\":1}\n{n:2,}\n"), 0644)
+
+	// Changing the loading settings while files load is safe under -race; each
+	// load applies the settings read when it started
+	app := NewApp()
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 50; i++ {
+			app.SetLenientParsing(i%2 == 0)
+			app.SetKeepDuplicateKeys(i%2 == 0)
+			app.SetMaxLineSize(0)
+			app.SetParseMode(ParseModeJSONL)
+			app.SetURLRequestOptions(URLRequestOptions{})
+			app.SetComputedFields(nil)
+			app.SetExtractionRules(nil)
+			app.SetAuditLogEnabled(false)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 50; i++ {
+			if file, err := app.LoadJSONLFile(path); err != nil || (file.Records != 1 && file.Records != 2) {
+				t.Errorf("Unexpected load: %+v (%v)", file, err)
+			}
+			app.GetAuditLog(0, 10)
+		}
+	}()
+	wg.Wait()
+}
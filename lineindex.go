@@ -0,0 +1,495 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+)
+
+// diskBackedThreshold is the file size from which LoadJSONLFile keeps only a
+// line-offset index in memory and decodes records from disk on demand
+var diskBackedThreshold int64 = 1 << 30
+
+// Records of a disk-backed file are decoded in pages of indexPageRecords, keeping
+// the maxIndexPages most recently used pages
+const (
+	indexPageRecords = 256
+	maxIndexPages    = 64
+)
+
+// lineIndex locates the valid records of a disk-backed file
+type lineIndex struct {
+	path        string
+	offsets     []int64 // byte offset of each record's line
+	lengths     []int32 // byte length of each record's line, without the newline
+	lineNumbers []int   // 1-based line number of each record
+
+	// derive adds virtual fields to decoded records, see applyDerivedFields
+	derive func(content map[string]interface{})
+
+	keepDuplicates bool // keep every value of repeated keys, see SetKeepDuplicateKeys
+
+	pages decodedPages
+}
+
+// decodedPages is an LRU cache of decoded record pages. The zero value is ready to use.
+type decodedPages struct {
+	mu    sync.Mutex
+	pages map[int][]JSONRecord
+	order []int // least recently used first
+}
+
+func (c *decodedPages) get(page int) ([]JSONRecord, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	records, exists := c.pages[page]
+	if exists {
+		c.touch(page)
+	}
+	return records, exists
+}
+
+func (c *decodedPages) put(page int, records []JSONRecord) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.pages == nil {
+		c.pages = make(map[int][]JSONRecord)
+	}
+	c.pages[page] = records
+	c.touch(page)
+
+	for len(c.order) > maxIndexPages {
+		delete(c.pages, c.order[0])
+		c.order = c.order[1:]
+	}
+}
+
+// touch moves page to the most recently used end; the caller holds mu
+func (c *decodedPages) touch(page int) {
+	for i, p := range c.order {
+		if p == page {
+			c.order = append(c.order[:i], c.order[i+1:]...)
+			break
+		}
+	}
+	c.order = append(c.order, page)
+}
+
+// reset drops every decoded page
+func (c *decodedPages) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pages = nil
+	c.order = nil
+}
+
+// buildLineIndex scans a JSONL file once, recording where each valid record starts
+// and collecting the same statistics as ParseJSONL without keeping any records.
+// Progress is reported every loadProgressLines lines, when the scan can be cancelled.
+func buildLineIndex(ctx context.Context, filePath string, maxLineSize int, progress func(bytesRead int64, records int)) (*lineIndex, *FileStats, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, nil, &JSONLError{
+			Message: "Failed to open file",
+			Err:     ErrFileNotFound,
+		}
+	}
+	defer file.Close()
+
+	fileInfo, err := file.Stat()
+	if err != nil {
+		return nil, nil, &JSONLError{
+			Message: "Failed to get file information",
+			Err:     err,
+		}
+	}
+
+	index := &lineIndex{path: filePath}
+	var invalidLines, longLines []int
+	invalidDetails := make(map[int]InvalidLineDetail)
+	var duplicateKeys []DuplicateKeyLine
+	fieldCounts := make(map[string]int)
+	lineCount := 0
+	truncatedLine := 0
+	var offset int64
+
+	reader := bufio.NewReaderSize(file, 1024*1024)
+	for {
+		line, readErr := reader.ReadBytes('\n')
+		if readErr != nil && readErr != io.EOF {
+			return nil, nil, &JSONLError{
+				Message: "Error reading file",
+				Err:     readErr,
+			}
+		}
+		if len(line) == 0 {
+			break
+		}
+
+		lineCount++
+		if lineCount%loadProgressLines == 0 {
+			if ctx.Err() != nil {
+				return nil, nil, &JSONLError{
+					Message: "Loading was cancelled",
+					Err:     ErrLoadCancelled,
+				}
+			}
+			progress(offset, len(index.offsets))
+		}
+		lineOffset := offset
+		offset += int64(len(line))
+		content := bytes.TrimSpace(line)
+
+		// Skip empty lines; lines over the size limit are reported rather than indexed
+		if len(content) > maxLineSize {
+			invalidLines = append(invalidLines, lineCount)
+			longLines = append(longLines, lineCount)
+			invalidDetails[lineCount] = longLineDetail(lineCount, maxLineSize)
+		} else if len(content) > 0 {
+			// Only top-level keys are decoded, enough to validate the line and count
+			// fields; non-object lines are valid records without fields
+			var fields map[string]json.RawMessage
+			if err := json.Unmarshal(content, &fields); err != nil && !json.Valid(content) {
+				_, _, parseErr := parseRecordLine(string(content))
+				invalidDetails[lineCount] = invalidLineDetail(lineCount, string(bytes.TrimRight(line, "\r\n")), parseErr, jsonErrorOffset(parseErr))
+				// A final line without newline may still be being written
+				if readErr == io.EOF {
+					truncatedLine = lineCount
+				} else {
+					invalidLines = append(invalidLines, lineCount)
+				}
+			} else {
+				for field := range fields {
+					fieldCounts[field]++
+				}
+				if duplicates := findDuplicateKeys(string(content), false); len(duplicates) > 0 {
+					duplicateKeys = append(duplicateKeys, DuplicateKeyLine{Line: lineCount, Keys: duplicateKeyPaths(duplicates)})
+				}
+				index.offsets = append(index.offsets, lineOffset)
+				index.lengths = append(index.lengths, int32(len(bytes.TrimRight(line, "\r\n"))))
+				index.lineNumbers = append(index.lineNumbers, lineCount)
+			}
+		}
+
+		if readErr == io.EOF {
+			break
+		}
+	}
+
+	stats := &FileStats{
+		TotalLines:    lineCount,
+		ValidRecords:  len(index.offsets),
+		InvalidLines:  invalidLines,
+		CommonFields:  selectCommonFields(fieldCounts, len(index.offsets), CommonFieldOptions{}),
+		FileSize:      fileInfo.Size(),
+		LongLines:     longLines,
+		DuplicateKeys: duplicateKeys,
+		TruncatedLine: truncatedLine,
+		RecordSizes:   measureRecordSizes(indexSizes(index)),
+
+		invalidDetails: invalidDetails,
+	}
+
+	return index, stats, nil
+}
+
+// readRecords reads and decodes records [start, end) of the index with a single read
+func (idx *lineIndex) readRecords(file *os.File, start, end int) ([]JSONRecord, error) {
+	base := idx.offsets[start]
+	buf := make([]byte, idx.offsets[end-1]+int64(idx.lengths[end-1])-base)
+	if _, err := file.ReadAt(buf, base); err != nil {
+		return nil, fmt.Errorf("failed to read records: %w", err)
+	}
+
+	records := make([]JSONRecord, 0, end-start)
+	for i := start; i < end; i++ {
+		lineStart := idx.offsets[i] - base
+		line := string(bytes.TrimSpace(buf[lineStart : lineStart+int64(idx.lengths[i])]))
+
+		content, value, err := parseRecordLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d changed on disk: %w", idx.lineNumbers[i], err)
+		}
+		if idx.derive != nil {
+			idx.derive(content)
+		}
+		record := JSONRecord{
+			LineNumber: idx.lineNumbers[i],
+			Content:    content,
+			RawJSON:    line,
+			Value:      value,
+		}
+		if idx.keepDuplicates {
+			detectDuplicateKeys(&record, true)
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// page returns a decoded page of records, reading it from disk when it is not cached
+func (idx *lineIndex) page(page int) ([]JSONRecord, error) {
+	if records, exists := idx.pages.get(page); exists {
+		return records, nil
+	}
+
+	file, err := os.Open(idx.path)
+	if err != nil {
+		return nil, &JSONLError{
+			Message: "Failed to open file",
+			Err:     ErrFileNotFound,
+		}
+	}
+	defer file.Close()
+
+	start := page * indexPageRecords
+	end := start + indexPageRecords
+	if end > len(idx.offsets) {
+		end = len(idx.offsets)
+	}
+	records, err := idx.readRecords(file, start, end)
+	if err != nil {
+		return nil, err
+	}
+	idx.pages.put(page, records)
+	return records, nil
+}
+
+// slice returns records [start, end) of the cache, decoding them from disk for
+// disk-backed files
+func (c *RecordCache) slice(start, end int) ([]JSONRecord, error) {
+	if c.index == nil {
+		if c.derive == nil {
+			return c.records[start:end], nil
+		}
+		records := make([]JSONRecord, end-start)
+		for i, record := range c.records[start:end] {
+			records[i] = c.withDerived(record)
+		}
+		return records, nil
+	}
+
+	records := make([]JSONRecord, 0, end-start)
+	for i := start; i < end; {
+		page, err := c.index.page(i / indexPageRecords)
+		if err != nil {
+			return nil, err
+		}
+		pageStart := i % indexPageRecords
+		pageEnd := len(page)
+		if remaining := end - i; pageEnd-pageStart > remaining {
+			pageEnd = pageStart + remaining
+		}
+		records = append(records, page[pageStart:pageEnd]...)
+		i += pageEnd - pageStart
+	}
+	return records, nil
+}
+
+// at returns the records at the ascending positions, reading runs of consecutive
+// positions as one slice
+func (c *RecordCache) at(positions []int) ([]JSONRecord, error) {
+	records := make([]JSONRecord, 0, len(positions))
+	for start := 0; start < len(positions); {
+		end := start + 1
+		for end < len(positions) && positions[end] == positions[end-1]+1 {
+			end++
+		}
+		run, err := c.slice(positions[start], positions[end-1]+1)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, run...)
+		start = end
+	}
+	return records, nil
+}
+
+// forEach calls fn for every record in order until fn returns false. Disk-backed
+// files are streamed without filling the page cache; a page that can no longer be
+// read, e.g. because the file changed on disk, stops the scan with an error.
+func (c *RecordCache) forEach(fn func(record JSONRecord) bool) error {
+	if c.index == nil {
+		for _, record := range c.records {
+			if !fn(c.withDerived(record)) {
+				return nil
+			}
+		}
+		return nil
+	}
+
+	file, err := os.Open(c.index.path)
+	if err != nil {
+		return &JSONLError{
+			Message: "Failed to open file",
+			Err:     ErrFileNotFound,
+		}
+	}
+	defer file.Close()
+
+	for start := 0; start < c.totalCount; start += indexPageRecords {
+		end := start + indexPageRecords
+		if end > c.totalCount {
+			end = c.totalCount
+		}
+		records, exists := c.index.pages.get(start / indexPageRecords)
+		if !exists {
+			if records, err = c.index.readRecords(file, start, end); err != nil {
+				return &JSONLError{
+					Message: "Failed to read records",
+					Err:     err,
+				}
+			}
+		}
+		for _, record := range records {
+			if !fn(record) {
+				return nil
+			}
+		}
+	}
+	return nil
+}
+
+// fileRecord returns the in-memory record at position i in file order
+func (c *RecordCache) fileRecord(i int) JSONRecord {
+	if c.sortedIndex != nil {
+		return c.withDerived(c.records[c.sortedIndex[i]])
+	}
+	return c.withDerived(c.records[i])
+}
+
+// withDerived returns an in-memory record with its virtual fields added to a copy
+// of its content, leaving the loaded record unchanged
+func (c *RecordCache) withDerived(record JSONRecord) JSONRecord {
+	if c.derive == nil || record.Content == nil {
+		return record
+	}
+	content := make(map[string]interface{}, len(record.Content))
+	for field, value := range record.Content {
+		content[field] = value
+	}
+	c.derive(content)
+	record.Content = content
+	return record
+}
+
+// derivedRecords returns the in-memory records with their virtual fields, copying
+// them only when virtual fields are defined
+func (c *RecordCache) derivedRecords() []JSONRecord {
+	if c.derive == nil {
+		return c.records
+	}
+	records := make([]JSONRecord, len(c.records))
+	for i, record := range c.records {
+		records[i] = c.withDerived(record)
+	}
+	return records
+}
+
+// searchLine returns the position in file order of the first record whose line
+// number satisfies after. Records are read in line order, so this is a binary search.
+func (c *RecordCache) searchLine(after func(lineNumber int) bool) int {
+	count := len(c.records)
+	if c.index != nil {
+		count = len(c.index.lineNumbers)
+	}
+	return sort.Search(count, func(i int) bool {
+		return after(c.lineNumberAt(i))
+	})
+}
+
+// lineRange returns the records from startLine through endLine in file order
+func (c *RecordCache) lineRange(startLine, endLine int) ([]JSONRecord, error) {
+	start := c.searchLine(func(lineNumber int) bool { return lineNumber >= startLine })
+	end := c.searchLine(func(lineNumber int) bool { return lineNumber > endLine })
+	if end < start {
+		end = start
+	}
+
+	if c.index != nil {
+		return c.slice(start, end)
+	}
+	records := make([]JSONRecord, 0, end-start)
+	for i := start; i < end; i++ {
+		records = append(records, c.fileRecord(i))
+	}
+	return records, nil
+}
+
+// lineNumberAt returns the line number of the record at position i in file order
+func (c *RecordCache) lineNumberAt(i int) int {
+	if c.index != nil {
+		return c.index.lineNumbers[i]
+	}
+	return c.fileRecord(i).LineNumber
+}
+
+// recordByLine returns the record at lineNumber
+func (c *RecordCache) recordByLine(lineNumber int) (*JSONRecord, bool) {
+	records, err := c.lineRange(lineNumber, lineNumber)
+	if err != nil || len(records) == 0 {
+		return nil, false
+	}
+	return &records[0], true
+}
+
+// diskBacked reports whether records are decoded from disk on demand
+func (c *RecordCache) diskBacked() bool {
+	return c.index != nil
+}
+
+// LoadJSONLFileIndexed loads a JSONL file in disk-backed mode: only the offset of
+// each record is kept in memory and pages of records are decoded from disk as they
+// are requested. LoadJSONLFile switches to this mode for files of at least 1 GiB.
+func (a *App) LoadJSONLFileIndexed(filePath string) (*JSONLFile, error) {
+	if filePath == "" {
+		return nil, &JSONLError{
+			Message: "File path cannot be empty",
+			Err:     ErrFileNotFound,
+		}
+	}
+
+	fileInfo, err := os.Stat(filePath)
+	if err != nil {
+		return nil, &JSONLError{
+			Message: "File not found or cannot be accessed",
+			Err:     ErrFileNotFound,
+		}
+	}
+
+	if isCompressedFile(filePath) || isTranscodedFile(filePath) {
+		return nil, &JSONLError{
+			Message: "Compressed or UTF-16 files cannot be indexed, load them with LoadJSONLFile",
+			Err:     ErrInvalidJSONL,
+		}
+	}
+
+	ctx, done := a.beginLoad()
+	defer done()
+
+	options := a.loadOptions()
+	progress := LoadProgress{Source: filePath, TotalBytes: fileInfo.Size()}
+	index, stats, err := buildLineIndex(ctx, filePath, effectiveMaxLineSize(options.maxLineSize), func(bytesRead int64, records int) {
+		progress.BytesRead = bytesRead
+		progress.RecordsParsed = records
+		a.emitLoadProgress(progress)
+	})
+	if err != nil {
+		return nil, err
+	}
+	index.keepDuplicates = options.keepDuplicates
+	progress.BytesRead = stats.FileSize
+	progress.RecordsParsed = stats.ValidRecords
+	progress.Done = true
+	a.emitLoadProgress(progress)
+
+	return a.storeLoadedFile(filePath, fileInfo, "", nil, stats, index), nil
+}
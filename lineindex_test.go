@@ -0,0 +1,196 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadJSONLFileIndexed(t *testing.T) {
+	useTempConfigDir(t)
+
+	var sb strings.Builder
+	for i := 0; i < 1000; i++ {
+		fmt.Fprintf(&sb, "{\"n\":%d,\"level\":\"%s\"}\r\n", i, []string{"info", "error"}[i%2])
+		if i == 10 {
+			sb.WriteString("not json\n\n")
+		}
+	}
+	sb.WriteString(`{"n":`) // still being written
+	path := filepath.Join(t.TempDir(), "big.jsonl")
+	os.WriteFile(path, []byte(sb.String()), 0644)
+
+	app := &App{}
+	file, err := app.LoadJSONLFileIndexed(path)
+	if err != nil {
+		t.Fatalf("LoadJSONLFileIndexed returned error: %v", err)
+	}
+	if file.Records != 1000 || !app.cache.diskBacked() || app.cache.records != nil {
+		t.Fatalf("Expected 1000 disk-backed records, got %d", file.Records)
+	}
+	if stats := app.cache.stats; len(stats.InvalidLines) != 1 || stats.InvalidLines[0] != 12 || stats.TotalLines != 1003 || stats.TruncatedLine != 1003 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+
+	// A page spanning two decoded pages
+	page, err := app.GetRecords(250, 10)
+	if err != nil {
+		t.Fatalf("GetRecords returned error: %v", err)
+	}
+	if len(page.Records) != 10 || page.Records[0].Content["n"] != json.Number("250") || page.Records[9].Content["n"] != json.Number("259") {
+		t.Fatalf("Unexpected page: %+v", page.Records)
+	}
+	if page.Records[0].RawJSON != `{"n":250,"level":"info"}` || page.Records[0].LineNumber != 253 {
+		t.Errorf("Unexpected record: %+v", page.Records[0])
+	}
+
+	record, err := app.GetRecordByLineNumber(14)
+	if err != nil || record.Content["n"] != json.Number("11") {
+		t.Errorf("Expected record 11 after the invalid line, got %+v (%v)", record, err)
+	}
+	if _, err := app.GetRecordByLineNumber(12); err == nil {
+		t.Error("Expected an error for the invalid line")
+	}
+
+	result, err := app.SearchRecords(SearchOptions{Query: "level:error", UseLucene: true, Limit: 5})
+	if err != nil {
+		t.Fatalf("SearchRecords returned error: %v", err)
+	}
+	if result.TotalMatches != 500 || len(result.Records) != 5 {
+		t.Errorf("Expected 500 matches, got %d", result.TotalMatches)
+	}
+
+	rangeRecords, _ := app.GetRecordRange(1, 3)
+	if len(rangeRecords) != 3 {
+		t.Errorf("Expected 3 records in range, got %d", len(rangeRecords))
+	}
+}
+
+func TestLoadJSONLFileSwitchesToIndex(t *testing.T) {
+	useTempConfigDir(t)
+
+	previous := diskBackedThreshold
+	diskBackedThreshold = 10
+	defer func() { diskBackedThreshold = previous }()
+
+	path := filepath.Join(t.TempDir(), "data.jsonl")
+	os.WriteFile(path, []byte("{\"n\":1}\n{\"n\":2}\n"), 0644)
+
+	app := &App{}
+	if _, err := app.LoadJSONLFile(path); err != nil {
+		t.Fatalf("LoadJSONLFile returned error: %v", err)
+	}
+	if !app.cache.diskBacked() {
+		t.Error("Expected a file above the threshold to be disk-backed")
+	}
+}
+
+func TestDecodedPagesEvictsLeastRecentlyUsed(t *testing.T) {
+	var pages decodedPages
+	for i := 0; i <= maxIndexPages; i++ {
+		pages.put(i, []JSONRecord{})
+		if i == 0 {
+			continue
+		}
+		// Keep page 0 in use
+		pages.get(0)
+	}
+
+	if _, exists := pages.get(0); !exists {
+		t.Error("Expected the recently used page to be kept")
+	}
+	if _, exists := pages.get(1); exists {
+		t.Error("Expected the least recently used page to be evicted")
+	}
+}
+
+func TestIndexedDerivedFields(t *testing.T) {
+	useTempConfigDir(t)
+
+	path := filepath.Join(t.TempDir(), "data.jsonl")
+	os.WriteFile(path, []byte("{\"a\":2,\"b\":3}\n"), 0644)
+
+	app := &App{}
+	app.LoadJSONLFileIndexed(path)
+	if err := app.SetComputedFields([]ComputedField{{Name: "sum", Expression: "a + b"}}); err != nil {
+		t.Fatalf("SetComputedFields returned error: %v", err)
+	}
+
+	page, _ := app.GetRecords(0, 1)
+	if page.Records[0].Content["sum"] != float64(5) {
+		t.Errorf("Expected the computed field on decoded records, got %+v", page.Records[0].Content)
+	}
+}
+
+func TestIndexedScanReportsUnreadablePages(t *testing.T) {
+	useTempConfigDir(t)
+
+	var sb strings.Builder
+	for i := 0; i < 600; i++ {
+		fmt.Fprintf(&sb, "{\"n\":%d}\n", i)
+	}
+	path := filepath.Join(t.TempDir(), "big.jsonl")
+	os.WriteFile(path, []byte(sb.String()), 0644)
+
+	app := &App{}
+	if _, err := app.LoadJSONLFileIndexed(path); err != nil {
+		t.Fatalf("LoadJSONLFileIndexed returned error: %v", err)
+	}
+
+	// No page parses once the file is overwritten
+	os.WriteFile(path, []byte(strings.Repeat("x", sb.Len())), 0644)
+	app.cache.index.pages.reset()
+
+	visited := 0
+	if err := app.cache.forEach(func(JSONRecord) bool { visited++; return true }); err == nil {
+		t.Errorf("Expected an error for the unreadable pages, visited %d records", visited)
+	}
+	if _, err := app.GetFieldCatalog(nil); err == nil {
+		t.Error("Expected GetFieldCatalog to fail instead of returning a partial catalog")
+	}
+}
+
+func TestRecordLookupByLine(t *testing.T) {
+	app := newTestApp(t, "{\"n\":3}\nbad\n{\"n\":1}\n\n{\"n\":2}\n{\"n\":5}\n")
+	lines := func(records []JSONRecord) string {
+		var numbers []int
+		for _, record := range records {
+			numbers = append(numbers, record.LineNumber)
+		}
+		return fmt.Sprint(numbers)
+	}
+
+	for _, sorted := range []bool{false, true} {
+		if sorted {
+			// Lookups stay in line order after the records are reordered
+			if _, err := app.SortRecords([]SortKey{{Field: "n"}}); err != nil {
+				t.Fatalf("SortRecords returned error: %v", err)
+			}
+		}
+
+		record, err := app.GetRecordByLineNumber(5)
+		if err != nil || record.Content["n"] != json.Number("2") {
+			t.Errorf("Expected n=2 at line 5, got %+v (%v)", record, err)
+		}
+		if _, err := app.GetRecordByLineNumber(2); err == nil {
+			t.Error("Expected an error for the invalid line")
+		}
+		if _, err := app.GetRecordByLineNumber(7); err == nil {
+			t.Error("Expected an error past the last line")
+		}
+
+		if records, _ := app.GetRecordRange(2, 5); lines(records) != "[3 5]" {
+			t.Errorf("Expected lines [3 5] in range, got %s", lines(records))
+		}
+		if records, _ := app.GetRecordRange(1, math.MaxInt); lines(records) != "[1 3 5 6]" {
+			t.Errorf("Expected every line in an open range, got %s", lines(records))
+		}
+		if records, _ := app.GetRecordRange(4, 4); len(records) != 0 {
+			t.Errorf("Expected an empty range, got %s", lines(records))
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Line length limits; lines longer than the limit are reported instead of loaded
+const (
+	defaultMaxLineSize = 16 * 1024 * 1024
+	minMaxLineSize     = 1024
+	maxMaxLineSize     = 1024 * 1024 * 1024
+)
+
+// ErrInvalidLineSize is returned for line size limits outside the supported range
+var ErrInvalidLineSize = errors.New("invalid max line size")
+
+// lineReader reads lines of any length up to a limit, tracking how far it has read
+type lineReader struct {
+	reader *bufio.Reader
+	limit  int
+	lines  int
+	bytes  int64
+	onLine func(bytesRead int64, lines int) // called after each newline-terminated line
+}
+
+func newLineReader(r io.Reader, limit int) *lineReader {
+	if limit <= 0 {
+		limit = defaultMaxLineSize
+	}
+	return &lineReader{reader: bufio.NewReaderSize(r, 64*1024), limit: limit}
+}
+
+// next returns the next line without its line ending. A line longer than the limit
+// is skipped to its end and returned as nil with tooLong set. It returns io.EOF
+// after the last line.
+func (r *lineReader) next() (line []byte, tooLong bool, err error) {
+	var buf []byte
+	read := false
+	for {
+		chunk, readErr := r.reader.ReadSlice('\n')
+		r.bytes += int64(len(chunk))
+		read = read || len(chunk) > 0
+
+		if !tooLong {
+			content := bytes.TrimRight(chunk, "\r\n")
+			if len(buf)+len(content) > r.limit {
+				tooLong = true
+				buf = nil
+			} else {
+				buf = append(buf, content...)
+			}
+		}
+
+		switch {
+		case readErr == bufio.ErrBufferFull:
+			continue
+		case readErr == nil:
+			r.lines++
+			if r.onLine != nil {
+				r.onLine(r.bytes, r.lines)
+			}
+			return buf, tooLong, nil
+		case readErr == io.EOF && read:
+			// Final line without newline
+			r.lines++
+			return buf, tooLong, nil
+		default:
+			return nil, false, readErr
+		}
+	}
+}
+
+// SetMaxLineSize sets the longest line, in bytes, loaded as a record (0 restores
+// the 16 MB default). Longer lines are listed in FileStats.LongLines and counted as
+// invalid instead of aborting the load. It applies to files loaded afterwards.
+func (a *App) SetMaxLineSize(size int) error {
+	if size != 0 && (size < minMaxLineSize || size > maxMaxLineSize) {
+		return &JSONLError{
+			Message: fmt.Sprintf("Max line size must be between %d and %d bytes", minMaxLineSize, maxMaxLineSize),
+			Err:     ErrInvalidLineSize,
+		}
+	}
+	a.stateMu.Lock()
+	defer a.stateMu.Unlock()
+	a.maxLineSize = size
+	return nil
+}
+
+// GetMaxLineSize returns the longest line, in bytes, loaded as a record
+func (a *App) GetMaxLineSize() int {
+	a.stateMu.RLock()
+	defer a.stateMu.RUnlock()
+	return effectiveMaxLineSize(a.maxLineSize)
+}
+
+// effectiveMaxLineSize resolves a configured max line size, 0 for the default
+func effectiveMaxLineSize(size int) int {
+	if size <= 0 {
+		return defaultMaxLineSize
+	}
+	return size
+}
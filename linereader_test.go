@@ -0,0 +1,63 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLineReaderLimit(t *testing.T) {
+	long := "{\"data\":\"" + strings.Repeat("x", 200*1024) + "\"}"
+	reader := newLineReader(strings.NewReader("{\"a\":1}\r\n"+long+"\n{\"b\":2}"), 100*1024)
+
+	line, tooLong, err := reader.next()
+	if err != nil || tooLong || string(line) != `{"a":1}` {
+		t.Fatalf("Unexpected first line %q (%v, %v)", line, tooLong, err)
+	}
+	if line, tooLong, _ = reader.next(); !tooLong || line != nil {
+		t.Fatalf("Expected the long line to be reported as too long")
+	}
+	if line, _, _ = reader.next(); string(line) != `{"b":2}` {
+		t.Fatalf("Expected the line after the long one, got %q", line)
+	}
+	if _, _, err = reader.next(); err != io.EOF {
+		t.Errorf("Expected io.EOF, got %v", err)
+	}
+}
+
+func TestLoadWithLongLines(t *testing.T) {
+	useTempConfigDir(t)
+
+	// Larger than the 64KB bufio.Scanner default
+	big := "{\"data\":\"" + strings.Repeat("x", 1024*1024) + "\"}"
+	path := filepath.Join(t.TempDir(), "big.jsonl")
+	os.WriteFile(path, []byte("{\"n\":1}\n"+big+"\n{\"n\":2}\n"), 0644)
+
+	app := &App{}
+	if _, err := app.LoadJSONLFile(path); err != nil {
+		t.Fatalf("LoadJSONLFile returned error: %v", err)
+	}
+	if app.cache.totalCount != 3 {
+		t.Errorf("Expected the 1MB record to load with the default limit, got %d records", app.cache.totalCount)
+	}
+
+	if err := app.SetMaxLineSize(64 * 1024); err != nil {
+		t.Fatalf("SetMaxLineSize returned error: %v", err)
+	}
+	if _, err := app.LoadJSONLFile(path); err != nil {
+		t.Fatalf("LoadJSONLFile returned error: %v", err)
+	}
+	stats := app.cache.stats
+	if app.cache.totalCount != 2 || len(stats.LongLines) != 1 || stats.LongLines[0] != 2 || len(stats.InvalidLines) != 1 {
+		t.Errorf("Expected line 2 to be reported as too long, got %+v", stats.LongLines)
+	}
+
+	if err := app.SetMaxLineSize(10); err == nil {
+		t.Error("Expected an error for a too small limit")
+	}
+	if err := app.SetMaxLineSize(0); err != nil || app.GetMaxLineSize() != defaultMaxLineSize {
+		t.Errorf("Expected 0 to restore the default, got %d (%v)", app.GetMaxLineSize(), err)
+	}
+}
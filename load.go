@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrLoadCancelled is returned by loads aborted through CancelLoad
+var ErrLoadCancelled = errors.New("load cancelled")
+
+// Progress of a load is reported every loadProgressLines parsed lines, or every
+// loadProgressInterval downloaded bytes
+const (
+	loadProgressLines    = 10000
+	loadProgressInterval = 1024 * 1024
+)
+
+// LoadProgress is emitted as the "load:progress" event while a source is read
+type LoadProgress struct {
+	Source        string  `json:"source"`
+	BytesRead     int64   `json:"bytesRead"`
+	TotalBytes    int64   `json:"totalBytes"` // -1 when the size is unknown
+	RecordsParsed int     `json:"recordsParsed"`
+	Percent       float64 `json:"percent"` // 0-100, 0 while the size is unknown
+	Done          bool    `json:"done"`
+}
+
+// withPercent fills in Percent from the bytes read so far
+func (p LoadProgress) withPercent() LoadProgress {
+	if p.TotalBytes > 0 {
+		p.Percent = float64(p.BytesRead) * 100 / float64(p.TotalBytes)
+		if p.Percent > 100 {
+			p.Percent = 100
+		}
+	}
+	if p.Done {
+		p.Percent = 100
+	}
+	return p
+}
+
+// emitLoadProgress emits a "load:progress" event
+func (a *App) emitLoadProgress(progress LoadProgress) {
+	a.emitEvent("load:progress", progress.withPercent())
+}
+
+// beginLoad starts a cancellable load, cancelling any load still running, and
+// returns its context and a function to call once it finishes
+func (a *App) beginLoad() (context.Context, func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	a.loadMu.Lock()
+	if a.loadCancel != nil {
+		a.loadCancel()
+	}
+	a.loadCancel = cancel
+	a.loadSeq++
+	seq := a.loadSeq
+	a.loadMu.Unlock()
+
+	return ctx, func() {
+		cancel()
+		a.loadMu.Lock()
+		defer a.loadMu.Unlock()
+		// A newer load may have replaced this one
+		if a.loadSeq == seq {
+			a.loadCancel = nil
+		}
+	}
+}
+
+// CancelLoad aborts the file, URL or S3 load in progress; the load returns
+// ErrLoadCancelled and the previously loaded file stays current. It reports whether
+// a load was running.
+func (a *App) CancelLoad() bool {
+	a.loadMu.Lock()
+	defer a.loadMu.Unlock()
+
+	if a.loadCancel == nil {
+		return false
+	}
+	a.loadCancel()
+	a.loadCancel = nil
+	return true
+}
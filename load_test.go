@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseJSONLCancelled(t *testing.T) {
+	content := strings.Repeat("{\"n\":1}\n", 3*loadProgressLines)
+
+	var reports []int
+	parser := NewJSONLReaderParser(strings.NewReader(content))
+	parser.progress = func(bytesRead int64, records int) {
+		reports = append(reports, records)
+	}
+	if _, stats, err := parser.ParseJSONL(); err != nil || stats.ValidRecords != 3*loadProgressLines {
+		t.Fatalf("Expected a full parse, got %v", err)
+	}
+	// Records are counted as workers finish, so only the number of reports is fixed
+	if len(reports) != 3 || reports[2] < reports[0] {
+		t.Errorf("Expected a progress report every %d lines, got %v", loadProgressLines, reports)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	parser = NewJSONLReaderParser(strings.NewReader(content))
+	parser.ctx = ctx
+	_, _, err := parser.ParseJSONL()
+	var jsonlErr *JSONLError
+	if !errors.As(err, &jsonlErr) || !errors.Is(jsonlErr.Err, ErrLoadCancelled) {
+		t.Errorf("Expected ErrLoadCancelled, got %v", err)
+	}
+}
+
+func TestCancelLoad(t *testing.T) {
+	app := &App{}
+	if app.CancelLoad() {
+		t.Error("Expected no load to cancel")
+	}
+
+	first, firstDone := app.beginLoad()
+	second, secondDone := app.beginLoad()
+	if first.Err() == nil {
+		t.Error("Expected a new load to cancel the previous one")
+	}
+	firstDone()
+
+	// The finished first load must not forget the running second one
+	if !app.CancelLoad() || second.Err() == nil {
+		t.Error("Expected CancelLoad to cancel the running load")
+	}
+	secondDone()
+	if app.CancelLoad() {
+		t.Error("Expected no load to cancel after it finished")
+	}
+}
+
+func TestLoadProgressPercent(t *testing.T) {
+	if p := (LoadProgress{BytesRead: 25, TotalBytes: 200}).withPercent(); p.Percent != 12.5 {
+		t.Errorf("Expected 12.5%%, got %v", p.Percent)
+	}
+	if p := (LoadProgress{BytesRead: 25, TotalBytes: -1}).withPercent(); p.Percent != 0 {
+		t.Errorf("Expected 0%% for unknown sizes, got %v", p.Percent)
+	}
+	if p := (LoadProgress{TotalBytes: -1, Done: true}).withPercent(); p.Percent != 100 {
+		t.Errorf("Expected 100%% when done, got %v", p.Percent)
+	}
+}
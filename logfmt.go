@@ -0,0 +1,132 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// errNoLogfmtPairs is returned for lines without any key=value pair
+var errNoLogfmtPairs = errors.New("no key=value pairs")
+
+// parseLogfmtLine converts a logfmt line such as `level=error msg="boom" dur=4ms`.
+// Quoted values stay strings; bare numbers and true/false become numbers and
+// booleans; a key without value is true. A repeated key keeps its last value.
+func parseLogfmtLine(line string) (map[string]interface{}, string, error) {
+	content := make(map[string]interface{})
+	var keys []string
+	hasPair := false
+
+	for i := 0; i < len(line); {
+		if line[i] == ' ' || line[i] == '\t' {
+			i++
+			continue
+		}
+
+		start := i
+		for i < len(line) && line[i] != '=' && line[i] != ' ' && line[i] != '\t' && line[i] != '"' {
+			i++
+		}
+		key := line[start:i]
+		if key == "" {
+			return nil, "", errors.New("invalid key")
+		}
+
+		var value interface{} = true
+		if i < len(line) && line[i] == '=' {
+			hasPair = true
+			i++
+			if i < len(line) && line[i] == '"' {
+				end := closingQuote(line, i)
+				if end < 0 {
+					return nil, "", errors.New("unterminated quoted value")
+				}
+				unquoted, err := strconv.Unquote(line[i : end+1])
+				if err != nil {
+					unquoted = line[i+1 : end]
+				}
+				value = unquoted
+				i = end + 1
+			} else {
+				start = i
+				for i < len(line) && line[i] != ' ' && line[i] != '\t' {
+					i++
+				}
+				value = logfmtValue(line[start:i])
+			}
+		}
+
+		if _, exists := content[key]; !exists {
+			keys = append(keys, key)
+		}
+		content[key] = value
+	}
+
+	if !hasPair {
+		return nil, "", errNoLogfmtPairs
+	}
+	rawJSON, err := orderedJSON(keys, content)
+	if err != nil {
+		return nil, "", err
+	}
+	return content, rawJSON, nil
+}
+
+// closingQuote returns the index of the quote ending the string opened at
+// line[start], or -1
+func closingQuote(line string, start int) int {
+	for i := start + 1; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return -1
+}
+
+// logfmtValue types an unquoted logfmt value
+func logfmtValue(raw string) interface{} {
+	switch raw {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+	if number, err := strconv.ParseFloat(raw, 64); err == nil && !strings.ContainsAny(raw, "xXpPnN_") {
+		return number
+	}
+	return raw
+}
+
+// orderedJSON encodes content as a JSON object with its fields in the given order
+func orderedJSON(keys []string, content map[string]interface{}) (string, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		keyJSON, err := json.Marshal(key)
+		if err != nil {
+			return "", err
+		}
+		valueJSON, err := json.Marshal(content[key])
+		if err != nil {
+			return "", err
+		}
+		buf.Write(keyJSON)
+		buf.WriteByte(':')
+		buf.Write(valueJSON)
+	}
+	buf.WriteByte('}')
+	return buf.String(), nil
+}
+
+// LoadLogfmtFile loads a logfmt log file, converting each key=value line to a record
+func (a *App) LoadLogfmtFile(filePath string) (*JSONLFile, error) {
+	return a.LoadLogFile(filePath, ParseModeLogfmt)
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseLogfmtLine(t *testing.T) {
+	content, rawJSON, err := parseLogfmtLine(`level=error msg="boom \"here\"" dur=4ms count=3 ok=true cached empty= level=warn`)
+	if err != nil {
+		t.Fatalf("parseLogfmtLine returned error: %v", err)
+	}
+	expected := `{"level":"warn","msg":"boom \"here\"","dur":"4ms","count":3,"ok":true,"cached":true,"empty":""}`
+	if rawJSON != expected {
+		t.Errorf("Expected %s, got %s", expected, rawJSON)
+	}
+	if content["count"] != float64(3) || content["dur"] != "4ms" {
+		t.Errorf("Unexpected content %+v", content)
+	}
+
+	for _, line := range []string{"just some text", `msg="unterminated`, `="no key"`} {
+		if _, _, err := parseLogfmtLine(line); err == nil {
+			t.Errorf("Expected an error for %q", line)
+		}
+	}
+}
+
+func TestLoadLogfmtFile(t *testing.T) {
+	useTempConfigDir(t)
+
+	path := filepath.Join(t.TempDir(), "app.log")
+	os.WriteFile(path, []byte("ts=2024-01-01T00:00:00Z level=info msg=started\nnot logfmt\nts=2024-01-01T00:00:01Z level=error msg=\"boom\"\n"), 0644)
+
+	app := &App{}
+	file, err := app.LoadLogfmtFile(path)
+	if err != nil {
+		t.Fatalf("LoadLogfmtFile returned error: %v", err)
+	}
+	if file.Records != 2 || file.Format != ParseModeLogfmt {
+		t.Fatalf("Expected 2 logfmt records, got %+v", file)
+	}
+	if stats := app.cache.stats; len(stats.InvalidLines) != 1 || stats.InvalidLines[0] != 2 {
+		t.Errorf("Expected line 2 to be invalid, got %v", stats.InvalidLines)
+	}
+
+	result, err := app.SearchRecords(SearchOptions{Query: "level:error", UseLucene: true, Limit: 10})
+	if err != nil || result.TotalMatches != 1 || result.Records[0].LineNumber != 3 {
+		t.Errorf("Expected to find the error line, got %+v (%v)", result, err)
+	}
+
+	// Reloading keeps the format the file was opened in
+	os.WriteFile(path, []byte("level=debug\n"), 0644)
+	os.Chtimes(path, file.ModifiedAt.Add(1e9), file.ModifiedAt.Add(1e9))
+	if file, err := app.ReloadCurrentFile(); err != nil || file.Records != 1 || file.Format != ParseModeLogfmt {
+		t.Errorf("Expected a logfmt reload, got %+v (%v)", file, err)
+	}
+}
@@ -0,0 +1,287 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// lineConverter turns a non-empty line of another log format into record content
+// and its JSON text, with fields in line order
+type lineConverter func(line string) (content map[string]interface{}, rawJSON string, err error)
+
+// LogFormat describes a log format that can be converted to records
+type LogFormat struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Example     string `json:"example"`
+	convert     lineConverter
+}
+
+// logFormats lists the line converters in the order they are tried when a log
+// format is detected; add a converter here to support another format
+var logFormats = []LogFormat{
+	{
+		Name:        ParseModeSyslog,
+		Description: "RFC 5424 syslog",
+		Example:     `<34>1 2024-01-01T12:00:00Z host app 1234 ID47 - message`,
+		convert:     parseSyslogLine,
+	},
+	{
+		Name:        ParseModeAccessCombined,
+		Description: "Nginx/Apache combined access log",
+		Example:     `127.0.0.1 - - [01/Jan/2024:12:00:00 +0000] "GET / HTTP/1.1" 200 512 "-" "curl/8.0"`,
+		convert:     parseCombinedAccessLine,
+	},
+	{
+		Name:        ParseModeAccessCommon,
+		Description: "Nginx/Apache common access log",
+		Example:     `127.0.0.1 - - [01/Jan/2024:12:00:00 +0000] "GET / HTTP/1.1" 200 512`,
+		convert:     parseCommonAccessLine,
+	},
+	{
+		Name:        ParseModeLogfmt,
+		Description: "logfmt key=value pairs",
+		Example:     `level=error msg="boom" dur=4ms`,
+		convert:     parseLogfmtLine,
+	},
+}
+
+// lineConverters maps the parse modes that convert lines to their converter
+var lineConverters = func() map[string]lineConverter {
+	converters := make(map[string]lineConverter, len(logFormats))
+	for _, format := range logFormats {
+		converters[format.Name] = format.convert
+	}
+	return converters
+}()
+
+// errLineFormat is returned for lines that do not match a log format
+var errLineFormat = errors.New("line does not match the log format")
+
+// GetLogFormats returns the log formats a .log file can be opened as
+func (a *App) GetLogFormats() []LogFormat {
+	return logFormats
+}
+
+// LoadLogFile loads a log file, converting each line to a record in the given
+// format (see GetLogFormats). An empty format is detected from the first lines,
+// falling back to JSONL.
+func (a *App) LoadLogFile(filePath, format string) (*JSONLFile, error) {
+	if format == "" {
+		format = detectLogFormat(filePath)
+	} else if lineConverters[format] == nil {
+		return nil, &JSONLError{
+			Message: fmt.Sprintf("Unknown log format %q", format),
+			Err:     ErrInvalidParseMode,
+		}
+	}
+	return a.loadFile(filePath, format)
+}
+
+// logDetectionLines is the number of leading non-empty lines a detected log format
+// must convert
+const logDetectionLines = 5
+
+// detectLogFormat returns the first log format converting every sampled line of a
+// file, or "" when none does
+func detectLogFormat(filePath string) string {
+	source, err := openJSONLSource(filePath)
+	if err != nil {
+		return ""
+	}
+	defer source.Close()
+
+	var sample []string
+	lines := newLineReader(source, 0)
+	for len(sample) < logDetectionLines {
+		raw, tooLong, err := lines.next()
+		if err != nil {
+			break
+		}
+		if line := strings.TrimSpace(string(raw)); line != "" && !tooLong {
+			sample = append(sample, line)
+		}
+	}
+	if len(sample) == 0 {
+		return ""
+	}
+	if allLinesMatch(sample, func(line string) error {
+		_, _, err := parseRecordLine(line)
+		return err
+	}) {
+		return ""
+	}
+
+	for _, format := range logFormats {
+		convert := format.convert
+		if allLinesMatch(sample, func(line string) error {
+			_, _, err := convert(line)
+			return err
+		}) {
+			return format.Name
+		}
+	}
+	return ""
+}
+
+// allLinesMatch reports whether parse accepts every line
+func allLinesMatch(lines []string, parse func(line string) error) bool {
+	for _, line := range lines {
+		if parse(line) != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// logRecord collects converted fields in line order
+type logRecord struct {
+	keys    []string
+	content map[string]interface{}
+}
+
+func newLogRecord() *logRecord {
+	return &logRecord{content: make(map[string]interface{})}
+}
+
+// set adds a field; "-" and empty values mean absent in these formats
+func (r *logRecord) set(key string, value interface{}) {
+	if s, ok := value.(string); ok && (s == "" || s == "-") {
+		return
+	}
+	if _, exists := r.content[key]; !exists {
+		r.keys = append(r.keys, key)
+	}
+	r.content[key] = value
+}
+
+func (r *logRecord) result() (map[string]interface{}, string, error) {
+	rawJSON, err := orderedJSON(r.keys, r.content)
+	if err != nil {
+		return nil, "", err
+	}
+	return r.content, rawJSON, nil
+}
+
+// syslogPattern matches an RFC 5424 message: PRI, version, timestamp, hostname,
+// app name, process ID, message ID, structured data and message
+var syslogPattern = regexp.MustCompile(`^<(\d{1,3})>(\d{1,2}) (\S+) (\S+) (\S+) (\S+) (\S+) (-|(?:\[(?:[^\]\\"]|\\.|"(?:[^"\\]|\\.)*")*\])+)(?: (.*))?$`)
+
+// sdElementPattern and sdParamPattern split structured data into its elements and parameters
+var (
+	sdElementPattern = regexp.MustCompile(`\[([^\s\]]+)((?:\s+[^\s=\]]+="(?:[^"\\]|\\.)*")*)\]`)
+	sdParamPattern   = regexp.MustCompile(`([^\s=\]]+)="((?:[^"\\]|\\.)*)"`)
+)
+
+// syslogSeverities are the RFC 5424 severity names by code
+var syslogSeverities = []string{"emerg", "alert", "crit", "err", "warning", "notice", "info", "debug"}
+
+// parseSyslogLine converts an RFC 5424 syslog line. Structured data becomes an
+// object of SD-IDs holding their parameters.
+func parseSyslogLine(line string) (map[string]interface{}, string, error) {
+	match := syslogPattern.FindStringSubmatch(line)
+	if match == nil {
+		return nil, "", errLineFormat
+	}
+	priority, _ := strconv.Atoi(match[1])
+	if priority > 191 {
+		return nil, "", errLineFormat
+	}
+	version, _ := strconv.Atoi(match[2])
+
+	record := newLogRecord()
+	record.set("priority", float64(priority))
+	record.set("facility", float64(priority/8))
+	record.set("severity", syslogSeverities[priority%8])
+	record.set("version", float64(version))
+	record.set("timestamp", match[3])
+	record.set("hostname", match[4])
+	record.set("appName", match[5])
+	record.set("procId", match[6])
+	record.set("msgId", match[7])
+
+	if match[8] != "-" {
+		structured := make(map[string]interface{})
+		for _, element := range sdElementPattern.FindAllStringSubmatch(match[8], -1) {
+			params := make(map[string]interface{})
+			for _, param := range sdParamPattern.FindAllStringSubmatch(element[2], -1) {
+				params[param[1]] = unescapeSDValue(param[2])
+			}
+			structured[element[1]] = params
+		}
+		record.set("structuredData", structured)
+	}
+	record.set("message", strings.TrimPrefix(match[9], "\ufeff"))
+	return record.result()
+}
+
+// unescapeSDValue removes the escaping of ", \ and ] in a structured data value
+func unescapeSDValue(value string) string {
+	return strings.NewReplacer(`\"`, `"`, `\\`, `\`, `\]`, `]`).Replace(value)
+}
+
+// commonLogPattern matches the common log format; combinedLogPattern adds the
+// referer and user agent
+var (
+	commonLogPattern   = regexp.MustCompile(`^(\S+) (\S+) (\S+) \[([^\]]+)\] "((?:[^"\\]|\\.)*)" (\d{3}) (\d+|-)`)
+	combinedLogPattern = regexp.MustCompile(commonLogPattern.String() + ` "((?:[^"\\]|\\.)*)" "((?:[^"\\]|\\.)*)"`)
+)
+
+// accessLogTimeLayout is the timestamp layout of access logs
+const accessLogTimeLayout = "02/Jan/2006:15:04:05 -0700"
+
+// parseCommonAccessLine converts a common log format line
+func parseCommonAccessLine(line string) (map[string]interface{}, string, error) {
+	return parseAccessLine(line, false)
+}
+
+// parseCombinedAccessLine converts a combined log format line
+func parseCombinedAccessLine(line string) (map[string]interface{}, string, error) {
+	return parseAccessLine(line, true)
+}
+
+// parseAccessLine converts an access log line. The time is rewritten as RFC 3339
+// and the request line is split into method, path and protocol.
+func parseAccessLine(line string, combined bool) (map[string]interface{}, string, error) {
+	pattern := commonLogPattern
+	if combined {
+		pattern = combinedLogPattern
+	}
+	match := pattern.FindStringSubmatch(line)
+	if match == nil {
+		return nil, "", errLineFormat
+	}
+
+	record := newLogRecord()
+	record.set("remoteHost", match[1])
+	record.set("ident", match[2])
+	record.set("user", match[3])
+	if timestamp, err := time.Parse(accessLogTimeLayout, match[4]); err == nil {
+		record.set("time", timestamp.Format(time.RFC3339))
+	} else {
+		record.set("time", match[4])
+	}
+
+	request := strings.ReplaceAll(match[5], `\"`, `"`)
+	record.set("request", request)
+	if parts := strings.Fields(request); len(parts) == 3 {
+		record.set("method", parts[0])
+		record.set("path", parts[1])
+		record.set("protocol", parts[2])
+	}
+
+	status, _ := strconv.Atoi(match[6])
+	record.set("status", float64(status))
+	if size, err := strconv.Atoi(match[7]); err == nil {
+		record.set("bytes", float64(size))
+	}
+	if combined {
+		record.set("referer", strings.ReplaceAll(match[8], `\"`, `"`))
+		record.set("userAgent", strings.ReplaceAll(match[9], `\"`, `"`))
+	}
+	return record.result()
+}
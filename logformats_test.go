@@ -0,0 +1,98 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseSyslogLine(t *testing.T) {
+	line := `<165>1 2003-10-11T22:14:15.003Z mymachine.example.com evntslog - ID47 [exampleSDID@32473 iut="3" eventSource="App\]lication"][meta seq="1"] An application event`
+	content, rawJSON, err := parseSyslogLine(line)
+	if err != nil {
+		t.Fatalf("parseSyslogLine returned error: %v", err)
+	}
+	if content["facility"] != float64(20) || content["severity"] != "notice" || content["appName"] != "evntslog" {
+		t.Errorf("Unexpected header fields %+v", content)
+	}
+	if _, exists := content["procId"]; exists {
+		t.Error("Expected the nil process ID to be omitted")
+	}
+	sd := content["structuredData"].(map[string]interface{})
+	if params := sd["exampleSDID@32473"].(map[string]interface{}); params["eventSource"] != "App]lication" || params["iut"] != "3" {
+		t.Errorf("Unexpected structured data %+v", sd)
+	}
+	if content["message"] != "An application event" {
+		t.Errorf("Unexpected message %v", content["message"])
+	}
+	if rawJSON[:16] != `{"priority":165,` {
+		t.Errorf("Expected fields in line order, got %s", rawJSON)
+	}
+
+	if _, _, err := parseSyslogLine("Oct 11 22:14:15 host app: BSD style"); err == nil {
+		t.Error("Expected an error for a non-RFC 5424 line")
+	}
+}
+
+func TestParseAccessLine(t *testing.T) {
+	line := `203.0.113.9 - frank [10/Oct/2000:13:55:36 -0700] "GET /apache_pb.gif HTTP/1.0" 200 2326 "http://www.example.com/start.html" "Mozilla/4.08"`
+	content, _, err := parseCombinedAccessLine(line)
+	if err != nil {
+		t.Fatalf("parseCombinedAccessLine returned error: %v", err)
+	}
+	expected := map[string]interface{}{
+		"remoteHost": "203.0.113.9",
+		"user":       "frank",
+		"time":       "2000-10-10T13:55:36-07:00",
+		"method":     "GET",
+		"path":       "/apache_pb.gif",
+		"status":     float64(200),
+		"bytes":      float64(2326),
+		"userAgent":  "Mozilla/4.08",
+	}
+	for field, value := range expected {
+		if content[field] != value {
+			t.Errorf("Expected %s=%v, got %v", field, value, content[field])
+		}
+	}
+	if _, exists := content["ident"]; exists {
+		t.Error("Expected the - ident to be omitted")
+	}
+
+	common := `127.0.0.1 - - [10/Oct/2000:13:55:36 -0700] "GET / HTTP/1.1" 304 -`
+	if _, _, err := parseCombinedAccessLine(common); err == nil {
+		t.Error("Expected a common log line not to match the combined format")
+	}
+	if content, _, err := parseCommonAccessLine(common); err != nil || content["status"] != float64(304) || content["bytes"] != nil {
+		t.Errorf("Unexpected common log record %+v (%v)", content, err)
+	}
+}
+
+func TestLoadLogFile(t *testing.T) {
+	useTempConfigDir(t)
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "access.log")
+	os.WriteFile(path, []byte(`10.0.0.1 - - [01/Jan/2024:12:00:00 +0000] "GET / HTTP/1.1" 200 512 "-" "curl/8.0"
+10.0.0.2 - - [01/Jan/2024:12:00:01 +0000] "POST /api HTTP/1.1" 500 0 "-" "curl/8.0"
+`), 0644)
+
+	app := &App{}
+	file, err := app.LoadLogFile(path, "")
+	if err != nil {
+		t.Fatalf("LoadLogFile returned error: %v", err)
+	}
+	if file.Format != ParseModeAccessCombined || file.Records != 2 {
+		t.Errorf("Expected 2 combined access log records, got %+v", file)
+	}
+
+	if _, err := app.LoadLogFile(path, "apache-xml"); err == nil {
+		t.Error("Expected an error for an unknown log format")
+	}
+
+	jsonlPath := filepath.Join(dir, "app.log")
+	os.WriteFile(jsonlPath, []byte("{\"level\":\"info\"}\n"), 0644)
+	if file, err := app.LoadLogFile(jsonlPath, ""); err != nil || file.Format != "" || file.Records != 1 {
+		t.Errorf("Expected a JSONL .log file to load as JSONL, got %+v (%v)", file, err)
+	}
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Normalized log levels, from least to most severe
+const (
+	LevelTrace = "trace"
+	LevelDebug = "debug"
+	LevelInfo  = "info"
+	LevelWarn  = "warn"
+	LevelError = "error"
+	LevelFatal = "fatal"
+)
+
+// logLevels lists the normalized levels in order of severity
+var logLevels = []string{LevelTrace, LevelDebug, LevelInfo, LevelWarn, LevelError, LevelFatal}
+
+// levelFieldNames are common names of severity fields, in order of preference
+var levelFieldNames = []string{
+	"level", "severity", "loglevel", "log_level", "logLevel", "lvl",
+	"levelname", "severity_text", "log.level", "@level",
+}
+
+// levelNames maps the spellings used by common loggers to normalized levels
+var levelNames = map[string]string{
+	"trace": LevelTrace, "trc": LevelTrace, "verbose": LevelTrace, "finest": LevelTrace, "finer": LevelTrace,
+	"debug": LevelDebug, "dbg": LevelDebug, "fine": LevelDebug, "d": LevelDebug,
+	"info": LevelInfo, "inf": LevelInfo, "information": LevelInfo, "informational": LevelInfo, "notice": LevelInfo, "i": LevelInfo,
+	"warn": LevelWarn, "warning": LevelWarn, "wrn": LevelWarn, "w": LevelWarn,
+	"error": LevelError, "err": LevelError, "eror": LevelError, "severe": LevelError, "e": LevelError,
+	"fatal": LevelFatal, "critical": LevelFatal, "crit": LevelFatal, "alert": LevelFatal,
+	"emerg": LevelFatal, "emergency": LevelFatal, "panic": LevelFatal, "f": LevelFatal,
+}
+
+// normalizeLevel maps a severity value to a normalized level. Numbers of 10 and up
+// follow the pino and bunyan scale (30 is info), smaller ones the syslog severity
+// codes (3 is error).
+func normalizeLevel(value interface{}) (string, bool) {
+	text := strings.ToLower(strings.TrimSpace(scalarText(value)))
+	if level, exists := levelNames[text]; exists {
+		return level, true
+	}
+
+	number, err := strconv.Atoi(text)
+	switch {
+	case err != nil || number < 0:
+		return "", false
+	case number >= 60:
+		return LevelFatal, true
+	case number >= 50:
+		return LevelError, true
+	case number >= 40:
+		return LevelWarn, true
+	case number >= 30:
+		return LevelInfo, true
+	case number >= 20:
+		return LevelDebug, true
+	case number >= 10:
+		return LevelTrace, true
+	case number <= 2:
+		return LevelFatal, true
+	case number == 3:
+		return LevelError, true
+	case number == 4:
+		return LevelWarn, true
+	case number <= 6:
+		return LevelInfo, true
+	}
+	return LevelDebug, true
+}
+
+// detectLevelField picks the first well-known severity field whose values mostly
+// normalize to a level in a sample of records
+func detectLevelField(records []JSONRecord) string {
+	for _, field := range levelFieldNames {
+		present, normalized := 0, 0
+		for _, record := range records {
+			value, exists := getFieldValue(record.Content, field)
+			if !exists {
+				continue
+			}
+			present++
+			if _, ok := normalizeLevel(value); ok {
+				normalized++
+			}
+		}
+		if present > 0 && normalized*2 > present {
+			return field
+		}
+	}
+	return ""
+}
+
+// LevelCount is the number of records at one normalized level
+type LevelCount struct {
+	Level  string   `json:"level"`
+	Count  int      `json:"count"`
+	Values []string `json:"values"` // values of the field normalized to this level, sorted
+}
+
+// LevelSummary is the distribution of records over log levels
+type LevelSummary struct {
+	Field   string       `json:"field"`
+	Levels  []LevelCount `json:"levels"`  // every level, least severe first
+	Other   []ValueCount `json:"other"`   // values not recognized as a level, most frequent first
+	Missing int          `json:"missing"` // records in scope without the field
+	Records int          `json:"records"` // records in scope
+}
+
+// GetLevelSummary counts records per log level. An empty levelField detects the
+// severity field (level, severity, loglevel...) from the records. Values are
+// normalized across loggers, so WARN, warning and 40 all count as warn. Counts
+// cover the records left by the filter stack, narrowed to those matching filter
+// when it carries a query.
+func (a *App) GetLevelSummary(levelField string, filter *SearchOptions) (*LevelSummary, error) {
+	a.stateMu.RLock()
+	defer a.stateMu.RUnlock()
+
+	if a.currentFile == nil || a.cache == nil {
+		return nil, &JSONLError{
+			Message: "No file currently loaded",
+			Err:     ErrNoFileLoaded,
+		}
+	}
+
+	field := strings.TrimSpace(levelField)
+	if field == "" {
+		sample, _ := a.cache.slice(0, min(a.cache.totalCount, 100))
+		if field = detectLevelField(sample); field == "" {
+			return nil, &JSONLError{
+				Message: "No log level field found",
+				Err:     errors.New("no log level field"),
+			}
+		}
+	}
+
+	summary := &LevelSummary{Field: field, Levels: make([]LevelCount, len(logLevels)), Other: []ValueCount{}}
+	positions := make(map[string]int, len(logLevels))
+	for i, level := range logLevels {
+		summary.Levels[i] = LevelCount{Level: level, Values: []string{}}
+		positions[level] = i
+	}
+	seen := make(map[string]bool)
+	other := make(map[string]int)
+
+	err := a.forEachInScope(filter, func(record JSONRecord) {
+		summary.Records++
+		value, exists := getFieldValue(record.Content, field)
+		if !exists {
+			summary.Missing++
+			return
+		}
+		text := valueToString(value)
+		level, ok := normalizeLevel(value)
+		if !ok {
+			other[text]++
+			return
+		}
+		count := &summary.Levels[positions[level]]
+		count.Count++
+		if !seen[text] {
+			seen[text] = true
+			count.Values = append(count.Values, text)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range summary.Levels {
+		sort.Strings(summary.Levels[i].Values)
+	}
+	for value, count := range other {
+		summary.Other = append(summary.Other, ValueCount{Value: value, Count: count})
+	}
+	sort.Slice(summary.Other, func(i, j int) bool {
+		if summary.Other[i].Count != summary.Other[j].Count {
+			return summary.Other[i].Count > summary.Other[j].Count
+		}
+		return summary.Other[i].Value < summary.Other[j].Value
+	})
+	return summary, nil
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestNormalizeLevel(t *testing.T) {
+	tests := []struct {
+		value    interface{}
+		expected string
+		ok       bool
+	}{
+		{"WARN", LevelWarn, true},
+		{" warning ", LevelWarn, true},
+		{json.Number("40"), LevelWarn, true},
+		{json.Number("30"), LevelInfo, true},
+		{"60", LevelFatal, true},
+		{json.Number("3"), LevelError, true},
+		{json.Number("7"), LevelDebug, true},
+		{"CRITICAL", LevelFatal, true},
+		{"Information", LevelInfo, true},
+		{"TRACE", LevelTrace, true},
+		{"loud", "", false},
+		{nil, "", false},
+		{true, "", false},
+		{json.Number("-1"), "", false},
+	}
+
+	for _, tt := range tests {
+		level, ok := normalizeLevel(tt.value)
+		if level != tt.expected || ok != tt.ok {
+			t.Errorf("normalizeLevel(%v) = %q, %t; expected %q, %t", tt.value, level, ok, tt.expected, tt.ok)
+		}
+	}
+}
+
+func TestGetLevelSummary(t *testing.T) {
+	app := newTestApp(t, `{"severity":"WARN","service":"api"}
+{"severity":"warning","service":"web"}
+{"severity":40,"service":"api"}
+{"severity":"error","service":"api"}
+{"severity":"INFO","service":"web"}
+{"severity":"loud","service":"api"}
+{"service":"api"}
+`)
+
+	summary, err := app.GetLevelSummary("", nil)
+	if err != nil {
+		t.Fatalf("GetLevelSummary returned error: %v", err)
+	}
+	if summary.Field != "severity" || summary.Records != 7 || summary.Missing != 1 {
+		t.Errorf("Unexpected totals: %+v", summary)
+	}
+	if len(summary.Levels) != len(logLevels) {
+		t.Fatalf("Expected every level, got %+v", summary.Levels)
+	}
+	expected := map[string]int{LevelTrace: 0, LevelDebug: 0, LevelInfo: 1, LevelWarn: 3, LevelError: 1, LevelFatal: 0}
+	for _, level := range summary.Levels {
+		if level.Count != expected[level.Level] {
+			t.Errorf("Level %s: expected %d records, got %d", level.Level, expected[level.Level], level.Count)
+		}
+	}
+	if warn := summary.Levels[3]; len(warn.Values) != 3 || warn.Values[0] != "40" || warn.Values[1] != "WARN" {
+		t.Errorf("Expected the raw warn values, got %v", warn.Values)
+	}
+	if len(summary.Other) != 1 || summary.Other[0].Value != "loud" || summary.Other[0].Count != 1 {
+		t.Errorf("Expected loud as an unrecognized value, got %+v", summary.Other)
+	}
+
+	filtered, _ := app.GetLevelSummary("severity", &SearchOptions{Query: "service:web", UseLucene: true})
+	if filtered.Records != 2 || filtered.Levels[2].Count != 1 || filtered.Levels[3].Count != 1 {
+		t.Errorf("Unexpected summary within service:web: %+v", filtered)
+	}
+
+	if _, err := newTestApp(t, `{"a":1}`).GetLevelSummary("", nil); err == nil {
+		t.Error("Expected an error without a level field")
+	}
+	var jsonlErr *JSONLError
+	if _, err := (&App{}).GetLevelSummary("", nil); !errors.As(err, &jsonlErr) || !errors.Is(jsonlErr.Err, ErrNoFileLoaded) {
+		t.Errorf("Expected ErrNoFileLoaded, got %v", err)
+	}
+}
@@ -0,0 +1,522 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// timeQueryField is the query field standing for the records' timestamp field, as
+// in `_time:[2024-05-01T00:00 TO 2024-05-02T00:00]`
+const timeQueryField = "_time"
+
+// existsQueryField and missingQueryField select records that have, or lack, a
+// non-null value for the field named after them, as in `_missing_:user_id`
+const (
+	existsQueryField  = "_exists_"
+	missingQueryField = "_missing_"
+)
+
+// luceneToken is a lexical token of a Lucene query
+type luceneToken struct {
+	kind    string // 'term', 'phrase', 'field', 'range', 'op', 'eof'
+	text    string
+	literal bool // a term with backslash escapes, matched as written
+	pos     int  // offset of the token in the query, in characters
+}
+
+// QuerySyntaxError describes why a query does not parse and where
+type QuerySyntaxError struct {
+	Position int    `json:"position"` // offset in the query, in characters
+	Message  string `json:"message"`
+}
+
+func (e *QuerySyntaxError) Error() string {
+	return fmt.Sprintf("%s at position %d", e.Message, e.Position)
+}
+
+// tokenizeLucene splits a Lucene query into tokens. Quoted phrases are kept whole so
+// they may contain spaces, colons and the words AND/OR; `name:` becomes a field
+// token applying to the value, group or [x TO y] range that follows it, and `name:=`
+// marks an exact match of the value after the = operator. A backslash
+// escapes the next character, in phrases as well as in terms such as `C\:\\temp` or
+// `\AND`; terms with escapes are never operators, wildcards, fuzzy terms or
+// comparisons.
+func tokenizeLucene(input string) ([]luceneToken, error) {
+	var tokens []luceneToken
+	runes := []rune(input)
+	afterField := func() bool {
+		if len(tokens) == 0 {
+			return false
+		}
+		last := tokens[len(tokens)-1]
+		return last.kind == "field" || (last.kind == "op" && last.text == "=")
+	}
+
+	for i := 0; i < len(runes); {
+		r, start := runes[i], i
+		switch {
+		case unicode.IsSpace(r):
+			i++
+
+		case r == '(' || r == ')':
+			tokens = append(tokens, luceneToken{kind: "op", text: string(r), pos: start})
+			i++
+
+		case r == '"':
+			var sb strings.Builder
+			i++
+			for i < len(runes) && runes[i] != '"' {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+				}
+				sb.WriteRune(runes[i])
+				i++
+			}
+			if i >= len(runes) {
+				return nil, &QuerySyntaxError{Position: start, Message: "unterminated quote"}
+			}
+			i++
+			tokens = append(tokens, luceneToken{kind: "phrase", text: sb.String(), pos: start})
+
+		case (r == '[' || r == '{') && afterField():
+			for i < len(runes) && runes[i] != ']' && runes[i] != '}' {
+				i++
+			}
+			if i >= len(runes) {
+				return nil, &QuerySyntaxError{Position: start, Message: "unterminated range"}
+			}
+			i++
+			tokens = append(tokens, luceneToken{kind: "range", text: string(runes[start:i]), pos: start})
+
+		case r == '=' && len(tokens) > 0 && tokens[len(tokens)-1].kind == "field":
+			tokens = append(tokens, luceneToken{kind: "op", text: "=", pos: start})
+			i++
+
+		case (r == '&' || r == '|') && i+1 < len(runes) && runes[i+1] == r:
+			op := "AND"
+			if r == '|' {
+				op = "OR"
+			}
+			tokens = append(tokens, luceneToken{kind: "op", text: op, pos: start})
+			i += 2
+
+		case (r == '-' || r == '!') && !afterField() && i+1 < len(runes) && !unicode.IsSpace(runes[i+1]):
+			tokens = append(tokens, luceneToken{kind: "op", text: "NOT", pos: start})
+			i++
+
+		default:
+			var sb strings.Builder
+			literal := false
+			for i < len(runes) && !unicode.IsSpace(runes[i]) && runes[i] != '(' && runes[i] != ')' && runes[i] != '"' {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					literal = true
+					i++
+				} else if runes[i] == ':' && sb.Len() > 0 && !afterField() {
+					break
+				}
+				sb.WriteRune(runes[i])
+				i++
+			}
+			word := sb.String()
+			if i < len(runes) && runes[i] == ':' {
+				tokens = append(tokens, luceneToken{kind: "field", text: word, pos: start})
+				i++
+				continue
+			}
+			if !afterField() && !literal && (word == "AND" || word == "OR" || word == "NOT") {
+				tokens = append(tokens, luceneToken{kind: "op", text: word, pos: start})
+				continue
+			}
+			tokens = append(tokens, luceneToken{kind: "term", text: word, literal: literal, pos: start})
+		}
+	}
+
+	return append(tokens, luceneToken{kind: "eof", pos: len(runes)}), nil
+}
+
+// luceneParser is a recursive descent parser over Lucene query tokens. NOT binds
+// tightest, then AND, then OR; terms without an operator between them are ANDed.
+type luceneParser struct {
+	tokens []luceneToken
+	pos    int
+}
+
+// parseLucene parses a Lucene query such as `(level:error OR level:warn) AND "timed out"`
+func parseLucene(input string) (*LuceneQuery, error) {
+	tokens, err := tokenizeLucene(input)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &luceneParser{tokens: tokens}
+	if p.peek().kind == "eof" {
+		return nil, &QuerySyntaxError{Position: 0, Message: "empty query"}
+	}
+	query, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != "eof" {
+		return nil, p.errorAt(p.peek(), fmt.Errorf("unexpected %q", p.peek().text))
+	}
+	return query, nil
+}
+
+// parseLuceneQuery parses a Lucene query string into a structured query, returning
+// nil when it is empty or malformed
+func parseLuceneQuery(query string) *LuceneQuery {
+	parsed, err := parseLucene(query)
+	if err != nil {
+		return nil
+	}
+	return parsed
+}
+
+// errorAt reports err as a syntax error at token
+func (p *luceneParser) errorAt(token luceneToken, err error) error {
+	return &QuerySyntaxError{Position: token.pos, Message: err.Error()}
+}
+
+func (p *luceneParser) peek() luceneToken {
+	return p.tokens[p.pos]
+}
+
+// acceptOp consumes the next token if it is the given operator
+func (p *luceneParser) acceptOp(op string) bool {
+	if token := p.peek(); token.kind == "op" && token.text == op {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// startsOperand reports whether the next token can begin an implicitly ANDed operand
+func (p *luceneParser) startsOperand() bool {
+	token := p.peek()
+	switch token.kind {
+	case "term", "phrase", "field":
+		return true
+	case "op":
+		return token.text == "(" || token.text == "NOT"
+	}
+	return false
+}
+
+func (p *luceneParser) parseOr() (*LuceneQuery, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.acceptOp("OR") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &LuceneQuery{Type: "or", Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *luceneParser) parseAnd() (*LuceneQuery, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.acceptOp("AND") || p.startsOperand() {
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = &LuceneQuery{Type: "and", Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *luceneParser) parseNot() (*LuceneQuery, error) {
+	if p.acceptOp("NOT") {
+		query, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &LuceneQuery{Type: "not", Query: query}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *luceneParser) parsePrimary() (*LuceneQuery, error) {
+	token := p.peek()
+	switch token.kind {
+	case "field":
+		p.pos++
+		if token.text == existsQueryField || token.text == missingQueryField {
+			return p.parseExists(token.text)
+		}
+		if p.acceptOp("=") {
+			return p.parseExact(token)
+		}
+		if p.peek().kind == "field" || (p.peek().kind == "op" && p.peek().text != "(") || p.peek().kind == "eof" {
+			return nil, p.errorAt(token, fmt.Errorf("missing value for field %q", token.text))
+		}
+		if next := p.peek(); next.kind == "term" && !next.literal && strings.ContainsAny(next.text[:1], "<>") {
+			p.pos++
+			query, err := parseComparison(next.text)
+			if err != nil {
+				return nil, p.errorAt(next, err)
+			}
+			return withField(query, token.text), nil
+		}
+		if next := p.peek(); next.kind == "term" && !next.literal && next.text == "*" {
+			p.pos++
+			return &LuceneQuery{Type: "exists", Field: token.text}, nil
+		}
+		query, err := p.parsePrimary()
+		if err != nil {
+			return nil, err
+		}
+		return withField(query, token.text), nil
+
+	case "term":
+		p.pos++
+		if token.literal {
+			return &LuceneQuery{Type: "term", Value: token.text}, nil
+		}
+		if term, distance, found := cutFuzzy(token.text); found {
+			query, err := parseFuzzy(term, distance)
+			if err != nil {
+				return nil, p.errorAt(token, err)
+			}
+			return query, nil
+		}
+		if strings.ContainsAny(token.text, "*?") {
+			return &LuceneQuery{Type: "wildcard", Value: token.text}, nil
+		}
+		return &LuceneQuery{Type: "term", Value: token.text}, nil
+
+	case "phrase":
+		p.pos++
+		return &LuceneQuery{Type: "phrase", Value: token.text}, nil
+
+	case "range":
+		p.pos++
+		query, err := parseRange(token.text)
+		if err != nil {
+			return nil, p.errorAt(token, err)
+		}
+		return query, nil
+
+	case "op":
+		if token.text == "(" {
+			p.pos++
+			query, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			if !p.acceptOp(")") {
+				return nil, p.errorAt(p.peek(), errors.New("missing closing parenthesis"))
+			}
+			return query, nil
+		}
+	}
+
+	if token.kind == "eof" {
+		return nil, p.errorAt(token, errors.New("unexpected end of query"))
+	}
+	return nil, p.errorAt(token, fmt.Errorf("unexpected %q", token.text))
+}
+
+// parseExists parses the field name following `_exists_:` or `_missing_:`
+func (p *luceneParser) parseExists(kind string) (*LuceneQuery, error) {
+	token := p.peek()
+	if token.kind != "term" && token.kind != "phrase" {
+		return nil, p.errorAt(token, fmt.Errorf("%s: must be followed by a field name", kind))
+	}
+	p.pos++
+
+	query := &LuceneQuery{Type: "exists", Field: token.text}
+	if kind == missingQueryField {
+		return &LuceneQuery{Type: "not", Query: query}, nil
+	}
+	return query, nil
+}
+
+// parseExact parses the value of an exact match: `field:="text"` matches string
+// values equal to text, and `field:=200`, `field:=true` or `field:=null` match other
+// values whose JSON text is the term
+func (p *luceneParser) parseExact(field luceneToken) (*LuceneQuery, error) {
+	token := p.peek()
+	switch token.kind {
+	case "phrase":
+		p.pos++
+		return &LuceneQuery{Type: "exact", Field: field.text, Value: `"` + token.text + `"`}, nil
+	case "term":
+		p.pos++
+		return &LuceneQuery{Type: "exact", Field: field.text, Value: token.text}, nil
+	}
+	return nil, p.errorAt(field, fmt.Errorf("missing value for field %q", field.text))
+}
+
+// exactText renders a field value as an exact query's Value: strings wrapped in
+// quotes and other values as their JSON text
+func exactText(value interface{}) string {
+	if text, isString := value.(string); isString {
+		return `"` + text + `"`
+	}
+	return valueToString(value)
+}
+
+// exactDisplayText is the text shown for an exactText value: strings lose their quotes
+func exactDisplayText(exact string) string {
+	if unquoted, quoted := strings.CutPrefix(exact, `"`); quoted {
+		return strings.TrimSuffix(unquoted, `"`)
+	}
+	return exact
+}
+
+// parseRange parses a range such as `[20 TO 30]` or `{a TO *}`: square brackets
+// include the bound, curly ones exclude it and * leaves that side open
+func parseRange(text string) (*LuceneQuery, error) {
+	inner := text[1 : len(text)-1]
+	lower, upper, found := strings.Cut(inner, " TO ")
+	if !found {
+		return nil, fmt.Errorf("range %s must have the form [x TO y]", text)
+	}
+	bound := func(s string) string {
+		s = strings.TrimSpace(s)
+		if len(s) > 1 && s[0] == '"' && s[len(s)-1] == '"' {
+			return s[1 : len(s)-1]
+		}
+		return s
+	}
+	query := &LuceneQuery{
+		Type:         "range",
+		Lower:        bound(lower),
+		Upper:        bound(upper),
+		IncludeLower: text[0] == '[',
+		IncludeUpper: text[len(text)-1] == ']',
+	}
+	// An open side has no bound to include
+	query.IncludeLower = query.IncludeLower && query.Lower != "*"
+	query.IncludeUpper = query.IncludeUpper && query.Upper != "*"
+	if query.Lower == "" || query.Upper == "" {
+		return nil, fmt.Errorf("range %s is missing a bound", text)
+	}
+	return query, nil
+}
+
+// parseComparison parses a comparison such as `>499` or `<=0.5` into the equivalent
+// open-ended range
+func parseComparison(text string) (*LuceneQuery, error) {
+	query := &LuceneQuery{Type: "range", Lower: "*", Upper: "*"}
+	op := text[:1]
+	if strings.HasPrefix(text[1:], "=") {
+		op = text[:2]
+	}
+	bound := strings.TrimSpace(text[len(op):])
+	if bound == "" || bound == "*" {
+		return nil, fmt.Errorf("comparison %s is missing a value", text)
+	}
+
+	switch op {
+	case ">":
+		query.Lower = bound
+	case ">=":
+		query.Lower, query.IncludeLower = bound, true
+	case "<":
+		query.Upper = bound
+	case "<=":
+		query.Upper, query.IncludeUpper = bound, true
+	}
+	return query, nil
+}
+
+// matchRange checks whether a field value lies within a range query's bounds
+func (o matchOptions) matchRange(query *LuceneQuery, fieldValue interface{}) bool {
+	if query.Lower != "*" {
+		cmp, ok := o.compareField(query.Field, fieldValue, query.Lower)
+		if !ok || cmp < 0 || (cmp == 0 && !query.IncludeLower) {
+			return false
+		}
+	}
+	if query.Upper != "*" {
+		cmp, ok := o.compareField(query.Field, fieldValue, query.Upper)
+		if !ok || cmp > 0 || (cmp == 0 && !query.IncludeUpper) {
+			return false
+		}
+	}
+	return true
+}
+
+// withField scopes the unfielded terms of a query to field, so `level:(error OR warn)`
+// behaves like `level:error OR level:warn`
+func withField(query *LuceneQuery, field string) *LuceneQuery {
+	switch query.Type {
+	case "and", "or":
+		query.Left = withField(query.Left, field)
+		query.Right = withField(query.Right, field)
+	case "not":
+		query.Query = withField(query.Query, field)
+	case "term":
+		if query.Field == "" {
+			query.Type = "field"
+			query.Field = field
+		}
+	default:
+		if query.Field == "" {
+			query.Field = field
+		}
+	}
+	return query
+}
+
+// queryUsesField reports whether any node of a query addresses field
+func queryUsesField(query *LuceneQuery, field string) bool {
+	if query == nil {
+		return false
+	}
+	return query.Field == field || queryUsesField(query.Left, field) ||
+		queryUsesField(query.Right, field) || queryUsesField(query.Query, field)
+}
+
+// withTimestampField points the _time nodes of a query at the timestamp field, whose
+// values they compare as timestamps. They stay unresolved, matching nothing, when
+// there is none.
+func withTimestampField(query *LuceneQuery, field string) *LuceneQuery {
+	if query == nil || field == "" {
+		return query
+	}
+	if query.Field == timeQueryField {
+		query.Field = field
+	}
+	withTimestampField(query.Left, field)
+	withTimestampField(query.Right, field)
+	withTimestampField(query.Query, field)
+	return query
+}
+
+// withTimestampCoercion returns coercions extended to compare field as timestamps,
+// unless it already has an override
+func withTimestampCoercion(coercions map[string]FieldCoercion, field string) map[string]FieldCoercion {
+	if _, exists := coercions[field]; exists || field == "" {
+		return coercions
+	}
+	extended := map[string]FieldCoercion{field: {Field: field, Type: CoerceTimestamp}}
+	for name, coercion := range coercions {
+		extended[name] = coercion
+	}
+	return extended
+}
+
+// queryTimestampField returns the field queried as _time: the chosen field, or the
+// one detected in the first records of the loaded file
+func (a *App) queryTimestampField(chosen string) string {
+	if chosen != "" || a.cache == nil {
+		return chosen
+	}
+	sample, err := a.cache.slice(0, min(a.cache.totalCount, 100))
+	if err != nil {
+		return ""
+	}
+	return detectTimestampField(sample)
+}
@@ -0,0 +1,253 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+func TestParseLuceneGrammar(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{"(a:1 OR b:2) AND c:3", "((field:a:1 or field:b:2) and field:c:3)"},
+		{"a:1 OR b:2 AND c:3", "(field:a:1 or (field:b:2 and field:c:3))"},
+		{"a:1 AND (b:2 OR (c:3 AND NOT d:4))", "(field:a:1 and (field:b:2 or (field:c:3 and NOT field:d:4)))"},
+		{`msg:"slow AND retrying" OR "a OR b"`, "(phrase:msg:slow AND retrying or phrase:a OR b)"},
+		{"error timeout", "(term:error and term:timeout)"},
+		{"level:(error OR warn) -debug", "((field:level:error or field:level:warn) and NOT term:debug)"},
+		{"a:1 && b:2 || !c:3", "((field:a:1 and field:b:2) or NOT field:c:3)"},
+		{"time:10:30 n:-5", "(field:time:10:30 and field:n:-5)"},
+		{"name:Jo* OR name:AND", "(wildcard:name:Jo* or field:name:AND)"},
+	}
+
+	for _, tt := range tests {
+		query, err := parseLucene(tt.query)
+		if err != nil {
+			t.Errorf("parseLucene(%q) returned error: %v", tt.query, err)
+			continue
+		}
+		if got := formatQuery(query); got != tt.expected {
+			t.Errorf("parseLucene(%q) = %s, expected %s", tt.query, got, tt.expected)
+		}
+	}
+
+	for _, query := range []string{"", "(a:1", "a:1)", "a AND", "OR b", "name:", `"open`, "()"} {
+		if _, err := parseLucene(query); err == nil {
+			t.Errorf("Expected parseLucene(%q) to fail", query)
+		}
+		if parseLuceneQuery(query) != nil {
+			t.Errorf("Expected parseLuceneQuery(%q) to return nil", query)
+		}
+	}
+}
+
+func TestGroupedLuceneSearch(t *testing.T) {
+	app := newTestApp(t, "{\"a\":1,\"c\":3}\n{\"b\":2,\"c\":4}\n{\"b\":2,\"c\":3}\n{\"a\":5,\"c\":3}\n")
+
+	result, err := app.SearchRecords(SearchOptions{Query: "(a:1 OR b:2) AND c:3", UseLucene: true, Limit: 10})
+	if err != nil {
+		t.Fatalf("SearchRecords returned error: %v", err)
+	}
+	if len(result.Records) != 2 || result.Records[0].LineNumber != 1 || result.Records[1].LineNumber != 3 {
+		t.Errorf("Expected lines 1 and 3, got %+v", result.Records)
+	}
+}
+
+func TestLuceneRangeQueries(t *testing.T) {
+	query, err := parseLucene(`age:[20 TO 30} AND ts:{"2024-01-01" TO *]`)
+	if err != nil {
+		t.Fatalf("parseLucene returned error: %v", err)
+	}
+	age, ts := query.Left, query.Right
+	if age.Type != "range" || age.Field != "age" || age.Lower != "20" || age.Upper != "30" || !age.IncludeLower || age.IncludeUpper {
+		t.Errorf("Unexpected age range: %+v", age)
+	}
+	if ts.Field != "ts" || ts.Lower != "2024-01-01" || ts.Upper != "*" || ts.IncludeLower || ts.IncludeUpper {
+		t.Errorf("Unexpected ts range: %+v", ts)
+	}
+	for _, invalid := range []string{"age:[20 30]", "age:[20 TO 30", "age:[ TO 30]"} {
+		if _, err := parseLucene(invalid); err == nil {
+			t.Errorf("Expected parseLucene(%q) to fail", invalid)
+		}
+	}
+
+	app := newTestApp(t, `{"age":9,"day":"2024-01-15"}
+{"age":20,"day":"2024-02-01"}
+{"age":100,"day":"2023-12-31"}
+{"age":"25","day":"2024-01-01"}
+`)
+	tests := []struct {
+		query    string
+		expected []int
+	}{
+		{"age:[10 TO 100]", []int{2, 3, 4}}, // numeric, not lexicographic, including "25"
+		{"age:{20 TO 100]", []int{3, 4}},    // exclusive lower bound
+		{"age:[* TO 20]", []int{1, 2}},      // open lower bound
+		{"day:[2024-01-01 TO 2024-02-01}", []int{1, 4}},
+	}
+	for _, tt := range tests {
+		result, err := app.SearchRecords(SearchOptions{Query: tt.query, UseLucene: true, Limit: 10})
+		if err != nil {
+			t.Fatalf("SearchRecords(%q) returned error: %v", tt.query, err)
+		}
+		lines := []int{}
+		for _, record := range result.Records {
+			lines = append(lines, record.LineNumber)
+		}
+		if fmt.Sprint(lines) != fmt.Sprint(tt.expected) {
+			t.Errorf("%s: expected lines %v, got %v", tt.query, tt.expected, lines)
+		}
+	}
+}
+
+func TestLuceneComparisonOperators(t *testing.T) {
+	app := newTestApp(t, `{"status":200,"ratio":0.25}
+{"status":503,"ratio":0.5}
+{"status":404,"ratio":0.75}
+{"status":500}
+{"status":"n/a","ratio":1}
+`)
+	tests := []struct {
+		query    string
+		expected []int
+	}{
+		{"status:>499", []int{2, 4}},
+		{"status:>=500 AND status:<503", []int{4}},
+		{"ratio:<=0.5", []int{1, 2}},
+		{"ratio:<0.5", []int{1}},
+		{"status:>404 OR ratio:>0.9", []int{2, 4, 5}},
+	}
+	for _, tt := range tests {
+		result, err := app.SearchRecords(SearchOptions{Query: tt.query, UseLucene: true, Limit: 10})
+		if err != nil {
+			t.Fatalf("SearchRecords(%q) returned error: %v", tt.query, err)
+		}
+		lines := []int{}
+		for _, record := range result.Records {
+			lines = append(lines, record.LineNumber)
+		}
+		if fmt.Sprint(lines) != fmt.Sprint(tt.expected) {
+			t.Errorf("%s: expected lines %v, got %v", tt.query, tt.expected, lines)
+		}
+	}
+
+	if _, err := parseLucene("status:>"); err == nil {
+		t.Error("Expected a comparison without value to fail")
+	}
+
+	// json.Number values compare exactly beyond float64 precision
+	cmp, ok := matchOptions{}.compareField("id", json.Number("9007199254740993"), "9007199254740992")
+	if !ok || cmp != 1 {
+		t.Errorf("Expected the larger json.Number to compare greater, got %d (%v)", cmp, ok)
+	}
+}
+
+func TestLuceneTimestampRanges(t *testing.T) {
+	app := newTestApp(t, `{"ts":"2024-05-01T10:00:00Z","at":1714600000}
+{"ts":"2024-05-01T23:30:00+02:00","at":1714600000000}
+{"ts":"2024-05-02T00:00:00Z","at":"2024-05-03T00:00:00Z"}
+{"ts":1714480000,"at":"bad"}
+`)
+	tests := []struct {
+		query          string
+		timestampField string
+		expected       []int
+	}{
+		{"ts:[2024-05-01T00:00 TO 2024-05-02T00:00}", "", []int{1, 2}}, // +02:00 is still May 1st in UTC
+		{"ts:<2024-05-01", "", []int{4}},                               // epoch seconds
+		{"_time:[2024-05-01 TO 2024-05-02]", "", []int{1, 2, 3}},       // detected timestamp field
+		{"_time:>=2024-05-02T00:00:00Z", "at", []int{3}},               // chosen field
+		{"_time:[1714599999 TO 1714600001]", "at", []int{1, 2}},        // epoch bounds, seconds and millis
+	}
+	for _, tt := range tests {
+		result, err := app.SearchRecords(SearchOptions{Query: tt.query, UseLucene: true, TimestampField: tt.timestampField, Limit: 10})
+		if err != nil {
+			t.Fatalf("SearchRecords(%q) returned error: %v", tt.query, err)
+		}
+		lines := []int{}
+		for _, record := range result.Records {
+			lines = append(lines, record.LineNumber)
+		}
+		if fmt.Sprint(lines) != fmt.Sprint(tt.expected) {
+			t.Errorf("%s: expected lines %v, got %v", tt.query, tt.expected, lines)
+		}
+	}
+}
+
+func TestLuceneExistsQueries(t *testing.T) {
+	app := newTestApp(t, `{"user_id":1,"msg":"login"}
+{"msg":"anonymous"}
+{"user_id":null,"msg":"logout"}
+{"user_id":"","trace":"abc"}
+`)
+	tests := []struct {
+		query    string
+		expected []int
+	}{
+		{"_exists_:user_id", []int{1, 4}},
+		{"_missing_:user_id", []int{2, 3}},
+		{"user_id:*", []int{1, 4}},
+		{"_missing_:user_id AND msg:log*", []int{3}},
+		{`NOT _exists_:"msg"`, []int{4}},
+	}
+	for _, tt := range tests {
+		result, err := app.SearchRecords(SearchOptions{Query: tt.query, UseLucene: true, Limit: 10})
+		if err != nil {
+			t.Fatalf("SearchRecords(%q) returned error: %v", tt.query, err)
+		}
+		lines := []int{}
+		for _, record := range result.Records {
+			lines = append(lines, record.LineNumber)
+		}
+		if fmt.Sprint(lines) != fmt.Sprint(tt.expected) {
+			t.Errorf("%s: expected lines %v, got %v", tt.query, tt.expected, lines)
+		}
+	}
+
+	if _, err := parseLucene("_exists_:(a OR b)"); err == nil {
+		t.Error("Expected _exists_ without a field name to fail")
+	}
+}
+
+func TestLuceneEscaping(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{`path:C\:\\logs`, `field:path:C:\logs`},
+		{`msg:"say \"hi\" AND go"`, `phrase:msg:say "hi" AND go`},
+		{`\AND OR \(x\)`, "(term:AND or term:(x))"},
+		{`my\ field:1`, "field:my field:1"},
+		{`name:\*star\* size:\>5 id:\-3 typo\~1`, `(((field:name:*star* and field:size:>5) and field:id:-3) and term:typo~1)`},
+	}
+	for _, tt := range tests {
+		query, err := parseLucene(tt.query)
+		if err != nil {
+			t.Errorf("parseLucene(%q) returned error: %v", tt.query, err)
+			continue
+		}
+		if got := formatQuery(query); got != tt.expected {
+			t.Errorf("parseLucene(%q) = %s, expected %s", tt.query, got, tt.expected)
+		}
+	}
+
+	app := newTestApp(t, `{"msg":"slow AND retrying","path":"C:\\logs\\app"}
+{"msg":"slow","note":"retrying AND more"}
+{"msg":"rate *limited*"}`)
+	for query, expected := range map[string]int{
+		`msg:"slow AND retrying"`: 1,
+		`path:C\:\\logs`:          1,
+		`msg:\*limited\*`:         1,
+		`\AND`:                    2,
+	} {
+		result, err := app.SearchRecords(SearchOptions{Query: query, UseLucene: true})
+		if err != nil {
+			t.Fatalf("SearchRecords returned error: %v", err)
+		}
+		if result.TotalMatches != expected {
+			t.Errorf("%s: expected %d matches, got %d", query, expected, result.TotalMatches)
+		}
+	}
+}
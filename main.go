@@ -2,19 +2,30 @@ package main
 
 import (
 	"embed"
+	"os"
 
 	"github.com/wailsapp/wails/v2"
 	"github.com/wailsapp/wails/v2/pkg/options"
 	"github.com/wailsapp/wails/v2/pkg/options/assetserver"
+	"github.com/wailsapp/wails/v2/pkg/options/mac"
 )
 
 //go:embed all:frontend/dist
 var assets embed.FS
 
 func main() {
+	// Run command-line subcommands without starting the GUI
+	if len(os.Args) > 1 && os.Args[1] == "convert" {
+		os.Exit(runConvertCommand(os.Args[2:]))
+	}
+
 	// Create an instance of the app structure
 	app := NewApp()
 
+	// Files given as arguments are opened in tabs at launch
+	workingDir, _ := os.Getwd()
+	app.startupFiles = launchFiles(os.Args[1:], workingDir)
+
 	// Create application with options
 	err := wails.Run(&options.App{
 		Title:  "Jsonl Viewer",
@@ -25,6 +36,19 @@ func main() {
 		},
 		BackgroundColour: &options.RGBA{R: 27, G: 38, B: 54, A: 1},
 		OnStartup:        app.startup,
+		OnDomReady:       app.domReady,
+		OnShutdown:       app.shutdown,
+		DragAndDrop: &options.DragAndDrop{
+			EnableFileDrop: true,
+		},
+		// Files opened through the OS file association reach the running instance
+		SingleInstanceLock: &options.SingleInstanceLock{
+			UniqueId:               "com.wails.jsonl-viewer",
+			OnSecondInstanceLaunch: app.onSecondInstanceLaunch,
+		},
+		Mac: &mac.Options{
+			OnFileOpen: app.onFileOpen,
+		},
 		Bind: []interface{}{
 			app,
 		},
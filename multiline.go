@@ -0,0 +1,200 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Parse modes selecting how loaded files are split into records
+const (
+	ParseModeJSONL     = "jsonl"     // one JSON value per line
+	ParseModeMultiline = "multiline" // concatenated JSON values, e.g. pretty-printed records
+	ParseModeLogfmt    = "logfmt"    // key=value lines, see parseLogfmtLine
+
+	// Log formats, see logformats.go
+	ParseModeSyslog         = "syslog"
+	ParseModeAccessCommon   = "access-common"
+	ParseModeAccessCombined = "access-combined"
+)
+
+// ErrInvalidParseMode is returned for unknown parse modes
+var ErrInvalidParseMode = errors.New("invalid parse mode")
+
+// SetParseMode selects how files loaded afterwards are split into records:
+// ParseModeJSONL reads one value per line, ParseModeMultiline reads concatenated
+// JSON values regardless of newlines, numbering each record by its starting line,
+// and the log formats listed by GetLogFormats convert each log line to a record
+func (a *App) SetParseMode(mode string) error {
+	a.stateMu.Lock()
+	defer a.stateMu.Unlock()
+	switch {
+	case mode == "" || mode == ParseModeJSONL:
+		a.parseMode = ""
+	case mode == ParseModeMultiline || lineConverters[mode] != nil:
+		a.parseMode = mode
+	default:
+		return &JSONLError{
+			Message: fmt.Sprintf("Unknown parse mode %q", mode),
+			Err:     ErrInvalidParseMode,
+		}
+	}
+	return nil
+}
+
+// GetParseMode returns the mode files are parsed in
+func (a *App) GetParseMode() string {
+	a.stateMu.RLock()
+	defer a.stateMu.RUnlock()
+	if a.parseMode == "" {
+		return ParseModeJSONL
+	}
+	return a.parseMode
+}
+
+// newlineTracker records the offsets of the newlines read through it so that a
+// decoder's input offsets can be mapped back to line numbers
+type newlineTracker struct {
+	reader   io.Reader
+	offset   int64
+	newlines []int64 // offsets not yet consumed
+	total    int     // newlines read so far
+	lastByte byte
+}
+
+func (t *newlineTracker) Read(p []byte) (int, error) {
+	n, err := t.reader.Read(p)
+	for i, b := range p[:n] {
+		if b == '\n' {
+			t.newlines = append(t.newlines, t.offset+int64(i))
+			t.total++
+		}
+	}
+	if n > 0 {
+		t.lastByte = p[n-1]
+	}
+	t.offset += int64(n)
+	return n, err
+}
+
+// consume drops and counts the newlines before offset
+func (t *newlineTracker) consume(offset int64) int {
+	count := 0
+	for count < len(t.newlines) && t.newlines[count] < offset {
+		count++
+	}
+	t.newlines = t.newlines[count:]
+	return count
+}
+
+// lines returns the number of lines read so far, counting a final unterminated one
+func (t *newlineTracker) lines() int {
+	if t.offset > 0 && t.lastByte != '\n' {
+		return t.total + 1
+	}
+	return t.total
+}
+
+// parseConcatenated reads the input as a stream of JSON values separated by any
+// whitespace. Each record gets the line its value starts on. A malformed value
+// cannot be skipped reliably, so parsing stops there and the rest of the input is
+// reported as a single invalid line. The max line size does not apply.
+func (p *JSONLParser) parseConcatenated() ([]JSONRecord, *FileStats, error) {
+	var fileSize int64
+	if p.file != nil {
+		fileInfo, err := p.file.Stat()
+		if err != nil {
+			return nil, nil, &JSONLError{
+				Message: "Failed to get file information",
+				Err:     err,
+			}
+		}
+		fileSize = fileInfo.Size()
+	}
+
+	tracker := &newlineTracker{reader: p.lines.reader}
+	decoder := json.NewDecoder(tracker)
+	var records []JSONRecord
+	invalidLines := []int{}
+	invalidDetails := make(map[int]InvalidLineDetail)
+	var duplicateKeys []DuplicateKeyLine
+	fieldCounts := make(map[string]int)
+	line := 1
+	var compacted bytes.Buffer
+
+	for {
+		var raw json.RawMessage
+		err := decoder.Decode(&raw)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var syntaxErr *json.SyntaxError
+			if !errors.As(err, &syntaxErr) && err != io.ErrUnexpectedEOF {
+				return nil, nil, &JSONLError{
+					Message: "Error reading file",
+					Err:     err,
+				}
+			}
+			// The malformed value starts after the whitespace following the last value
+			rest, _ := io.ReadAll(decoder.Buffered())
+			start := decoder.InputOffset() + int64(len(rest)-len(bytes.TrimLeft(rest, " \t\r\n")))
+			line += tracker.consume(start)
+			invalidLines = append(invalidLines, line)
+			// The error offset counts from the start of the stream, not the line
+			text, _, _ := bytes.Cut(bytes.TrimLeft(rest, " \t\r\n"), []byte("\n"))
+			invalidDetails[line] = invalidLineDetail(line, string(bytes.TrimRight(text, "\r")), err, -1)
+			io.Copy(io.Discard, tracker)
+			break
+		}
+
+		end := decoder.InputOffset()
+		line += tracker.consume(end - int64(len(raw)))
+
+		compacted.Reset()
+		json.Compact(&compacted, raw)
+		content, value, _ := parseRecordLine(compacted.String())
+		for field := range content {
+			fieldCounts[field]++
+		}
+		record := JSONRecord{
+			LineNumber: line,
+			Content:    content,
+			RawJSON:    compacted.String(),
+			Value:      value,
+		}
+		if duplicate, found := detectDuplicateKeys(&record, p.keepDuplicates); found {
+			duplicateKeys = append(duplicateKeys, duplicate)
+		}
+		records = append(records, record)
+
+		if len(records)%loadProgressLines == 0 {
+			if p.ctx != nil && p.ctx.Err() != nil {
+				return nil, nil, &JSONLError{
+					Message: "Loading was cancelled",
+					Err:     ErrLoadCancelled,
+				}
+			}
+			if p.progress != nil {
+				p.progress(end, len(records))
+			}
+		}
+	}
+	p.bytesRead = tracker.offset
+	p.lineCount = tracker.lines()
+
+	stats := &FileStats{
+		TotalLines:    p.lineCount,
+		ValidRecords:  len(records),
+		InvalidLines:  invalidLines,
+		CommonFields:  selectCommonFields(fieldCounts, len(records), CommonFieldOptions{}),
+		FileSize:      fileSize,
+		DuplicateKeys: duplicateKeys,
+		RecordSizes:   measureRecordSizes(recordSizes(records)),
+
+		invalidDetails: invalidDetails,
+	}
+	return records, stats, nil
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseConcatenatedJSON(t *testing.T) {
+	content := "{\n  \"id\": 1,\n  \"tags\": [\n    \"a\"\n  ]\n}\n{\"id\": 2} {\"id\": 3}\n\n[1,\n 2]\n{\n  \"id\": \n"
+	parser := NewJSONLReaderParser(strings.NewReader(content))
+	parser.mode = ParseModeMultiline
+
+	records, stats, err := parser.ParseJSONL()
+	if err != nil {
+		t.Fatalf("ParseJSONL returned error: %v", err)
+	}
+
+	expectedLines := []int{1, 7, 7, 9}
+	if len(records) != len(expectedLines) {
+		t.Fatalf("Expected %d records, got %d", len(expectedLines), len(records))
+	}
+	for i, line := range expectedLines {
+		if records[i].LineNumber != line {
+			t.Errorf("Record %d: expected line %d, got %d", i, line, records[i].LineNumber)
+		}
+	}
+	if records[0].RawJSON != `{"id":1,"tags":["a"]}` {
+		t.Errorf("Expected compacted JSON, got %s", records[0].RawJSON)
+	}
+	if stats.TotalLines != 12 || len(stats.InvalidLines) != 1 || stats.InvalidLines[0] != 11 {
+		t.Errorf("Expected the truncated value at line 11 to be invalid, got %+v", stats)
+	}
+}
+
+func TestLoadMultilineFile(t *testing.T) {
+	useTempConfigDir(t)
+
+	path := filepath.Join(t.TempDir(), "pretty.json")
+	os.WriteFile(path, []byte("{\n  \"level\": \"info\"\n}\n{\n  \"level\": \"error\"\n}\n"), 0644)
+
+	app := &App{}
+	if err := app.SetParseMode("xml"); err == nil {
+		t.Error("Expected an error for an unknown parse mode")
+	}
+	if err := app.SetParseMode(ParseModeMultiline); err != nil {
+		t.Fatalf("SetParseMode returned error: %v", err)
+	}
+
+	file, err := app.LoadJSONLFile(path)
+	if err != nil {
+		t.Fatalf("LoadJSONLFile returned error: %v", err)
+	}
+	if file.Records != 2 || file.Format != ParseModeMultiline {
+		t.Fatalf("Expected 2 records in multiline format, got %+v", file)
+	}
+	if record, _ := app.GetRecordByLineNumber(4); record == nil || record.Content["level"] != "error" {
+		t.Errorf("Expected the second record at line 4, got %+v", record)
+	}
+	if stats, err := app.GetFileStats(); err != nil || stats.ValidRecords != 2 || len(stats.InvalidLines) != 0 {
+		t.Errorf("Expected the multiline records in the stats, got %+v (%v)", stats, err)
+	}
+
+	exported, err := app.GetAllRecords("error")
+	if err != nil || len(exported) != 1 || exported[0].LineNumber != 4 {
+		t.Errorf("Expected the loaded record to be exported, got %+v (%v)", exported, err)
+	}
+
+	app.SetParseMode("")
+	if app.GetParseMode() != ParseModeJSONL {
+		t.Errorf("Expected the default parse mode, got %s", app.GetParseMode())
+	}
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Outlier detection methods
+const (
+	OutlierZScore = "zscore" // values more than zScoreThreshold standard deviations from the mean
+	OutlierIQR    = "iqr"    // values beyond iqrMultiplier interquartile ranges outside Q1/Q3
+)
+
+// Default outlier thresholds
+const (
+	zScoreThreshold = 3.0
+	iqrMultiplier   = 1.5
+)
+
+// Outlier is a record whose numeric field value is anomalous
+type Outlier struct {
+	LineNumber int     `json:"lineNumber"`
+	Value      float64 `json:"value"`
+	Score      float64 `json:"score"` // z-score, or distance from the nearest bound in IQRs
+}
+
+// OutlierReport lists the outliers of a numeric field together with the statistics used
+type OutlierReport struct {
+	Field      string    `json:"field"`
+	Method     string    `json:"method"`
+	Values     int       `json:"values"` // records with a numeric value for the field
+	Mean       float64   `json:"mean"`
+	StdDev     float64   `json:"stdDev"`
+	Q1         float64   `json:"q1"`
+	Q3         float64   `json:"q3"`
+	LowerBound float64   `json:"lowerBound"`
+	UpperBound float64   `json:"upperBound"`
+	Outliers   []Outlier `json:"outliers"` // most extreme first
+}
+
+// percentile returns the p-th percentile (0-1) of sorted values using linear interpolation
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	position := p * float64(len(sorted)-1)
+	lower := int(math.Floor(position))
+	upper := int(math.Ceil(position))
+	if lower == upper {
+		return sorted[lower]
+	}
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(position-float64(lower))
+}
+
+// detectOutliers computes bounds for values with the given method and returns the
+// report with every point outside them
+func detectOutliers(field, method string, lines []int, values []float64) *OutlierReport {
+	report := &OutlierReport{
+		Field:    field,
+		Method:   method,
+		Values:   len(values),
+		Outliers: []Outlier{},
+	}
+	if len(values) == 0 {
+		return report
+	}
+
+	sum := 0.0
+	for _, value := range values {
+		sum += value
+	}
+	report.Mean = sum / float64(len(values))
+	variance := 0.0
+	for _, value := range values {
+		variance += (value - report.Mean) * (value - report.Mean)
+	}
+	report.StdDev = math.Sqrt(variance / float64(len(values)))
+
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	report.Q1 = percentile(sorted, 0.25)
+	report.Q3 = percentile(sorted, 0.75)
+	iqr := report.Q3 - report.Q1
+
+	if method == OutlierIQR {
+		report.LowerBound = report.Q1 - iqrMultiplier*iqr
+		report.UpperBound = report.Q3 + iqrMultiplier*iqr
+	} else {
+		report.LowerBound = report.Mean - zScoreThreshold*report.StdDev
+		report.UpperBound = report.Mean + zScoreThreshold*report.StdDev
+	}
+
+	for i, value := range values {
+		if value >= report.LowerBound && value <= report.UpperBound {
+			continue
+		}
+
+		var score float64
+		switch {
+		case method == OutlierIQR && iqr > 0 && value < report.LowerBound:
+			score = (report.LowerBound - value) / iqr
+		case method == OutlierIQR && iqr > 0:
+			score = (value - report.UpperBound) / iqr
+		case method != OutlierIQR && report.StdDev > 0:
+			score = math.Abs(value-report.Mean) / report.StdDev
+		}
+		report.Outliers = append(report.Outliers, Outlier{LineNumber: lines[i], Value: value, Score: score})
+	}
+
+	sort.SliceStable(report.Outliers, func(i, j int) bool {
+		return report.Outliers[i].Score > report.Outliers[j].Score
+	})
+	return report
+}
+
+// DetectOutliers flags records whose numeric value for field is anomalous using the
+// z-score or IQR method, restricted to records matching filter when it has a query.
+// The outliers carry line numbers for jumping to them and can be saved with ExportStats.
+func (a *App) DetectOutliers(field, method string, filter *SearchOptions) (*OutlierReport, error) {
+	a.stateMu.RLock()
+	defer a.stateMu.RUnlock()
+
+	if a.currentFile == nil || a.cache == nil {
+		return nil, &JSONLError{
+			Message: "No file currently loaded",
+			Err:     ErrNoFileLoaded,
+		}
+	}
+
+	if strings.TrimSpace(field) == "" {
+		return nil, &JSONLError{
+			Message: "Field name cannot be empty",
+			Err:     errors.New("empty field name"),
+		}
+	}
+
+	if method == "" {
+		method = OutlierZScore
+	}
+	if method != OutlierZScore && method != OutlierIQR {
+		return nil, &JSONLError{
+			Message: fmt.Sprintf("Unknown outlier method %q", method),
+			Err:     errors.New("unknown outlier method"),
+		}
+	}
+
+	var lines []int
+	var values []float64
+	err := a.forEachScoped(filter, func(record JSONRecord) {
+		value, exists := getFieldValue(record.Content, field)
+		if !exists {
+			return
+		}
+		if number, ok := toFloat64(value); ok {
+			lines = append(lines, record.LineNumber)
+			values = append(values, number)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return detectOutliers(field, method, lines, values), nil
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestDetectOutliers(t *testing.T) {
+	var lines []string
+	for i := 0; i < 20; i++ {
+		lines = append(lines, fmt.Sprintf(`{"service":"api","latency":%d}`, 100+i%5))
+	}
+	lines = append(lines,
+		`{"service":"api","latency":950}`,
+		`{"service":"db","latency":5000}`,
+		`{"service":"api","latency":"n/a"}`)
+	app := newTestApp(t, strings.Join(lines, "\n"))
+
+	tests := []struct {
+		name          string
+		method        string
+		filter        *SearchOptions
+		expectedLines []int
+		values        int
+	}{
+		{"IQR over all records", OutlierIQR, nil, []int{22, 21}, 22},
+		{"IQR under filter", OutlierIQR, &SearchOptions{Query: "service:api", UseLucene: true}, []int{21}, 21},
+		{"Z-score over all records", OutlierZScore, nil, []int{22}, 22},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := app.DetectOutliers("latency", tt.method, tt.filter)
+			if err != nil {
+				t.Fatalf("DetectOutliers returned error: %v", err)
+			}
+			var outlierLines []int
+			for _, outlier := range report.Outliers {
+				outlierLines = append(outlierLines, outlier.LineNumber)
+			}
+			if fmt.Sprint(outlierLines) != fmt.Sprint(tt.expectedLines) || report.Values != tt.values {
+				t.Errorf("Expected outliers %v over %d values, got %v over %d", tt.expectedLines, tt.values, outlierLines, report.Values)
+			}
+		})
+	}
+
+	if _, err := app.DetectOutliers("latency", "mad", nil); err == nil {
+		t.Errorf("Expected an error for an unknown method")
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	sorted := []float64{1, 2, 3, 4}
+	if result := percentile(sorted, 0.5); result != 2.5 {
+		t.Errorf("Expected median 2.5, got %v", result)
+	}
+	if result := percentile(sorted, 1); result != 4 {
+		t.Errorf("Expected max 4, got %v", result)
+	}
+}
@@ -0,0 +1,133 @@
+package main
+
+import "encoding/json"
+
+// Page size modes
+const (
+	PageSizeManual = "manual" // the page size set with SetPageSize
+	PageSizeAuto   = "auto"   // derived from measured record sizes and a payload budget
+)
+
+// Adaptive page sizing limits
+const (
+	defaultPayloadBudget = 512 * 1024 // serialized bytes per page targeted in auto mode
+	minAutoPageSize      = 10
+	maxPageSampleRecords = 200 // records measured to estimate the average record size
+)
+
+// PageSizeSettings describes how the effective page size is chosen
+type PageSizeSettings struct {
+	Mode           string  `json:"mode"`           // 'manual' or 'auto'
+	PageSize       int     `json:"pageSize"`       // effective page size
+	PayloadBudget  int     `json:"payloadBudget"`  // target bytes per page in auto mode
+	AvgRecordBytes float64 `json:"avgRecordBytes"` // measured serialized record size
+}
+
+// averageRecordBytes measures the serialized size of records spread evenly over the file
+func averageRecordBytes(records []JSONRecord) float64 {
+	if len(records) == 0 {
+		return 0
+	}
+
+	step := 1
+	if len(records) > maxPageSampleRecords {
+		step = len(records) / maxPageSampleRecords
+	}
+
+	total, sampled := 0, 0
+	for i := 0; i < len(records) && sampled < maxPageSampleRecords; i += step {
+		data, err := json.Marshal(records[i])
+		if err != nil {
+			continue
+		}
+		total += len(data)
+		sampled++
+	}
+	if sampled == 0 {
+		return 0
+	}
+	return float64(total) / float64(sampled)
+}
+
+// autoPageSize fits as many records of the given average size as the budget allows
+func autoPageSize(avgRecordBytes float64, payloadBudget int) int {
+	if avgRecordBytes <= 0 {
+		return 50 // Default page size
+	}
+
+	pageSize := int(float64(payloadBudget) / avgRecordBytes)
+	if pageSize < minAutoPageSize {
+		pageSize = minAutoPageSize
+	}
+	if pageSize > 1000 {
+		pageSize = 1000 // Cap maximum page size
+	}
+	return pageSize
+}
+
+// applyPageSizeMode recomputes the cache's page size when auto mode is enabled
+func (a *App) applyPageSizeMode() {
+	if a.cache == nil || !a.autoPageSize {
+		return
+	}
+
+	records := a.cache.records
+	if a.cache.diskBacked() {
+		// Sample the leading records rather than decoding the whole file
+		end := a.cache.totalCount
+		if end > maxPageSampleRecords {
+			end = maxPageSampleRecords
+		}
+		records, _ = a.cache.slice(0, end)
+	}
+	a.cache.avgRecordBytes = averageRecordBytes(records)
+	a.cache.pageSize = autoPageSize(a.cache.avgRecordBytes, a.payloadBudget)
+}
+
+// SetAutoPageSize switches to auto page sizing, targeting payloadBudget serialized
+// bytes per page (0 uses the default), and returns the resulting page size. The mode
+// stays active for files loaded later until SetPageSize is called.
+func (a *App) SetAutoPageSize(payloadBudget int) (int, error) {
+	a.stateMu.Lock()
+	defer a.stateMu.Unlock()
+
+	if a.cache == nil {
+		return 0, &JSONLError{
+			Message: "No file currently loaded",
+			Err:     ErrNoFileLoaded,
+		}
+	}
+
+	if payloadBudget <= 0 {
+		payloadBudget = defaultPayloadBudget
+	}
+
+	a.autoPageSize = true
+	a.payloadBudget = payloadBudget
+	a.applyPageSizeMode()
+	return a.cache.pageSize, nil
+}
+
+// GetPageSizeSettings returns the page size mode and the effective page size
+func (a *App) GetPageSizeSettings() (*PageSizeSettings, error) {
+	a.stateMu.RLock()
+	defer a.stateMu.RUnlock()
+
+	if a.cache == nil {
+		return nil, &JSONLError{
+			Message: "No file currently loaded",
+			Err:     ErrNoFileLoaded,
+		}
+	}
+
+	settings := &PageSizeSettings{
+		Mode:           PageSizeManual,
+		PageSize:       a.cache.pageSize,
+		AvgRecordBytes: a.cache.avgRecordBytes,
+	}
+	if a.autoPageSize {
+		settings.Mode = PageSizeAuto
+		settings.PayloadBudget = a.payloadBudget
+	}
+	return settings, nil
+}
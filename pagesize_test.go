@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestAutoPageSize(t *testing.T) {
+	tests := []struct {
+		name     string
+		avgBytes float64
+		budget   int
+		expected int
+	}{
+		{"Tiny records fill large pages", 100, 50000, 500},
+		{"Capped at the maximum page size", 10, 1000000, 1000},
+		{"Fat records get a minimum page size", 100000, 50000, minAutoPageSize},
+		{"Unknown size uses the default", 0, 50000, 50},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := autoPageSize(tt.avgBytes, tt.budget); result != tt.expected {
+				t.Errorf("Expected %d, got %d", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestSetAutoPageSize(t *testing.T) {
+	var lines []string
+	for i := 0; i < 20; i++ {
+		lines = append(lines, fmt.Sprintf(`{"id":%d,"payload":"%s"}`, i, strings.Repeat("x", 100)))
+	}
+	app := newTestApp(t, strings.Join(lines, "\n"))
+
+	pageSize, err := app.SetAutoPageSize(4000)
+	if err != nil {
+		t.Fatalf("SetAutoPageSize returned error: %v", err)
+	}
+
+	settings, _ := app.GetPageSizeSettings()
+	if settings.Mode != PageSizeAuto || settings.PageSize != pageSize || settings.AvgRecordBytes == 0 {
+		t.Errorf("Unexpected settings: %+v", settings)
+	}
+	if expected := autoPageSize(settings.AvgRecordBytes, 4000); pageSize != expected {
+		t.Errorf("Expected page size %d, got %d", expected, pageSize)
+	}
+
+	if err := app.SetPageSize(25); err != nil {
+		t.Fatalf("SetPageSize returned error: %v", err)
+	}
+	settings, _ = app.GetPageSizeSettings()
+	if settings.Mode != PageSizeManual || settings.PageSize != 25 {
+		t.Errorf("Expected manual mode with 25 records per page, got %+v", settings)
+	}
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	goruntime "runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// parseChunkLines is the number of lines handed to a parse worker at a time
+const parseChunkLines = 2048
+
+// lineChunk is a run of consecutive non-empty lines to unmarshal
+type lineChunk struct {
+	index          int
+	lines          []string // untrimmed, without line endings
+	lineNumbers    []int
+	lenient        bool          // repair almost-JSON lines
+	keepDuplicates bool          // keep every value of repeated keys
+	convert        lineConverter // converts lines of another format to records, nil for JSON
+}
+
+// parsedChunk holds the records and invalid lines of a lineChunk
+type parsedChunk struct {
+	index          int
+	records        []JSONRecord
+	invalidLines   []int
+	invalidDetails []InvalidLineDetail
+	repairedLines  []int
+	duplicateKeys  []DuplicateKeyLine
+	fieldCounts    map[string]int
+}
+
+// parseChunk unmarshals the lines of a chunk
+func parseChunk(chunk lineChunk) parsedChunk {
+	result := parsedChunk{
+		index:       chunk.index,
+		records:     make([]JSONRecord, 0, len(chunk.lines)),
+		fieldCounts: make(map[string]int),
+	}
+
+	for i, raw := range chunk.lines {
+		line := strings.TrimSpace(raw)
+		if chunk.convert != nil {
+			content, rawJSON, err := chunk.convert(line)
+			if err != nil {
+				result.invalidLines = append(result.invalidLines, chunk.lineNumbers[i])
+				result.invalidDetails = append(result.invalidDetails, invalidLineDetail(chunk.lineNumbers[i], raw, err, -1))
+				continue
+			}
+			for field := range content {
+				result.fieldCounts[field]++
+			}
+			result.records = append(result.records, JSONRecord{
+				LineNumber: chunk.lineNumbers[i],
+				Content:    content,
+				RawJSON:    rawJSON,
+			})
+			continue
+		}
+
+		content, value, err := parseRecordLine(line)
+		strictErr := err
+		repaired := false
+		if err != nil && chunk.lenient {
+			content, value, line, err = parseLenientLine(line)
+			repaired = err == nil
+		}
+		if err != nil {
+			// Report why the line is not valid JSON rather than why repair failed
+			result.invalidLines = append(result.invalidLines, chunk.lineNumbers[i])
+			result.invalidDetails = append(result.invalidDetails, invalidLineDetail(chunk.lineNumbers[i], raw, strictErr, jsonErrorOffset(strictErr)))
+			continue
+		}
+		if repaired {
+			result.repairedLines = append(result.repairedLines, chunk.lineNumbers[i])
+		}
+
+		// Count fields for common fields analysis
+		for field := range content {
+			result.fieldCounts[field]++
+		}
+
+		record := JSONRecord{
+			LineNumber: chunk.lineNumbers[i],
+			Content:    content,
+			RawJSON:    line,
+			Value:      value,
+			Repaired:   repaired,
+		}
+		if duplicate, found := detectDuplicateKeys(&record, chunk.keepDuplicates); found {
+			result.duplicateKeys = append(result.duplicateKeys, duplicate)
+		}
+		result.records = append(result.records, record)
+	}
+	return result
+}
+
+// chunkPool unmarshals line chunks across GOMAXPROCS workers while the caller keeps
+// reading, then reassembles the results in line order
+type chunkPool struct {
+	jobs    chan lineChunk
+	results chan parsedChunk
+	workers sync.WaitGroup
+	done    chan struct{}
+	chunks  []parsedChunk
+	parsed  atomic.Int64 // records unmarshalled so far, for progress reports
+}
+
+func newChunkPool() *chunkPool {
+	workers := goruntime.GOMAXPROCS(0)
+	pool := &chunkPool{
+		jobs:    make(chan lineChunk, workers),
+		results: make(chan parsedChunk, workers),
+		done:    make(chan struct{}),
+	}
+
+	for i := 0; i < workers; i++ {
+		pool.workers.Add(1)
+		go func() {
+			defer pool.workers.Done()
+			for chunk := range pool.jobs {
+				result := parseChunk(chunk)
+				pool.parsed.Add(int64(len(result.records)))
+				pool.results <- result
+			}
+		}()
+	}
+
+	go func() {
+		defer close(pool.done)
+		for result := range pool.results {
+			for len(pool.chunks) <= result.index {
+				pool.chunks = append(pool.chunks, parsedChunk{})
+			}
+			pool.chunks[result.index] = result
+		}
+	}()
+
+	return pool
+}
+
+// submit queues a chunk, blocking while every worker is busy
+func (pool *chunkPool) submit(chunk lineChunk) {
+	pool.jobs <- chunk
+}
+
+// wait stops the workers once the queued chunks are parsed and returns the
+// results in submission order
+func (pool *chunkPool) wait() []parsedChunk {
+	close(pool.jobs)
+	pool.workers.Wait()
+	close(pool.results)
+	<-pool.done
+	return pool.chunks
+}
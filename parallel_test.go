@@ -0,0 +1,48 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestParseJSONLPreservesOrderAcrossChunks(t *testing.T) {
+	var sb strings.Builder
+	total := 5*parseChunkLines + 17
+	for i := 1; i <= total; i++ {
+		switch {
+		case i%1000 == 0:
+			sb.WriteString("{broken\n")
+		case i%777 == 0:
+			sb.WriteString("\n")
+		default:
+			fmt.Fprintf(&sb, "{\"line\":%d}\n", i)
+		}
+	}
+
+	records, stats, err := NewJSONLReaderParser(strings.NewReader(sb.String())).ParseJSONL()
+	if err != nil {
+		t.Fatalf("ParseJSONL returned error: %v", err)
+	}
+	if stats.TotalLines != total {
+		t.Errorf("Expected %d lines, got %d", total, stats.TotalLines)
+	}
+
+	for i, record := range records {
+		if record.Content["line"] != json.Number(fmt.Sprint(record.LineNumber)) {
+			t.Fatalf("Record %d has line number %d but content %v", i, record.LineNumber, record.Content)
+		}
+		if i > 0 && records[i-1].LineNumber >= record.LineNumber {
+			t.Fatalf("Records out of order at %d", i)
+		}
+	}
+	for i, line := range stats.InvalidLines {
+		if line != (i+1)*1000 {
+			t.Fatalf("Expected invalid line %d, got %d", (i+1)*1000, line)
+		}
+	}
+	if stats.ValidRecords+len(stats.InvalidLines) != total-total/777+total/(777*1000) {
+		t.Errorf("Unexpected counts: %d valid, %d invalid", stats.ValidRecords, len(stats.InvalidLines))
+	}
+}
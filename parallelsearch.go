@@ -0,0 +1,76 @@
+package main
+
+import (
+	goruntime "runtime"
+	"sync"
+)
+
+// parallelSearchThreshold is the default smallest record count searched by several workers
+const parallelSearchThreshold = 20000
+
+// filterRecords returns the records accepted by matches in their original order.
+// Inputs of at least threshold records are split into contiguous shards evaluated concurrently across
+// GOMAXPROCS workers, whose results are concatenated in shard order, so the output
+// is the same as a serial scan. matches must be safe for concurrent use. Progress
+// and cancellation go through scan; a cancelled scan returns ErrSearchCancelled.
+func filterRecords(scan *searchScan, records []JSONRecord, matches func(JSONRecord) bool, threshold int) ([]JSONRecord, error) {
+	workers := goruntime.GOMAXPROCS(0)
+	if len(records) < threshold || workers < 2 {
+		return filterShard(scan, records, matches)
+	}
+
+	shardSize := (len(records) + workers - 1) / workers
+	shards := make([][]JSONRecord, workers)
+	errs := make([]error, workers)
+	var wg sync.WaitGroup
+	for w := range shards {
+		start := w * shardSize
+		end := min(start+shardSize, len(records))
+		if start >= end {
+			break
+		}
+		wg.Add(1)
+		go func(w int, shard []JSONRecord) {
+			defer wg.Done()
+			shards[w], errs[w] = filterShard(scan, shard, matches)
+		}(w, records[start:end])
+	}
+	wg.Wait()
+
+	total := 0
+	for w, shard := range shards {
+		if errs[w] != nil {
+			return nil, errs[w]
+		}
+		total += len(shard)
+	}
+	if total == 0 {
+		return nil, nil
+	}
+	matched := make([]JSONRecord, 0, total)
+	for _, shard := range shards {
+		matched = append(matched, shard...)
+	}
+	return matched, nil
+}
+
+// filterShard scans one shard of records, stepping scan every check interval of
+// records
+func filterShard(scan *searchScan, records []JSONRecord, matches func(JSONRecord) bool) ([]JSONRecord, error) {
+	var matched []JSONRecord
+	pending, pendingMatches := 0, 0
+	for _, record := range records {
+		if matches(record) {
+			matched = append(matched, record)
+			pendingMatches++
+		}
+		if pending++; pending == scan.tuning.checkInterval {
+			if !scan.step(pending, pendingMatches) {
+				return nil, ErrSearchCancelled
+			}
+			pending, pendingMatches = 0, 0
+		}
+	}
+	scan.step(pending, pendingMatches)
+	return matched, nil
+}
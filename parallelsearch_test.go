@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParallelSearchKeepsOrder(t *testing.T) {
+	var sb strings.Builder
+	for i := 0; i < 2000; i++ {
+		fmt.Fprintf(&sb, `{"n":%d,"level":"%s"}`+"\n", i, []string{"info", "error", "warn", "error"}[i%4])
+	}
+	app := newTestApp(t, sb.String())
+
+	options := SearchOptions{Query: "level:error AND NOT n:7", UseLucene: true, Limit: 1000}
+	serial, err := app.SearchRecords(options)
+	if err != nil {
+		t.Fatalf("SearchRecords returned error: %v", err)
+	}
+
+	app.scanning.parallelThreshold = 100
+
+	parallel, err := app.SearchRecords(options)
+	if err != nil {
+		t.Fatalf("SearchRecords returned error: %v", err)
+	}
+	if parallel.TotalMatches != serial.TotalMatches || !reflect.DeepEqual(parallel.Records, serial.Records) {
+		t.Errorf("Parallel search found %d matches, serial %d", parallel.TotalMatches, serial.TotalMatches)
+	}
+	for i := 1; i < len(parallel.Records); i++ {
+		if parallel.Records[i].LineNumber <= parallel.Records[i-1].LineNumber {
+			t.Fatalf("Results out of line order at %d", i)
+		}
+	}
+
+	scan, done := app.beginSearch("", len(app.records))
+	defer done()
+	if matched, err := filterRecords(scan, app.records, func(JSONRecord) bool { return false }, 100); err != nil || matched != nil {
+		t.Errorf("Expected no matches, got %d (%v)", len(matched), err)
+	}
+}
@@ -0,0 +1,442 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Parquet physical types, encodings and the other enum values used by the writer,
+// as defined in parquet.thrift
+const (
+	parquetBoolean   = 0
+	parquetInt64     = 2
+	parquetDouble    = 5
+	parquetByteArray = 6
+
+	parquetEncodingPlain = 0
+	parquetEncodingRLE   = 3
+
+	parquetOptional    = 1
+	parquetConvertUTF8 = 0
+	parquetDataPage    = 0
+)
+
+// parquetRowGroupSize is the number of rows buffered per row group
+const parquetRowGroupSize = 10000
+
+// parquetMagic starts and ends every Parquet file
+var parquetMagic = []byte("PAR1")
+
+// parquetColumn is one flat column of the output: a leaf field path and the
+// physical type inferred from every value it holds
+type parquetColumn struct {
+	name string
+	kind int
+
+	hasBool, hasInt, hasFloat, hasOther bool
+}
+
+// observe widens the column's inferred type to cover value
+func (c *parquetColumn) observe(value interface{}) {
+	switch v := value.(type) {
+	case nil:
+	case bool:
+		c.hasBool = true
+	case json.Number:
+		if _, err := strconv.ParseInt(string(v), 10, 64); err == nil {
+			c.hasInt = true
+		} else if strings.ContainsAny(string(v), ".eE") {
+			c.hasFloat = true
+		} else {
+			// Integers beyond int64 are kept as text rather than rounded
+			c.hasOther = true
+		}
+	default:
+		c.hasOther = true
+	}
+}
+
+// resolve picks the physical type: booleans and numbers keep their type when a
+// column holds nothing else, and every other column is stored as UTF-8 text
+func (c *parquetColumn) resolve() {
+	switch {
+	case c.hasOther || (c.hasBool && (c.hasInt || c.hasFloat)):
+		c.kind = parquetByteArray
+	case c.hasBool:
+		c.kind = parquetBoolean
+	case c.hasFloat:
+		c.kind = parquetDouble
+	case c.hasInt:
+		c.kind = parquetInt64
+	default:
+		c.kind = parquetByteArray
+	}
+}
+
+// parquetChunk locates a written column chunk for the file footer
+type parquetChunk struct {
+	offset     int64
+	size       int64
+	valueCount int
+}
+
+// parquetRowGroup is the footer entry of a written row group
+type parquetRowGroup struct {
+	rows   int
+	chunks []parquetChunk
+}
+
+// leafRow flattens a JSON value into leaf field paths and their values, like
+// csvRow but without converting the values to text
+func leafRow(raw []byte) (map[string]interface{}, error) {
+	content, value, err := parseRecordLine(string(raw))
+	if err != nil {
+		return nil, err
+	}
+
+	row := make(map[string]interface{})
+	if content == nil {
+		row["value"] = value
+		return row, nil
+	}
+
+	walkFields(content, "", func(path string, fieldValue interface{}) {
+		if _, ok := fieldValue.(map[string]interface{}); !ok {
+			row[path] = fieldValue
+		}
+	})
+	return row, nil
+}
+
+// convertToParquet writes the values of an input file as an uncompressed Parquet
+// file with one optional column per leaf field path, named like the CSV columns;
+// the input is read twice so the schema can be computed up front
+func convertToParquet(inputPath string, w io.Writer, result *ConversionResult) error {
+	columnsByName := make(map[string]*parquetColumn)
+	firstPass := &ConversionResult{}
+	if err := withConversionInput(inputPath, func(r io.Reader, format string) error {
+		return forEachJSONValue(r, format, firstPass, func(raw []byte) error {
+			row, err := leafRow(raw)
+			if err != nil {
+				return err
+			}
+			for name, value := range row {
+				column := columnsByName[name]
+				if column == nil {
+					column = &parquetColumn{name: name}
+					columnsByName[name] = column
+				}
+				column.observe(value)
+			}
+			return nil
+		})
+	}); err != nil {
+		return err
+	}
+
+	columns := make([]*parquetColumn, 0, len(columnsByName))
+	for _, column := range columnsByName {
+		column.resolve()
+		columns = append(columns, column)
+	}
+	sort.Slice(columns, func(i, j int) bool { return columns[i].name < columns[j].name })
+
+	writer := &parquetWriter{w: bufio.NewWriter(w), columns: columns}
+	if err := writer.write(parquetMagic); err != nil {
+		return err
+	}
+
+	values := make([][]interface{}, len(columns))
+	rows := 0
+	err := withConversionInput(inputPath, func(r io.Reader, format string) error {
+		return forEachJSONValue(r, format, result, func(raw []byte) error {
+			row, err := leafRow(raw)
+			if err != nil {
+				return err
+			}
+			for i, column := range columns {
+				values[i] = append(values[i], row[column.name])
+			}
+			rows++
+			result.RecordsWritten++
+			if rows == parquetRowGroupSize {
+				if err := writer.writeRowGroup(values, rows); err != nil {
+					return err
+				}
+				rows = 0
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return err
+	}
+	if rows > 0 {
+		if err := writer.writeRowGroup(values, rows); err != nil {
+			return err
+		}
+	}
+
+	return writer.finish(result.RecordsWritten)
+}
+
+// parquetWriter writes row groups and the footer, tracking file offsets
+type parquetWriter struct {
+	w         *bufio.Writer
+	offset    int64
+	columns   []*parquetColumn
+	rowGroups []parquetRowGroup
+}
+
+func (p *parquetWriter) write(data []byte) error {
+	n, err := p.w.Write(data)
+	p.offset += int64(n)
+	return err
+}
+
+// writeRowGroup writes one data page per column and clears the buffered values
+func (p *parquetWriter) writeRowGroup(values [][]interface{}, rows int) error {
+	group := parquetRowGroup{rows: rows}
+	for i, column := range p.columns {
+		page := encodeParquetPage(column.kind, values[i])
+
+		var header thriftCompactWriter
+		header.i32Field(1, parquetDataPage)
+		header.i32Field(2, int32(len(page)))
+		header.i32Field(3, int32(len(page)))
+		header.beginStruct(5)
+		header.i32Field(1, int32(rows))
+		header.i32Field(2, parquetEncodingPlain)
+		header.i32Field(3, parquetEncodingRLE)
+		header.i32Field(4, parquetEncodingRLE)
+		header.endStruct()
+		header.stop()
+
+		chunk := parquetChunk{offset: p.offset, size: int64(header.buf.Len() + len(page)), valueCount: rows}
+		if err := p.write(header.buf.Bytes()); err != nil {
+			return err
+		}
+		if err := p.write(page); err != nil {
+			return err
+		}
+		group.chunks = append(group.chunks, chunk)
+		values[i] = values[i][:0]
+	}
+	p.rowGroups = append(p.rowGroups, group)
+	return nil
+}
+
+// finish writes the file metadata footer and flushes the output
+func (p *parquetWriter) finish(rows int) error {
+	var meta thriftCompactWriter
+	meta.i32Field(1, 1)
+
+	meta.listField(2, thriftStruct, len(p.columns)+1)
+	meta.beginElement()
+	meta.binaryField(4, "schema")
+	meta.i32Field(5, int32(len(p.columns)))
+	meta.endStruct()
+	for _, column := range p.columns {
+		meta.beginElement()
+		meta.i32Field(1, int32(column.kind))
+		meta.i32Field(3, parquetOptional)
+		meta.binaryField(4, column.name)
+		if column.kind == parquetByteArray {
+			meta.i32Field(6, parquetConvertUTF8)
+		}
+		meta.endStruct()
+	}
+
+	meta.i64Field(3, int64(rows))
+
+	meta.listField(4, thriftStruct, len(p.rowGroups))
+	for _, group := range p.rowGroups {
+		meta.beginElement()
+		meta.listField(1, thriftStruct, len(group.chunks))
+		var totalSize int64
+		for i, chunk := range group.chunks {
+			totalSize += chunk.size
+			meta.beginElement()
+			meta.i64Field(2, chunk.offset)
+			meta.beginStruct(3)
+			meta.i32Field(1, int32(p.columns[i].kind))
+			meta.listField(2, thriftI32, 2)
+			meta.i32(parquetEncodingPlain)
+			meta.i32(parquetEncodingRLE)
+			meta.listField(3, thriftBinary, 1)
+			meta.binary(p.columns[i].name)
+			meta.i32Field(4, 0) // uncompressed
+			meta.i64Field(5, int64(chunk.valueCount))
+			meta.i64Field(6, chunk.size)
+			meta.i64Field(7, chunk.size)
+			meta.i64Field(9, chunk.offset)
+			meta.endStruct()
+			meta.endStruct()
+		}
+		meta.i64Field(2, totalSize)
+		meta.i64Field(3, int64(group.rows))
+		meta.endStruct()
+	}
+
+	meta.binaryField(6, "jsonl-viewer")
+	meta.stop()
+
+	footer := meta.buf.Bytes()
+	if err := p.write(footer); err != nil {
+		return err
+	}
+	if err := p.write(binary.LittleEndian.AppendUint32(nil, uint32(len(footer)))); err != nil {
+		return err
+	}
+	if err := p.write(parquetMagic); err != nil {
+		return err
+	}
+	return p.w.Flush()
+}
+
+// encodeParquetPage encodes a data page body: definition levels marking which
+// rows hold a value, then the non-null values in plain encoding
+func encodeParquetPage(kind int, values []interface{}) []byte {
+	var levels []byte
+	for start := 0; start < len(values); {
+		defined := values[start] != nil
+		end := start + 1
+		for end < len(values) && (values[end] != nil) == defined {
+			end++
+		}
+		// An RLE run: the run length shifted left once, then the level in one byte
+		levels = binary.AppendUvarint(levels, uint64(end-start)<<1)
+		if defined {
+			levels = append(levels, 1)
+		} else {
+			levels = append(levels, 0)
+		}
+		start = end
+	}
+
+	page := binary.LittleEndian.AppendUint32(nil, uint32(len(levels)))
+	page = append(page, levels...)
+
+	bit := 0
+	for _, value := range values {
+		if value == nil {
+			continue
+		}
+		switch kind {
+		case parquetBoolean:
+			if bit%8 == 0 {
+				page = append(page, 0)
+			}
+			if value.(bool) {
+				page[len(page)-1] |= 1 << (bit % 8)
+			}
+			bit++
+		case parquetInt64:
+			n, _ := strconv.ParseInt(string(value.(json.Number)), 10, 64)
+			page = binary.LittleEndian.AppendUint64(page, uint64(n))
+		case parquetDouble:
+			f, _ := value.(json.Number).Float64()
+			page = binary.LittleEndian.AppendUint64(page, math.Float64bits(f))
+		default:
+			text := valueToString(value)
+			page = binary.LittleEndian.AppendUint32(page, uint32(len(text)))
+			page = append(page, text...)
+		}
+	}
+	return page
+}
+
+// Thrift compact protocol field types
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// thriftCompactWriter encodes the Thrift compact protocol used by Parquet
+// metadata. Fields must be written in increasing id order within a struct.
+type thriftCompactWriter struct {
+	buf     bytes.Buffer
+	lastID  int16
+	parents []int16
+}
+
+func (t *thriftCompactWriter) fieldHeader(id int16, fieldType byte) {
+	if delta := id - t.lastID; delta > 0 && delta <= 15 {
+		t.buf.WriteByte(byte(delta)<<4 | fieldType)
+	} else {
+		t.buf.WriteByte(fieldType)
+		t.varint(int64(id))
+	}
+	t.lastID = id
+}
+
+// varint writes a zigzag-encoded variable-length integer
+func (t *thriftCompactWriter) varint(v int64) {
+	t.buf.Write(binary.AppendUvarint(nil, uint64(v<<1)^uint64(v>>63)))
+}
+
+func (t *thriftCompactWriter) i32(v int32) { t.varint(int64(v)) }
+
+func (t *thriftCompactWriter) binary(s string) {
+	t.buf.Write(binary.AppendUvarint(nil, uint64(len(s))))
+	t.buf.WriteString(s)
+}
+
+func (t *thriftCompactWriter) i32Field(id int16, v int32) {
+	t.fieldHeader(id, thriftI32)
+	t.varint(int64(v))
+}
+
+func (t *thriftCompactWriter) i64Field(id int16, v int64) {
+	t.fieldHeader(id, thriftI64)
+	t.varint(v)
+}
+
+func (t *thriftCompactWriter) binaryField(id int16, s string) {
+	t.fieldHeader(id, thriftBinary)
+	t.binary(s)
+}
+
+// listField writes a list header; the caller then writes size elements
+func (t *thriftCompactWriter) listField(id int16, elementType byte, size int) {
+	t.fieldHeader(id, thriftList)
+	if size < 15 {
+		t.buf.WriteByte(byte(size)<<4 | elementType)
+	} else {
+		t.buf.WriteByte(0xF0 | elementType)
+		t.buf.Write(binary.AppendUvarint(nil, uint64(size)))
+	}
+}
+
+// beginStruct starts a struct-valued field, closed by endStruct
+func (t *thriftCompactWriter) beginStruct(id int16) {
+	t.fieldHeader(id, thriftStruct)
+	t.beginElement()
+}
+
+// beginElement starts a struct that is a list element, closed by endStruct
+func (t *thriftCompactWriter) beginElement() {
+	t.parents = append(t.parents, t.lastID)
+	t.lastID = 0
+}
+
+func (t *thriftCompactWriter) endStruct() {
+	t.stop()
+	t.lastID = t.parents[len(t.parents)-1]
+	t.parents = t.parents[:len(t.parents)-1]
+}
+
+// stop ends the fields of the current struct
+func (t *thriftCompactWriter) stop() {
+	t.buf.WriteByte(0)
+}
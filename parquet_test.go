@@ -0,0 +1,177 @@
+package main
+
+import (
+	"encoding/binary"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// thriftReader decodes Thrift compact protocol values into maps keyed by field id
+type thriftReader struct {
+	data []byte
+	pos  int
+}
+
+func (r *thriftReader) uvarint() uint64 {
+	v, n := binary.Uvarint(r.data[r.pos:])
+	r.pos += n
+	return v
+}
+
+func (r *thriftReader) zigzag() int64 {
+	v := r.uvarint()
+	return int64(v>>1) ^ -int64(v&1)
+}
+
+func (r *thriftReader) value(fieldType byte) interface{} {
+	switch fieldType {
+	case 1:
+		return true
+	case 2:
+		return false
+	case thriftI32, thriftI64:
+		return r.zigzag()
+	case thriftBinary:
+		n := int(r.uvarint())
+		r.pos += n
+		return string(r.data[r.pos-n : r.pos])
+	case thriftList:
+		header := r.data[r.pos]
+		r.pos++
+		size := int(header >> 4)
+		if size == 15 {
+			size = int(r.uvarint())
+		}
+		list := make([]interface{}, size)
+		for i := range list {
+			list[i] = r.value(header & 0x0f)
+		}
+		return list
+	case thriftStruct:
+		fields := make(map[int16]interface{})
+		var lastID int16
+		for {
+			header := r.data[r.pos]
+			r.pos++
+			if header == 0 {
+				return fields
+			}
+			id := lastID + int16(header>>4)
+			if header>>4 == 0 {
+				id = int16(r.zigzag())
+			}
+			fields[id] = r.value(header & 0x0f)
+			lastID = id
+		}
+	}
+	panic("unexpected thrift type")
+}
+
+func TestConvertToParquet(t *testing.T) {
+	dir := t.TempDir()
+	inputPath := filepath.Join(dir, "input.jsonl")
+	outputPath := filepath.Join(dir, "output.parquet")
+
+	input := "{\"id\":1,\"name\":\"a\",\"ok\":true,\"score\":1.5,\"user\":{\"tag\":\"x\"}}\n" +
+		"{\"id\":2,\"score\":2,\"ok\":false,\"tags\":[1,2]}\n" +
+		"not json\n" +
+		"{\"id\":3,\"name\":null,\"big\":123456789012345678901234567890}\n"
+	if err := os.WriteFile(inputPath, []byte(input), 0644); err != nil {
+		t.Fatalf("Failed to write input: %v", err)
+	}
+
+	result, err := convertFileToFormat(inputPath, outputPath, FormatParquet)
+	if err != nil {
+		t.Fatalf("convertFileToFormat returned error: %v", err)
+	}
+	if result.RecordsWritten != 3 || !reflect.DeepEqual(result.SkippedLines, []int{3}) {
+		t.Errorf("Unexpected conversion result: %+v", result)
+	}
+
+	data, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("Failed to read output: %v", err)
+	}
+	if string(data[:4]) != "PAR1" || string(data[len(data)-4:]) != "PAR1" {
+		t.Fatalf("Output is missing the Parquet magic")
+	}
+	footerLength := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	footer := &thriftReader{data: data[len(data)-8-footerLength : len(data)-8]}
+	meta := footer.value(thriftStruct).(map[int16]interface{})
+
+	if meta[3] != int64(3) {
+		t.Errorf("Expected 3 rows, got %v", meta[3])
+	}
+
+	// Columns are the sorted leaf paths, typed when every value agrees
+	expectedTypes := map[string]int64{
+		"big":      parquetByteArray,
+		"id":       parquetInt64,
+		"name":     parquetByteArray,
+		"ok":       parquetBoolean,
+		"score":    parquetDouble,
+		"tags":     parquetByteArray,
+		"user.tag": parquetByteArray,
+	}
+	schema := meta[2].([]interface{})
+	if root := schema[0].(map[int16]interface{}); root[5] != int64(len(expectedTypes)) {
+		t.Errorf("Unexpected schema root: %v", root)
+	}
+	var names []string
+	for _, element := range schema[1:] {
+		fields := element.(map[int16]interface{})
+		name := fields[4].(string)
+		names = append(names, name)
+		if fields[1] != expectedTypes[name] || fields[3] != int64(parquetOptional) {
+			t.Errorf("Unexpected schema element for %s: %v", name, fields)
+		}
+	}
+	if !reflect.DeepEqual(names, []string{"big", "id", "name", "ok", "score", "tags", "user.tag"}) {
+		t.Errorf("Unexpected column order: %v", names)
+	}
+
+	rowGroups := meta[4].([]interface{})
+	chunks := rowGroups[0].(map[int16]interface{})[1].([]interface{})
+	readPage := func(column int) []byte {
+		metaData := chunks[column].(map[int16]interface{})[3].(map[int16]interface{})
+		reader := &thriftReader{data: data, pos: int(metaData[9].(int64))}
+		header := reader.value(thriftStruct).(map[int16]interface{})
+		size := int(header[3].(int64))
+		return data[reader.pos : reader.pos+size]
+	}
+
+	// id: every row defined (one RLE run of 3 ones), then three int64 values
+	page := readPage(1)
+	if levels := page[4 : 4+binary.LittleEndian.Uint32(page)]; !reflect.DeepEqual(levels, []byte{3 << 1, 1}) {
+		t.Errorf("Unexpected id definition levels: %v", levels)
+	}
+	for i, expected := range []uint64{1, 2, 3} {
+		if got := binary.LittleEndian.Uint64(page[6+8*i:]); got != expected {
+			t.Errorf("Expected id %d, got %d", expected, got)
+		}
+	}
+
+	// name: defined in the first row only, null and missing alike otherwise
+	page = readPage(2)
+	if !reflect.DeepEqual(page, []byte{4, 0, 0, 0, 1 << 1, 1, 2 << 1, 0, 1, 0, 0, 0, 'a'}) {
+		t.Errorf("Unexpected name page: %v", page)
+	}
+
+	// ok: bit-packed booleans; score: doubles including the integer 2
+	if page = readPage(3); page[len(page)-1] != 0b01 {
+		t.Errorf("Unexpected ok values: %v", page)
+	}
+	page = readPage(4)
+	if math.Float64frombits(binary.LittleEndian.Uint64(page[len(page)-8:])) != 2 {
+		t.Errorf("Unexpected score page: %v", page)
+	}
+
+	// big: integers beyond int64 keep every digit as text
+	page = readPage(0)
+	if text := string(page[len(page)-30:]); text != "123456789012345678901234567890" {
+		t.Errorf("Unexpected big value %q", text)
+	}
+}
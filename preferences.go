@@ -0,0 +1,143 @@
+package main
+
+import (
+	"sort"
+	"time"
+)
+
+// File preference storage
+const (
+	filePreferencesFile  = "file-preferences.json"
+	maxRememberedFiles   = 200 // least recently updated entries are dropped beyond this
+	filePreferencesEvent = "file:preferences"
+)
+
+// FilePreferences is view state remembered per file path and re-applied when the
+// same file is opened again
+type FilePreferences struct {
+	ShownFields    []string      `json:"shownFields"`
+	HiddenFields   []string      `json:"hiddenFields"`
+	PageSize       int           `json:"pageSize"` // 0 keeps the global default
+	SortField      string        `json:"sortField"`
+	SortDescending bool          `json:"sortDescending"`
+	LastSearch     SearchOptions `json:"lastSearch"`
+	LastOffset     int           `json:"lastOffset"` // first visible record
+	UpdatedAt      time.Time     `json:"updatedAt"`
+}
+
+// loadFilePreferences reads the remembered preferences of every file
+func loadFilePreferences() (map[string]FilePreferences, error) {
+	prefs := make(map[string]FilePreferences)
+	if err := readConfigFile(filePreferencesFile, &prefs); err != nil {
+		return nil, err
+	}
+	return prefs, nil
+}
+
+// saveFilePreferences stores the preferences, keeping only the most recently updated files
+func saveFilePreferences(prefs map[string]FilePreferences) error {
+	if len(prefs) > maxRememberedFiles {
+		paths := make([]string, 0, len(prefs))
+		for path := range prefs {
+			paths = append(paths, path)
+		}
+		sort.Slice(paths, func(i, j int) bool {
+			return prefs[paths[i]].UpdatedAt.After(prefs[paths[j]].UpdatedAt)
+		})
+		for _, path := range paths[maxRememberedFiles:] {
+			delete(prefs, path)
+		}
+	}
+	return writeConfigFile(filePreferencesFile, prefs)
+}
+
+// SaveFilePreferences remembers view state for the currently loaded file
+func (a *App) SaveFilePreferences(preferences FilePreferences) error {
+	a.stateMu.RLock()
+	defer a.stateMu.RUnlock()
+
+	if a.currentFile == nil || a.cache == nil {
+		return &JSONLError{
+			Message: "No file currently loaded",
+			Err:     ErrNoFileLoaded,
+		}
+	}
+
+	// Clipboard content has no path to remember it by
+	if a.currentFile.Path == "<clipboard>" {
+		return nil
+	}
+
+	prefs, err := loadFilePreferences()
+	if err != nil {
+		return err
+	}
+	preferences.UpdatedAt = time.Now()
+	prefs[a.currentFile.Path] = preferences
+	return saveFilePreferences(prefs)
+}
+
+// GetFilePreferences returns the remembered preferences of the currently loaded
+// file, or nil when none were saved
+func (a *App) GetFilePreferences() (*FilePreferences, error) {
+	a.stateMu.RLock()
+	defer a.stateMu.RUnlock()
+	return a.getFilePreferences()
+}
+
+// getFilePreferences implements GetFilePreferences; the caller holds stateMu
+func (a *App) getFilePreferences() (*FilePreferences, error) {
+	if a.currentFile == nil || a.cache == nil {
+		return nil, &JSONLError{
+			Message: "No file currently loaded",
+			Err:     ErrNoFileLoaded,
+		}
+	}
+
+	prefs, err := loadFilePreferences()
+	if err != nil {
+		return nil, err
+	}
+	if preferences, exists := prefs[a.currentFile.Path]; exists {
+		return &preferences, nil
+	}
+	return nil, nil
+}
+
+// ForgetFilePreferences removes the remembered preferences of the current file
+func (a *App) ForgetFilePreferences() error {
+	a.stateMu.RLock()
+	defer a.stateMu.RUnlock()
+
+	if a.currentFile == nil {
+		return &JSONLError{
+			Message: "No file currently loaded",
+			Err:     ErrNoFileLoaded,
+		}
+	}
+
+	prefs, err := loadFilePreferences()
+	if err != nil {
+		return err
+	}
+	delete(prefs, a.currentFile.Path)
+	return saveFilePreferences(prefs)
+}
+
+// applyFilePreferences restores the remembered page size of a newly opened file and
+// emits the "file:preferences" event so the frontend can restore the rest of the
+// view. The caller holds stateMu.
+func (a *App) applyFilePreferences() {
+	preferences, err := a.getFilePreferences()
+	if err != nil || preferences == nil {
+		return
+	}
+
+	if preferences.PageSize > 0 && !a.autoPageSize {
+		a.cache.pageSize = preferences.PageSize
+		if a.cache.pageSize > 1000 {
+			a.cache.pageSize = 1000 // Cap maximum page size
+		}
+	}
+	a.emitEvent(filePreferencesEvent, preferences)
+}
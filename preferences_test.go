@@ -0,0 +1,70 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// useTempConfigDir redirects persisted app state to a temporary directory
+func useTempConfigDir(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	original := configDir
+	configDir = func() (string, error) { return dir, nil }
+	t.Cleanup(func() { configDir = original })
+	return dir
+}
+
+func TestFilePreferences(t *testing.T) {
+	useTempConfigDir(t)
+
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	if err := os.WriteFile(path, []byte("{\"a\":1}\n{\"a\":2}\n"), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	app := &App{}
+	if _, err := app.LoadJSONLFile(path); err != nil {
+		t.Fatalf("LoadJSONLFile returned error: %v", err)
+	}
+	if preferences, err := app.GetFilePreferences(); err != nil || preferences != nil {
+		t.Fatalf("Expected no preferences before saving, got %+v, %v", preferences, err)
+	}
+
+	saved := FilePreferences{
+		HiddenFields: []string{"secret"},
+		PageSize:     20,
+		SortField:    "a",
+		LastSearch:   SearchOptions{Query: "a:2", UseLucene: true},
+		LastOffset:   1,
+	}
+	if err := app.SaveFilePreferences(saved); err != nil {
+		t.Fatalf("SaveFilePreferences returned error: %v", err)
+	}
+
+	// Reopening the file restores them
+	app = &App{}
+	if _, err := app.LoadJSONLFile(path); err != nil {
+		t.Fatalf("LoadJSONLFile returned error: %v", err)
+	}
+	if pageSize, _ := app.GetPageSize(); pageSize != 20 {
+		t.Errorf("Expected remembered page size 20, got %d", pageSize)
+	}
+	preferences, err := app.GetFilePreferences()
+	if err != nil || preferences == nil {
+		t.Fatalf("Expected remembered preferences, got %+v, %v", preferences, err)
+	}
+	if !reflect.DeepEqual(preferences.HiddenFields, saved.HiddenFields) || preferences.LastSearch.Query != "a:2" || preferences.UpdatedAt.IsZero() {
+		t.Errorf("Unexpected preferences: %+v", preferences)
+	}
+
+	if err := app.ForgetFilePreferences(); err != nil {
+		t.Fatalf("ForgetFilePreferences returned error: %v", err)
+	}
+	if preferences, _ := app.GetFilePreferences(); preferences != nil {
+		t.Errorf("Expected preferences to be forgotten, got %+v", preferences)
+	}
+}
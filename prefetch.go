@@ -0,0 +1,203 @@
+package main
+
+import (
+	"encoding/json"
+	"sync"
+)
+
+// maxPrefetchedPages bounds the number of highlighted pages kept per loaded file
+const maxPrefetchedPages = 8
+
+// HighlightedPage is a page of records with highlight matches for a query
+type HighlightedPage struct {
+	Page       *PaginatedRecords  `json:"page"`
+	Query      string             `json:"query"`
+	Highlights [][]HighlightMatch `json:"highlights"` // one entry per record in Page
+}
+
+// pageKey identifies a highlighted page in the page cache
+type pageKey struct {
+	offset        int
+	limit         int
+	query         string
+	caseSensitive bool
+	options       string // the search's other options, see newPageKey
+}
+
+// newPageKey keys a page highlighted for search by every option that affects
+// which records match; paging, the cancellation token and the version are left out
+func newPageKey(offset, limit int, search SearchOptions) pageKey {
+	key := pageKey{offset: offset, limit: limit, query: search.Query, caseSensitive: search.CaseSensitive}
+	search.Query, search.CaseSensitive = "", false
+	search.Offset, search.Limit, search.Token, search.Version = 0, 0, "", ""
+	search.SelectedFields = searchFields(search.SelectedFields)
+	options, _ := json.Marshal(search)
+	key.options = string(options)
+	return key
+}
+
+// pageCache keeps recently served and prefetched pages, evicting the oldest first.
+// The zero value is ready to use and it lives in RecordCache, so loading a file
+// starts with an empty cache.
+type pageCache struct {
+	mu      sync.Mutex
+	pages   map[pageKey]*HighlightedPage
+	order   []pageKey
+	pending map[pageKey]bool // pages being prefetched
+}
+
+func (c *pageCache) get(key pageKey) (*HighlightedPage, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	page, exists := c.pages[key]
+	return page, exists
+}
+
+// claim reports whether the page for key is neither cached nor being prefetched,
+// and if so marks it as being prefetched until it is put
+func (c *pageCache) claim(key pageKey) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, exists := c.pages[key]; exists || c.pending[key] {
+		return false
+	}
+	if c.pending == nil {
+		c.pending = make(map[pageKey]bool)
+	}
+	c.pending[key] = true
+	return true
+}
+
+func (c *pageCache) put(key pageKey, page *HighlightedPage) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.pending, key)
+	if c.pages == nil {
+		c.pages = make(map[pageKey]*HighlightedPage)
+	}
+	if _, exists := c.pages[key]; !exists {
+		c.order = append(c.order, key)
+	}
+	c.pages[key] = page
+
+	for len(c.order) > maxPrefetchedPages {
+		delete(c.pages, c.order[0])
+		c.order = c.order[1:]
+	}
+}
+
+// reset drops every cached page
+func (c *pageCache) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pages = nil
+	c.order = nil
+	c.pending = nil
+}
+
+// buildHighlightedPage slices a page out of cache and computes highlights for the key's query
+func (a *App) buildHighlightedPage(cache *RecordCache, key pageKey) *HighlightedPage {
+	page := &PaginatedRecords{
+		Records: []JSONRecord{},
+		Offset:  key.offset,
+		Limit:   key.limit,
+		Total:   cache.totalCount,
+		Version: cache.versionToken(),
+	}
+	if key.offset < cache.totalCount {
+		endIndex := key.offset + key.limit
+		if endIndex > cache.totalCount {
+			endIndex = cache.totalCount
+		}
+		records, err := cache.slice(key.offset, endIndex)
+		if err != nil {
+			records = []JSONRecord{}
+		}
+		page.Records = records
+		page.HasMore = endIndex < cache.totalCount
+	}
+
+	highlights := make([][]HighlightMatch, len(page.Records))
+	for i, record := range page.Records {
+		matches, err := a.GetSearchHighlights(record, key.query, key.caseSensitive)
+		if err != nil || matches == nil {
+			matches = []HighlightMatch{}
+		}
+		highlights[i] = matches
+	}
+
+	return &HighlightedPage{Page: page, Query: key.query, Highlights: highlights}
+}
+
+// prefetchAdjacentPages prepares the pages before and after key in the background
+// so scrolling in either direction is served from the page cache. Pages already
+// cached or being prefetched are skipped. The caller holds stateMu.
+func (a *App) prefetchAdjacentPages(key pageKey) {
+	cache := a.cache
+
+	var neighbours []pageKey
+	if key.offset > 0 {
+		previous := key
+		previous.offset -= key.limit
+		if previous.offset < 0 {
+			previous.offset = 0
+		}
+		if cache.pages.claim(previous) {
+			neighbours = append(neighbours, previous)
+		}
+	}
+	if key.offset+key.limit < cache.totalCount {
+		next := key
+		next.offset += key.limit
+		if cache.pages.claim(next) {
+			neighbours = append(neighbours, next)
+		}
+	}
+	if len(neighbours) == 0 {
+		return
+	}
+
+	go func() {
+		a.stateMu.RLock()
+		defer a.stateMu.RUnlock()
+		for _, neighbour := range neighbours {
+			cache.pages.put(neighbour, a.buildHighlightedPage(cache, neighbour))
+		}
+	}()
+}
+
+// GetRecordsWithHighlights returns a page of records with highlights for query,
+// served from the page cache when it was prefetched, and prefetches its neighbours
+func (a *App) GetRecordsWithHighlights(offset, limit int, query string, caseSensitive bool) (*HighlightedPage, error) {
+	a.stateMu.RLock()
+	defer a.stateMu.RUnlock()
+
+	if a.currentFile == nil || a.cache == nil {
+		return nil, &JSONLError{
+			Message: "No file currently loaded",
+			Err:     ErrNoFileLoaded,
+		}
+	}
+
+	// Validate parameters
+	if offset < 0 {
+		offset = 0
+	}
+	if limit <= 0 {
+		limit = a.cache.pageSize
+	}
+	if limit > 1000 {
+		limit = 1000 // Cap maximum limit for performance
+	}
+
+	key := newPageKey(offset, limit, SearchOptions{Query: query, CaseSensitive: caseSensitive})
+	page, exists := a.cache.pages.get(key)
+	if !exists {
+		page = a.buildHighlightedPage(a.cache, key)
+		a.cache.pages.put(key, page)
+	}
+
+	a.prefetchAdjacentPages(key)
+	return page, nil
+}
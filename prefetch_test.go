@@ -0,0 +1,115 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGetRecordsWithHighlights(t *testing.T) {
+	app := newTestApp(t, `{"msg":"error one"}
+{"msg":"fine"}
+{"msg":"error two"}
+{"msg":"fine again"}
+{"msg":"error three"}`)
+
+	page, err := app.GetRecordsWithHighlights(2, 2, "error", false)
+	if err != nil {
+		t.Fatalf("GetRecordsWithHighlights returned error: %v", err)
+	}
+	if len(page.Page.Records) != 2 || page.Page.Records[0].LineNumber != 3 || !page.Page.HasMore {
+		t.Errorf("Unexpected page: %+v", page.Page)
+	}
+	if len(page.Highlights) != 2 || len(page.Highlights[0]) == 0 || len(page.Highlights[1]) != 0 {
+		t.Errorf("Unexpected highlights: %+v", page.Highlights)
+	}
+
+	// Both neighbours are prepared in the background
+	for _, offset := range []int{0, 4} {
+		key := newPageKey(offset, 2, SearchOptions{Query: "error"})
+		deadline := time.Now().Add(2 * time.Second)
+		for {
+			if _, exists := app.cache.pages.get(key); exists {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("Page at offset %d was not prefetched", offset)
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+
+	cached, _ := app.cache.pages.get(newPageKey(4, 2, SearchOptions{Query: "error"}))
+	if len(cached.Page.Records) != 1 || cached.Page.HasMore || len(cached.Highlights[0]) == 0 {
+		t.Errorf("Unexpected prefetched last page: %+v", cached)
+	}
+}
+
+func TestPageCacheEviction(t *testing.T) {
+	var cache pageCache
+	for i := 0; i <= maxPrefetchedPages; i++ {
+		cache.put(pageKey{offset: i}, &HighlightedPage{})
+	}
+
+	if _, exists := cache.get(pageKey{offset: 0}); exists {
+		t.Errorf("Expected the oldest page to be evicted")
+	}
+	if _, exists := cache.get(pageKey{offset: maxPrefetchedPages}); !exists {
+		t.Errorf("Expected the newest page to be cached")
+	}
+}
+
+func TestGetRecordsServedFromPageCache(t *testing.T) {
+	app := newTestApp(t, `{"n":1}
+{"n":2}
+{"n":3}
+{"n":4}`)
+	if _, err := app.PinBaseline(1); err != nil {
+		t.Fatalf("PinBaseline returned error: %v", err)
+	}
+
+	// A cached page is served as is, with baseline diffs still attached
+	key := newPageKey(2, 2, SearchOptions{})
+	cached := app.buildHighlightedPage(app.cache, key)
+	cached.Page.Records = cached.Page.Records[:1]
+	app.cache.pages.put(key, cached)
+
+	page, err := app.GetRecords(2, 2)
+	if err != nil {
+		t.Fatalf("GetRecords returned error: %v", err)
+	}
+	if len(page.Records) != 1 || page.Records[0].LineNumber != 3 || len(page.Records[0].BaselineDiffs) != 1 {
+		t.Errorf("Expected the cached page with baseline diffs, got %+v", page.Records)
+	}
+	if cached.Page.Records[0].BaselineDiffs != nil {
+		t.Error("Expected the cached records to be left without baseline diffs")
+	}
+
+	// Pages are only served for the search they were cached for, every option included
+	app.setActiveSearch(SearchOptions{Query: "n", WholeWord: true})
+	if page, _ := app.GetRecords(2, 2); len(page.Records) != 2 {
+		t.Errorf("Expected a page cached for another search to be skipped, got %+v", page.Records)
+	}
+	app.cache.pages.put(newPageKey(2, 2, SearchOptions{Query: "n", WholeWord: true, Limit: 50, Token: "t"}), cached)
+	if page, _ := app.GetRecords(2, 2); len(page.Records) != 1 {
+		t.Errorf("Expected the page cached for the same search options, got %+v", page.Records)
+	}
+}
+
+func TestPageCacheClaim(t *testing.T) {
+	var cache pageCache
+	key := pageKey{offset: 10, limit: 5}
+	if !cache.claim(key) {
+		t.Fatal("Expected an uncached page to be claimed")
+	}
+	if cache.claim(key) {
+		t.Error("Expected a page being prefetched not to be claimed again")
+	}
+	cache.put(key, &HighlightedPage{})
+	if cache.claim(key) {
+		t.Error("Expected a cached page not to be claimed")
+	}
+	cache.reset()
+	if !cache.claim(key) {
+		t.Error("Expected the page to be claimed again after a reset")
+	}
+}
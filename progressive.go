@@ -0,0 +1,179 @@
+package main
+
+import "context"
+
+// Default progressive search tuning
+const (
+	progressiveSearchThreshold = 100000 // minimum record count before searches become progressive
+	progressiveReportInterval  = 50000  // records scanned between "search:count" events
+)
+
+// progressiveTuning sets when searches become progressive and how often they
+// report; zero fields use the defaults, so tests can use small files
+type progressiveTuning struct {
+	threshold int
+	interval  int
+}
+
+// progressiveSettings returns the app's progressive tuning with defaults applied
+func (a *App) progressiveSettings() progressiveTuning {
+	tuning := a.progressive
+	if tuning.threshold <= 0 {
+		tuning.threshold = progressiveSearchThreshold
+	}
+	if tuning.interval <= 0 {
+		tuning.interval = progressiveReportInterval
+	}
+	return tuning
+}
+
+// searchSequence numbers searches so tokens and events can be matched to requests
+var searchSequence int64
+
+// SearchCountProgress is emitted as the "search:count" event while a progressive
+// search keeps counting matches in the background
+type SearchCountProgress struct {
+	SearchID       string `json:"searchId"`
+	Scanned        int    `json:"scanned"`
+	Total          int    `json:"total"`
+	Matches        int    `json:"matches"`         // matches found so far
+	EstimatedTotal int    `json:"estimatedTotal"`  // extrapolated from the scanned fraction
+	Done           bool   `json:"done"`            // when true, Matches is the exact total
+	Error          string `json:"error,omitempty"` // set when counting stopped because records could not be read
+}
+
+// estimateMatches extrapolates the total match count from a partial scan
+func estimateMatches(matches, scanned, total int) int {
+	if scanned <= 0 || scanned >= total {
+		return matches
+	}
+	estimate := int(float64(matches) * float64(total) / float64(scanned))
+	if estimate < matches {
+		estimate = matches
+	}
+	return estimate
+}
+
+// countRemainingMatches continues counting matches from record start of total,
+// reading records in pages with read and reporting progress every interval records
+// and once more when finished. It stops early, without a final report, when ctx is
+// cancelled, and with an error report when a page cannot be read.
+func countRemainingMatches(ctx context.Context, read func(start, end int) ([]JSONRecord, error), total, start, matched, interval int, matches func(JSONRecord) bool, report func(SearchCountProgress)) {
+	for pageStart := start; pageStart < total; pageStart += indexPageRecords {
+		records, err := read(pageStart, min(pageStart+indexPageRecords, total))
+		if err != nil {
+			if ctx.Err() == nil {
+				report(SearchCountProgress{Scanned: pageStart, Total: total, Matches: matched, Error: err.Error()})
+			}
+			return
+		}
+
+		for i, record := range records {
+			if matches(record) {
+				matched++
+			}
+
+			scanned := pageStart + i + 1
+			if scanned%interval == 0 && scanned < total {
+				if ctx.Err() != nil {
+					return
+				}
+				report(SearchCountProgress{
+					Scanned:        scanned,
+					Total:          total,
+					Matches:        matched,
+					EstimatedTotal: estimateMatches(matched, scanned, total),
+				})
+			}
+		}
+	}
+
+	if ctx.Err() != nil {
+		return
+	}
+	report(SearchCountProgress{
+		Scanned:        total,
+		Total:          total,
+		Matches:        matched,
+		EstimatedTotal: matched,
+		Done:           true,
+	})
+}
+
+// searchProgressive scans only until the requested page is filled and returns an
+// estimated TotalMatches; the exact count is completed in the background and
+// delivered through "search:count" events tagged with the result's SearchID
+func (a *App) searchProgressive(options SearchOptions, matches func(JSONRecord) bool) (*SearchResult, error) {
+	cache := a.cache
+	total := cache.totalCount
+	pageEnd := options.Offset + options.Limit
+
+	page := []JSONRecord{}
+	matched := 0
+	scanned := 0
+	err := cache.forEach(func(record JSONRecord) bool {
+		if matched >= pageEnd {
+			return false
+		}
+		if matches(record) {
+			if matched >= options.Offset {
+				page = append(page, record)
+			}
+			matched++
+		}
+		scanned++
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Cancel the background count of any previous progressive search
+	if previous := a.swapProgressiveCancel(nil); previous != nil {
+		previous()
+	}
+
+	result := &SearchResult{
+		Records:      page,
+		Offset:       options.Offset,
+		Limit:        options.Limit,
+		Total:        total,
+		TotalMatches: estimateMatches(matched, scanned, total),
+		Query:        options.Query,
+		Version:      a.cache.versionToken(),
+	}
+
+	a.audit(AuditEntry{Action: AuditSearchRun, Path: a.auditPath(), Query: options.Query, Records: result.TotalMatches, Detail: "estimated"})
+
+	if scanned >= total {
+		result.HasMore = pageEnd < matched
+		return result, nil
+	}
+
+	// The page is full but the scan is not: more matches may follow. The count
+	// runs under the search's token, so CancelSearch stops it.
+	result.HasMore = true
+	result.Estimated = true
+	searchID, ctx, done := a.registerSearch(options.Token)
+	result.SearchID = searchID
+
+	if previous := a.swapProgressiveCancel(done); previous != nil {
+		previous()
+	}
+	// The count reads the records of this search's file even if another is loaded
+	read := func(start, end int) ([]JSONRecord, error) {
+		a.stateMu.RLock()
+		defer a.stateMu.RUnlock()
+		return cache.slice(start, end)
+	}
+	interval := a.progressiveSettings().interval
+	go func() {
+		defer done()
+		countRemainingMatches(ctx, read, total, scanned, matched, interval, matches, func(progress SearchCountProgress) {
+			progress.SearchID = searchID
+			a.emitEvent("search:count", progress)
+		})
+	}()
+
+	return result, nil
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestSearchProgressive(t *testing.T) {
+	var lines []string
+	for i := 1; i <= 100; i++ {
+		level := "info"
+		if i%4 == 0 {
+			level = "error"
+		}
+		lines = append(lines, fmt.Sprintf(`{"id":%d,"level":"%s"}`, i, level))
+	}
+	app := newTestApp(t, strings.Join(lines, "\n"))
+
+	app.progressive = progressiveTuning{threshold: 10, interval: 10}
+
+	options := SearchOptions{Query: "level:error", UseLucene: true, Limit: 5, Progressive: true}
+	result, err := app.SearchRecords(options)
+	if err != nil {
+		t.Fatalf("SearchRecords returned error: %v", err)
+	}
+	// Stop the background count started by the search
+	if cancel := app.swapProgressiveCancel(nil); cancel != nil {
+		cancel()
+	}
+
+	// The first 5 matches are found after scanning 20 records, so 25 are extrapolated
+	if !result.Estimated || result.SearchID == "" || result.TotalMatches != 25 || !result.HasMore {
+		t.Errorf("Expected an estimated result, got %+v", result)
+	}
+	if len(result.Records) != 5 || result.Records[4].LineNumber != 20 {
+		t.Errorf("Unexpected page: %+v", result.Records)
+	}
+
+	matches, err := app.searchPredicate(options)
+	if err != nil {
+		t.Fatalf("searchPredicate returned error: %v", err)
+	}
+	var reports []SearchCountProgress
+	countRemainingMatches(context.Background(), app.cache.slice, app.cache.totalCount, 20, 5, 10, matches, func(p SearchCountProgress) {
+		reports = append(reports, p)
+	})
+
+	last := reports[len(reports)-1]
+	if !last.Done || last.Matches != 25 || last.Scanned != 100 {
+		t.Errorf("Expected final exact count of 25, got %+v", last)
+	}
+	if len(reports) != 8 || reports[0].Scanned != 30 || reports[0].Done {
+		t.Errorf("Expected periodic reports every 10 records, got %+v", reports)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	reports = nil
+	countRemainingMatches(ctx, app.cache.slice, app.cache.totalCount, 20, 5, 10, matches, func(p SearchCountProgress) {
+		reports = append(reports, p)
+	})
+	if len(reports) != 0 {
+		t.Errorf("Expected no reports after cancellation, got %+v", reports)
+	}
+}
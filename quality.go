@@ -0,0 +1,227 @@
+package main
+
+import (
+	"hash/fnv"
+	"sort"
+	"time"
+)
+
+// Limits for the timestamp gap analysis
+const (
+	gapMedianMultiplier = 10 // intervals this many times the median are reported as gaps
+	maxReportedGaps     = 10
+)
+
+// FieldQuality describes completeness and type consistency of a single field
+type FieldQuality struct {
+	Field           string  `json:"field"`
+	Completeness    float64 `json:"completeness"`    // fraction of records containing the field
+	TypeConsistency float64 `json:"typeConsistency"` // fraction of values with the dominant type
+	DominantType    string  `json:"dominantType"`
+}
+
+// TimeGap is an unusually long interval between two consecutive records
+type TimeGap struct {
+	AfterLine  int       `json:"afterLine"`
+	BeforeLine int       `json:"beforeLine"`
+	From       time.Time `json:"from"`
+	To         time.Time `json:"to"`
+	Seconds    float64   `json:"seconds"`
+}
+
+// TimestampMetrics describes ordering and gaps of the records' timestamps
+type TimestampMetrics struct {
+	Field                 string    `json:"field"`
+	ParsedCount           int       `json:"parsedCount"`
+	OutOfOrder            int       `json:"outOfOrder"`
+	IsMonotonic           bool      `json:"isMonotonic"`
+	First                 time.Time `json:"first"`
+	Last                  time.Time `json:"last"`
+	MedianIntervalSeconds float64   `json:"medianIntervalSeconds"`
+	MaxGapSeconds         float64   `json:"maxGapSeconds"`
+	Gaps                  []TimeGap `json:"gaps"`
+}
+
+// QualityMetrics summarizes the data quality of the loaded file
+type QualityMetrics struct {
+	TotalLines       int               `json:"totalLines"`
+	ValidRecords     int               `json:"validRecords"`
+	AnalyzedRecords  int               `json:"analyzedRecords"` // records covered by duplicate, field and timestamp metrics
+	InvalidLines     int               `json:"invalidLines"`
+	InvalidLineRatio float64           `json:"invalidLineRatio"`
+	DuplicateRecords int               `json:"duplicateRecords"`
+	DuplicateRatio   float64           `json:"duplicateRatio"`
+	TypeConsistency  float64           `json:"typeConsistency"` // average over all fields
+	Fields           []FieldQuality    `json:"fields"`
+	Timestamp        *TimestampMetrics `json:"timestamp"`
+}
+
+// GetQualityMetrics computes completeness, type consistency, invalid and duplicate
+// ratios and timestamp ordering for the loaded file in one call. A non-nil filter
+// with a query restricts the record-level metrics to matching records; line counts
+// always describe the whole file.
+func (a *App) GetQualityMetrics(filter *SearchOptions) (*QualityMetrics, error) {
+	a.stateMu.RLock()
+	defer a.stateMu.RUnlock()
+
+	if a.currentFile == nil || a.cache == nil {
+		return nil, &JSONLError{
+			Message: "No file currently loaded",
+			Err:     ErrNoFileLoaded,
+		}
+	}
+
+	metrics := &QualityMetrics{
+		ValidRecords: a.cache.totalCount,
+		Fields:       []FieldQuality{},
+	}
+
+	if stats := a.cache.stats; stats != nil {
+		metrics.TotalLines = stats.TotalLines
+		metrics.InvalidLines = len(stats.InvalidLines)
+	}
+	if nonEmpty := metrics.ValidRecords + metrics.InvalidLines; nonEmpty > 0 {
+		metrics.InvalidLineRatio = float64(metrics.InvalidLines) / float64(nonEmpty)
+	}
+
+	// Duplicates are detected on the raw line using a hash to keep memory low
+	seen := make(map[uint64]bool)
+	fields := newFieldCatalog()
+	var sample []JSONRecord
+	err := a.forEachScoped(filter, func(record JSONRecord) {
+		metrics.AnalyzedRecords++
+		hash := fnv.New64a()
+		hash.Write([]byte(record.RawJSON))
+		sum := hash.Sum64()
+		if seen[sum] {
+			metrics.DuplicateRecords++
+		}
+		seen[sum] = true
+		fields.add(record)
+		if len(sample) < 100 {
+			sample = append(sample, record)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	records := metrics.AnalyzedRecords
+	if records > 0 {
+		metrics.DuplicateRatio = float64(metrics.DuplicateRecords) / float64(records)
+	}
+
+	catalog := fields.fields()
+	totalConsistency := 0.0
+	for _, info := range catalog {
+		quality := FieldQuality{
+			Field:        info.Path,
+			DominantType: info.DominantType,
+		}
+		if records > 0 {
+			quality.Completeness = float64(info.Count) / float64(records)
+		}
+		if info.Count > 0 {
+			quality.TypeConsistency = float64(info.Types[info.DominantType]) / float64(info.Count)
+		}
+		totalConsistency += quality.TypeConsistency
+		metrics.Fields = append(metrics.Fields, quality)
+	}
+	if len(catalog) > 0 {
+		metrics.TypeConsistency = totalConsistency / float64(len(catalog))
+	} else {
+		metrics.TypeConsistency = 1
+	}
+
+	// detectTimestampField only looks at the first records
+	if field := detectTimestampField(sample); field != "" {
+		var points []timePoint
+		err := a.forEachScoped(filter, func(record JSONRecord) {
+			value, exists := getFieldValue(record.Content, field)
+			if !exists {
+				return
+			}
+			if t, ok := parseTimestamp(value); ok {
+				points = append(points, timePoint{line: record.LineNumber, t: t})
+			}
+		})
+		if err != nil {
+			return nil, err
+		}
+		metrics.Timestamp = analyzeTimestamps(points, field)
+	}
+
+	return metrics, nil
+}
+
+// timePoint is a parsed timestamp and the line it was read from
+type timePoint struct {
+	line int
+	t    time.Time
+}
+
+// analyzeTimestamps checks ordering and gaps of a timestamp field's values in
+// file order
+func analyzeTimestamps(points []timePoint, field string) *TimestampMetrics {
+	metrics := &TimestampMetrics{
+		Field:       field,
+		IsMonotonic: true,
+		Gaps:        []TimeGap{},
+	}
+
+	metrics.ParsedCount = len(points)
+	if len(points) == 0 {
+		return metrics
+	}
+
+	metrics.First = points[0].t
+	metrics.Last = points[0].t
+
+	var intervals []float64
+	for i := 1; i < len(points); i++ {
+		t := points[i].t
+		if t.Before(metrics.First) {
+			metrics.First = t
+		}
+		if t.After(metrics.Last) {
+			metrics.Last = t
+		}
+
+		interval := t.Sub(points[i-1].t).Seconds()
+		if interval < 0 {
+			metrics.OutOfOrder++
+			metrics.IsMonotonic = false
+			continue
+		}
+		intervals = append(intervals, interval)
+		if interval > metrics.MaxGapSeconds {
+			metrics.MaxGapSeconds = interval
+		}
+	}
+
+	if len(intervals) == 0 {
+		return metrics
+	}
+
+	sorted := append([]float64(nil), intervals...)
+	sort.Float64s(sorted)
+	metrics.MedianIntervalSeconds = sorted[len(sorted)/2]
+
+	// Report intervals far above the median as gaps
+	if metrics.MedianIntervalSeconds > 0 {
+		limit := metrics.MedianIntervalSeconds * gapMedianMultiplier
+		for i := 1; i < len(points) && len(metrics.Gaps) < maxReportedGaps; i++ {
+			interval := points[i].t.Sub(points[i-1].t).Seconds()
+			if interval > limit {
+				metrics.Gaps = append(metrics.Gaps, TimeGap{
+					AfterLine:  points[i-1].line,
+					BeforeLine: points[i].line,
+					From:       points[i-1].t,
+					To:         points[i].t,
+					Seconds:    interval,
+				})
+			}
+		}
+	}
+
+	return metrics
+}
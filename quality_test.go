@@ -0,0 +1,77 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestGetQualityMetrics(t *testing.T) {
+	app := newTestApp(t, `{"ts":"2024-05-01T00:00:00Z","level":"info","code":200}
+{"ts":"2024-05-01T00:00:01Z","level":"info","code":"200"}
+not json
+{"ts":"2024-05-01T00:00:02Z","level":"warn"}
+{"ts":"2024-05-01T00:00:02Z","level":"warn"}
+{"ts":"2024-05-01T00:01:00Z","level":"error","code":500}
+{"ts":"2024-05-01T00:00:59Z","level":"error","code":503}`)
+
+	metrics, err := app.GetQualityMetrics(nil)
+	if err != nil {
+		t.Fatalf("GetQualityMetrics returned error: %v", err)
+	}
+
+	if metrics.ValidRecords != 6 || metrics.InvalidLines != 1 {
+		t.Errorf("Unexpected record counts: %+v", metrics)
+	}
+	if math.Abs(metrics.InvalidLineRatio-1.0/7.0) > 1e-9 {
+		t.Errorf("Expected invalid ratio 1/7, got %v", metrics.InvalidLineRatio)
+	}
+	if metrics.DuplicateRecords != 1 {
+		t.Errorf("Expected 1 duplicate, got %d", metrics.DuplicateRecords)
+	}
+
+	for _, field := range metrics.Fields {
+		if field.Field == "code" {
+			if math.Abs(field.Completeness-4.0/6.0) > 1e-9 || field.TypeConsistency != 0.75 {
+				t.Errorf("Unexpected code quality: %+v", field)
+			}
+		}
+	}
+
+	ts := metrics.Timestamp
+	if ts == nil {
+		t.Fatal("Expected timestamp metrics")
+	}
+	if ts.Field != "ts" || ts.ParsedCount != 6 || ts.OutOfOrder != 1 || ts.IsMonotonic {
+		t.Errorf("Unexpected timestamp metrics: %+v", ts)
+	}
+	if len(ts.Gaps) != 1 || ts.Gaps[0].AfterLine != 5 || ts.Gaps[0].BeforeLine != 6 {
+		t.Errorf("Expected one gap between lines 5 and 6, got %+v", ts.Gaps)
+	}
+}
+
+func TestParseTimestamp(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    interface{}
+		expected int64 // Unix milliseconds
+		ok       bool
+	}{
+		{"RFC3339", "2024-05-01T10:00:00Z", 1714557600000, true},
+		{"RFC3339 with offset", "2024-05-01T12:00:00+02:00", 1714557600000, true},
+		{"Space separated", "2024-05-01 10:00:00", 1714557600000, true},
+		{"Epoch seconds", float64(1714557600), 1714557600000, true},
+		{"Epoch millis", float64(1714557600000), 1714557600000, true},
+		{"Epoch string", "1714557600", 1714557600000, true},
+		{"Not a timestamp", "hello", 0, false},
+		{"Boolean", true, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, ok := parseTimestamp(tt.value)
+			if ok != tt.ok || (ok && result.UnixMilli() != tt.expected) {
+				t.Errorf("Expected (%d, %v), got (%d, %v)", tt.expected, tt.ok, result.UnixMilli(), ok)
+			}
+		})
+	}
+}
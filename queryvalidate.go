@@ -0,0 +1,134 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// QueryValidation is the result of checking a Lucene query without running it
+type QueryValidation struct {
+	Valid      bool              `json:"valid"`
+	Query      *LuceneQuery      `json:"query,omitempty"`      // the parsed tree
+	Normalized string            `json:"normalized,omitempty"` // canonical form, e.g. with explicit ANDs
+	Error      *QuerySyntaxError `json:"error,omitempty"`
+}
+
+// ValidateQuery parses a Lucene query and returns its tree and normalized form, or
+// where and why it fails to parse, so syntax problems can be shown while typing
+func (a *App) ValidateQuery(query string) QueryValidation {
+	parsed, err := parseLucene(query)
+	if err != nil {
+		var syntaxErr *QuerySyntaxError
+		if !errors.As(err, &syntaxErr) {
+			syntaxErr = &QuerySyntaxError{Message: err.Error()}
+		}
+		return QueryValidation{Error: syntaxErr}
+	}
+
+	return QueryValidation{
+		Valid:      true,
+		Query:      parsed,
+		Normalized: formatLuceneQuery(parsed),
+	}
+}
+
+// formatLuceneQuery renders a parsed query in canonical Lucene syntax, which parses
+// back to the same tree
+func formatLuceneQuery(query *LuceneQuery) string {
+	// grouped wraps operands that would otherwise bind differently
+	grouped := func(operand *LuceneQuery, types ...string) string {
+		for _, t := range types {
+			if operand.Type == t {
+				return "(" + formatLuceneQuery(operand) + ")"
+			}
+		}
+		return formatLuceneQuery(operand)
+	}
+	prefix := ""
+	if query.Field != "" {
+		prefix = escapeQueryText(query.Field, false) + ":"
+	}
+
+	switch query.Type {
+	case "or":
+		return formatLuceneQuery(query.Left) + " OR " + grouped(query.Right, "or")
+	case "and":
+		return grouped(query.Left, "or") + " AND " + grouped(query.Right, "or", "and")
+	case "not":
+		return "NOT " + grouped(query.Query, "or", "and")
+	case "phrase":
+		return prefix + `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(query.Value) + `"`
+	case "wildcard":
+		// Escapes would make the term literal; wildcard terms never need them
+		return prefix + query.Value
+	case "fuzzy":
+		return fmt.Sprintf("%s%s~%d", prefix, query.Value, query.Distance)
+	case "exists":
+		return existsQueryField + ":" + escapeQueryText(query.Field, false)
+	case "exact":
+		if text, quoted := strings.CutPrefix(query.Value, `"`); quoted && strings.HasSuffix(text, `"`) {
+			return prefix + `="` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(strings.TrimSuffix(text, `"`)) + `"`
+		}
+		return prefix + "=" + escapeQueryText(query.Value, true)
+	case "range":
+		return prefix + formatRange(query)
+	default:
+		return prefix + escapeQueryText(query.Value, true)
+	}
+}
+
+// formatRange renders a range's bounds, as a comparison when one side is open
+func formatRange(query *LuceneQuery) string {
+	bound := func(s string) string {
+		if strings.ContainsAny(s, " \t]}") {
+			return `"` + s + `"`
+		}
+		return s
+	}
+
+	switch {
+	case query.Upper == "*" && query.Lower != "*":
+		if query.IncludeLower {
+			return ">=" + query.Lower
+		}
+		return ">" + query.Lower
+	case query.Lower == "*" && query.Upper != "*":
+		if query.IncludeUpper {
+			return "<=" + query.Upper
+		}
+		return "<" + query.Upper
+	}
+
+	open, close := "{", "}"
+	if query.IncludeLower {
+		open = "["
+	}
+	if query.IncludeUpper {
+		close = "]"
+	}
+	return open + bound(query.Lower) + " TO " + bound(query.Upper) + close
+}
+
+// escapeQueryText backslash-escapes the characters the tokenizer would otherwise
+// interpret. Values also escape wildcard, fuzzy and comparison characters and the
+// operator keywords.
+func escapeQueryText(s string, value bool) string {
+	if value && (s == "AND" || s == "OR" || s == "NOT") {
+		return `\` + s
+	}
+
+	var sb strings.Builder
+	for i, r := range s {
+		special := unicode.IsSpace(r) || strings.ContainsRune(`\():"`, r) ||
+			(value && strings.ContainsRune("*?~", r)) ||
+			(i == 0 && strings.ContainsRune("-![{", r)) ||
+			(i == 0 && value && strings.ContainsRune("<>=", r))
+		if special {
+			sb.WriteByte('\\')
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
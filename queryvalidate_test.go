@@ -0,0 +1,65 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestValidateQuery(t *testing.T) {
+	app := &App{}
+
+	tests := []struct {
+		query      string
+		normalized string
+	}{
+		{"a:1 b:2 OR c:3", "a:1 AND b:2 OR c:3"},
+		{"(a:1 OR b:2) c:3", "(a:1 OR b:2) AND c:3"},
+		{"a OR (b OR c)", "a OR (b OR c)"},
+		{"-(a || b) && !c", "NOT (a OR b) AND NOT c"},
+		{"level:(error OR warn)", "level:error OR level:warn"},
+		{`msg:"say \"hi\""`, `msg:"say \"hi\""`},
+		{`time:10:30 my\ field:\AND`, `time:10\:30 AND my\ field:\AND`},
+		{"age:[20 TO 30} n:{* TO 5] x:[1 TO *]", "age:[20 TO 30} AND n:<=5 AND x:>=1"},
+		{"status:>499 name:jo*n user:jonh~ _missing_:id _exists_:ts", "status:>499 AND name:jo*n AND user:jonh~2 AND NOT _exists_:id AND _exists_:ts"},
+		{`name:\*`, `name:\*`},
+		{`level:="say \"hi\"" code:=200 user:=null note:\=x`, `level:="say \"hi\"" AND code:=200 AND user:=null AND note:\=x`},
+	}
+	for _, tt := range tests {
+		result := app.ValidateQuery(tt.query)
+		if !result.Valid || result.Error != nil || result.Query == nil {
+			t.Errorf("Expected %q to be valid, got %+v", tt.query, result.Error)
+			continue
+		}
+		if result.Normalized != tt.normalized {
+			t.Errorf("ValidateQuery(%q).Normalized = %s, expected %s", tt.query, result.Normalized, tt.normalized)
+		}
+		// The normalized form parses back to the same tree
+		if reparsed, err := parseLucene(result.Normalized); err != nil || !reflect.DeepEqual(reparsed, result.Query) {
+			t.Errorf("Normalized %s does not parse back to the same query (%v)", result.Normalized, err)
+		}
+	}
+
+	errorTests := []struct {
+		query    string
+		position int
+	}{
+		{"", 0},
+		{"a AND (b OR c", 13},
+		{"a:1 )", 4},
+		{`msg:"open`, 4},
+		{"x AND name:", 6},
+		{"n:[1 TO", 2},
+		{"a:1 b:jonh~7", 6},
+		{"x AND level:=", 6},
+	}
+	for _, tt := range errorTests {
+		result := app.ValidateQuery(tt.query)
+		if result.Valid || result.Error == nil {
+			t.Errorf("Expected %q to be invalid", tt.query)
+			continue
+		}
+		if result.Error.Position != tt.position || result.Error.Message == "" {
+			t.Errorf("ValidateQuery(%q) error = %+v, expected position %d", tt.query, result.Error, tt.position)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"sort"
+	"time"
+)
+
+// Recent file storage
+const (
+	recentFilesFile = "recent-files.json"
+	maxRecentFiles  = 20 // unpinned entries beyond this are dropped, oldest first
+)
+
+// ErrRecentFileNotFound is returned when pinning a path that is not in the recent files
+var ErrRecentFileNotFound = errors.New("recent file not found")
+
+// RecentFile is an entry of the recent files list
+type RecentFile struct {
+	Path       string    `json:"path"`
+	Name       string    `json:"name"`
+	Size       int64     `json:"size"`
+	Records    int       `json:"records"`
+	Format     string    `json:"format,omitempty"` // see JSONLFile.Format
+	LastOpened time.Time `json:"lastOpened"`
+	Pinned     bool      `json:"pinned"`
+	Missing    bool      `json:"missing"` // a local file that no longer exists, set by GetRecentFiles
+}
+
+// loadRecentFiles reads the stored recent files
+func loadRecentFiles() ([]RecentFile, error) {
+	var files []RecentFile
+	if err := readConfigFile(recentFilesFile, &files); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// saveRecentFiles stores the recent files pinned first, then most recently opened,
+// dropping the oldest unpinned entries beyond maxRecentFiles
+func saveRecentFiles(files []RecentFile) error {
+	sort.SliceStable(files, func(i, j int) bool {
+		if files[i].Pinned != files[j].Pinned {
+			return files[i].Pinned
+		}
+		return files[i].LastOpened.After(files[j].LastOpened)
+	})
+
+	kept := files[:0]
+	unpinned := 0
+	for _, file := range files {
+		if !file.Pinned {
+			if unpinned == maxRecentFiles {
+				continue
+			}
+			unpinned++
+		}
+		file.Missing = false
+		kept = append(kept, file)
+	}
+	return writeConfigFile(recentFilesFile, kept)
+}
+
+// addRecentFile records an opened file at the top of the recent files. Clipboard
+// content cannot be reopened and is not recorded; failures are ignored.
+func (a *App) addRecentFile(file *JSONLFile) {
+	if !a.trackRecentFiles || file.Path == "<clipboard>" {
+		return
+	}
+
+	files, err := loadRecentFiles()
+	if err != nil {
+		return
+	}
+	entry := RecentFile{
+		Path:       file.Path,
+		Name:       file.Name,
+		Size:       file.Size,
+		Records:    file.Records,
+		Format:     file.Format,
+		LastOpened: time.Now(),
+	}
+	for i, existing := range files {
+		if existing.Path == file.Path {
+			entry.Pinned = existing.Pinned
+			files = append(files[:i], files[i+1:]...)
+			break
+		}
+	}
+	saveRecentFiles(append(files, entry))
+}
+
+// GetRecentFiles returns the recently opened files, pinned ones first, flagging
+// local files that no longer exist
+func (a *App) GetRecentFiles() ([]RecentFile, error) {
+	files, err := loadRecentFiles()
+	if err != nil {
+		return nil, err
+	}
+	for i := range files {
+		if !isRemotePath(files[i].Path) && len(files[i].Path) > 0 {
+			if _, err := os.Stat(files[i].Path); errors.Is(err, os.ErrNotExist) {
+				files[i].Missing = true
+			}
+		}
+	}
+	if files == nil {
+		files = []RecentFile{}
+	}
+	return files, nil
+}
+
+// ClearRecentFiles removes every unpinned entry from the recent files
+func (a *App) ClearRecentFiles() error {
+	files, err := loadRecentFiles()
+	if err != nil {
+		return err
+	}
+	var pinned []RecentFile
+	for _, file := range files {
+		if file.Pinned {
+			pinned = append(pinned, file)
+		}
+	}
+	return saveRecentFiles(pinned)
+}
+
+// PinRecentFile pins or unpins a recent file; pinned files stay at the top of the
+// list and are kept by ClearRecentFiles
+func (a *App) PinRecentFile(path string, pinned bool) error {
+	files, err := loadRecentFiles()
+	if err != nil {
+		return err
+	}
+	for i := range files {
+		if files[i].Path == path {
+			files[i].Pinned = pinned
+			return saveRecentFiles(files)
+		}
+	}
+	return &JSONLError{
+		Message: "File is not in the recent files",
+		Err:     ErrRecentFileNotFound,
+	}
+}
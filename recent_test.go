@@ -0,0 +1,66 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRecentFiles(t *testing.T) {
+	useTempConfigDir(t)
+
+	dir := t.TempDir()
+	app := &App{trackRecentFiles: true}
+	var paths []string
+	for i := 0; i < maxRecentFiles+2; i++ {
+		path := filepath.Join(dir, fmt.Sprintf("file%02d.jsonl", i))
+		os.WriteFile(path, []byte("{\"n\":1}\n{\"n\":2}\n"), 0644)
+		paths = append(paths, path)
+		if _, err := app.LoadJSONLFile(path); err != nil {
+			t.Fatalf("LoadJSONLFile returned error: %v", err)
+		}
+	}
+
+	files, err := app.GetRecentFiles()
+	if err != nil {
+		t.Fatalf("GetRecentFiles returned error: %v", err)
+	}
+	if len(files) != maxRecentFiles || files[0].Path != paths[len(paths)-1] || files[0].Records != 2 || files[0].Size == 0 {
+		t.Fatalf("Expected the %d most recent files newest first, got %+v", maxRecentFiles, files[0])
+	}
+
+	// Pinned files stay on top, are not trimmed and survive clearing
+	if err := app.PinRecentFile(paths[5], true); err != nil {
+		t.Fatalf("PinRecentFile returned error: %v", err)
+	}
+	var jsonErr *JSONLError
+	if err := app.PinRecentFile(paths[0], true); !errors.As(err, &jsonErr) || !errors.Is(jsonErr.Err, ErrRecentFileNotFound) {
+		t.Errorf("Expected ErrRecentFileNotFound for a trimmed file, got %v", err)
+	}
+
+	// Reopening moves a file to the top of the unpinned entries
+	app.LoadJSONLFile(paths[10])
+	os.Remove(paths[len(paths)-1])
+	files, _ = app.GetRecentFiles()
+	if !files[0].Pinned || files[0].Path != paths[5] || files[1].Path != paths[10] {
+		t.Errorf("Expected the pinned file, then the reopened one, got %s, %s", files[0].Path, files[1].Path)
+	}
+	if !files[2].Missing || files[3].Missing {
+		t.Errorf("Expected only the deleted file to be missing, got %+v %+v", files[2], files[3])
+	}
+
+	if err := app.ClearRecentFiles(); err != nil {
+		t.Fatalf("ClearRecentFiles returned error: %v", err)
+	}
+	if files, _ = app.GetRecentFiles(); len(files) != 1 || files[0].Path != paths[5] {
+		t.Errorf("Expected only the pinned file to remain, got %+v", files)
+	}
+
+	// Apps not created by NewApp, such as in tests, do not record files
+	(&App{}).LoadJSONLFile(paths[1])
+	if files, _ = app.GetRecentFiles(); len(files) != 1 {
+		t.Errorf("Expected untracked loads not to be recorded, got %d files", len(files))
+	}
+}
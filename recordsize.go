@@ -0,0 +1,120 @@
+package main
+
+import (
+	"math"
+	"sort"
+)
+
+// maxLargestRecords caps the records returned by GetLargestRecords
+const maxLargestRecords = 100
+
+// RecordSizeStats describes the byte lengths of the valid record lines, newlines
+// excluded
+type RecordSizeStats struct {
+	Min   int     `json:"min"`
+	Max   int     `json:"max"`
+	Mean  float64 `json:"mean"`
+	P95   int     `json:"p95"` // 95% of records are at most this long
+	Total int64   `json:"total"`
+}
+
+// measureRecordSizes summarizes record lengths, or returns nil without records
+func measureRecordSizes(sizes []int) *RecordSizeStats {
+	if len(sizes) == 0 {
+		return nil
+	}
+
+	sorted := append([]int(nil), sizes...)
+	sort.Ints(sorted)
+	stats := &RecordSizeStats{Min: sorted[0], Max: sorted[len(sorted)-1]}
+	for _, size := range sorted {
+		stats.Total += int64(size)
+	}
+	stats.Mean = float64(stats.Total) / float64(len(sorted))
+	// Nearest rank
+	stats.P95 = sorted[int(math.Ceil(0.95*float64(len(sorted))))-1]
+	return stats
+}
+
+// recordSizes returns the byte length of each record's line
+func recordSizes(records []JSONRecord) []int {
+	sizes := make([]int, len(records))
+	for i, record := range records {
+		sizes[i] = len(record.RawJSON)
+	}
+	return sizes
+}
+
+// indexSizes returns the byte length of each indexed record's line
+func indexSizes(index *lineIndex) []int {
+	sizes := make([]int, len(index.lengths))
+	for i, length := range index.lengths {
+		sizes[i] = int(length)
+	}
+	return sizes
+}
+
+// LargeRecord is a record with the byte length of its line
+type LargeRecord struct {
+	Bytes  int        `json:"bytes"`
+	Record JSONRecord `json:"record"`
+}
+
+// GetLargestRecords returns the n records with the longest lines, largest first,
+// to find the records inflating a file. n defaults to 10 and is capped at 100.
+func (a *App) GetLargestRecords(n int) ([]LargeRecord, error) {
+	a.stateMu.RLock()
+	defer a.stateMu.RUnlock()
+
+	if a.currentFile == nil || a.cache == nil {
+		return nil, &JSONLError{
+			Message: "No file currently loaded",
+			Err:     ErrNoFileLoaded,
+		}
+	}
+
+	if n <= 0 {
+		n = 10
+	}
+	if n > maxLargestRecords {
+		n = maxLargestRecords
+	}
+
+	var sizes []int
+	if a.cache.diskBacked() {
+		sizes = indexSizes(a.cache.index)
+	} else {
+		sizes = recordSizes(a.cache.records)
+	}
+
+	// Keep the positions of the n largest seen so far, largest first; ties keep
+	// the earlier record first
+	var largest []int
+	for i, size := range sizes {
+		if len(largest) == n && size <= sizes[largest[n-1]] {
+			continue
+		}
+		at := sort.Search(len(largest), func(j int) bool { return sizes[largest[j]] < size })
+		if len(largest) < n {
+			largest = append(largest, 0)
+		}
+		copy(largest[at+1:], largest[at:len(largest)-1])
+		largest[at] = i
+	}
+
+	records := make([]LargeRecord, 0, len(largest))
+	for _, position := range largest {
+		page, err := a.cache.slice(position, position+1)
+		if err != nil {
+			return nil, &JSONLError{
+				Message: "Failed to read records",
+				Err:     err,
+			}
+		}
+		if len(page) == 0 {
+			continue
+		}
+		records = append(records, LargeRecord{Bytes: sizes[position], Record: page[0]})
+	}
+	return records, nil
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMeasureRecordSizes(t *testing.T) {
+	if stats := measureRecordSizes(nil); stats != nil {
+		t.Errorf("Expected no statistics without records, got %+v", stats)
+	}
+
+	sizes := make([]int, 100)
+	for i := range sizes {
+		sizes[i] = 100 - i
+	}
+	stats := measureRecordSizes(sizes)
+	if stats.Min != 1 || stats.Max != 100 || stats.Mean != 50.5 || stats.P95 != 95 || stats.Total != 5050 {
+		t.Errorf("Unexpected statistics: %+v", stats)
+	}
+	if sizes[0] != 100 {
+		t.Error("Expected the sizes to be left in order")
+	}
+}
+
+func TestRecordSizesInFileStats(t *testing.T) {
+	_, stats, err := ParseJSONLFromString("{\"a\":1}\nnot json\n{\"a\":\"long\"}\n")
+	if err != nil {
+		t.Fatalf("ParseJSONLFromString returned error: %v", err)
+	}
+	if sizes := stats.RecordSizes; sizes == nil || sizes.Min != 7 || sizes.Max != 12 || sizes.P95 != 12 || sizes.Total != 19 {
+		t.Errorf("Unexpected record sizes: %+v", stats.RecordSizes)
+	}
+}
+
+func TestGetLargestRecords(t *testing.T) {
+	app := newTestApp(t, `{"id":1,"pad":"x"}
+{"id":2,"pad":"xxxxxxxxxx"}
+{"id":3,"pad":"xxxxx"}
+{"id":4,"pad":"xxxxxxxxxx"}
+{"id":5}
+`)
+
+	largest, err := app.GetLargestRecords(3)
+	if err != nil {
+		t.Fatalf("GetLargestRecords returned error: %v", err)
+	}
+	var lines []int
+	for _, large := range largest {
+		lines = append(lines, large.Record.LineNumber)
+	}
+	if fmt.Sprint(lines) != "[2 4 3]" || largest[0].Bytes != 27 || largest[2].Bytes != 22 {
+		t.Errorf("Expected lines 2, 4 and 3 largest first, got %v (%+v)", lines, largest)
+	}
+	if all, _ := app.GetLargestRecords(0); len(all) != 5 || all[4].Record.LineNumber != 5 {
+		t.Errorf("Expected every record with the default n, got %+v", all)
+	}
+
+	var jsonlErr *JSONLError
+	if _, err := (&App{}).GetLargestRecords(3); !errors.As(err, &jsonlErr) || !errors.Is(jsonlErr.Err, ErrNoFileLoaded) {
+		t.Errorf("Expected ErrNoFileLoaded, got %v", err)
+	}
+}
+
+func TestGetLargestRecordsIndexed(t *testing.T) {
+	useTempConfigDir(t)
+
+	var sb strings.Builder
+	for i := 0; i < 600; i++ {
+		fmt.Fprintf(&sb, "{\"n\":%d,\"pad\":\"%s\"}\n", i, strings.Repeat("x", i%7))
+	}
+	path := filepath.Join(t.TempDir(), "big.jsonl")
+	os.WriteFile(path, []byte(sb.String()), 0644)
+
+	app := &App{}
+	if _, err := app.LoadJSONLFileIndexed(path); err != nil {
+		t.Fatalf("LoadJSONLFileIndexed returned error: %v", err)
+	}
+	if sizes := app.cache.stats.RecordSizes; sizes == nil || sizes.Min != 16 || sizes.Max != 24 {
+		t.Errorf("Unexpected record sizes: %+v", sizes)
+	}
+
+	largest, err := app.GetLargestRecords(2)
+	if err != nil || len(largest) != 2 {
+		t.Fatalf("Expected 2 records, got %+v (%v)", largest, err)
+	}
+	if largest[0].Record.LineNumber != 105 || largest[1].Record.LineNumber != 112 || largest[0].Bytes != 24 {
+		t.Errorf("Expected lines 105 and 112 of 24 bytes, got %+v", largest)
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestLoadJSONLFromURL(t *testing.T) {
+	useTempConfigDir(t)
+
+	var gzipped bytes.Buffer
+	gz := gzip.NewWriter(&gzipped)
+	gz.Write([]byte("{\"n\":1}\n{\"n\":2}\n"))
+	gz.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" || r.Header.Get("X-Team") != "logs" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/export.jsonl":
+			w.Write([]byte("{\"level\":\"info\"}\nbad\n{\"level\":\"error\"}\n"))
+		case "/export.jsonl.gz":
+			w.Write(gzipped.Bytes())
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	app := &App{}
+	if _, err := app.LoadJSONLFromURL(server.URL + "/export.jsonl"); err == nil {
+		t.Fatal("Expected an error without the configured auth")
+	}
+
+	app.SetURLRequestOptions(URLRequestOptions{Headers: map[string]string{"X-Team": "logs"}, AuthToken: "secret"})
+	file, err := app.LoadJSONLFromURL(server.URL + "/export.jsonl")
+	if err != nil {
+		t.Fatalf("LoadJSONLFromURL returned error: %v", err)
+	}
+	if file.Name != "export.jsonl" || file.Records != 2 {
+		t.Errorf("Unexpected file: %+v", file)
+	}
+	if stats, err := app.GetFileStats(); err != nil || len(stats.InvalidLines) != 1 || stats.ValidRecords != 2 {
+		t.Errorf("Expected the stats of the download, got %+v (%v)", stats, err)
+	}
+
+	// Exports use the downloaded records
+	records, err := app.GetAllRecords("error")
+	if err != nil || len(records) != 1 {
+		t.Errorf("Expected 1 exported record, got %d (%v)", len(records), err)
+	}
+	if modified, err := app.CheckFileModification(); err != nil || modified {
+		t.Errorf("Expected remote sources to skip modification checks, got %v (%v)", modified, err)
+	}
+
+	file, err = app.LoadJSONLFromURL(server.URL + "/export.jsonl.gz")
+	if err != nil || file.Records != 2 {
+		t.Errorf("Expected the gzip download to decompress, got %+v (%v)", file, err)
+	}
+
+	if _, err := app.LoadJSONLFromURL(server.URL + "/missing.jsonl"); err == nil {
+		t.Error("Expected an error for a 404 response")
+	}
+	if _, err := app.LoadJSONLFromURL("ftp://example.com/data.jsonl"); err == nil {
+		t.Error("Expected an error for a non-HTTP URL")
+	}
+}
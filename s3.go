@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// s3URI returns the s3:// URI of an object
+func s3URI(bucket, key string) string {
+	return "s3://" + bucket + "/" + key
+}
+
+// parseS3URI splits an s3:// URI into bucket and key
+func parseS3URI(uri string) (bucket, key string, ok bool) {
+	rest, found := strings.CutPrefix(uri, "s3://")
+	if !found {
+		return "", "", false
+	}
+	bucket, key, found = strings.Cut(rest, "/")
+	return bucket, key, found && bucket != "" && key != ""
+}
+
+// LoadJSONLFromS3 streams an S3 object through the JSONL parser, emitting
+// "load:progress" events as it downloads. Credentials and region come from the
+// named profile of the shared AWS configuration, or the default chain when profile
+// is empty. Compressed objects are decompressed like local files.
+func (a *App) LoadJSONLFromS3(bucket, key, profile string) (*JSONLFile, error) {
+	if bucket == "" || key == "" {
+		return nil, &JSONLError{
+			Message: "Bucket and key cannot be empty",
+			Err:     ErrDownloadFailed,
+		}
+	}
+
+	ctx, done := a.beginLoad()
+	defer done()
+
+	var loadOptions []func(*config.LoadOptions) error
+	if profile != "" {
+		loadOptions = append(loadOptions, config.WithSharedConfigProfile(profile))
+	}
+	cfg, err := config.LoadDefaultConfig(ctx, loadOptions...)
+	if err != nil {
+		return nil, &JSONLError{
+			Message: "Failed to load AWS configuration",
+			Err:     err,
+		}
+	}
+
+	client := s3.NewFromConfig(cfg, func(o *s3.Options) {
+		// Custom endpoints (e.g. MinIO or LocalStack) rarely support virtual-hosted buckets
+		o.UsePathStyle = o.BaseEndpoint != nil
+	})
+	object, err := client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, &JSONLError{
+			Message: fmt.Sprintf("Failed to fetch %s", s3URI(bucket, key)),
+			Err:     fmt.Errorf("%w: %v", ErrDownloadFailed, err),
+		}
+	}
+	defer object.Body.Close()
+
+	body := &progressReader{
+		reader:   object.Body,
+		progress: LoadProgress{Source: s3URI(bucket, key), TotalBytes: aws.ToInt64(object.ContentLength)},
+		emit:     a.emitLoadProgress,
+	}
+	if object.ContentLength == nil {
+		body.progress.TotalBytes = -1
+	}
+
+	modifiedAt := time.Now()
+	if object.LastModified != nil {
+		modifiedAt = *object.LastModified
+	}
+
+	jsonlFile, err := a.loadStream(ctx, s3URI(bucket, key), path.Base(key), body, modifiedAt)
+	if err != nil {
+		return nil, err
+	}
+	a.stateMu.Lock()
+	a.s3Profile = profile
+	a.stateMu.Unlock()
+	return jsonlFile, nil
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseS3URI(t *testing.T) {
+	bucket, key, ok := parseS3URI(s3URI("logs", "firehose/2024/01/part-0.jsonl.gz"))
+	if !ok || bucket != "logs" || key != "firehose/2024/01/part-0.jsonl.gz" {
+		t.Errorf("Unexpected parse: %q %q %v", bucket, key, ok)
+	}
+	for _, uri := range []string{"s3://logs", "s3:///key", "https://logs/key"} {
+		if _, _, ok := parseS3URI(uri); ok {
+			t.Errorf("Expected %q to be rejected", uri)
+		}
+	}
+}
+
+func TestLoadJSONLFromS3(t *testing.T) {
+	useTempConfigDir(t)
+
+	var requested string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = r.URL.Path
+		w.Header().Set("Last-Modified", "Mon, 01 Jan 2024 10:00:00 GMT")
+		w.Write([]byte("{\"n\":1}\n{\"n\":2}\n"))
+	}))
+	defer server.Close()
+
+	t.Setenv("AWS_ENDPOINT_URL_S3", server.URL)
+	t.Setenv("AWS_ACCESS_KEY_ID", "test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+	t.Setenv("AWS_REGION", "us-east-1")
+	t.Setenv("AWS_CONFIG_FILE", t.TempDir()+"/config")
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", t.TempDir()+"/credentials")
+
+	app := &App{}
+	file, err := app.LoadJSONLFromS3("logs", "athena/results.jsonl", "")
+	if err != nil {
+		t.Fatalf("LoadJSONLFromS3 returned error: %v", err)
+	}
+	if requested != "/logs/athena/results.jsonl" {
+		t.Errorf("Expected a path-style request for the object, got %s", requested)
+	}
+	if file.Name != "results.jsonl" || file.Path != "s3://logs/athena/results.jsonl" || file.Records != 2 || file.ModifiedAt.Year() != 2024 {
+		t.Errorf("Unexpected file: %+v", file)
+	}
+	if stats, err := app.GetFileStats(); err != nil || stats.ValidRecords != 2 {
+		t.Errorf("Expected the stats of the object, got %+v (%v)", stats, err)
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"math/rand"
+	"sort"
+	"time"
+)
+
+// maxSampleSize caps the records returned by SampleRecords
+const maxSampleSize = 1000
+
+// SampleResult is a uniform random sample of records
+type SampleResult struct {
+	Records    []JSONRecord `json:"records"`    // in dataset order
+	Population int          `json:"population"` // records the sample was drawn from
+	Seed       int64        `json:"seed"`       // pass again to draw the same sample
+}
+
+// sampledRecord is a record kept in the reservoir with its dataset position
+type sampledRecord struct {
+	position int
+	record   JSONRecord
+}
+
+// sampleReservoir draws n records uniformly from a stream of unknown length,
+// keeping at most n records in memory
+type sampleReservoir struct {
+	rng     *rand.Rand
+	size    int
+	seen    int
+	records []sampledRecord
+}
+
+// add offers the next record of the stream to the reservoir
+func (r *sampleReservoir) add(record JSONRecord) {
+	r.seen++
+	if len(r.records) < r.size {
+		r.records = append(r.records, sampledRecord{position: r.seen - 1, record: record})
+		return
+	}
+	if i := r.rng.Intn(r.seen); i < r.size {
+		r.records[i] = sampledRecord{position: r.seen - 1, record: record}
+	}
+}
+
+// sample returns the kept records in the order they were added
+func (r *sampleReservoir) sample() []JSONRecord {
+	sort.Slice(r.records, func(i, j int) bool {
+		return r.records[i].position < r.records[j].position
+	})
+	records := make([]JSONRecord, len(r.records))
+	for i, sampled := range r.records {
+		records[i] = sampled.record
+	}
+	return records
+}
+
+// SampleRecords returns n records drawn uniformly at random from the records left
+// by the filter stack, narrowed further to those matching filter when it carries a
+// query. The same seed draws the same sample from the same records; seed 0 picks a
+// new seed, returned with the sample. n defaults to 50 and is capped at 1000.
+func (a *App) SampleRecords(n int, seed int64, filter *SearchOptions) (*SampleResult, error) {
+	a.stateMu.RLock()
+	defer a.stateMu.RUnlock()
+
+	if a.currentFile == nil || a.cache == nil {
+		return nil, &JSONLError{
+			Message: "No file currently loaded",
+			Err:     ErrNoFileLoaded,
+		}
+	}
+
+	if n <= 0 {
+		n = 50
+	}
+	if n > maxSampleSize {
+		n = maxSampleSize
+	}
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+
+	reservoir := &sampleReservoir{rng: rand.New(rand.NewSource(seed)), size: n}
+	if err := a.forEachInScope(filter, reservoir.add); err != nil {
+		return nil, err
+	}
+
+	return &SampleResult{
+		Records:    reservoir.sample(),
+		Population: reservoir.seen,
+		Seed:       seed,
+	}, nil
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestSampleRecords(t *testing.T) {
+	var content strings.Builder
+	for i := 1; i <= 200; i++ {
+		fmt.Fprintf(&content, "{\"id\":%d,\"even\":%t}\n", i, i%2 == 0)
+	}
+	app := newTestApp(t, content.String())
+
+	first, err := app.SampleRecords(10, 42, nil)
+	if err != nil {
+		t.Fatalf("SampleRecords returned error: %v", err)
+	}
+	if len(first.Records) != 10 || first.Population != 200 || first.Seed != 42 {
+		t.Fatalf("Unexpected sample: %d records of %d, seed %d", len(first.Records), first.Population, first.Seed)
+	}
+	for i := 1; i < len(first.Records); i++ {
+		if first.Records[i].LineNumber <= first.Records[i-1].LineNumber {
+			t.Errorf("Expected the sample in dataset order, got line %d after %d", first.Records[i].LineNumber, first.Records[i-1].LineNumber)
+		}
+	}
+
+	second, _ := app.SampleRecords(10, 42, nil)
+	if fmt.Sprint(second.Records) != fmt.Sprint(first.Records) {
+		t.Error("Expected the same seed to draw the same sample")
+	}
+	if random, _ := app.SampleRecords(10, 0, nil); random.Seed == 0 {
+		t.Error("Expected a generated seed to be returned")
+	}
+
+	filtered, _ := app.SampleRecords(5, 7, &SearchOptions{Query: "even:true", UseLucene: true})
+	if filtered.Population != 100 || len(filtered.Records) != 5 {
+		t.Errorf("Expected 5 of 100 filtered records, got %d of %d", len(filtered.Records), filtered.Population)
+	}
+	for _, record := range filtered.Records {
+		if record.Content["even"] != true {
+			t.Errorf("Expected only filtered records, got %s", record.RawJSON)
+		}
+	}
+
+	// Every record is equally likely to be drawn
+	counts := map[int]int{}
+	for seed := int64(1); seed <= 2000; seed++ {
+		sample, _ := app.SampleRecords(1, seed, &SearchOptions{Query: "id:[1 TO 4]", UseLucene: true})
+		counts[sample.Records[0].LineNumber]++
+	}
+	for line := 1; line <= 4; line++ {
+		if counts[line] < 400 || counts[line] > 600 {
+			t.Errorf("Expected line %d in about a quarter of the samples, got %d of 2000", line, counts[line])
+		}
+	}
+
+	if all, _ := app.SampleRecords(500, 1, &SearchOptions{Query: "id:[7 TO 8]", UseLucene: true}); len(all.Records) != 2 {
+		t.Errorf("Expected every record when fewer than n match, got %d", len(all.Records))
+	}
+}
@@ -0,0 +1,383 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"time"
+)
+
+// jsonSchemaDraft07 identifies the JSON Schema dialect of inferred schemas
+const jsonSchemaDraft07 = "http://json-schema.org/draft-07/schema#"
+
+// draft07Formats are the inferred string formats defined by draft-07
+var draft07Formats = map[string]bool{
+	"date-time": true, "date": true, "email": true, "ipv4": true, "ipv6": true, "uri": true,
+}
+
+// maxTrackedStringValues caps the distinct string values remembered per schema node
+const maxTrackedStringValues = 50
+
+// String formats recognized during schema inference
+var (
+	emailPattern = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)
+	uuidPattern  = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	datePattern  = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+)
+
+// schemaNode accumulates the observed shape of every value seen at one position
+// in the records: its types, object properties, array items and value ranges
+type schemaNode struct {
+	count int
+	types map[string]int
+
+	// Objects
+	objectCount   int
+	properties    map[string]*schemaNode
+	propertyOrder []string
+
+	// Arrays
+	arrayCount int
+	items      *schemaNode
+	minItems   int
+	maxItems   int
+
+	// Numbers
+	numberCount int
+	minNumber   float64
+	maxNumber   float64
+	allIntegers bool
+
+	// Strings
+	stringCount    int
+	minLength      int
+	maxLength      int
+	stringValues   map[string]int
+	stringOverflow bool
+	formats        map[string]int
+	minTime        time.Time
+	maxTime        time.Time
+}
+
+// newSchemaNode creates an empty schema node
+func newSchemaNode() *schemaNode {
+	return &schemaNode{
+		types:        make(map[string]int),
+		stringValues: make(map[string]int),
+		formats:      make(map[string]int),
+		allIntegers:  true,
+	}
+}
+
+// inferSchemaNode builds the schema of the contents of every record of cache,
+// streaming disk-backed files
+func inferSchemaNode(cache *RecordCache) (*schemaNode, error) {
+	root := newSchemaNode()
+	err := cache.forEach(func(record JSONRecord) bool {
+		root.observe(record.Content)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return root, nil
+}
+
+// detectStringFormat returns the JSON Schema format of a string, if any
+func detectStringFormat(s string) string {
+	switch {
+	case uuidPattern.MatchString(s):
+		return "uuid"
+	case emailPattern.MatchString(s):
+		return "email"
+	case datePattern.MatchString(s):
+		return "date"
+	}
+	if _, ok := parseTimestampString(s); ok {
+		return "date-time"
+	}
+	if ip := net.ParseIP(s); ip != nil {
+		if ip.To4() != nil {
+			return "ipv4"
+		}
+		return "ipv6"
+	}
+	if u, err := url.Parse(s); err == nil && u.Scheme != "" && u.Host != "" {
+		return "uri"
+	}
+	return ""
+}
+
+// observe records one value at this node's position
+func (n *schemaNode) observe(value interface{}) {
+	n.count++
+	valueType := jsonTypeOf(value)
+	n.types[valueType]++
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		n.objectCount++
+		if n.properties == nil {
+			n.properties = make(map[string]*schemaNode)
+		}
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			property, exists := n.properties[key]
+			if !exists {
+				property = newSchemaNode()
+				n.properties[key] = property
+				n.propertyOrder = append(n.propertyOrder, key)
+			}
+			property.observe(v[key])
+		}
+
+	case []interface{}:
+		if n.arrayCount == 0 || len(v) < n.minItems {
+			n.minItems = len(v)
+		}
+		if len(v) > n.maxItems {
+			n.maxItems = len(v)
+		}
+		n.arrayCount++
+		if n.items == nil {
+			n.items = newSchemaNode()
+		}
+		for _, item := range v {
+			n.items.observe(item)
+		}
+
+	case string:
+		if n.stringCount == 0 || len(v) < n.minLength {
+			n.minLength = len(v)
+		}
+		if len(v) > n.maxLength {
+			n.maxLength = len(v)
+		}
+		n.stringCount++
+
+		if !n.stringOverflow {
+			n.stringValues[v]++
+			if len(n.stringValues) > maxTrackedStringValues {
+				n.stringOverflow = true
+				n.stringValues = make(map[string]int)
+			}
+		}
+
+		format := detectStringFormat(v)
+		n.formats[format]++
+		if format == "date-time" {
+			if t, ok := parseTimestampString(v); ok {
+				if n.minTime.IsZero() || t.Before(n.minTime) {
+					n.minTime = t
+				}
+				if t.After(n.maxTime) {
+					n.maxTime = t
+				}
+			}
+		}
+
+	default:
+		if number, ok := toFloat64(value); ok {
+			if n.numberCount == 0 || number < n.minNumber {
+				n.minNumber = number
+			}
+			if n.numberCount == 0 || number > n.maxNumber {
+				n.maxNumber = number
+			}
+			n.numberCount++
+			if number != float64(int64(number)) {
+				n.allIntegers = false
+			}
+		}
+	}
+}
+
+// required reports whether a property was present in every object observed
+func (n *schemaNode) required(property string) bool {
+	p, exists := n.properties[property]
+	return exists && p.count == n.objectCount
+}
+
+// stringFormat returns the format shared by all observed strings, if any
+func (n *schemaNode) stringFormat() string {
+	for format, count := range n.formats {
+		if format != "" && count == n.stringCount {
+			return format
+		}
+	}
+	return ""
+}
+
+// isEnumLike reports whether the observed strings form a small, repeated value set
+// that can be reused without revealing individual values
+func (n *schemaNode) isEnumLike() bool {
+	if n.stringOverflow || len(n.stringValues) == 0 || len(n.stringValues) > 10 {
+		return false
+	}
+	for _, count := range n.stringValues {
+		if count < 2 {
+			return false
+		}
+	}
+	return true
+}
+
+// sortedTypes returns the observed type names, most frequent first
+func (n *schemaNode) sortedTypes() []string {
+	types := make([]string, 0, len(n.types))
+	for t := range n.types {
+		types = append(types, t)
+	}
+	sort.Slice(types, func(i, j int) bool {
+		if n.types[types[i]] != n.types[types[j]] {
+			return n.types[types[i]] > n.types[types[j]]
+		}
+		return types[i] < types[j]
+	})
+	return types
+}
+
+// jsonSchema returns the draft-07 JSON Schema of the values observed at this node:
+// the union of their types, the properties of objects with those present in every
+// object required, and the schema shared by all array items
+func (n *schemaNode) jsonSchema() map[string]interface{} {
+	schema := make(map[string]interface{})
+	if n.count == 0 {
+		return schema
+	}
+
+	var types []string
+	for _, t := range n.sortedTypes() {
+		if t == "number" && n.allIntegers {
+			t = "integer"
+		}
+		types = append(types, t)
+	}
+	if len(types) == 1 {
+		schema["type"] = types[0]
+	} else {
+		schema["type"] = types
+	}
+
+	if n.objectCount > 0 {
+		properties := make(map[string]interface{}, len(n.properties))
+		required := []string{}
+		for _, key := range n.propertyOrder {
+			properties[key] = n.properties[key].jsonSchema()
+			if n.required(key) {
+				required = append(required, key)
+			}
+		}
+		sort.Strings(required)
+		schema["properties"] = properties
+		if len(required) > 0 {
+			schema["required"] = required
+		}
+	}
+
+	if n.arrayCount > 0 && n.items != nil && n.items.count > 0 {
+		schema["items"] = n.items.jsonSchema()
+	}
+
+	if n.stringCount > 0 {
+		if format := n.stringFormat(); draft07Formats[format] {
+			schema["format"] = format
+		}
+		if len(types) == 1 && n.isEnumLike() {
+			values := make([]string, 0, len(n.stringValues))
+			for value := range n.stringValues {
+				values = append(values, value)
+			}
+			sort.Strings(values)
+			schema["enum"] = values
+		}
+	}
+	return schema
+}
+
+// inferSchema walks every record and returns the draft-07 JSON Schema they
+// conform to. The caller holds stateMu.
+func (a *App) inferSchema() (map[string]interface{}, error) {
+	root := newSchemaNode()
+	err := a.cache.forEach(func(record JSONRecord) bool {
+		if record.Content != nil {
+			root.observe(record.Content)
+		} else {
+			root.observe(record.Value)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	schema := root.jsonSchema()
+	schema["$schema"] = jsonSchemaDraft07
+	schema["title"] = a.currentFile.Name
+	return schema, nil
+}
+
+// InferSchema walks all loaded records and returns a draft-07 JSON Schema document
+// describing them. Field types are unioned across records, nested objects and
+// arrays are described recursively, and fields missing from some records are left
+// out of "required".
+func (a *App) InferSchema() (map[string]interface{}, error) {
+	a.stateMu.RLock()
+	defer a.stateMu.RUnlock()
+
+	if a.currentFile == nil || a.cache == nil {
+		return nil, &JSONLError{
+			Message: "No file currently loaded",
+			Err:     ErrNoFileLoaded,
+		}
+	}
+
+	return a.inferSchema()
+}
+
+// ExportSchema saves the schema returned by InferSchema to the Downloads folder as
+// a .schema.json file and returns its path
+func (a *App) ExportSchema() (string, error) {
+	a.stateMu.RLock()
+	defer a.stateMu.RUnlock()
+
+	if a.currentFile == nil || a.cache == nil {
+		return "", &JSONLError{
+			Message: "No file currently loaded",
+			Err:     ErrNoFileLoaded,
+		}
+	}
+
+	schema, err := a.inferSchema()
+	if err != nil {
+		return "", err
+	}
+
+	filePath, err := a.exportFilePath("jsonl-viewer-schema", "schema.json")
+	if err != nil {
+		return "", err
+	}
+
+	file, err := os.Create(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to create export file: %w", err)
+	}
+	defer file.Close()
+
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(schema); err != nil {
+		return "", fmt.Errorf("failed to write schema export: %w", err)
+	}
+
+	a.audit(AuditEntry{Action: AuditExportSaved, Path: a.auditPath(), Destination: filePath, Detail: "schema"})
+	return filePath, nil
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInferSchema(t *testing.T) {
+	app := newTestApp(t, `{"id":1,"level":"info","at":"2024-01-01T00:00:00Z","user":{"name":"a","email":"a@example.com"},"tags":["x"],"score":1.5}
+{"id":2,"level":"error","at":"2024-01-02T00:00:00Z","user":{"name":"b"},"tags":[],"score":null}
+{"id":3,"level":"info","at":"2024-01-03T00:00:00Z","user":{"name":"c","email":"c@example.com"},"tags":["y",1]}
+{"id":4,"level":"error","at":"2024-01-04T00:00:00Z","user":{"name":"d"},"extra":true}
+`)
+
+	schema, err := app.InferSchema()
+	if err != nil {
+		t.Fatalf("InferSchema returned error: %v", err)
+	}
+	raw, _ := json.Marshal(schema)
+	var document struct {
+		Schema     string                            `json:"$schema"`
+		Type       string                            `json:"type"`
+		Required   []string                          `json:"required"`
+		Properties map[string]map[string]interface{} `json:"properties"`
+	}
+	if err := json.Unmarshal(raw, &document); err != nil {
+		t.Fatalf("Failed to decode schema %s: %v", raw, err)
+	}
+
+	if document.Schema != jsonSchemaDraft07 || document.Type != "object" {
+		t.Errorf("Expected a draft-07 object schema, got %s", raw)
+	}
+	if strings.Join(document.Required, ",") != "at,id,level,user" {
+		t.Errorf("Expected at, id, level and user required, got %v", document.Required)
+	}
+
+	checks := []struct {
+		property string
+		expected string
+	}{
+		{"id", `{"type":"integer"}`},
+		{"level", `{"enum":["error","info"],"type":"string"}`},
+		{"at", `{"format":"date-time","type":"string"}`},
+		{"score", `{"type":["null","number"]}`},
+		{"tags", `{"items":{"type":["string","integer"]},"type":"array"}`},
+		{"extra", `{"type":"boolean"}`},
+		{"user", `{"properties":{"email":{"format":"email","type":"string"},"name":{"type":"string"}},"required":["name"],"type":"object"}`},
+	}
+	for _, check := range checks {
+		got, _ := json.Marshal(document.Properties[check.property])
+		if string(got) != check.expected {
+			t.Errorf("Property %s: expected %s, got %s", check.property, check.expected, got)
+		}
+	}
+
+	var jsonlErr *JSONLError
+	if _, err := (&App{}).InferSchema(); !errors.As(err, &jsonlErr) || !errors.Is(jsonlErr.Err, ErrNoFileLoaded) {
+		t.Errorf("Expected ErrNoFileLoaded, got %v", err)
+	}
+}
+
+func TestExportSchema(t *testing.T) {
+	app := newTestApp(t, "{\"a\":1}\n{\"a\":2,\"b\":\"x\"}\n")
+	app.settings.ExportDirectory = t.TempDir()
+
+	path, err := app.ExportSchema()
+	if err != nil {
+		t.Fatalf("ExportSchema returned error: %v", err)
+	}
+	if filepath.Dir(path) != app.settings.ExportDirectory || !strings.HasSuffix(path, ".schema.json") {
+		t.Errorf("Unexpected export path %s", path)
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read exported schema: %v", err)
+	}
+	var schema map[string]interface{}
+	if err := json.Unmarshal(raw, &schema); err != nil {
+		t.Fatalf("Exported schema is not JSON: %v", err)
+	}
+	if required, _ := schema["required"].([]interface{}); len(required) != 1 || required[0] != "a" {
+		t.Errorf("Expected only a required, got %v", schema["required"])
+	}
+}
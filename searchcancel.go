@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+)
+
+// ErrSearchCancelled is returned by searches aborted through CancelSearch
+var ErrSearchCancelled = errors.New("search cancelled")
+
+// Default search scan tuning
+const (
+	searchProgressInterval = 50000 // records scanned between "search:progress" events
+	searchCheckInterval    = 1024  // records scanned between cancellation checks
+)
+
+// scanTuning sets how often searches report progress and check for cancellation,
+// and from how many records they scan in parallel; zero fields use the defaults, so
+// tests can use small files
+type scanTuning struct {
+	progressInterval  int
+	checkInterval     int
+	parallelThreshold int
+}
+
+// scanSettings returns the app's scan tuning with defaults applied
+func (a *App) scanSettings() scanTuning {
+	tuning := a.scanning
+	if tuning.progressInterval <= 0 {
+		tuning.progressInterval = searchProgressInterval
+	}
+	if tuning.checkInterval <= 0 {
+		tuning.checkInterval = searchCheckInterval
+	}
+	if tuning.parallelThreshold <= 0 {
+		tuning.parallelThreshold = parallelSearchThreshold
+	}
+	return tuning
+}
+
+// SearchProgress is emitted as the "search:progress" event while SearchRecords scans
+type SearchProgress struct {
+	Token   string `json:"token"`
+	Scanned int    `json:"scanned"`
+	Total   int    `json:"total"`
+	Matches int    `json:"matches"` // matches found so far
+}
+
+// searchScan tracks one search's progress across the workers scanning for it
+type searchScan struct {
+	ctx     context.Context
+	token   string
+	total   int
+	scanned atomic.Int64
+	matched atomic.Int64
+	emit    func(SearchProgress)
+	tuning  scanTuning
+}
+
+// step adds records scanned since the last step, matched of them matching, emits
+// progress whenever another progress interval of records has been scanned, and
+// reports false once the search is cancelled
+func (s *searchScan) step(scanned, matched int) bool {
+	total := s.scanned.Add(int64(scanned))
+	matches := s.matched.Add(int64(matched))
+	interval := int64(s.tuning.progressInterval)
+	if s.emit != nil && total/interval != (total-int64(scanned))/interval {
+		s.emit(SearchProgress{Token: s.token, Scanned: int(total), Total: s.total, Matches: int(matches)})
+	}
+	return s.ctx.Err() == nil
+}
+
+// runningSearch is a search registered for CancelSearch
+type runningSearch struct {
+	id     int64
+	cancel context.CancelFunc
+}
+
+// registerSearch makes a search cancellable under token, generating a unique one
+// when it is empty. A search still running under the same token is cancelled, as
+// the new one replaces it. It returns the token, the search's context and a
+// function to call once the search finishes, which may be called more than once.
+func (a *App) registerSearch(token string) (string, context.Context, func()) {
+	id := atomic.AddInt64(&searchSequence, 1)
+	if token == "" {
+		token = fmt.Sprintf("search-%d", id)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	a.searchMu.Lock()
+	if a.searchCancels == nil {
+		a.searchCancels = make(map[string]runningSearch)
+	}
+	if previous, exists := a.searchCancels[token]; exists {
+		previous.cancel()
+	}
+	a.searchCancels[token] = runningSearch{id: id, cancel: cancel}
+	a.searchMu.Unlock()
+
+	return token, ctx, func() {
+		cancel()
+		a.searchMu.Lock()
+		// A later search may have taken over the token
+		if running, exists := a.searchCancels[token]; exists && running.id == id {
+			delete(a.searchCancels, token)
+		}
+		a.searchMu.Unlock()
+	}
+}
+
+// beginSearch registers a cancellable search under token, see registerSearch, and
+// returns its scan and a function to call once it finishes
+func (a *App) beginSearch(token string, total int) (*searchScan, func()) {
+	token, ctx, done := a.registerSearch(token)
+	scan := &searchScan{ctx: ctx, token: token, total: total, tuning: a.scanSettings(), emit: func(progress SearchProgress) {
+		a.emitEvent("search:progress", progress)
+	}}
+	return scan, done
+}
+
+// CancelSearch aborts the running search started with SearchOptions.Token set to
+// token; it returns ErrSearchCancelled. The background count of a progressive
+// search is stopped by its SearchID. It reports whether such a search was running.
+func (a *App) CancelSearch(token string) bool {
+	a.searchMu.Lock()
+	defer a.searchMu.Unlock()
+
+	running, exists := a.searchCancels[token]
+	if exists {
+		running.cancel()
+	}
+	return exists
+}
+
+// currentSearch returns the most recent search
+func (a *App) currentSearch() SearchOptions {
+	a.searchMu.Lock()
+	defer a.searchMu.Unlock()
+	return a.activeSearch
+}
+
+// setActiveSearch makes options the most recent search
+func (a *App) setActiveSearch(options SearchOptions) {
+	a.searchMu.Lock()
+	defer a.searchMu.Unlock()
+	a.activeSearch = options
+}
+
+// swapProgressiveCancel makes cancel stop the background count of the last
+// progressive search and returns the function it replaces
+func (a *App) swapProgressiveCancel(cancel context.CancelFunc) context.CancelFunc {
+	a.searchMu.Lock()
+	defer a.searchMu.Unlock()
+	previous := a.progressiveCancel
+	a.progressiveCancel = cancel
+	return previous
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSearchProgressAndCancellation(t *testing.T) {
+	var sb strings.Builder
+	for i := 0; i < 35; i++ {
+		fmt.Fprintf(&sb, `{"n":%d}`+"\n", i)
+	}
+	app := newTestApp(t, sb.String())
+	app.scanning = scanTuning{progressInterval: 10, checkInterval: 5}
+	isEven := func(record JSONRecord) bool {
+		n, _ := record.Content["n"].(json.Number).Int64()
+		return n%2 == 0
+	}
+
+	scan, done := app.beginSearch("t1", len(app.records))
+	var mu sync.Mutex
+	var events []SearchProgress
+	scan.emit = func(progress SearchProgress) {
+		mu.Lock()
+		events = append(events, progress)
+		mu.Unlock()
+	}
+	matched, err := filterRecords(scan, app.records, isEven, parallelSearchThreshold)
+	if err != nil || len(matched) != 18 {
+		t.Fatalf("Expected 18 matches, got %d (%v)", len(matched), err)
+	}
+	expected := []SearchProgress{
+		{Token: "t1", Scanned: 10, Total: 35, Matches: 5},
+		{Token: "t1", Scanned: 20, Total: 35, Matches: 10},
+		{Token: "t1", Scanned: 30, Total: 35, Matches: 15},
+	}
+	if fmt.Sprint(events) != fmt.Sprint(expected) {
+		t.Errorf("Expected progress %+v, got %+v", expected, events)
+	}
+
+	if !app.CancelSearch("t1") {
+		t.Error("Expected the running search to be cancelled")
+	}
+	if _, err := filterRecords(scan, app.records, isEven, parallelSearchThreshold); !errors.Is(err, ErrSearchCancelled) {
+		t.Errorf("Expected ErrSearchCancelled, got %v", err)
+	}
+	done()
+	if app.CancelSearch("t1") || app.CancelSearch("unknown") {
+		t.Error("Expected no search to cancel once finished")
+	}
+
+	// A finished search releases its token
+	if _, err := app.SearchRecords(SearchOptions{Query: "n", Token: "t2"}); err != nil {
+		t.Fatalf("SearchRecords returned error: %v", err)
+	}
+	if app.CancelSearch("t2") {
+		t.Error("Expected the search token to be released")
+	}
+}
+
+func TestSearchTokens(t *testing.T) {
+	app := newTestApp(t, `{"n":1}`)
+
+	// Generated tokens are unique
+	first, _, firstDone := app.registerSearch("")
+	second, _, secondDone := app.registerSearch("")
+	if first == second {
+		t.Errorf("Expected unique generated tokens, got %s twice", first)
+	}
+	firstDone()
+	secondDone()
+
+	// A search reusing a running search's token replaces it
+	_, oldCtx, oldDone := app.registerSearch("t1")
+	_, newCtx, newDone := app.registerSearch("t1")
+	if oldCtx.Err() == nil || newCtx.Err() != nil {
+		t.Error("Expected only the replaced search to be cancelled")
+	}
+	oldDone()
+	if !app.CancelSearch("t1") || newCtx.Err() == nil {
+		t.Error("Expected the replacing search to stay cancellable after the replaced one finished")
+	}
+	newDone()
+
+	// The background count of a progressive search runs under its token
+	var lines []string
+	for i := 0; i < 100; i++ {
+		lines = append(lines, fmt.Sprintf(`{"n":%d}`, i))
+	}
+	app = newTestApp(t, strings.Join(lines, "\n"))
+	app.progressive = progressiveTuning{threshold: 10, interval: 10}
+	result, err := app.SearchRecords(SearchOptions{Query: "n", Limit: 5, Progressive: true, Token: "p1"})
+	if err != nil {
+		t.Fatalf("SearchRecords returned error: %v", err)
+	}
+	if result.SearchID != "p1" || !result.Estimated {
+		t.Fatalf("Expected an estimated search identified by its token, got %+v", result)
+	}
+	app.CancelSearch("p1")
+	deadline := time.Now().Add(2 * time.Second)
+	for app.CancelSearch("p1") {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the count to release its token")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -0,0 +1,278 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// SearchIndexStatus describes the search index of the loaded file; it is also
+// emitted as the "search:index" event once BuildSearchIndex finishes
+type SearchIndexStatus struct {
+	Ready      bool   `json:"ready"`    // the index matches the loaded records and is used by SearchRecords
+	Building   bool   `json:"building"` // a build is running in the background
+	Records    int    `json:"records"`
+	Trigrams   int    `json:"trigrams"` // distinct indexed trigrams
+	DurationMs int64  `json:"durationMs"`
+	Version    string `json:"version"`         // data version the index was built for
+	Error      string `json:"error,omitempty"` // why the last build failed
+}
+
+// searchIndex is an inverted index from case-folded trigrams to the positions of the
+// records containing them, in their raw JSON or in the text of a field value. Any
+// text a record matches by substring contains all of the text's trigrams, so
+// intersecting posting lists narrows a search to candidate records, which are then
+// checked with the regular matcher.
+type searchIndex struct {
+	version  uint64
+	records  int
+	postings map[string][]int32 // trigram -> ascending record positions
+	duration time.Duration
+}
+
+// buildSearchIndex indexes the trigrams of every record of cache, streaming
+// disk-backed files
+func buildSearchIndex(cache *RecordCache, version uint64) (*searchIndex, error) {
+	start := time.Now()
+	idx := &searchIndex{
+		version:  version,
+		postings: make(map[string][]int32),
+	}
+
+	seen := make(map[string]struct{})
+	addText := func(position int32, text string) {
+		text = foldCase(text)
+		for i := 0; i+3 <= len(text); i++ {
+			trigram := text[i : i+3]
+			if _, exists := seen[trigram]; exists {
+				continue
+			}
+			seen[trigram] = struct{}{}
+			idx.postings[trigram] = append(idx.postings[trigram], position)
+		}
+	}
+
+	err := cache.forEach(func(record JSONRecord) bool {
+		i := idx.records
+		idx.records++
+		clear(seen)
+		addText(int32(i), record.RawJSON)
+		// The decoded text the matchers check: the JSON of a nested object is part of
+		// the JSON of the top-level value holding it
+		index := func(text string) bool {
+			addText(int32(i), text)
+			return false
+		}
+		if record.Content == nil {
+			matchValueText(record.Value, index)
+		}
+		for _, value := range record.Content {
+			matchValueText(value, index)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	idx.duration = time.Since(start)
+	return idx, nil
+}
+
+// lookup returns the positions of the records that may contain text. ok is false
+// when text is too short to narrow the search.
+func (idx *searchIndex) lookup(text string) (positions []int32, ok bool) {
+	text = foldCase(text)
+	if len(text) < 3 {
+		return nil, false
+	}
+
+	var lists [][]int32
+	for i := 0; i+3 <= len(text); i++ {
+		list, exists := idx.postings[text[i:i+3]]
+		if !exists {
+			return []int32{}, true
+		}
+		lists = append(lists, list)
+	}
+	sort.Slice(lists, func(i, j int) bool {
+		return len(lists[i]) < len(lists[j])
+	})
+
+	positions = lists[0]
+	for _, list := range lists[1:] {
+		positions = intersectPositions(positions, list)
+	}
+	return positions, true
+}
+
+// intersectPositions returns the positions present in both ascending lists
+func intersectPositions(a, b []int32) []int32 {
+	result := []int32{}
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] < b[j]:
+			i++
+		case a[i] > b[j]:
+			j++
+		default:
+			result = append(result, a[i])
+			i++
+			j++
+		}
+	}
+	return result
+}
+
+// unionPositions returns the positions present in either ascending list
+func unionPositions(a, b []int32) []int32 {
+	result := make([]int32, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] < b[j]:
+			result = append(result, a[i])
+			i++
+		case a[i] > b[j]:
+			result = append(result, b[j])
+			j++
+		default:
+			result = append(result, a[i])
+			i++
+			j++
+		}
+	}
+	result = append(result, a[i:]...)
+	return append(result, b[j:]...)
+}
+
+// queryCandidates narrows a Lucene query to candidate positions. Substring terms,
+// phrases and field values use the index; other nodes, such as negations, ranges
+// and typed comparisons, can match records without the text and are unrestricted.
+func (idx *searchIndex) queryCandidates(query *LuceneQuery, opts matchOptions) ([]int32, bool) {
+	switch query.Type {
+	case "and":
+		left, leftOK := idx.queryCandidates(query.Left, opts)
+		right, rightOK := idx.queryCandidates(query.Right, opts)
+		switch {
+		case leftOK && rightOK:
+			return intersectPositions(left, right), true
+		case leftOK:
+			return left, true
+		}
+		return right, rightOK
+
+	case "or":
+		left, leftOK := idx.queryCandidates(query.Left, opts)
+		right, rightOK := idx.queryCandidates(query.Right, opts)
+		if !leftOK || !rightOK {
+			return nil, false
+		}
+		return unionPositions(left, right), true
+
+	case "field", "term":
+		if query.Field != "" && !opts.textOnly(query.Field) {
+			return nil, false
+		}
+		return idx.lookup(query.Value)
+
+	case "phrase":
+		return idx.lookup(query.Value)
+	}
+	return nil, false
+}
+
+// textOnly reports whether a field's values are matched purely as text, so the
+// index can narrow searches on it
+func (o matchOptions) textOnly(field string) bool {
+	_, coerced := o.Coercions[field]
+	return !coerced && !o.StrictTypes
+}
+
+// searchCandidates returns the positions of the records that may match a search,
+// or false when the index is missing, stale or cannot narrow the search
+func (c *RecordCache) searchCandidates(options SearchOptions, opts matchOptions) ([]int32, bool) {
+	idx := c.searchIndex.Load()
+	if idx == nil || idx.version != c.version || idx.records != c.totalCount || opts.IgnoreDiacritics || options.UseJSONPath {
+		return nil, false
+	}
+
+	if options.UseLucene {
+		query := parseLuceneQuery(options.Query)
+		if query == nil {
+			return nil, false
+		}
+		return idx.queryCandidates(query, opts)
+	}
+	for _, field := range searchFields(options.SelectedFields) {
+		if !opts.textOnly(field) {
+			return nil, false
+		}
+	}
+	return idx.lookup(options.Query)
+}
+
+// BuildSearchIndex builds a trigram index of the loaded records in the background.
+// Once ready, SearchRecords checks only the records that can contain the searched
+// text instead of scanning them all. A "search:index" event reports completion; the
+// index is dropped whenever the records change.
+func (a *App) BuildSearchIndex() error {
+	a.stateMu.RLock()
+	defer a.stateMu.RUnlock()
+
+	if a.currentFile == nil || a.cache == nil {
+		return &JSONLError{
+			Message: "No file currently loaded",
+			Err:     ErrNoFileLoaded,
+		}
+	}
+
+	cache := a.cache
+	if !cache.indexBuilding.CompareAndSwap(false, true) {
+		return nil
+	}
+	go func() {
+		defer cache.indexBuilding.Store(false)
+		a.stateMu.RLock()
+		idx, err := buildSearchIndex(cache, cache.version)
+		a.stateMu.RUnlock()
+		if err != nil {
+			a.emitEvent("search:index", SearchIndexStatus{Error: err.Error()})
+			return
+		}
+		cache.searchIndex.Store(idx)
+		status := idx.status()
+		status.Ready = true
+		a.emitEvent("search:index", status)
+	}()
+	return nil
+}
+
+// GetSearchIndexStatus reports whether a search index is ready or being built
+func (a *App) GetSearchIndexStatus() SearchIndexStatus {
+	a.stateMu.RLock()
+	defer a.stateMu.RUnlock()
+
+	if a.cache == nil {
+		return SearchIndexStatus{}
+	}
+	return a.cache.searchIndexStatus()
+}
+
+func (c *RecordCache) searchIndexStatus() SearchIndexStatus {
+	status := SearchIndexStatus{}
+	if idx := c.searchIndex.Load(); idx != nil {
+		status = idx.status()
+		status.Ready = idx.version == c.version && idx.records == c.totalCount
+	}
+	status.Building = c.indexBuilding.Load()
+	return status
+}
+
+func (idx *searchIndex) status() SearchIndexStatus {
+	return SearchIndexStatus{
+		Records:    idx.records,
+		Trigrams:   len(idx.postings),
+		DurationMs: idx.duration.Milliseconds(),
+		Version:    fmt.Sprintf("v%d", idx.version),
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// waitForSearchIndex waits until the background index build has finished
+func waitForSearchIndex(t *testing.T, app *App) SearchIndexStatus {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if status := app.GetSearchIndexStatus(); status.Ready && !status.Building {
+			return status
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatal("Search index was not built in time")
+	return SearchIndexStatus{}
+}
+
+func TestSearchIndexMatchesLinearScan(t *testing.T) {
+	var sb strings.Builder
+	levels := []string{"info", "warn", "error"}
+	for i := 0; i < 300; i++ {
+		fmt.Fprintf(&sb, `{"level":"%s","msg":"request %d took %dms","user":{"name":"user-%d"},"tags":["t%d"],"status":%d}`+"\n",
+			levels[i%3], i, i*7, i%17, i%5, 200+i%4*100)
+	}
+	app := newTestApp(t, sb.String())
+
+	searches := []SearchOptions{
+		{Query: "error"},
+		{Query: "took 14"},
+		{Query: "ER", CaseSensitive: true},
+		{Query: "user-1", SelectedFields: []string{"user.name"}},
+		{Query: "level:error AND msg:took", UseLucene: true},
+		{Query: "level:warn OR user.name:user-16", UseLucene: true},
+		{Query: `"request 12" OR NOT level:info`, UseLucene: true},
+		{Query: "status:>300 AND tags:t3", UseLucene: true},
+		{Query: "status:500", UseLucene: true, StrictTypes: true},
+		{Query: "nothing-like-this"},
+	}
+	expected := make([]*SearchResult, len(searches))
+	for i, options := range searches {
+		options.Limit = 1000
+		expected[i], _ = app.SearchRecords(options)
+	}
+
+	if err := app.BuildSearchIndex(); err != nil {
+		t.Fatalf("BuildSearchIndex returned error: %v", err)
+	}
+	status := waitForSearchIndex(t, app)
+	if status.Records != 300 || status.Trigrams == 0 {
+		t.Errorf("Unexpected index status: %+v", status)
+	}
+
+	opts := matchOptions{}
+	if candidates, ok := app.cache.searchCandidates(SearchOptions{Query: "nothing-like-this"}, opts); !ok || len(candidates) != 0 {
+		t.Errorf("Expected the index to rule out every record, got %v (%v)", candidates, ok)
+	}
+	if candidates, ok := app.cache.searchCandidates(SearchOptions{Query: "level:error", UseLucene: true}, opts); !ok || len(candidates) != 100 {
+		t.Errorf("Expected 100 candidates for level:error, got %d (%v)", len(candidates), ok)
+	}
+
+	for i, options := range searches {
+		options.Limit = 1000
+		result, err := app.SearchRecords(options)
+		if err != nil {
+			t.Fatalf("SearchRecords returned error: %v", err)
+		}
+		if result.TotalMatches != expected[i].TotalMatches || !reflect.DeepEqual(result.Records, expected[i].Records) {
+			t.Errorf("%+v: indexed search found %d matches, linear scan %d", options, result.TotalMatches, expected[i].TotalMatches)
+		}
+	}
+
+	// Changing the records drops the index
+	app.bumpDataVersion()
+	if status := app.GetSearchIndexStatus(); status.Ready {
+		t.Errorf("Expected the index to be dropped, got %+v", status)
+	}
+}
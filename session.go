@@ -0,0 +1,117 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// sessionFile stores the session saved at shutdown
+const sessionFile = "session.json"
+
+// Session is the view state saved when the app shuts down and restored by
+// RestoreLastSession
+type Session struct {
+	Path     string        `json:"path"`
+	Format   string        `json:"format,omitempty"` // see JSONLFile.Format
+	Table    string        `json:"table,omitempty"`  // see JSONLFile.Table
+	Merged   bool          `json:"merged,omitempty"` // Path is a pattern loaded by LoadJSONLGlob
+	Offset   int           `json:"offset"`           // first record of the last requested page
+	PageSize int           `json:"pageSize"`
+	Search   SearchOptions `json:"search"` // active query and selected fields
+	SavedAt  time.Time     `json:"savedAt"`
+}
+
+// currentSession captures the session of the current file, or nil when there is
+// nothing that could be reopened
+func (a *App) currentSession() *Session {
+	a.stateMu.RLock()
+	defer a.stateMu.RUnlock()
+
+	if a.currentFile == nil || a.cache == nil || a.currentFile.Path == "<clipboard>" {
+		return nil
+	}
+	return &Session{
+		Path:     a.currentFile.Path,
+		Format:   a.currentFile.Format,
+		Table:    a.currentFile.Table,
+		Merged:   len(a.currentFile.Sources) > 0,
+		Offset:   int(a.cache.lastOffset.Load()),
+		PageSize: a.cache.pageSize,
+		Search:   a.currentSearch(),
+		SavedAt:  time.Now(),
+	}
+}
+
+// saveSession stores the current session, or clears the saved one when no file is open
+func (a *App) saveSession() error {
+	session := a.currentSession()
+	if session == nil {
+		return a.ClearLastSession()
+	}
+	return writeConfigFile(sessionFile, session)
+}
+
+// GetLastSession returns the session saved at the last shutdown, or nil
+func (a *App) GetLastSession() (*Session, error) {
+	var session *Session
+	if err := readConfigFile(sessionFile, &session); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// ClearLastSession forgets the saved session
+func (a *App) ClearLastSession() error {
+	dir, err := configDir()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(filepath.Join(dir, sessionFile)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// RestoreLastSession reopens the file of the session saved at the last shutdown
+// with its page size and active search, and returns the session so the frontend
+// can scroll back to its offset and re-run its search. It returns nil when no
+// session was saved.
+func (a *App) RestoreLastSession() (*Session, error) {
+	session, err := a.GetLastSession()
+	if err != nil || session == nil {
+		return nil, err
+	}
+
+	if _, err := a.reopenSource(session.Path, session.Format, session.Table, session.Merged); err != nil {
+		return nil, err
+	}
+	if session.PageSize > 0 {
+		a.SetPageSize(session.PageSize)
+	}
+	a.setActiveSearch(session.Search)
+	a.stateMu.RLock()
+	a.cache.lastOffset.Store(int64(session.Offset))
+	a.stateMu.RUnlock()
+	return session, nil
+}
+
+// reopenSource loads a source again the way it was first opened
+func (a *App) reopenSource(path, format, table string, merged bool) (*JSONLFile, error) {
+	if merged {
+		return a.LoadJSONLGlob(path)
+	}
+	if bucket, key, ok := parseS3URI(path); ok {
+		return a.LoadJSONLFromS3(bucket, key, a.s3Profile)
+	}
+	if isRemotePath(path) {
+		return a.LoadJSONLFromURL(path)
+	}
+	if format == FormatJSON {
+		return a.LoadJSONArrayFile(path)
+	}
+	if format == FormatSQLite {
+		return a.LoadFromSQLite(path, table)
+	}
+	return a.loadFile(path, format)
+}
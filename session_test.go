@@ -0,0 +1,48 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRestoreLastSession(t *testing.T) {
+	useTempConfigDir(t)
+
+	path := filepath.Join(t.TempDir(), "app.log")
+	os.WriteFile(path, []byte("level=info n=1\nlevel=error n=2\nlevel=info n=3\n"), 0644)
+
+	app := &App{}
+	if session, err := app.RestoreLastSession(); err != nil || session != nil {
+		t.Fatalf("Expected no session before the first shutdown, got %+v (%v)", session, err)
+	}
+
+	if _, err := app.LoadLogfmtFile(path); err != nil {
+		t.Fatalf("LoadLogfmtFile returned error: %v", err)
+	}
+	app.SetPageSize(2)
+	app.GetRecords(2, 2)
+	app.SearchRecords(SearchOptions{Query: "info", SelectedFields: []string{"level"}})
+	app.shutdown(nil)
+
+	restored := &App{}
+	session, err := restored.RestoreLastSession()
+	if err != nil {
+		t.Fatalf("RestoreLastSession returned error: %v", err)
+	}
+	if session.Offset != 2 || session.PageSize != 2 || session.Search.Query != "info" || session.Search.SelectedFields[0] != "level" {
+		t.Errorf("Unexpected session %+v", session)
+	}
+	if restored.currentFile == nil || restored.currentFile.Format != ParseModeLogfmt || restored.cache.totalCount != 3 {
+		t.Fatalf("Expected the logfmt file to be reopened, got %+v", restored.currentFile)
+	}
+	if size, _ := restored.GetPageSize(); size != 2 || restored.activeSearch.Query != "info" {
+		t.Errorf("Expected the page size and search to be restored, got %d and %+v", size, restored.activeSearch)
+	}
+
+	// Shutting down without a file forgets the session
+	(&App{}).shutdown(nil)
+	if session, _ := restored.GetLastSession(); session != nil {
+		t.Errorf("Expected the session to be cleared, got %+v", session)
+	}
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+)
+
+// settingsFile stores the user's settings
+const settingsFile = "settings.json"
+
+// Defaults used for settings left at zero
+const (
+	defaultPageSize       = 50
+	defaultFollowInterval = 1000 // milliseconds
+	minFollowInterval     = 100
+)
+
+// ErrInvalidSettings is returned by UpdateSettings for out-of-range values
+var ErrInvalidSettings = errors.New("invalid settings")
+
+// Settings are the user's persisted defaults
+type Settings struct {
+	DefaultPageSize  int    `json:"defaultPageSize"`  // page size of newly opened files
+	CaseSensitive    bool   `json:"caseSensitive"`    // case sensitivity of searches that do not choose one
+	ExportDirectory  string `json:"exportDirectory"`  // where exports are written, "" for Downloads
+	FollowIntervalMs int    `json:"followIntervalMs"` // how often a followed file is polled, see StartFollow
+	MaxLineSize      int    `json:"maxLineSize"`      // see SetMaxLineSize, 0 for the default
+}
+
+// withDefaults fills in the defaults of settings left at zero
+func (s Settings) withDefaults() Settings {
+	if s.DefaultPageSize <= 0 {
+		s.DefaultPageSize = defaultPageSize
+	}
+	if s.FollowIntervalMs <= 0 {
+		s.FollowIntervalMs = defaultFollowInterval
+	}
+	if s.MaxLineSize <= 0 {
+		s.MaxLineSize = defaultMaxLineSize
+	}
+	return s
+}
+
+// validate checks settings with defaults filled in
+func (s Settings) validate() error {
+	switch {
+	case s.DefaultPageSize > 1000:
+		return fmt.Errorf("%w: default page size cannot exceed 1000", ErrInvalidSettings)
+	case s.FollowIntervalMs < minFollowInterval:
+		return fmt.Errorf("%w: follow interval must be at least %d ms", ErrInvalidSettings, minFollowInterval)
+	case s.MaxLineSize < minMaxLineSize || s.MaxLineSize > maxMaxLineSize:
+		return fmt.Errorf("%w: max line size must be between %d and %d bytes", ErrInvalidSettings, minMaxLineSize, maxMaxLineSize)
+	case s.ExportDirectory != "" && !filepath.IsAbs(s.ExportDirectory):
+		return fmt.Errorf("%w: export directory must be an absolute path", ErrInvalidSettings)
+	}
+	return nil
+}
+
+// loadSettings reads the stored settings, falling back to defaults
+func loadSettings() Settings {
+	var settings Settings
+	readConfigFile(settingsFile, &settings)
+	return settings.withDefaults()
+}
+
+// applySettings makes settings the app's current settings
+func (a *App) applySettings(settings Settings) {
+	a.stateMu.Lock()
+	defer a.stateMu.Unlock()
+	a.settings = settings
+	a.maxLineSize = settings.MaxLineSize
+}
+
+// GetSettings returns the current settings
+func (a *App) GetSettings() Settings {
+	a.stateMu.RLock()
+	defer a.stateMu.RUnlock()
+	return a.settings.withDefaults()
+}
+
+// UpdateSettings validates, applies and persists settings; zero values select the
+// defaults. The default page size applies to files opened afterwards, the follow
+// interval from the next poll on.
+func (a *App) UpdateSettings(settings Settings) (Settings, error) {
+	settings = settings.withDefaults()
+	if err := settings.validate(); err != nil {
+		return a.GetSettings(), &JSONLError{
+			Message: err.Error(),
+			Err:     ErrInvalidSettings,
+		}
+	}
+	if err := writeConfigFile(settingsFile, settings); err != nil {
+		return a.GetSettings(), err
+	}
+	a.applySettings(settings)
+	return settings, nil
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestUpdateSettings(t *testing.T) {
+	useTempConfigDir(t)
+
+	app := NewApp()
+	if settings := app.GetSettings(); settings.DefaultPageSize != defaultPageSize || settings.MaxLineSize != defaultMaxLineSize {
+		t.Fatalf("Expected default settings, got %+v", settings)
+	}
+
+	exportDir := t.TempDir()
+	settings, err := app.UpdateSettings(Settings{DefaultPageSize: 25, CaseSensitive: true, ExportDirectory: exportDir, MaxLineSize: 4096})
+	if err != nil {
+		t.Fatalf("UpdateSettings returned error: %v", err)
+	}
+	if settings.FollowIntervalMs != defaultFollowInterval || app.GetMaxLineSize() != 4096 {
+		t.Errorf("Expected defaults for zero values and the line size applied, got %+v", settings)
+	}
+
+	for _, invalid := range []Settings{
+		{DefaultPageSize: 5000},
+		{FollowIntervalMs: 10},
+		{MaxLineSize: 10},
+		{ExportDirectory: "relative/dir"},
+	} {
+		var jsonErr *JSONLError
+		if _, err := app.UpdateSettings(invalid); !errors.As(err, &jsonErr) || !errors.Is(jsonErr.Err, ErrInvalidSettings) {
+			t.Errorf("Expected ErrInvalidSettings for %+v, got %v", invalid, err)
+		}
+	}
+	if app.GetSettings().DefaultPageSize != 25 {
+		t.Error("Expected invalid updates to keep the previous settings")
+	}
+
+	// Settings persist and apply to newly opened files and exports
+	restarted := NewApp()
+	path := filepath.Join(t.TempDir(), "data.jsonl")
+	os.WriteFile(path, []byte("{\"n\":1,\"level\":\"ERROR\"}\n{\"n\":2,\"level\":\"error\"}\n"), 0644)
+	if _, err := restarted.LoadJSONLFile(path); err != nil {
+		t.Fatalf("LoadJSONLFile returned error: %v", err)
+	}
+	if size, _ := restarted.GetPageSize(); size != 25 || !restarted.GetSettings().CaseSensitive {
+		t.Errorf("Expected persisted settings to apply, got page size %d", size)
+	}
+
+	// Searches that do not choose a case sensitivity use the default
+	exported, err := restarted.ExportSearchResults("error", nil, nil)
+	if err != nil {
+		t.Fatalf("ExportSearchResults returned error: %v", err)
+	}
+	if !strings.HasPrefix(exported, exportDir) {
+		t.Errorf("Expected the export in %s, got %s", exportDir, exported)
+	}
+	if content, _ := os.ReadFile(exported); strings.Count(string(content), "\n") != 1 {
+		t.Errorf("Expected a case-sensitive export of one record, got %q", content)
+	}
+	result, err := restarted.ExecuteCommand("search.run", map[string]interface{}{"query": "ERROR"})
+	if err != nil {
+		t.Fatalf("ExecuteCommand returned error: %v", err)
+	}
+	if total := result.(*SearchResult).TotalMatches; total != 1 {
+		t.Errorf("Expected the search command to match case by default, got %d records", total)
+	}
+}
@@ -0,0 +1,261 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Sort directions for SortKey
+const (
+	SortAscending  = "asc"
+	SortDescending = "desc"
+)
+
+// ErrInvalidSort is returned for sort keys with an unknown direction or type
+var ErrInvalidSort = errors.New("invalid sort key")
+
+// SortKey orders records by a field's values
+type SortKey struct {
+	Field     string `json:"field"`
+	Direction string `json:"direction"` // 'asc' (default) or 'desc'
+	Type      string `json:"type"`      // 'number', 'timestamp' (or 'date'), 'string'; empty uses the field's coercion or detects it
+}
+
+// validate checks and normalizes a sort key
+func (k *SortKey) validate() error {
+	k.Field = strings.TrimSpace(k.Field)
+	if k.Field == "" {
+		return fmt.Errorf("%w: field cannot be empty", ErrInvalidSort)
+	}
+
+	switch strings.ToLower(k.Direction) {
+	case "", SortAscending, "ascending":
+		k.Direction = SortAscending
+	case SortDescending, "descending":
+		k.Direction = SortDescending
+	default:
+		return fmt.Errorf("%w: unknown direction %q", ErrInvalidSort, k.Direction)
+	}
+
+	switch strings.ToLower(k.Type) {
+	case "":
+		k.Type = ""
+	case CoerceNumber:
+		k.Type = CoerceNumber
+	case CoerceTimestamp, "date":
+		k.Type = CoerceTimestamp
+	case CoerceString:
+		k.Type = CoerceString
+	default:
+		return fmt.Errorf("%w: unknown type %q", ErrInvalidSort, k.Type)
+	}
+	return nil
+}
+
+// sortCoercion returns how a key's values are compared: its type hint, the field's
+// coercion, or the type all of the field's values convert to
+func (a *App) sortCoercion(key SortKey, records []JSONRecord) FieldCoercion {
+	if key.Type != "" {
+		return FieldCoercion{Field: key.Field, Type: key.Type}
+	}
+	if coercion, exists := a.coercions[key.Field]; exists {
+		return coercion
+	}
+
+	number := FieldCoercion{Field: key.Field, Type: CoerceNumber}
+	numeric, dated := true, true
+	for _, record := range records {
+		value, exists := getFieldValue(record.Content, key.Field)
+		if !exists || value == nil {
+			continue
+		}
+		if _, ok := number.coerce(value); !ok {
+			numeric = false
+		}
+		if str, ok := value.(string); !ok {
+			dated = false
+		} else if _, ok := parseTimestampString(strings.TrimSpace(str)); !ok {
+			dated = false
+		}
+		if !numeric && !dated {
+			break
+		}
+	}
+
+	switch {
+	case numeric:
+		return number
+	case dated:
+		return FieldCoercion{Field: key.Field, Type: CoerceTimestamp}
+	}
+	return FieldCoercion{Field: key.Field, Type: CoerceString}
+}
+
+// sortRecords returns the records stably ordered by the keys, the first key taking
+// precedence. Values missing or not convertible to a key's type sort last in
+// either direction.
+func (a *App) sortRecords(records []JSONRecord, keys []SortKey) []JSONRecord {
+	if len(keys) == 0 || len(records) < 2 {
+		return records
+	}
+
+	order := a.sortOrder(records, keys)
+	sorted := make([]JSONRecord, len(records))
+	for i, index := range order {
+		sorted[i] = records[index]
+	}
+	return sorted
+}
+
+// sortOrder returns the positions of the records in the order given by the keys
+func (a *App) sortOrder(records []JSONRecord, keys []SortKey) []int {
+	// Convert every value once up front
+	values := make([][]interface{}, len(keys))
+	for k, key := range keys {
+		coercion := a.sortCoercion(key, records)
+		values[k] = make([]interface{}, len(records))
+		for i, record := range records {
+			value, _ := getFieldValue(record.Content, key.Field)
+			if coerced, ok := coercion.coerce(value); ok {
+				values[k][i] = coerced
+			}
+		}
+	}
+
+	order := make([]int, len(records))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		for k, key := range keys {
+			left, right := values[k][order[i]], values[k][order[j]]
+			switch {
+			case left == nil && right == nil:
+				continue
+			case left == nil:
+				return false
+			case right == nil:
+				return true
+			}
+			cmp := compareCoerced(left, right)
+			if cmp == 0 {
+				continue
+			}
+			if key.Direction == SortDescending {
+				return cmp > 0
+			}
+			return cmp < 0
+		}
+		return false
+	})
+	return order
+}
+
+// restoreFileOrder puts the cached records back in the order they were read
+func (a *App) restoreFileOrder() {
+	if a.cache.fileOrder == nil {
+		return
+	}
+	records := make([]JSONRecord, len(a.cache.records))
+	for i, position := range a.cache.fileOrder {
+		records[position] = a.cache.records[i]
+	}
+	a.cache.records = records
+	a.records = records
+	a.cache.fileOrder = nil
+	a.cache.sortedIndex = nil
+	a.cache.sortKeys = nil
+}
+
+// applyRecordOrder reorders the cached records, held in file order, by the keys
+func (a *App) applyRecordOrder(keys []SortKey) {
+	order := a.sortOrder(a.cache.derivedRecords(), keys)
+	records := make([]JSONRecord, len(order))
+	sortedIndex := make([]int, len(order))
+	for i, position := range order {
+		records[i] = a.cache.records[position]
+		sortedIndex[position] = i
+	}
+	a.cache.records = records
+	a.records = records
+	a.cache.fileOrder = order
+	a.cache.sortedIndex = sortedIndex
+	a.cache.sortKeys = keys
+}
+
+// SortRecords reorders the loaded records by one or more fields, the first key
+// taking precedence, with typed comparison as in SearchOptions.SortBy. Pagination,
+// search and export then follow the sorted order until ResetOrder. It returns the
+// first page of sorted records.
+func (a *App) SortRecords(keys []SortKey) (*PaginatedRecords, error) {
+	a.stateMu.Lock()
+	defer a.stateMu.Unlock()
+
+	if a.currentFile == nil || a.cache == nil {
+		return nil, &JSONLError{
+			Message: "No file currently loaded",
+			Err:     ErrNoFileLoaded,
+		}
+	}
+	if len(keys) == 0 {
+		return nil, &JSONLError{
+			Message: "At least one sort key is required",
+			Err:     ErrInvalidSort,
+		}
+	}
+	if a.cache.diskBacked() {
+		return nil, &JSONLError{
+			Message: "Disk-backed files can only be shown in file order",
+			Err:     ErrInvalidSort,
+		}
+	}
+
+	normalized := make([]SortKey, len(keys))
+	for i, key := range keys {
+		if err := key.validate(); err != nil {
+			return nil, &JSONLError{
+				Message: err.Error(),
+				Err:     ErrInvalidSort,
+			}
+		}
+		normalized[i] = key
+	}
+
+	a.restoreFileOrder()
+	a.applyRecordOrder(normalized)
+	a.bumpDataVersion()
+	return a.getRecords(0, 0)
+}
+
+// ResetOrder returns the loaded records to file order after SortRecords
+func (a *App) ResetOrder() (*PaginatedRecords, error) {
+	a.stateMu.Lock()
+	defer a.stateMu.Unlock()
+
+	if a.currentFile == nil || a.cache == nil {
+		return nil, &JSONLError{
+			Message: "No file currently loaded",
+			Err:     ErrNoFileLoaded,
+		}
+	}
+
+	if a.cache.fileOrder != nil {
+		a.restoreFileOrder()
+		a.bumpDataVersion()
+	}
+	return a.getRecords(0, 0)
+}
+
+// GetRecordOrder returns the sort keys the loaded records are ordered by, or an
+// empty list in file order
+func (a *App) GetRecordOrder() []SortKey {
+	a.stateMu.RLock()
+	defer a.stateMu.RUnlock()
+
+	if a.cache == nil || a.cache.sortKeys == nil {
+		return []SortKey{}
+	}
+	return a.cache.sortKeys
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSearchSortBy(t *testing.T) {
+	app := newTestApp(t, `{"path":"/a","latency_ms":120,"ts":"2024-05-02T10:00:00Z","code":"9"}
+{"path":"/b","latency_ms":900,"ts":"2024-05-01T23:00:00+02:00","code":"10"}
+{"path":"/c","ts":"2024-05-01T08:00:00Z","code":"x"}
+{"path":"/d","latency_ms":30,"ts":"2024-05-03T00:00:00Z","code":"100"}
+{"path":"/e","latency_ms":900,"ts":"bad","code":"2"}
+`)
+	lines := func(result *SearchResult) string {
+		var numbers []int
+		for _, record := range result.Records {
+			numbers = append(numbers, record.LineNumber)
+		}
+		return fmt.Sprint(numbers)
+	}
+
+	tests := []struct {
+		sort     SortKey
+		expected string
+	}{
+		{SortKey{Field: "latency_ms", Direction: "desc"}, "[2 5 1 4 3]"}, // stable ties, missing last
+		{SortKey{Field: "latency_ms"}, "[4 1 2 5 3]"},
+		{SortKey{Field: "ts", Direction: "asc"}, "[3 2 1 4 5]"}, // detected as text: "bad" is not a date
+		{SortKey{Field: "ts", Type: "date"}, "[3 2 1 4 5]"},     // UTC order, unparseable last
+		{SortKey{Field: "code", Type: "number", Direction: "DESC"}, "[4 2 1 5 3]"},
+		{SortKey{Field: "code", Type: "string"}, "[2 4 5 1 3]"},
+	}
+	for _, tt := range tests {
+		sortKey := tt.sort
+		result, err := app.SearchRecords(SearchOptions{Query: "path", SortBy: &sortKey, Limit: 10})
+		if err != nil {
+			t.Fatalf("SearchRecords returned error: %v", err)
+		}
+		if got := lines(result); got != tt.expected {
+			t.Errorf("%+v: expected %s, got %s", tt.sort, tt.expected, got)
+		}
+	}
+
+	// Sorting happens before pagination
+	result, _ := app.SearchRecords(SearchOptions{Query: "path", SortBy: &SortKey{Field: "latency_ms", Direction: "desc"}, Offset: 1, Limit: 2})
+	if lines(result) != "[5 1]" {
+		t.Errorf("Expected the second page of sorted results, got %s", lines(result))
+	}
+
+	for _, invalid := range []SortKey{{Field: ""}, {Field: "a", Direction: "up"}, {Field: "a", Type: "bool"}} {
+		key := invalid
+		_, err := app.SearchRecords(SearchOptions{Query: "path", SortBy: &key})
+		var jsonlErr *JSONLError
+		if !errors.As(err, &jsonlErr) || !errors.Is(jsonlErr.Err, ErrInvalidSort) {
+			t.Errorf("Expected ErrInvalidSort for %+v, got %v", invalid, err)
+		}
+	}
+}
+
+func TestSortRecords(t *testing.T) {
+	useTempConfigDir(t)
+	path := filepath.Join(t.TempDir(), "requests.jsonl")
+	os.WriteFile(path, []byte(`{"svc":"api","latency_ms":120}
+{"svc":"db","latency_ms":900}
+{"svc":"api"}
+{"svc":"db","latency_ms":30}
+{"svc":"api","latency_ms":900}
+`), 0644)
+	app := &App{}
+	if _, err := app.LoadJSONLFile(path); err != nil {
+		t.Fatalf("LoadJSONLFile returned error: %v", err)
+	}
+	lines := func(records []JSONRecord) string {
+		var numbers []int
+		for _, record := range records {
+			numbers = append(numbers, record.LineNumber)
+		}
+		return fmt.Sprint(numbers)
+	}
+
+	page, err := app.SortRecords([]SortKey{{Field: "svc"}, {Field: "latency_ms", Direction: "desc"}})
+	if err != nil {
+		t.Fatalf("SortRecords returned error: %v", err)
+	}
+	if lines(page.Records) != "[5 1 3 2 4]" {
+		t.Errorf("Expected sorted order [5 1 3 2 4], got %s", lines(page.Records))
+	}
+	if keys := app.GetRecordOrder(); len(keys) != 2 || keys[0].Direction != SortAscending {
+		t.Errorf("Expected normalized sort keys, got %+v", keys)
+	}
+
+	// Pagination, search and export follow the sorted order
+	if page, _ := app.GetRecords(1, 2); lines(page.Records) != "[1 3]" {
+		t.Errorf("Expected page [1 3], got %s", lines(page.Records))
+	}
+	if result, _ := app.SearchRecords(SearchOptions{Query: "db"}); lines(result.Records) != "[2 4]" {
+		t.Errorf("Expected search results in sorted order, got %s", lines(result.Records))
+	}
+	if all, _ := app.GetAllRecords(""); lines(all) != "[5 1 3 2 4]" {
+		t.Errorf("Expected export in sorted order, got %s", lines(all))
+	}
+
+	// Appended records are merged and sorted in
+	f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	f.WriteString(`{"svc":"api","latency_ms":500}` + "\n")
+	f.Close()
+	if _, err := app.ReloadIncremental(); err != nil {
+		t.Fatalf("ReloadIncremental returned error: %v", err)
+	}
+	if page, _ := app.GetRecords(0, 10); lines(page.Records) != "[5 6 1 3 2 4]" {
+		t.Errorf("Expected the appended record in sorted position, got %s", lines(page.Records))
+	}
+
+	page, err = app.ResetOrder()
+	if err != nil || lines(page.Records) != "[1 2 3 4 5 6]" || len(app.GetRecordOrder()) != 0 {
+		t.Errorf("Expected file order after reset, got %s (%v)", lines(page.Records), err)
+	}
+
+	// IDs beyond float64 precision keep their exact order
+	ids := newTestApp(t, `{"id":9007199254740993}
+{"id":9007199254740992}
+{"id":"9007199254740994"}
+`)
+	if page, _ := ids.SortRecords([]SortKey{{Field: "id"}}); lines(page.Records) != "[2 1 3]" {
+		t.Errorf("Expected exact numeric order [2 1 3], got %s", lines(page.Records))
+	}
+
+	_, err = app.SortRecords([]SortKey{{Field: "svc", Direction: "sideways"}})
+	var jsonlErr *JSONLError
+	if !errors.As(err, &jsonlErr) || !errors.Is(jsonlErr.Err, ErrInvalidSort) {
+		t.Errorf("Expected ErrInvalidSort, got %v", err)
+	}
+}
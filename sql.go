@@ -0,0 +1,1108 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// ErrInvalidSQL is returned for SQL queries that cannot be parsed
+var ErrInvalidSQL = errors.New("invalid SQL query")
+
+// sqlTable is the table name the loaded records are queried as
+const sqlTable = "records"
+
+// maxSQLRows caps the rows RunSQL returns without a LIMIT
+var maxSQLRows = 10000
+
+// SQLResult is the tabular result of RunSQL
+type SQLResult struct {
+	Columns    []string        `json:"columns"`
+	Rows       [][]interface{} `json:"rows"`
+	Scanned    int             `json:"scanned"`   // records read
+	Truncated  bool            `json:"truncated"` // more rows matched than maxSQLRows
+	DurationMs int64           `json:"durationMs"`
+}
+
+// sqlQuery is a parsed SELECT statement. Expressions compile to the computed field
+// expression nodes, plus the SQL-only nodes below.
+type sqlQuery struct {
+	distinct   bool // SELECT DISTINCT
+	star       bool // SELECT *
+	columns    []sqlColumn
+	where      exprNode
+	groupBy    []exprNode
+	having     exprNode
+	orderBy    []sqlOrder
+	limit      int // -1 when absent
+	offset     int
+	aggregates []*aggregateNode
+}
+
+type sqlColumn struct {
+	name string
+	expr exprNode
+}
+
+type sqlOrder struct {
+	expr   exprNode
+	column int // index of the output column ordered by, or -1
+	desc   bool
+}
+
+// likeNode matches text against a LIKE pattern, case-insensitively
+type likeNode struct {
+	operand exprNode
+	pattern *regexp.Regexp
+	negate  bool
+}
+
+// inNode tests membership in a list of values
+type inNode struct {
+	operand exprNode
+	values  []exprNode
+	negate  bool
+}
+
+// isNullNode tests for null or missing values
+type isNullNode struct {
+	operand exprNode
+	negate  bool
+}
+
+// aggregateNode reads the value of an aggregate computed for the current group,
+// which is stored in the group's row under key
+type aggregateNode struct {
+	fn       string // count, sum, avg, min, max
+	arg      exprNode
+	distinct bool
+	key      string
+}
+
+func (n likeNode) eval(content map[string]interface{}) (interface{}, error) {
+	value, err := n.operand.eval(content)
+	if err != nil || value == nil {
+		return false, err
+	}
+	return n.pattern.MatchString(valueToString(value)) != n.negate, nil
+}
+
+func (n inNode) eval(content map[string]interface{}) (interface{}, error) {
+	value, err := n.operand.eval(content)
+	if err != nil || value == nil {
+		return false, err
+	}
+	for _, candidate := range n.values {
+		other, err := candidate.eval(content)
+		if err != nil {
+			return nil, err
+		}
+		if exprEqual(value, other) {
+			return !n.negate, nil
+		}
+	}
+	return n.negate, nil
+}
+
+func (n isNullNode) eval(content map[string]interface{}) (interface{}, error) {
+	value, err := n.operand.eval(content)
+	if err != nil {
+		return nil, err
+	}
+	return (value == nil) != n.negate, nil
+}
+
+func (n *aggregateNode) eval(content map[string]interface{}) (interface{}, error) {
+	return content[n.key], nil
+}
+
+// likePattern compiles a LIKE pattern, where % matches any text and _ one character
+func likePattern(pattern string) *regexp.Regexp {
+	var sb strings.Builder
+	sb.WriteString("(?is)^")
+	for _, r := range pattern {
+		switch r {
+		case '%':
+			sb.WriteString(".*")
+		case '_':
+			sb.WriteString(".")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	sb.WriteString("$")
+	return regexp.MustCompile(sb.String())
+}
+
+// tokenizeSQL splits a query into tokens. Identifiers may contain dots to reach
+// nested fields and can be quoted with double quotes or backticks; strings use
+// single quotes. Quoted identifiers carry a non-nil value so they are never read as
+// keywords. The offsets hold each token's byte offset in the query.
+func tokenizeSQL(input string) ([]exprToken, []int, error) {
+	var tokens []exprToken
+	var offsets []int
+
+	for i := 0; i < len(input); {
+		r := rune(input[i])
+		start := i
+		switch {
+		case unicode.IsSpace(r):
+			i++
+			continue
+
+		case unicode.IsDigit(r) || (r == '.' && i+1 < len(input) && unicode.IsDigit(rune(input[i+1]))):
+			for i < len(input) && (unicode.IsDigit(rune(input[i])) || input[i] == '.' || input[i] == 'e' || input[i] == 'E' ||
+				((input[i] == '+' || input[i] == '-') && (input[i-1] == 'e' || input[i-1] == 'E'))) {
+				i++
+			}
+			if _, err := strconv.ParseFloat(input[start:i], 64); err != nil {
+				return nil, nil, fmt.Errorf("invalid number %q", input[start:i])
+			}
+			tokens = append(tokens, exprToken{kind: "number", text: input[start:i], value: json.Number(input[start:i])})
+
+		case r == '\'' || r == '"' || r == '`':
+			var sb strings.Builder
+			i++
+			for {
+				if i >= len(input) {
+					return nil, nil, errors.New("unterminated quote")
+				}
+				if input[i] == byte(r) {
+					// A doubled quote stands for the quote itself
+					if i+1 < len(input) && input[i+1] == byte(r) {
+						sb.WriteByte(byte(r))
+						i += 2
+						continue
+					}
+					i++
+					break
+				}
+				sb.WriteByte(input[i])
+				i++
+			}
+			if r == '\'' {
+				tokens = append(tokens, exprToken{kind: "string", text: sb.String(), value: sb.String()})
+			} else {
+				tokens = append(tokens, exprToken{kind: "ident", text: sb.String(), value: true})
+			}
+
+		case r >= 0x80 || unicode.IsLetter(r) || r == '_' || r == '@' || r == '$':
+			for i < len(input) && (input[i] >= 0x80 || unicode.IsLetter(rune(input[i])) || unicode.IsDigit(rune(input[i])) ||
+				strings.IndexByte("_.@$", input[i]) >= 0) {
+				i++
+			}
+			tokens = append(tokens, exprToken{kind: "ident", text: input[start:i]})
+
+		default:
+			op := string(r)
+			if i+1 < len(input) {
+				switch two := input[i : i+2]; two {
+				case "<>", "!=", "<=", ">=", "==":
+					op = two
+				}
+			}
+			if len(op) == 1 && !strings.Contains("+-*/%(),<>=", op) {
+				return nil, nil, fmt.Errorf("unexpected character %q", r)
+			}
+			tokens = append(tokens, exprToken{kind: "op", text: op})
+			i += len(op)
+		}
+		offsets = append(offsets, start)
+	}
+
+	tokens = append(tokens, exprToken{kind: "eof"})
+	offsets = append(offsets, len(input))
+	return tokens, offsets, nil
+}
+
+// sqlParser is a recursive descent parser over SQL tokens
+type sqlParser struct {
+	input   string
+	tokens  []exprToken
+	offsets []int
+	pos     int
+	query   *sqlQuery
+}
+
+// parseSQL parses a SELECT statement over the records table
+func parseSQL(input string) (*sqlQuery, error) {
+	input = strings.TrimSuffix(strings.TrimSpace(input), ";")
+	tokens, offsets, err := tokenizeSQL(input)
+	if err != nil {
+		return nil, err
+	}
+
+	query := &sqlQuery{limit: -1}
+	p := &sqlParser{input: input, tokens: tokens, offsets: offsets, query: query}
+	if !p.acceptKeyword("SELECT") {
+		return nil, errors.New("expected SELECT")
+	}
+	query.distinct = p.acceptKeyword("DISTINCT")
+	if err := p.parseColumns(); err != nil {
+		return nil, err
+	}
+
+	if !p.acceptKeyword("FROM") {
+		return nil, fmt.Errorf("expected FROM, got %q", p.peek().text)
+	}
+	if table := p.peek(); table.kind != "ident" || !strings.EqualFold(table.text, sqlTable) {
+		return nil, fmt.Errorf("unknown table %q, query FROM %s", table.text, sqlTable)
+	}
+	p.pos++
+
+	if p.acceptKeyword("WHERE") {
+		selected := len(query.aggregates)
+		if query.where, err = p.parseOr(); err != nil {
+			return nil, err
+		}
+		if len(query.aggregates) > selected {
+			return nil, errors.New("aggregates are not allowed in WHERE")
+		}
+	}
+	if p.acceptKeyword("GROUP") {
+		if !p.acceptKeyword("BY") {
+			return nil, errors.New("expected BY after GROUP")
+		}
+		for {
+			expr, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			query.groupBy = append(query.groupBy, query.resolveAlias(expr))
+			if !p.acceptOp(",") {
+				break
+			}
+		}
+	}
+	if p.acceptKeyword("HAVING") {
+		if query.having, err = p.parseOr(); err != nil {
+			return nil, err
+		}
+	}
+	if p.acceptKeyword("ORDER") {
+		if !p.acceptKeyword("BY") {
+			return nil, errors.New("expected BY after ORDER")
+		}
+		grouped := query.grouped()
+		if err := p.parseOrderBy(); err != nil {
+			return nil, err
+		}
+		if !grouped && query.grouped() {
+			return nil, errors.New("aggregates in ORDER BY need GROUP BY or an aggregate in the select list")
+		}
+	}
+	if p.acceptKeyword("LIMIT") {
+		if query.limit, err = p.parseCount("LIMIT"); err != nil {
+			return nil, err
+		}
+		if p.acceptKeyword("OFFSET") {
+			if query.offset, err = p.parseCount("OFFSET"); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if p.peek().kind != "eof" {
+		return nil, fmt.Errorf("unexpected %q", p.peek().text)
+	}
+	if query.star && query.grouped() {
+		return nil, errors.New("SELECT * cannot be combined with GROUP BY or aggregates")
+	}
+	if query.grouped() {
+		for _, column := range query.columns {
+			if !query.groupedExpr(column.expr) {
+				return nil, fmt.Errorf("column %s must appear in GROUP BY or be used in an aggregate", column.name)
+			}
+		}
+		for _, order := range query.orderBy {
+			if order.column < 0 && !query.groupedExpr(order.expr) {
+				return nil, errors.New("ORDER BY terms must appear in GROUP BY or be used in an aggregate")
+			}
+		}
+	}
+	if query.distinct {
+		for _, order := range query.orderBy {
+			if order.column < 0 && !query.selected(order.expr) {
+				return nil, errors.New("ORDER BY terms of SELECT DISTINCT must appear in the select list")
+			}
+		}
+	}
+	return query, nil
+}
+
+// groupedExpr reports whether expr has one value per group: it is a GROUP BY
+// expression, an aggregate, a literal or built from those
+func (q *sqlQuery) groupedExpr(expr exprNode) bool {
+	for _, group := range q.groupBy {
+		if sameExpr(expr, group) {
+			return true
+		}
+	}
+	switch node := expr.(type) {
+	case literalNode, *aggregateNode:
+		return true
+	case unaryNode:
+		return q.groupedExpr(node.operand)
+	case binaryNode:
+		return q.groupedExpr(node.left) && q.groupedExpr(node.right)
+	case likeNode:
+		return q.groupedExpr(node.operand)
+	case isNullNode:
+		return q.groupedExpr(node.operand)
+	case inNode:
+		for _, value := range node.values {
+			if !q.groupedExpr(value) {
+				return false
+			}
+		}
+		return q.groupedExpr(node.operand)
+	}
+	return false
+}
+
+// selected reports whether expr is one of the output columns; after SELECT * any
+// top-level field is
+func (q *sqlQuery) selected(expr exprNode) bool {
+	if field, ok := expr.(fieldNode); ok && q.star && !strings.Contains(field.path, ".") {
+		return true
+	}
+	for _, column := range q.columns {
+		if sameExpr(expr, column.expr) {
+			return true
+		}
+	}
+	return false
+}
+
+// sameExpr reports whether two expressions compute the same value; aggregates
+// are the same when their function and argument are
+func sameExpr(left, right exprNode) bool {
+	switch l := left.(type) {
+	case *aggregateNode:
+		r, ok := right.(*aggregateNode)
+		if !ok || l.fn != r.fn || l.distinct != r.distinct || (l.arg == nil) != (r.arg == nil) {
+			return false
+		}
+		return l.arg == nil || sameExpr(l.arg, r.arg)
+	case unaryNode:
+		r, ok := right.(unaryNode)
+		return ok && l.op == r.op && sameExpr(l.operand, r.operand)
+	case binaryNode:
+		r, ok := right.(binaryNode)
+		return ok && l.op == r.op && sameExpr(l.left, r.left) && sameExpr(l.right, r.right)
+	case likeNode:
+		r, ok := right.(likeNode)
+		return ok && l.negate == r.negate && l.pattern.String() == r.pattern.String() && sameExpr(l.operand, r.operand)
+	case isNullNode:
+		r, ok := right.(isNullNode)
+		return ok && l.negate == r.negate && sameExpr(l.operand, r.operand)
+	case inNode:
+		r, ok := right.(inNode)
+		if !ok || l.negate != r.negate || len(l.values) != len(r.values) || !sameExpr(l.operand, r.operand) {
+			return false
+		}
+		for i := range l.values {
+			if !sameExpr(l.values[i], r.values[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	return reflect.DeepEqual(left, right)
+}
+
+// resolveAlias replaces a field reference naming an output column alias with the
+// column's expression, so GROUP BY can refer to computed columns
+func (q *sqlQuery) resolveAlias(expr exprNode) exprNode {
+	field, ok := expr.(fieldNode)
+	if !ok {
+		return expr
+	}
+	for _, column := range q.columns {
+		if column.name == field.path {
+			return column.expr
+		}
+	}
+	return expr
+}
+
+// grouped reports whether the query aggregates records into groups
+func (q *sqlQuery) grouped() bool {
+	return len(q.groupBy) > 0 || len(q.aggregates) > 0
+}
+
+func (p *sqlParser) peek() exprToken {
+	return p.tokens[p.pos]
+}
+
+// text returns the query source between two token positions
+func (p *sqlParser) text(from, to int) string {
+	return strings.TrimSpace(p.input[p.offsets[from]:p.offsets[to]])
+}
+
+// acceptKeyword consumes the next token if it is the unquoted keyword
+func (p *sqlParser) acceptKeyword(keyword string) bool {
+	token := p.peek()
+	if token.kind == "ident" && token.value == nil && strings.EqualFold(token.text, keyword) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *sqlParser) isKeyword(keyword string) bool {
+	token := p.peek()
+	return token.kind == "ident" && token.value == nil && strings.EqualFold(token.text, keyword)
+}
+
+func (p *sqlParser) acceptOp(ops ...string) bool {
+	token := p.peek()
+	if token.kind != "op" {
+		return false
+	}
+	for _, op := range ops {
+		if token.text == op {
+			p.pos++
+			return true
+		}
+	}
+	return false
+}
+
+// sqlReserved are the keywords that end a column expression without AS
+var sqlReserved = []string{"FROM", "WHERE", "GROUP", "HAVING", "ORDER", "LIMIT", "OFFSET", "AS", "ASC", "DESC"}
+
+func (p *sqlParser) parseColumns() error {
+	if p.acceptOp("*") {
+		p.query.star = true
+		return nil
+	}
+	for {
+		start := p.pos
+		expr, err := p.parseOr()
+		if err != nil {
+			return err
+		}
+		column := sqlColumn{name: p.text(start, p.pos), expr: expr}
+		if field, ok := expr.(fieldNode); ok {
+			column.name = field.path
+		}
+
+		alias := p.acceptKeyword("AS")
+		if token := p.peek(); token.kind == "ident" && (alias || !p.reserved()) {
+			column.name = token.text
+			p.pos++
+		} else if alias {
+			return errors.New("expected a column alias after AS")
+		}
+		p.query.columns = append(p.query.columns, column)
+
+		if !p.acceptOp(",") {
+			return nil
+		}
+	}
+}
+
+func (p *sqlParser) reserved() bool {
+	for _, keyword := range sqlReserved {
+		if p.isKeyword(keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseOrderBy parses the ORDER BY terms; a term naming an output column by alias
+// or 1-based position orders by that column. Positions after SELECT * are checked
+// by RunSQL once the columns are known.
+func (p *sqlParser) parseOrderBy() error {
+	for {
+		order := sqlOrder{column: -1}
+		token := p.peek()
+		expr, err := p.parseAdditive()
+		if err != nil {
+			return err
+		}
+		switch node := expr.(type) {
+		case literalNode:
+			position, ok := toFloat64(node.value)
+			if !ok || position < 1 || position != float64(int(position)) || (!p.query.star && int(position) > len(p.query.columns)) {
+				return fmt.Errorf("ORDER BY position %s is out of range", token.text)
+			}
+			order.column = int(position) - 1
+		case fieldNode:
+			for i, column := range p.query.columns {
+				if column.name == node.path {
+					order.column = i
+					break
+				}
+			}
+		}
+		order.expr = expr
+
+		if p.acceptKeyword("DESC") {
+			order.desc = true
+		} else {
+			p.acceptKeyword("ASC")
+		}
+		p.query.orderBy = append(p.query.orderBy, order)
+		if !p.acceptOp(",") {
+			return nil
+		}
+	}
+}
+
+func (p *sqlParser) parseCount(clause string) (int, error) {
+	token := p.peek()
+	count, ok := toFloat64(token.value)
+	if token.kind != "number" || !ok || count < 0 || count != float64(int(count)) {
+		return 0, fmt.Errorf("%s expects a non-negative integer", clause)
+	}
+	p.pos++
+	return int(count), nil
+}
+
+func (p *sqlParser) parseOr() (exprNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.acceptKeyword("OR") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryNode{op: "||", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *sqlParser) parseAnd() (exprNode, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.acceptKeyword("AND") {
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryNode{op: "&&", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *sqlParser) parseNot() (exprNode, error) {
+	if p.acceptKeyword("NOT") {
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return unaryNode{op: "!", operand: operand}, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *sqlParser) parseComparison() (exprNode, error) {
+	left, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.acceptKeyword("IS") {
+		negate := p.acceptKeyword("NOT")
+		if !p.acceptKeyword("NULL") {
+			return nil, errors.New("expected NULL after IS")
+		}
+		return isNullNode{operand: left, negate: negate}, nil
+	}
+
+	negate := p.acceptKeyword("NOT")
+	switch {
+	case p.acceptKeyword("LIKE"):
+		token := p.peek()
+		if token.kind != "string" {
+			return nil, errors.New("LIKE expects a quoted pattern")
+		}
+		p.pos++
+		return likeNode{operand: left, pattern: likePattern(token.text), negate: negate}, nil
+
+	case p.acceptKeyword("IN"):
+		if !p.acceptOp("(") {
+			return nil, errors.New("expected ( after IN")
+		}
+		node := inNode{operand: left, negate: negate}
+		for {
+			value, err := p.parseAdditive()
+			if err != nil {
+				return nil, err
+			}
+			node.values = append(node.values, value)
+			if !p.acceptOp(",") {
+				break
+			}
+		}
+		if !p.acceptOp(")") {
+			return nil, errors.New("missing closing parenthesis")
+		}
+		return node, nil
+	}
+	if negate {
+		return nil, errors.New("expected LIKE or IN after NOT")
+	}
+
+	for _, op := range []string{"=", "==", "<>", "!=", "<=", ">=", "<", ">"} {
+		if p.acceptOp(op) {
+			right, err := p.parseAdditive()
+			if err != nil {
+				return nil, err
+			}
+			switch op {
+			case "=":
+				op = "=="
+			case "<>":
+				op = "!="
+			}
+			return binaryNode{op: op, left: left, right: right}, nil
+		}
+	}
+	return left, nil
+}
+
+func (p *sqlParser) parseAdditive() (exprNode, error) {
+	left, err := p.parseMultiplicative()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op := p.peek().text
+		if !p.acceptOp("+", "-") {
+			return left, nil
+		}
+		right, err := p.parseMultiplicative()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryNode{op: op, left: left, right: right}
+	}
+}
+
+func (p *sqlParser) parseMultiplicative() (exprNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op := p.peek().text
+		if !p.acceptOp("*", "/", "%") {
+			return left, nil
+		}
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryNode{op: op, left: left, right: right}
+	}
+}
+
+func (p *sqlParser) parseUnary() (exprNode, error) {
+	if p.acceptOp("-") {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return unaryNode{op: "-", operand: operand}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *sqlParser) parsePrimary() (exprNode, error) {
+	token := p.peek()
+	switch token.kind {
+	case "number", "string":
+		p.pos++
+		return literalNode{value: token.value}, nil
+
+	case "ident":
+		if token.value == nil {
+			switch strings.ToUpper(token.text) {
+			case "TRUE":
+				p.pos++
+				return literalNode{value: true}, nil
+			case "FALSE":
+				p.pos++
+				return literalNode{value: false}, nil
+			case "NULL":
+				p.pos++
+				return literalNode{value: nil}, nil
+			}
+			if p.tokens[p.pos+1].kind == "op" && p.tokens[p.pos+1].text == "(" {
+				return p.parseAggregate()
+			}
+			if p.reserved() {
+				return nil, fmt.Errorf("unexpected %s", strings.ToUpper(token.text))
+			}
+		}
+		p.pos++
+		return fieldNode{path: token.text}, nil
+
+	case "op":
+		if p.acceptOp("(") {
+			node, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			if !p.acceptOp(")") {
+				return nil, errors.New("missing closing parenthesis")
+			}
+			return node, nil
+		}
+	}
+
+	if token.kind == "eof" {
+		return nil, errors.New("unexpected end of query")
+	}
+	return nil, fmt.Errorf("unexpected %q", token.text)
+}
+
+// parseAggregate parses COUNT(*), COUNT([DISTINCT] expr), SUM, AVG, MIN or MAX
+func (p *sqlParser) parseAggregate() (exprNode, error) {
+	name := strings.ToLower(p.peek().text)
+	switch name {
+	case "count", "sum", "avg", "min", "max":
+	default:
+		return nil, fmt.Errorf("unknown function %s", p.peek().text)
+	}
+	p.pos += 2 // name (
+
+	node := &aggregateNode{fn: name, key: fmt.Sprintf("\x00agg%d", len(p.query.aggregates))}
+	if name == "count" && p.acceptOp("*") {
+		// COUNT(*) counts rows; arg stays nil
+	} else {
+		node.distinct = p.acceptKeyword("DISTINCT")
+		arg, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		node.arg = arg
+	}
+	if !p.acceptOp(")") {
+		return nil, errors.New("missing closing parenthesis")
+	}
+	p.query.aggregates = append(p.query.aggregates, node)
+	return node, nil
+}
+
+// aggregateState accumulates one aggregate over the records of a group
+type aggregateState struct {
+	count int
+	sum   float64
+	best  interface{}
+	seen  map[string]struct{}
+}
+
+func (s *aggregateState) add(node *aggregateNode, content map[string]interface{}) {
+	if node.arg == nil {
+		s.count++
+		return
+	}
+	value, err := node.arg.eval(content)
+	if err != nil || value == nil {
+		return
+	}
+	if node.distinct {
+		key := sqlValueKey(value)
+		if _, exists := s.seen[key]; exists {
+			return
+		}
+		if s.seen == nil {
+			s.seen = make(map[string]struct{})
+		}
+		s.seen[key] = struct{}{}
+	}
+
+	switch node.fn {
+	case "sum", "avg":
+		number, ok := toFloat64(value)
+		if !ok {
+			return
+		}
+		s.sum += number
+	case "min", "max":
+		if s.best == nil {
+			s.best = value
+			break
+		}
+		if cmp, ok := exprCompare(value, s.best); ok && ((node.fn == "min" && cmp < 0) || (node.fn == "max" && cmp > 0)) {
+			s.best = value
+		}
+	}
+	s.count++
+}
+
+func (s *aggregateState) result(fn string) interface{} {
+	switch fn {
+	case "count":
+		return s.count
+	case "sum":
+		if s.count == 0 {
+			return nil
+		}
+		return s.sum
+	case "avg":
+		if s.count == 0 {
+			return nil
+		}
+		return s.sum / float64(s.count)
+	}
+	return s.best
+}
+
+// sqlGroup collects the records sharing the GROUP BY values
+type sqlGroup struct {
+	row        map[string]interface{} // first record's fields, plus the aggregates once computed
+	aggregates []aggregateState
+}
+
+// RunSQL runs a SELECT statement over the loaded records, queried as the records
+// table with their fields as columns, e.g.
+// `SELECT status, count(*) FROM records WHERE latency_ms > 500 GROUP BY status ORDER BY 2 DESC`.
+// SELECT DISTINCT, WHERE, GROUP BY, HAVING, ORDER BY and LIMIT/OFFSET are
+// supported, with the COUNT, SUM, AVG, MIN and MAX aggregates, LIKE, IN and
+// IS [NOT] NULL. Grouped queries may only select GROUP BY expressions and aggregates.
+// In analytics mode the query runs in DuckDB instead, with its full SELECT dialect.
+func (a *App) RunSQL(query string) (*SQLResult, error) {
+	a.stateMu.RLock()
+	defer a.stateMu.RUnlock()
+
+	if a.currentFile == nil || a.cache == nil {
+		return nil, &JSONLError{
+			Message: "No file currently loaded",
+			Err:     ErrNoFileLoaded,
+		}
+	}
+
+	if store := a.activeAnalytics(); store != nil && store.typed {
+		result, err := store.runSQL(query)
+		if err != nil {
+			return nil, &JSONLError{
+				Message: fmt.Sprintf("Invalid SQL: %v", err),
+				Err:     ErrInvalidSQL,
+			}
+		}
+		return result, nil
+	}
+
+	parsed, err := parseSQL(query)
+	if err != nil {
+		return nil, &JSONLError{
+			Message: fmt.Sprintf("Invalid SQL: %v", err),
+			Err:     ErrInvalidSQL,
+		}
+	}
+
+	start := time.Now()
+	result := &SQLResult{Columns: []string{}, Rows: [][]interface{}{}}
+	var rows []map[string]interface{}
+	var groups []*sqlGroup
+	groupIndex := make(map[string]*sqlGroup)
+
+	readErr := a.cache.forEach(func(record JSONRecord) bool {
+		result.Scanned++
+		content := record.Content
+		if content == nil {
+			content = map[string]interface{}{}
+		}
+		if parsed.where != nil {
+			if matched, err := parsed.where.eval(content); err != nil || !truthy(matched) {
+				return true
+			}
+		}
+
+		if !parsed.grouped() {
+			rows = append(rows, content)
+			return true
+		}
+
+		var key strings.Builder
+		for _, expr := range parsed.groupBy {
+			value, _ := expr.eval(content)
+			key.WriteString(sqlValueKey(value) + "\x00")
+		}
+		group, exists := groupIndex[key.String()]
+		if !exists {
+			group = &sqlGroup{row: content, aggregates: make([]aggregateState, len(parsed.aggregates))}
+			groupIndex[key.String()] = group
+			groups = append(groups, group)
+		}
+		for i, node := range parsed.aggregates {
+			group.aggregates[i].add(node, content)
+		}
+		return true
+	})
+	if readErr != nil {
+		return nil, readErr
+	}
+
+	if parsed.grouped() {
+		// Aggregates without GROUP BY summarize all records as one group
+		if len(groups) == 0 && len(parsed.groupBy) == 0 {
+			groups = append(groups, &sqlGroup{row: map[string]interface{}{}, aggregates: make([]aggregateState, len(parsed.aggregates))})
+		}
+		for _, group := range groups {
+			row := make(map[string]interface{}, len(group.row)+len(parsed.aggregates))
+			for field, value := range group.row {
+				row[field] = value
+			}
+			for i, node := range parsed.aggregates {
+				row[node.key] = group.aggregates[i].result(node.fn)
+			}
+			// HAVING may refer to computed columns by alias
+			for _, column := range parsed.columns {
+				if _, isField := column.expr.(fieldNode); !isField {
+					if _, exists := row[column.name]; !exists {
+						row[column.name], _ = column.expr.eval(row)
+					}
+				}
+			}
+			if parsed.having != nil {
+				if matched, err := parsed.having.eval(row); err != nil || !truthy(matched) {
+					continue
+				}
+			}
+			rows = append(rows, row)
+		}
+	}
+
+	columns := parsed.columns
+	if parsed.star {
+		columns = starColumns(rows)
+		for _, order := range parsed.orderBy {
+			if _, positional := order.expr.(literalNode); positional && len(rows) > 0 && order.column >= len(columns) {
+				return nil, &JSONLError{
+					Message: fmt.Sprintf("Invalid SQL: ORDER BY position %d is out of range", order.column+1),
+					Err:     ErrInvalidSQL,
+				}
+			}
+		}
+	}
+	for _, column := range columns {
+		result.Columns = append(result.Columns, column.name)
+	}
+
+	type outputRow struct {
+		values []interface{}
+		keys   []interface{}
+	}
+	output := make([]outputRow, len(rows))
+	for i, row := range rows {
+		values := make([]interface{}, len(columns))
+		for j, column := range columns {
+			values[j], _ = column.expr.eval(row)
+		}
+		keys := make([]interface{}, len(parsed.orderBy))
+		for j, order := range parsed.orderBy {
+			if order.column >= 0 && order.column < len(values) {
+				keys[j] = values[order.column]
+			} else {
+				keys[j], _ = order.expr.eval(row)
+			}
+		}
+		output[i] = outputRow{values: values, keys: keys}
+	}
+
+	if parsed.distinct {
+		seen := make(map[string]bool, len(output))
+		unique := output[:0]
+		for _, row := range output {
+			var key strings.Builder
+			for _, value := range row.values {
+				key.WriteString(sqlValueKey(value) + "\x00")
+			}
+			if !seen[key.String()] {
+				seen[key.String()] = true
+				unique = append(unique, row)
+			}
+		}
+		output = unique
+	}
+
+	if len(parsed.orderBy) > 0 {
+		sort.SliceStable(output, func(i, j int) bool {
+			for k, order := range parsed.orderBy {
+				cmp := compareSQLValues(output[i].keys[k], output[j].keys[k])
+				if cmp == 0 {
+					continue
+				}
+				if order.desc {
+					return cmp > 0
+				}
+				return cmp < 0
+			}
+			return false
+		})
+	}
+
+	from := parsed.offset
+	if from > len(output) {
+		from = len(output)
+	}
+	to := len(output)
+	if parsed.limit >= 0 && from+parsed.limit < to {
+		to = from + parsed.limit
+	}
+	if to-from > maxSQLRows {
+		to = from + maxSQLRows
+		result.Truncated = true
+	}
+	for _, row := range output[from:to] {
+		result.Rows = append(result.Rows, row.values)
+	}
+
+	result.DurationMs = time.Since(start).Milliseconds()
+	return result, nil
+}
+
+// sqlValueKey identifies a value for grouping and DISTINCT, so 200 and "200" differ
+func sqlValueKey(value interface{}) string {
+	return jsonTypeOf(value) + ":" + valueToString(value)
+}
+
+// starColumns lists the top-level fields of the rows in order of first appearance
+func starColumns(rows []map[string]interface{}) []sqlColumn {
+	var columns []sqlColumn
+	seen := make(map[string]bool)
+	for _, row := range rows {
+		for _, field := range sortedKeys(row) {
+			if !seen[field] {
+				seen[field] = true
+				columns = append(columns, sqlColumn{name: field, expr: fieldNode{path: field}})
+			}
+		}
+	}
+	return columns
+}
+
+// compareSQLValues orders values for ORDER BY: nulls first, then numbers,
+// timestamps and strings as exprCompare does, then by text
+func compareSQLValues(left, right interface{}) int {
+	switch {
+	case left == nil && right == nil:
+		return 0
+	case left == nil:
+		return -1
+	case right == nil:
+		return 1
+	}
+	if cmp, ok := exprCompare(left, right); ok {
+		return cmp
+	}
+	return strings.Compare(valueToString(left), valueToString(right))
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+const sqlTestRecords = `{"status":200,"path":"/a","latency_ms":120,"user":{"country":"DE"}}
+{"status":500,"path":"/b","latency_ms":900,"user":{"country":"US"}}
+{"status":200,"path":"/c","latency_ms":650,"user":{"country":"US"}}
+{"status":404,"path":"/a","latency_ms":30}
+{"status":500,"path":"/a","latency_ms":1500,"user":{"country":"DE"}}
+`
+
+func TestRunSQL(t *testing.T) {
+	app := newTestApp(t, sqlTestRecords)
+
+	tests := []struct {
+		query   string
+		columns string
+		rows    string
+	}{
+		{
+			"SELECT status, count(*) FROM records GROUP BY status",
+			"[status count(*)]", "[[200 2] [500 2] [404 1]]",
+		},
+		{
+			"select status, count(*) as n, avg(latency_ms) from records group by status order by n desc, status limit 2",
+			"[status n avg(latency_ms)]", "[[200 2 385] [500 2 1200]]",
+		},
+		{
+			"SELECT path, latency_ms FROM records WHERE latency_ms > 500 AND status <> 404 ORDER BY latency_ms DESC",
+			"[path latency_ms]", "[[/a 1500] [/b 900] [/c 650]]",
+		},
+		{
+			"SELECT user.country AS country, max(latency_ms), min(path) FROM records WHERE user.country IS NOT NULL GROUP BY country ORDER BY 1",
+			"[country max(latency_ms) min(path)]", "[[DE 1500 /a] [US 900 /b]]",
+		},
+		{
+			"SELECT count(*), count(user.country), count(DISTINCT path), sum(latency_ms) FROM records",
+			"[count(*) count(user.country) count(DISTINCT path) sum(latency_ms)]", "[[5 4 3 3200]]",
+		},
+		{
+			"SELECT path FROM records WHERE path LIKE '/_' AND status IN (404, 500) ORDER BY latency_ms LIMIT 2 OFFSET 1",
+			"[path]", "[[/b] [/a]]",
+		},
+		{
+			"SELECT path, count(*) AS hits FROM records GROUP BY path HAVING hits > 1",
+			"[path hits]", "[[/a 3]]",
+		},
+		{
+			`SELECT "status", latency_ms / 1000 seconds FROM records WHERE NOT status = 200 AND user.country IS NULL;`,
+			"[status seconds]", "[[404 0.03]]",
+		},
+		{
+			"SELECT * FROM records WHERE status = 404",
+			"[latency_ms path status]", "[[30 /a 404]]",
+		},
+		{
+			"SELECT DISTINCT path FROM records ORDER BY path",
+			"[path]", "[[/a] [/b] [/c]]",
+		},
+		{
+			"SELECT DISTINCT status, user.country IS NULL AS anonymous FROM records ORDER BY status DESC LIMIT 3",
+			"[status anonymous]", "[[500 false] [404 true] [200 false]]",
+		},
+		{
+			"SELECT status, count(*) * 2 AS double FROM records GROUP BY status ORDER BY count(*), status",
+			"[status double]", "[[404 2] [200 4] [500 4]]",
+		},
+		{
+			"SELECT * FROM records WHERE status = 500 ORDER BY 1 DESC",
+			"[latency_ms path status user]", "[[1500 /a 500 map[country:DE]] [900 /b 500 map[country:US]]]",
+		},
+	}
+	for _, tt := range tests {
+		result, err := app.RunSQL(tt.query)
+		if err != nil {
+			t.Errorf("RunSQL(%q) returned error: %v", tt.query, err)
+			continue
+		}
+		if got := fmt.Sprint(result.Columns); got != tt.columns {
+			t.Errorf("%s: columns %s, expected %s", tt.query, got, tt.columns)
+		}
+		if got := fmt.Sprint(result.Rows); got != tt.rows {
+			t.Errorf("%s: rows %s, expected %s", tt.query, got, tt.rows)
+		}
+		if result.Scanned != 5 {
+			t.Errorf("%s: expected 5 scanned records, got %d", tt.query, result.Scanned)
+		}
+	}
+}
+
+func TestRunSQLErrors(t *testing.T) {
+	app := newTestApp(t, sqlTestRecords)
+
+	for _, query := range []string{
+		"",
+		"DELETE FROM records",
+		"SELECT status FROM logs",
+		"SELECT status records",
+		"SELECT status FROM records WHERE count(*) > 1",
+		"SELECT * FROM records GROUP BY status",
+		"SELECT median(latency_ms) FROM records",
+		"SELECT status FROM records ORDER BY 3",
+		"SELECT * FROM records ORDER BY 9",
+		"SELECT status FROM records ORDER BY count(*)",
+		"SELECT status FROM records LIMIT -1",
+		"SELECT status FROM records WHERE path LIKE",
+		"SELECT status FROM records WHERE path = 'open",
+		"SELECT status, path, count(*) FROM records GROUP BY status",
+		"SELECT path, count(*) FROM records",
+		"SELECT status FROM records GROUP BY status ORDER BY latency_ms",
+		"SELECT DISTINCT status FROM records ORDER BY latency_ms",
+		"SELECT DISTINCT FROM records",
+	} {
+		_, err := app.RunSQL(query)
+		var jsonlErr *JSONLError
+		if !errors.As(err, &jsonlErr) || !errors.Is(jsonlErr.Err, ErrInvalidSQL) {
+			t.Errorf("Expected RunSQL(%q) to fail with ErrInvalidSQL, got %v", query, err)
+		}
+	}
+
+	maxSQLRows = 2
+	defer func() { maxSQLRows = 10000 }()
+	result, err := app.RunSQL("SELECT path FROM records")
+	if err != nil || len(result.Rows) != 2 || !result.Truncated {
+		t.Errorf("Expected 2 truncated rows, got %+v (%v)", result, err)
+	}
+}
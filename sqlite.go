@@ -0,0 +1,391 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// FormatSQLite marks files loaded from a SQLite table, see LoadFromSQLite
+const FormatSQLite = "sqlite"
+
+// ErrInvalidTable is returned for SQLite tables that are missing or cannot be used
+var ErrInvalidTable = errors.New("invalid SQLite table")
+
+// Column types written by ExportToSQLite. Values of JSON columns, used for objects,
+// arrays and fields mixing types, are stored as JSON text so they load back as they were.
+const (
+	sqliteInteger = "INTEGER"
+	sqliteReal    = "REAL"
+	sqliteBoolean = "BOOLEAN"
+	sqliteText    = "TEXT"
+	sqliteJSON    = "JSON"
+)
+
+// SQLiteColumn is a column of an exported table with its inferred type
+type SQLiteColumn struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// SQLiteExport summarizes an ExportToSQLite run
+type SQLiteExport struct {
+	Path    string         `json:"path"`
+	Table   string         `json:"table"`
+	Columns []SQLiteColumn `json:"columns"`
+	Rows    int            `json:"rows"`
+}
+
+// quoteSQLiteIdentifier quotes a table or column name
+func quoteSQLiteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// sqliteValueType returns the column type a single non-null value needs
+func sqliteValueType(value interface{}) string {
+	switch v := value.(type) {
+	case bool:
+		return sqliteBoolean
+	case string:
+		return sqliteText
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < 1<<53 {
+			return sqliteInteger
+		}
+		return sqliteReal
+	case json.Number:
+		if _, err := v.Int64(); err == nil {
+			return sqliteInteger
+		}
+		return sqliteReal
+	}
+	return sqliteJSON
+}
+
+// mergeSQLiteTypes widens a column type to hold values of both types: integers
+// widen to reals, anything else mixed becomes JSON
+func mergeSQLiteTypes(current, next string) string {
+	switch {
+	case current == "" || current == next:
+		return next
+	case (current == sqliteInteger && next == sqliteReal) || (current == sqliteReal && next == sqliteInteger):
+		return sqliteReal
+	}
+	return sqliteJSON
+}
+
+// inferSQLiteColumns returns the top-level fields of records in order of first
+// appearance with the narrowest type holding all their values. Fields that are
+// always null are stored as text.
+func inferSQLiteColumns(records []JSONRecord) []SQLiteColumn {
+	var columns []SQLiteColumn
+	index := make(map[string]int)
+	for _, record := range records {
+		for _, field := range sortedKeys(record.Content) {
+			i, exists := index[field]
+			if !exists {
+				i = len(columns)
+				index[field] = i
+				columns = append(columns, SQLiteColumn{Name: field})
+			}
+			if value := record.Content[field]; value != nil {
+				columns[i].Type = mergeSQLiteTypes(columns[i].Type, sqliteValueType(value))
+			}
+		}
+	}
+	for i := range columns {
+		if columns[i].Type == "" {
+			columns[i].Type = sqliteText
+		}
+	}
+	return columns
+}
+
+// sqliteValue converts a field value for storage in a column of the given type
+func sqliteValue(value interface{}, columnType string) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+	switch columnType {
+	case sqliteInteger:
+		if number, ok := value.(json.Number); ok {
+			return number.Int64()
+		}
+		return int64(value.(float64)), nil
+	case sqliteReal:
+		number, _ := toFloat64(value)
+		return number, nil
+	case sqliteBoolean, sqliteText:
+		return value, nil
+	}
+	raw, err := json.Marshal(value)
+	return string(raw), err
+}
+
+// ExportToSQLite writes the current records, filtered by the active search, into
+// tableName (default "records") of the SQLite database at path, creating the file
+// if needed and replacing an existing table of that name. Each top-level field
+// becomes a column typed from its values; LoadFromSQLite reads the table back.
+func (a *App) ExportToSQLite(path, tableName string) (*SQLiteExport, error) {
+	a.stateMu.RLock()
+	defer a.stateMu.RUnlock()
+
+	if a.currentFile == nil || a.cache == nil {
+		return nil, &JSONLError{
+			Message: "No file currently loaded",
+			Err:     ErrNoFileLoaded,
+		}
+	}
+	if tableName = strings.TrimSpace(tableName); tableName == "" {
+		tableName = sqlTable
+	}
+	if path == "" {
+		var err error
+		if path, err = a.exportFilePath("jsonl-viewer-export", "sqlite"); err != nil {
+			return nil, err
+		}
+	}
+
+	search := a.currentSearch()
+	var records []JSONRecord
+	err := a.forEachScoped(&search, func(record JSONRecord) {
+		if record.Content != nil {
+			records = append(records, record)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	columns := inferSQLiteColumns(records)
+	if len(columns) == 0 {
+		return nil, &JSONLError{
+			Message: "No object records to export",
+			Err:     ErrInvalidTable,
+		}
+	}
+
+	if err := writeSQLiteTable(path, tableName, columns, records); err != nil {
+		return nil, &JSONLError{
+			Message: fmt.Sprintf("Failed to write SQLite table %s", tableName),
+			Err:     err,
+		}
+	}
+
+	a.audit(AuditEntry{Action: AuditExportSaved, Path: a.auditPath(), Query: search.Query, Records: len(records), Destination: path, Detail: "sqlite"})
+	return &SQLiteExport{Path: path, Table: tableName, Columns: columns, Rows: len(records)}, nil
+}
+
+// writeSQLiteTable replaces the table with the records in a single transaction
+func writeSQLiteTable(path, tableName string, columns []SQLiteColumn, records []JSONRecord) error {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	table := quoteSQLiteIdentifier(tableName)
+	definitions := make([]string, len(columns))
+	placeholders := make([]string, len(columns))
+	for i, column := range columns {
+		definitions[i] = quoteSQLiteIdentifier(column.Name) + " " + column.Type
+		placeholders[i] = "?"
+	}
+	if _, err := tx.Exec("DROP TABLE IF EXISTS " + table); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(fmt.Sprintf("CREATE TABLE %s (%s)", table, strings.Join(definitions, ", "))); err != nil {
+		return err
+	}
+
+	stmt, err := tx.Prepare(fmt.Sprintf("INSERT INTO %s VALUES (%s)", table, strings.Join(placeholders, ", ")))
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	values := make([]interface{}, len(columns))
+	for _, record := range records {
+		for i, column := range columns {
+			if values[i], err = sqliteValue(record.Content[column.Name], column.Type); err != nil {
+				return err
+			}
+		}
+		if _, err := stmt.Exec(values...); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// recordFromSQLiteRow converts a row into a record, decoding each value according
+// to its column's declared type. NULL columns are left out of the record.
+func recordFromSQLiteRow(lineNumber int, names, types []string, values []interface{}) (JSONRecord, error) {
+	content := make(map[string]interface{}, len(names))
+	var raw strings.Builder
+	raw.WriteByte('{')
+	for i, value := range values {
+		if value == nil {
+			continue
+		}
+
+		var field interface{}
+		switch v := value.(type) {
+		case int64:
+			if types[i] == sqliteBoolean {
+				field = v != 0
+			} else {
+				field = json.Number(strconv.FormatInt(v, 10))
+			}
+		case float64:
+			field = json.Number(strconv.FormatFloat(v, 'g', -1, 64))
+		case []byte:
+			field = string(v)
+		case string:
+			field = v
+		default:
+			field = fmt.Sprintf("%v", v)
+		}
+		if text, ok := field.(string); ok && types[i] == sqliteJSON {
+			object, value, err := parseRecordLine(text)
+			if err != nil {
+				return JSONRecord{}, fmt.Errorf("column %s: %w", names[i], err)
+			}
+			field = value
+			if object != nil {
+				field = object
+			}
+		}
+		content[names[i]] = field
+
+		name, _ := json.Marshal(names[i])
+		encoded, err := json.Marshal(field)
+		if err != nil {
+			return JSONRecord{}, err
+		}
+		if raw.Len() > 1 {
+			raw.WriteByte(',')
+		}
+		raw.Write(name)
+		raw.WriteByte(':')
+		raw.Write(encoded)
+	}
+	raw.WriteByte('}')
+	return JSONRecord{LineNumber: lineNumber, Content: content, RawJSON: raw.String()}, nil
+}
+
+// LoadFromSQLite loads the rows of tableName (default "records") from the SQLite
+// database at path as records, one per row in rowid order, with columns as fields
+func (a *App) LoadFromSQLite(path, tableName string) (*JSONLFile, error) {
+	fileInfo, err := os.Stat(path)
+	if err != nil || fileInfo.IsDir() {
+		return nil, &JSONLError{
+			Message: "File not found or cannot be accessed",
+			Err:     ErrFileNotFound,
+		}
+	}
+	if tableName = strings.TrimSpace(tableName); tableName == "" {
+		tableName = sqlTable
+	}
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, &JSONLError{
+			Message: "Failed to open SQLite database",
+			Err:     err,
+		}
+	}
+	defer db.Close()
+
+	rows, err := db.Query("SELECT * FROM " + quoteSQLiteIdentifier(tableName))
+	if err != nil {
+		return nil, &JSONLError{
+			Message: fmt.Sprintf("Cannot read table %s: %v", tableName, err),
+			Err:     ErrInvalidTable,
+		}
+	}
+	defer rows.Close()
+
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, &JSONLError{
+			Message: fmt.Sprintf("Cannot read table %s: %v", tableName, err),
+			Err:     ErrInvalidTable,
+		}
+	}
+	names := make([]string, len(columnTypes))
+	types := make([]string, len(columnTypes))
+	for i, columnType := range columnTypes {
+		names[i] = columnType.Name()
+		types[i] = strings.ToUpper(columnType.DatabaseTypeName())
+	}
+
+	var records []JSONRecord
+	fieldCounts := make(map[string]int)
+	values := make([]interface{}, len(names))
+	pointers := make([]interface{}, len(names))
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(pointers...); err != nil {
+			return nil, &JSONLError{
+				Message: "Failed to read SQLite row",
+				Err:     err,
+			}
+		}
+		record, err := recordFromSQLiteRow(len(records)+1, names, types, values)
+		if err != nil {
+			return nil, &JSONLError{
+				Message:    fmt.Sprintf("Invalid value in row %d: %v", len(records)+1, err),
+				LineNumber: len(records) + 1,
+				Err:        ErrParsingFailed,
+			}
+		}
+		for field := range record.Content {
+			fieldCounts[field]++
+		}
+		records = append(records, record)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, &JSONLError{
+			Message: "Failed to read SQLite rows",
+			Err:     err,
+		}
+	}
+
+	stats := &FileStats{
+		TotalLines:   len(records),
+		ValidRecords: len(records),
+		InvalidLines: []int{},
+		CommonFields: selectCommonFields(fieldCounts, len(records), CommonFieldOptions{}),
+		FileSize:     fileInfo.Size(),
+		RecordSizes:  measureRecordSizes(recordSizes(records)),
+	}
+	jsonlFile := &JSONLFile{
+		Name:       fmt.Sprintf("%s (%s)", tableName, filepath.Base(path)),
+		Path:       path,
+		Size:       fileInfo.Size(),
+		Records:    len(records),
+		LoadedAt:   time.Now(),
+		ModifiedAt: fileInfo.ModTime(),
+		Format:     FormatSQLite,
+		Table:      tableName,
+	}
+	a.storeFile(jsonlFile, records, stats, nil)
+	return jsonlFile, nil
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+)
+
+func TestExportToSQLiteRoundTrip(t *testing.T) {
+	useTempConfigDir(t)
+	app := newTestApp(t, `{"id":1,"name":"a","score":1.5,"ok":true,"tags":["x"],"meta":{"k":"v"}}
+{"id":2,"name":"b","score":2,"ok":false,"mixed":"text"}
+[1,2,3]
+{"id":3,"score":null,"mixed":7}
+`)
+	path := filepath.Join(t.TempDir(), "export.db")
+
+	export, err := app.ExportToSQLite(path, "")
+	if err != nil {
+		t.Fatalf("ExportToSQLite returned error: %v", err)
+	}
+	if export.Table != "records" || export.Rows != 3 {
+		t.Errorf("Unexpected export summary: %+v", export)
+	}
+	expected := "[{id INTEGER} {meta JSON} {name TEXT} {ok BOOLEAN} {score REAL} {tags JSON} {mixed JSON}]"
+	if got := fmt.Sprint(export.Columns); got != expected {
+		t.Errorf("Expected columns %s, got %s", expected, got)
+	}
+
+	loaded := &App{}
+	file, err := loaded.LoadFromSQLite(path, "records")
+	if err != nil {
+		t.Fatalf("LoadFromSQLite returned error: %v", err)
+	}
+	if file.Records != 3 || file.Format != FormatSQLite || file.Table != "records" {
+		t.Errorf("Unexpected loaded file: %+v", file)
+	}
+
+	first := loaded.records[0]
+	if first.RawJSON != `{"id":1,"meta":{"k":"v"},"name":"a","ok":true,"score":1.5,"tags":["x"]}` {
+		t.Errorf("Unexpected first record: %s", first.RawJSON)
+	}
+	if first.Content["id"] != json.Number("1") || first.Content["ok"] != true {
+		t.Errorf("Expected typed values, got %+v", first.Content)
+	}
+	if loaded.records[1].Content["mixed"] != "text" || loaded.records[2].Content["mixed"] != json.Number("7") {
+		t.Errorf("Expected mixed values to keep their types, got %+v / %+v", loaded.records[1].Content, loaded.records[2].Content)
+	}
+	if _, exists := loaded.records[2].Content["score"]; exists || loaded.records[2].LineNumber != 3 {
+		t.Errorf("Expected null score to be left out of line 3, got %+v", loaded.records[2])
+	}
+
+	// The active search filters the export, which replaces the table
+	if _, err := app.SearchRecords(SearchOptions{Query: "id:2", UseLucene: true}); err != nil {
+		t.Fatal(err)
+	}
+	if export, err := app.ExportToSQLite(path, "records"); err != nil || export.Rows != 1 {
+		t.Fatalf("Expected a filtered export of 1 row, got %+v (%v)", export, err)
+	}
+	if file, err := loaded.LoadFromSQLite(path, ""); err != nil || file.Records != 1 {
+		t.Errorf("Expected the replaced table to hold 1 row, got %+v (%v)", file, err)
+	}
+}
+
+func TestLoadFromSQLiteErrors(t *testing.T) {
+	useTempConfigDir(t)
+	app := newTestApp(t, "{\"a\":1}\n")
+	path := filepath.Join(t.TempDir(), "export.db")
+	if _, err := app.ExportToSQLite(path, `odd "name"`); err != nil {
+		t.Fatalf("ExportToSQLite returned error: %v", err)
+	}
+
+	loaded := &App{}
+	if _, err := loaded.LoadFromSQLite(path, `odd "name"`); err != nil {
+		t.Errorf("Expected quoted table name to load, got %v", err)
+	}
+
+	_, err := loaded.LoadFromSQLite(path, "missing")
+	var jsonlErr *JSONLError
+	if !errors.As(err, &jsonlErr) || !errors.Is(jsonlErr.Err, ErrInvalidTable) {
+		t.Errorf("Expected ErrInvalidTable, got %v", err)
+	}
+	if _, err := loaded.LoadFromSQLite(filepath.Join(t.TempDir(), "none.db"), ""); !errors.As(err, &jsonlErr) || !errors.Is(jsonlErr.Err, ErrFileNotFound) {
+		t.Errorf("Expected ErrFileNotFound, got %v", err)
+	}
+}
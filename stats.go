@@ -0,0 +1,175 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// scopedVisitor wraps fn so it only sees the records matching filter when it
+// carries a non-empty query
+func (a *App) scopedVisitor(filter *SearchOptions, fn func(record JSONRecord)) (func(record JSONRecord) bool, error) {
+	matches := func(record JSONRecord) bool { return true }
+	if isFiltered(filter) {
+		var err error
+		if matches, err = a.searchPredicate(*filter); err != nil {
+			return nil, err
+		}
+	}
+	return func(record JSONRecord) bool {
+		if matches(record) {
+			fn(record)
+		}
+		return true
+	}, nil
+}
+
+// forEachScoped calls fn for each record statistics should be computed over: every
+// loaded record, or only those matching filter when it carries a non-empty query.
+// Disk-backed files are streamed.
+func (a *App) forEachScoped(filter *SearchOptions, fn func(record JSONRecord)) error {
+	visit, err := a.scopedVisitor(filter, fn)
+	if err != nil {
+		return err
+	}
+	return a.cache.forEach(visit)
+}
+
+// forEachInScope calls fn for each record left by the filter stack that matches
+// filter when it carries a query, streaming disk-backed files
+func (a *App) forEachInScope(filter *SearchOptions, fn func(record JSONRecord)) error {
+	visit, err := a.scopedVisitor(filter, fn)
+	if err != nil {
+		return err
+	}
+	scope, scoped, err := a.filterScope()
+	if err != nil {
+		return err
+	}
+	if scoped {
+		for _, record := range scope {
+			visit(record)
+		}
+		return nil
+	}
+	return a.cache.forEach(visit)
+}
+
+// isFiltered reports whether filter restricts statistics to a subset of records
+func isFiltered(filter *SearchOptions) bool {
+	return filter != nil && strings.TrimSpace(filter.Query) != ""
+}
+
+// writeStatsCSV writes a statistics result as CSV. Arrays become one row per element
+// and any other value a single row; nested objects are flattened into dot-path
+// columns and arrays inside rows are written as JSON.
+func writeStatsCSV(w io.Writer, result interface{}) error {
+	raw, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+
+	var elements []json.RawMessage
+	if err := json.Unmarshal(raw, &elements); err != nil {
+		elements = []json.RawMessage{raw}
+	}
+
+	rows := make([]map[string]string, 0, len(elements))
+	columnSet := make(map[string]bool)
+	for _, element := range elements {
+		row, err := csvRow(element)
+		if err != nil {
+			return err
+		}
+		for column := range row {
+			columnSet[column] = true
+		}
+		rows = append(rows, row)
+	}
+
+	columns := make([]string, 0, len(columnSet))
+	for column := range columnSet {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(columns); err != nil {
+		return err
+	}
+	record := make([]string, len(columns))
+	for _, row := range rows {
+		for i, column := range columns {
+			record[i] = row[column]
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// statsExportName reduces a caller-supplied stats name to a safe file name part:
+// its base name with anything but letters, digits, '-', '_' and '.' replaced by
+// '-'. It defaults to "stats".
+func statsExportName(name string) string {
+	name = filepath.Base(strings.TrimSpace(name))
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			return r
+		}
+		return '-'
+	}, name)
+	name = strings.Trim(name, ".-")
+	if name == "" {
+		return "stats"
+	}
+	return name
+}
+
+// ExportStats saves any statistics result (facets, field catalog, quality metrics,
+// histograms...) to the Downloads folder as CSV or JSON and returns the file path.
+// For CSV, pass the table to export, e.g. the facets array of a MatchCount.
+func (a *App) ExportStats(name string, result interface{}, format string) (string, error) {
+	if format != FormatCSV && format != FormatJSON {
+		return "", &JSONLError{
+			Message: fmt.Sprintf("Unsupported stats export format %q", format),
+			Err:     ErrUnsupportedConversion,
+		}
+	}
+
+	name = statsExportName(name)
+	a.stateMu.RLock()
+	filePath, err := a.exportFilePath("jsonl-viewer-"+name, format)
+	a.stateMu.RUnlock()
+	if err != nil {
+		return "", err
+	}
+
+	file, err := os.Create(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to create export file: %w", err)
+	}
+	defer file.Close()
+
+	if format == FormatCSV {
+		err = writeStatsCSV(file, result)
+	} else {
+		encoder := json.NewEncoder(file)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(result)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to write stats export: %w", err)
+	}
+
+	a.audit(AuditEntry{Action: AuditExportSaved, Path: a.auditPath(), Destination: filePath, Detail: "stats " + name})
+	return filePath, nil
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestStatsOverFilteredSubset(t *testing.T) {
+	app := newTestApp(t, `{"level":"info","user":"alice","ms":10}
+{"level":"error","user":"bob","code":500}
+{"level":"error","user":"carol","code":502}
+{"level":"info","user":"alice"}`)
+
+	filter := &SearchOptions{Query: "level:error", UseLucene: true}
+
+	catalog, err := app.GetFieldCatalog(filter)
+	if err != nil {
+		t.Fatalf("GetFieldCatalog returned error: %v", err)
+	}
+	var paths []string
+	for _, info := range catalog {
+		paths = append(paths, info.Path)
+	}
+	if expected := []string{"code", "level", "user"}; !reflect.DeepEqual(paths, expected) {
+		t.Errorf("Expected filtered paths %v, got %v", expected, paths)
+	}
+
+	page, err := app.BrowseFieldValues("user", 0, 10, "", filter)
+	if err != nil {
+		t.Fatalf("BrowseFieldValues returned error: %v", err)
+	}
+	if expected := []ValueCount{{"bob", 1}, {"carol", 1}}; !reflect.DeepEqual(page.Values, expected) {
+		t.Errorf("Expected filtered values %v, got %v", expected, page.Values)
+	}
+
+	// The filtered subset must not leak into the whole-file cache
+	page, _ = app.BrowseFieldValues("user", 0, 10, "", nil)
+	if page.Total != 3 {
+		t.Errorf("Expected 3 distinct users in the whole file, got %d", page.Total)
+	}
+
+	metrics, err := app.GetQualityMetrics(filter)
+	if err != nil {
+		t.Fatalf("GetQualityMetrics returned error: %v", err)
+	}
+	if metrics.ValidRecords != 4 || metrics.AnalyzedRecords != 2 {
+		t.Errorf("Expected 4 valid and 2 analyzed records, got %+v", metrics)
+	}
+	for _, field := range metrics.Fields {
+		if field.Field == "code" && field.Completeness != 1 {
+			t.Errorf("Expected code to be complete within the subset, got %v", field.Completeness)
+		}
+	}
+
+	filters, err := app.GetQuickFilters(5, &SearchOptions{Query: "alice"})
+	if err != nil {
+		t.Fatalf("GetQuickFilters returned error: %v", err)
+	}
+	if len(filters) != 0 {
+		t.Errorf("Expected no quick filters for a single-valued subset, got %+v", filters)
+	}
+}
+
+func TestWriteStatsCSV(t *testing.T) {
+	tests := []struct {
+		name     string
+		result   interface{}
+		expected string
+	}{
+		{
+			"Array of rows",
+			[]ValueCount{{"error", 2}, {"info, debug", 1}},
+			"count,value\n2,error\n1,\"info, debug\"\n",
+		},
+		{
+			"Single object with nested fields",
+			map[string]interface{}{"field": "ms", "stats": map[string]interface{}{"min": 1, "max": 9}, "tags": []string{"a"}},
+			"field,stats.max,stats.min,tags\nms,9,1,\"[\"\"a\"\"]\"\n",
+		},
+		{"Empty array", []ValueCount{}, "\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf strings.Builder
+			if err := writeStatsCSV(&buf, tt.result); err != nil {
+				t.Fatalf("writeStatsCSV returned error: %v", err)
+			}
+			if buf.String() != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, buf.String())
+			}
+		})
+	}
+}
+
+func TestExportStatsName(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"facets", "facets"},
+		{"", "stats"},
+		{"../../etc/passwd", "passwd"},
+		{`..\..\evil`, "evil"},
+		{"..", "stats"},
+		{"level facets: api", "level-facets--api"},
+	}
+	for _, tt := range tests {
+		if got := statsExportName(tt.name); got != tt.expected {
+			t.Errorf("statsExportName(%q) = %q, expected %q", tt.name, got, tt.expected)
+		}
+	}
+
+	app := newTestApp(t, "{\"a\":1}\n")
+	app.settings.ExportDirectory = t.TempDir()
+	path, err := app.ExportStats("../outside", []ValueCount{{"a", 1}}, FormatJSON)
+	if err != nil {
+		t.Fatalf("ExportStats returned error: %v", err)
+	}
+	if filepath.Dir(path) != app.settings.ExportDirectory || !strings.HasPrefix(filepath.Base(path), "jsonl-viewer-outside-") {
+		t.Errorf("Expected the export inside the export directory, got %s", path)
+	}
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Limits for generated synthetic values
+const (
+	maxSyntheticRecords = 1000000
+	maxSyntheticItems   = 10
+	maxSyntheticDepth   = 16
+)
+
+// syntheticWords is the vocabulary used for generated free-text strings
+var syntheticWords = []string{
+	"alpha", "bravo", "charlie", "delta", "echo", "foxtrot", "golf", "hotel",
+	"india", "juliet", "kilo", "lima", "mike", "november", "oscar", "papa",
+}
+
+// pickWeighted chooses a key from counts with probability proportional to its count
+func pickWeighted(rng *rand.Rand, keys []string, counts map[string]int) string {
+	total := 0
+	for _, key := range keys {
+		total += counts[key]
+	}
+	if total == 0 {
+		return keys[0]
+	}
+	target := rng.Intn(total)
+	for _, key := range keys {
+		target -= counts[key]
+		if target < 0 {
+			return key
+		}
+	}
+	return keys[len(keys)-1]
+}
+
+// generate produces a random value shaped like the values observed at this node
+func (n *schemaNode) generate(rng *rand.Rand, depth int) interface{} {
+	types := n.sortedTypes()
+	if len(types) == 0 || depth > maxSyntheticDepth {
+		return nil
+	}
+
+	switch pickWeighted(rng, types, n.types) {
+	case "object":
+		result := make(map[string]interface{})
+		for _, key := range n.propertyOrder {
+			property := n.properties[key]
+			// Include optional properties as often as they appeared
+			if rng.Intn(n.objectCount) < property.count {
+				result[key] = property.generate(rng, depth+1)
+			}
+		}
+		return result
+
+	case "array":
+		maxItems := n.maxItems
+		if maxItems > maxSyntheticItems {
+			maxItems = maxSyntheticItems
+		}
+		minItems := n.minItems
+		if minItems > maxItems {
+			minItems = maxItems
+		}
+		length := minItems + rng.Intn(maxItems-minItems+1)
+		result := make([]interface{}, 0, length)
+		for i := 0; i < length && n.items != nil; i++ {
+			result = append(result, n.items.generate(rng, depth+1))
+		}
+		return result
+
+	case "string":
+		return n.generateString(rng)
+
+	case "number":
+		number := n.minNumber + rng.Float64()*(n.maxNumber-n.minNumber)
+		if n.allIntegers {
+			return float64(int64(number))
+		}
+		return number
+
+	case "boolean":
+		return rng.Intn(2) == 1
+
+	default:
+		return nil
+	}
+}
+
+// generateString produces a fake string matching the observed format and length
+func (n *schemaNode) generateString(rng *rand.Rand) string {
+	if n.isEnumLike() {
+		values := make([]string, 0, len(n.stringValues))
+		for value := range n.stringValues {
+			values = append(values, value)
+		}
+		sort.Strings(values)
+		return pickWeighted(rng, values, n.stringValues)
+	}
+
+	switch n.stringFormat() {
+	case "email":
+		return fmt.Sprintf("%s%d@example.com", syntheticWords[rng.Intn(len(syntheticWords))], rng.Intn(1000))
+	case "uuid":
+		b := make([]byte, 16)
+		rng.Read(b)
+		return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+	case "date-time":
+		start, end := n.minTime, n.maxTime
+		if start.IsZero() {
+			start, end = time.Now().Add(-24*time.Hour), time.Now()
+		}
+		span := end.Sub(start)
+		offset := time.Duration(0)
+		if span > 0 {
+			offset = time.Duration(rng.Int63n(int64(span)))
+		}
+		return start.Add(offset).UTC().Format(time.RFC3339)
+	case "date":
+		return time.Now().AddDate(0, 0, -rng.Intn(365)).Format("2006-01-02")
+	case "ipv4":
+		return fmt.Sprintf("10.%d.%d.%d", rng.Intn(256), rng.Intn(256), rng.Intn(256))
+	case "ipv6":
+		return fmt.Sprintf("fd00::%x:%x", rng.Intn(65536), rng.Intn(65536))
+	case "uri":
+		return fmt.Sprintf("https://example.com/%s/%d", syntheticWords[rng.Intn(len(syntheticWords))], rng.Intn(10000))
+	}
+
+	length := n.minLength
+	if n.maxLength > n.minLength {
+		length += rng.Intn(n.maxLength - n.minLength + 1)
+	}
+	var b strings.Builder
+	for b.Len() < length {
+		if b.Len() > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(syntheticWords[rng.Intn(len(syntheticWords))])
+	}
+	return b.String()[:length]
+}
+
+// generateSyntheticRecords produces n records shaped like the schema root
+func generateSyntheticRecords(root *schemaNode, n int, rng *rand.Rand) []map[string]interface{} {
+	result := make([]map[string]interface{}, 0, n)
+	for i := 0; i < n; i++ {
+		content, ok := root.generate(rng, 0).(map[string]interface{})
+		if !ok {
+			content = map[string]interface{}{}
+		}
+		result = append(result, content)
+	}
+	return result
+}
+
+// GenerateSyntheticSample writes n fake records matching the inferred schema of the
+// loaded file to a new JSONL file and returns its path
+func (a *App) GenerateSyntheticSample(n int) (string, error) {
+	a.stateMu.RLock()
+	defer a.stateMu.RUnlock()
+
+	if a.currentFile == nil || a.cache == nil {
+		return "", &JSONLError{
+			Message: "No file currently loaded",
+			Err:     ErrNoFileLoaded,
+		}
+	}
+
+	if n <= 0 {
+		n = 100
+	}
+	if n > maxSyntheticRecords {
+		n = maxSyntheticRecords
+	}
+
+	outputPath, err := a.exportFilePath("jsonl-viewer-synthetic", "jsonl")
+	if err != nil {
+		return "", err
+	}
+
+	root, err := inferSchemaNode(a.cache)
+	if err != nil {
+		return "", err
+	}
+
+	file, err := os.Create(outputPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to create synthetic sample file: %w", err)
+	}
+	defer file.Close()
+
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	for _, content := range generateSyntheticRecords(root, n, rng) {
+		jsonBytes, err := json.Marshal(content)
+		if err != nil {
+			return "", fmt.Errorf("failed to encode synthetic record: %w", err)
+		}
+		if _, err := file.Write(append(jsonBytes, '\n')); err != nil {
+			return "", fmt.Errorf("failed to write synthetic sample file: %w", err)
+		}
+	}
+
+	return outputPath, nil
+}
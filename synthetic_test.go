@@ -0,0 +1,86 @@
+package main
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestGenerateSyntheticRecords(t *testing.T) {
+	app := newTestApp(t, `{"id":10,"level":"info","email":"ann@corp.io","score":0.5,"tags":["x"],"meta":{"ok":true}}
+{"id":20,"level":"error","email":"bob@corp.io","score":1.5,"tags":["y","z"]}
+{"id":30,"level":"info","email":"cid@corp.io","score":1.0,"tags":[]}
+{"id":40,"level":"error","email":"dee@corp.io","score":0.75,"tags":["x"]}`)
+
+	rng := rand.New(rand.NewSource(1))
+	root, err := inferSchemaNode(app.cache)
+	if err != nil {
+		t.Fatalf("inferSchemaNode returned error: %v", err)
+	}
+	generated := generateSyntheticRecords(root, 200, rng)
+	if len(generated) != 200 {
+		t.Fatalf("Expected 200 records, got %d", len(generated))
+	}
+
+	sawMeta := false
+	for _, content := range generated {
+		for _, field := range []string{"id", "level", "email", "score", "tags"} {
+			if _, exists := content[field]; !exists {
+				t.Fatalf("Expected required field %q in %v", field, content)
+			}
+		}
+
+		id := content["id"].(float64)
+		if id < 10 || id > 40 || id != float64(int64(id)) {
+			t.Errorf("Expected integer id within observed range, got %v", id)
+		}
+		if level := content["level"]; level != "info" && level != "error" {
+			t.Errorf("Expected enum-like level to reuse observed values, got %v", level)
+		}
+		email := content["email"].(string)
+		if !strings.HasSuffix(email, "@example.com") {
+			t.Errorf("Expected fake email, got %v", email)
+		}
+		if len(content["tags"].([]interface{})) > 2 {
+			t.Errorf("Expected at most 2 tags, got %v", content["tags"])
+		}
+		if _, exists := content["meta"]; exists {
+			sawMeta = true
+		}
+	}
+
+	if !sawMeta {
+		t.Error("Expected optional field meta to appear in some records")
+	}
+}
+
+func TestNewRecordTemplate(t *testing.T) {
+	app := newTestApp(t, `{"id":1,"name":"a","user":{"active":true,"roles":["x"]},"note":null}
+{"id":2,"name":null,"user":{"active":false},"note":"n"}`)
+
+	template, err := app.NewRecordTemplate()
+	if err != nil {
+		t.Fatalf("NewRecordTemplate returned error: %v", err)
+	}
+
+	expected := `{
+  "id": 0,
+  "name": "",
+  "note": "",
+  "user": {
+    "active": false,
+    "roles": []
+  }
+}`
+	if template.RawJSON != expected {
+		t.Errorf("Expected template:\n%s\ngot:\n%s", expected, template.RawJSON)
+	}
+
+	expectedRequired := []string{"id", "name", "note", "user", "user.active"}
+	if strings.Join(template.RequiredFields, ",") != strings.Join(expectedRequired, ",") {
+		t.Errorf("Expected required fields %v, got %v", expectedRequired, template.RequiredFields)
+	}
+	if template.FieldTypes["user.roles"] != "array" {
+		t.Errorf("Expected user.roles to be an array, got %q", template.FieldTypes["user.roles"])
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"encoding/json"
+	"sort"
+)
+
+// RecordTemplate is a blank record containing every known field with a
+// type-appropriate zero value
+type RecordTemplate struct {
+	Content        map[string]interface{} `json:"content"`
+	RawJSON        string                 `json:"rawJSON"`
+	RequiredFields []string               `json:"requiredFields"` // dot-separated paths present in every record
+	FieldTypes     map[string]string      `json:"fieldTypes"`     // dominant type per path
+}
+
+// templateType picks the type used for a field's zero value, preferring the most
+// frequent non-null type
+func (n *schemaNode) templateType() string {
+	types := n.sortedTypes()
+	for _, t := range types {
+		if t != "null" {
+			return t
+		}
+	}
+	if len(types) > 0 {
+		return types[0]
+	}
+	return "null"
+}
+
+// buildTemplate fills content with zero values for the properties of an object node
+func (n *schemaNode) buildTemplate(prefix string, content map[string]interface{}, template *RecordTemplate) {
+	for _, key := range n.propertyOrder {
+		property := n.properties[key]
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+
+		valueType := property.templateType()
+		template.FieldTypes[path] = valueType
+		if n.required(key) {
+			template.RequiredFields = append(template.RequiredFields, path)
+		}
+
+		switch valueType {
+		case "object":
+			nested := make(map[string]interface{})
+			property.buildTemplate(path, nested, template)
+			content[key] = nested
+		case "array":
+			content[key] = []interface{}{}
+		case "string":
+			content[key] = ""
+		case "number":
+			content[key] = 0
+		case "boolean":
+			content[key] = false
+		default:
+			content[key] = nil
+		}
+	}
+}
+
+// NewRecordTemplate returns a blank record with every field seen in the loaded file,
+// flagging the fields present in all records as required
+func (a *App) NewRecordTemplate() (*RecordTemplate, error) {
+	a.stateMu.RLock()
+	defer a.stateMu.RUnlock()
+
+	if a.currentFile == nil || a.cache == nil {
+		return nil, &JSONLError{
+			Message: "No file currently loaded",
+			Err:     ErrNoFileLoaded,
+		}
+	}
+
+	template := &RecordTemplate{
+		Content:        make(map[string]interface{}),
+		RequiredFields: []string{},
+		FieldTypes:     make(map[string]string),
+	}
+
+	root, err := inferSchemaNode(a.cache)
+	if err != nil {
+		return nil, err
+	}
+	root.buildTemplate("", template.Content, template)
+	sort.Strings(template.RequiredFields)
+
+	jsonBytes, err := json.MarshalIndent(template.Content, "", "  ")
+	if err != nil {
+		return nil, &JSONLError{
+			Message: "Failed to encode record template",
+			Err:     err,
+		}
+	}
+	template.RawJSON = string(jsonBytes)
+
+	return template, nil
+}
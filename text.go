@@ -0,0 +1,116 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// matchOptions controls how text is compared while searching
+type matchOptions struct {
+	CaseSensitive    bool
+	IgnoreDiacritics bool
+	WholeWord        bool
+	StrictTypes      bool                     // compare boolean and number field values by value, see matchTyped
+	Coercions        map[string]FieldCoercion // per-field type overrides, see matchField
+}
+
+// matchOptionsFor extracts the text comparison options of a search
+func matchOptionsFor(options SearchOptions) matchOptions {
+	return matchOptions{
+		CaseSensitive:    options.CaseSensitive,
+		IgnoreDiacritics: options.IgnoreDiacritics,
+		WholeWord:        options.WholeWord,
+		StrictTypes:      options.StrictTypes,
+	}
+}
+
+// normalize prepares text for comparison according to the options. With diacritics
+// ignored, text is NFC-normalized with combining marks removed; case-insensitive
+// comparison folds case, see foldCase.
+func (o matchOptions) normalize(s string) string {
+	if o.IgnoreDiacritics {
+		s = stripDiacritics(s)
+	}
+	if !o.CaseSensitive {
+		s = foldCase(s)
+	}
+	return s
+}
+
+// foldCase applies full Unicode case folding, so "Σ", "σ" and final "ς" compare
+// equal and "Straße" matches "STRASSE". The dot above that folding leaves after the
+// i of a Turkish "İ" is dropped, so "İstanbul" matches "istanbul".
+func foldCase(s string) string {
+	if isASCII(s) {
+		return strings.ToLower(s)
+	}
+	return strings.ReplaceAll(cases.Fold().String(s), "i\u0307", "i")
+}
+
+// isASCII reports whether s holds only ASCII characters
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// contains reports whether normalized text contains the normalized term. With
+// WholeWord set, the term must not continue a word on either side, so "err" does
+// not match "transferred".
+func (o matchOptions) contains(text, term string) bool {
+	if !o.WholeWord || term == "" {
+		return strings.Contains(text, term)
+	}
+
+	for offset := 0; ; {
+		index := strings.Index(text[offset:], term)
+		if index < 0 {
+			return false
+		}
+		start, end := offset+index, offset+index+len(term)
+		if wordBoundary(text, start) && wordBoundary(text, end) {
+			return true
+		}
+		_, size := utf8.DecodeRuneInString(text[start:])
+		offset = start + size
+	}
+}
+
+// wordBoundary reports whether position i of s does not split a word
+func wordBoundary(s string, i int) bool {
+	if i == 0 || i == len(s) {
+		return true
+	}
+	before, _ := utf8.DecodeLastRuneInString(s[:i])
+	after, _ := utf8.DecodeRuneInString(s[i:])
+	return !isWordRune(before) || !isWordRune(after)
+}
+
+// isWordRune reports whether r is part of a word: a letter, digit or underscore
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
+
+// stripDiacritics removes combining marks, so "Müller" and "Müller" both become "Muller"
+func stripDiacritics(s string) string {
+	// Pure ASCII text has nothing to strip
+	if isASCII(s) {
+		return s
+	}
+
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	result, _, err := transform.String(t, s)
+	if err != nil {
+		return norm.NFC.String(s)
+	}
+	return result
+}
@@ -0,0 +1,110 @@
+package main
+
+import "testing"
+
+func TestMatchOptionsIgnoreDiacritics(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		term     string
+		opts     matchOptions
+		expected bool
+	}{
+		{"Plain query finds accented value", "Jürgen Müller", "Muller", matchOptions{IgnoreDiacritics: true}, true},
+		{"Accented query finds plain value", "cafe latte", "café", matchOptions{IgnoreDiacritics: true}, true},
+		{"Decomposed value matches composed query", "Müller", "Müller", matchOptions{IgnoreDiacritics: true}, true},
+		{"Non-ASCII case folding", "STRASSE", "straße", matchOptions{IgnoreDiacritics: true}, true},
+		{"Greek final sigma", "ΟΔΟΣ", "οδος", matchOptions{IgnoreDiacritics: true}, true},
+		{"Case sensitive keeps case", "Müller", "muller", matchOptions{CaseSensitive: true, IgnoreDiacritics: true}, false},
+		{"Diacritics matter by default", "Müller", "Muller", matchOptions{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := tt.opts.matchTerm(tt.text, tt.term); result != tt.expected {
+				t.Errorf("Expected %v, got %v for matchTerm(%q, %q) with %+v", tt.expected, result, tt.text, tt.term, tt.opts)
+			}
+		})
+	}
+}
+
+func TestMatchOptionsCaseFolding(t *testing.T) {
+	tests := []struct {
+		text     string
+		term     string
+		expected bool
+	}{
+		{"ΟΔΟΣ", "οδος", true},
+		{"οδός", "ΟΔΌΣ", true},
+		{"Straße", "STRASSE", true},
+		{"İstanbul", "istanbul", true},
+		{"ISTANBUL", "istanbul", true},
+		{"café", "CAFÉ", true},
+		{"café", "cafe", false},
+	}
+
+	for _, tt := range tests {
+		if result := (matchOptions{}).matchTerm(tt.text, tt.term); result != tt.expected {
+			t.Errorf("Expected %v for matchTerm(%q, %q), got %v", tt.expected, tt.text, tt.term, result)
+		}
+	}
+}
+
+func TestSearchRecordsIgnoreDiacritics(t *testing.T) {
+	app := newTestApp(t, `{"name":"Müller"}
+{"name":"Miller"}`)
+
+	result, err := app.SearchRecords(SearchOptions{Query: "name:muller", UseLucene: true, IgnoreDiacritics: true})
+	if err != nil {
+		t.Fatalf("SearchRecords returned error: %v", err)
+	}
+	if result.TotalMatches != 1 || result.Records[0].LineNumber != 1 {
+		t.Errorf("Expected only line 1 to match, got %+v", result.Records)
+	}
+
+	result, _ = app.SearchRecords(SearchOptions{Query: "MULLER", IgnoreDiacritics: true})
+	if result.TotalMatches != 1 {
+		t.Errorf("Expected a plain search to fold case and diacritics, got %+v", result.Records)
+	}
+}
+
+func TestMatchOptionsWholeWord(t *testing.T) {
+	opts := matchOptions{WholeWord: true}
+	tests := []struct {
+		text     string
+		term     string
+		expected bool
+	}{
+		{"bytes transferred", "err", false},
+		{"err: timeout", "err", true},
+		{"transferred err", "ERR", true},
+		{`{"level":"err"}`, "err", true},
+		{"error_code", "error", false},
+		{"Grüße aus Köln", "köln", true},
+		{"Kölner Dom", "köln", false},
+		{"retry-after", "-after", true},
+		{"errerr err", "err", true},
+	}
+	for _, tt := range tests {
+		if result := opts.matchTerm(tt.text, tt.term); result != tt.expected {
+			t.Errorf("Expected %v for matchTerm(%q, %q) with whole words", tt.expected, tt.text, tt.term)
+		}
+	}
+
+	app := newTestApp(t, `{"msg":"bytes transferred"}
+{"level":"err"}`)
+	for _, options := range []SearchOptions{
+		{Query: "err", WholeWord: true},
+		{Query: "err", WholeWord: true, SelectedFields: []string{"msg", "level"}},
+		{Query: "err", WholeWord: true, UseLucene: true},
+		{Query: "level:err OR msg:err", WholeWord: true, UseLucene: true},
+	} {
+		result, err := app.SearchRecords(options)
+		if err != nil {
+			t.Fatalf("SearchRecords returned error: %v", err)
+		}
+		if result.TotalMatches != 1 || result.Records[0].LineNumber != 2 {
+			t.Errorf("Expected only line 2 to match %+v, got %+v", options, result.Records)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// timestampLayouts are the string formats recognized as timestamps, tried in order
+var timestampLayouts = []string{
+	time.RFC3339Nano,
+	time.RFC3339,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	time.RFC1123Z,
+	time.RFC1123,
+}
+
+// timestampFieldNames are common names of timestamp fields, in order of preference
+var timestampFieldNames = []string{
+	"@timestamp", "timestamp", "time", "ts", "datetime", "date",
+	"created_at", "createdAt", "event_time", "eventTime", "logged_at",
+}
+
+// epochToTime converts a numeric epoch value to time, guessing the unit from its
+// magnitude (seconds, milliseconds, microseconds or nanoseconds)
+func epochToTime(epoch float64) time.Time {
+	abs := math.Abs(epoch)
+	switch {
+	case abs >= 1e17:
+		return time.Unix(0, int64(epoch)).UTC()
+	case abs >= 1e14:
+		return time.UnixMicro(int64(epoch)).UTC()
+	case abs >= 1e11:
+		return time.UnixMilli(int64(epoch)).UTC()
+	default:
+		seconds, fraction := math.Modf(epoch)
+		return time.Unix(int64(seconds), int64(fraction*1e9)).UTC()
+	}
+}
+
+// parseTimestampString parses a date/time string using the known layouts
+func parseTimestampString(str string) (time.Time, bool) {
+	for _, layout := range timestampLayouts {
+		if t, err := time.Parse(layout, str); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// parseTimestamp parses a field value as a point in time. Strings are parsed with
+// the common layouts above or as numeric epochs, numbers are treated as epochs.
+func parseTimestamp(value interface{}) (time.Time, bool) {
+	if number, ok := toFloat64(value); ok {
+		return epochToTime(number), true
+	}
+
+	str, ok := value.(string)
+	if !ok {
+		return time.Time{}, false
+	}
+	str = strings.TrimSpace(str)
+	if str == "" {
+		return time.Time{}, false
+	}
+
+	if t, ok := parseTimestampString(str); ok {
+		return t, true
+	}
+
+	if number, err := strconv.ParseFloat(str, 64); err == nil {
+		return epochToTime(number), true
+	}
+
+	return time.Time{}, false
+}
+
+// detectTimestampField picks the field most likely to hold record timestamps:
+// a well-known name if one parses, otherwise the first top-level field whose
+// values parse as timestamps in a sample of records
+func detectTimestampField(records []JSONRecord) string {
+	sampleSize := len(records)
+	if sampleSize > 100 {
+		sampleSize = 100
+	}
+	sample := records[:sampleSize]
+
+	parsesInSample := func(field string) bool {
+		parsed, present := 0, 0
+		for _, record := range sample {
+			value, exists := getFieldValue(record.Content, field)
+			if !exists {
+				continue
+			}
+			present++
+			// Epochs are only accepted for well-known names, since any numeric
+			// field would otherwise look like a timestamp
+			if str, isString := value.(string); isString {
+				if _, ok := parseTimestampString(strings.TrimSpace(str)); ok {
+					parsed++
+				}
+			}
+		}
+		return present > 0 && parsed*2 > present
+	}
+
+	for _, field := range timestampFieldNames {
+		present := 0
+		parsed := 0
+		for _, record := range sample {
+			if value, exists := record.Content[field]; exists {
+				present++
+				if _, ok := parseTimestamp(value); ok {
+					parsed++
+				}
+			}
+		}
+		if present > 0 && parsed*2 > present {
+			return field
+		}
+	}
+
+	if len(sample) == 0 {
+		return ""
+	}
+
+	var candidates []string
+	for field := range sample[0].Content {
+		candidates = append(candidates, field)
+	}
+	sort.Strings(candidates)
+	for _, field := range candidates {
+		if parsesInSample(field) {
+			return field
+		}
+	}
+
+	return ""
+}
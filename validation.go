@@ -0,0 +1,391 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// Validation rule types
+const (
+	RuleRequired = "required" // field must be present and not null
+	RuleRegex    = "regex"    // string value must match Pattern
+	RuleEnum     = "enum"     // value must be one of Values
+	RuleRange    = "range"    // numeric value must be within Min/Max
+	RuleCompare  = "compare"  // value must compare to OtherField using Operator
+)
+
+// ValidationRule describes a single constraint evaluated against every record.
+// When set, the rule only applies to records that satisfy the When condition.
+type ValidationRule struct {
+	Name       string          `json:"name"`
+	Type       string          `json:"type"` // 'required', 'regex', 'enum', 'range', 'compare'
+	Field      string          `json:"field"`
+	Pattern    string          `json:"pattern,omitempty"`
+	Values     []string        `json:"values,omitempty"`
+	Min        *float64        `json:"min,omitempty"`
+	Max        *float64        `json:"max,omitempty"`
+	Operator   string          `json:"operator,omitempty"` // '==', '!=', '<', '<=', '>', '>='
+	OtherField string          `json:"otherField,omitempty"`
+	When       *ValidationRule `json:"when,omitempty"`
+}
+
+// ValidationRulesFile is the on-disk format of a rules file
+type ValidationRulesFile struct {
+	Rules []ValidationRule `json:"rules"`
+}
+
+// RuleResult summarizes the violations of a single rule
+type RuleResult struct {
+	Name           string `json:"name"`
+	Type           string `json:"type"`
+	Field          string `json:"field"`
+	ViolationCount int    `json:"violationCount"`
+	LineNumbers    []int  `json:"lineNumbers"`
+}
+
+// ValidationReport is the result of evaluating all rules over the loaded records
+type ValidationReport struct {
+	Rules           []RuleResult `json:"rules"`
+	CheckedRecords  int          `json:"checkedRecords"`
+	InvalidRecords  int          `json:"invalidRecords"`
+	TotalViolations int          `json:"totalViolations"`
+}
+
+// compiledRule is a validated rule with its regular expressions compiled
+type compiledRule struct {
+	rule    ValidationRule
+	pattern *regexp.Regexp
+	when    *compiledRule
+}
+
+// ErrInvalidRule is returned when a validation rule is malformed
+var ErrInvalidRule = errors.New("invalid validation rule")
+
+// compileRule validates a rule definition and prepares it for evaluation
+func compileRule(rule ValidationRule) (*compiledRule, error) {
+	invalid := func(message string) error {
+		return &JSONLError{
+			Message: fmt.Sprintf("Rule %q: %s", rule.Name, message),
+			Err:     ErrInvalidRule,
+		}
+	}
+
+	if strings.TrimSpace(rule.Field) == "" {
+		return nil, invalid("field is required")
+	}
+
+	compiled := &compiledRule{rule: rule}
+
+	switch rule.Type {
+	case RuleRequired:
+	case RuleRegex:
+		pattern, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return nil, invalid(fmt.Sprintf("invalid pattern: %v", err))
+		}
+		compiled.pattern = pattern
+	case RuleEnum:
+		if len(rule.Values) == 0 {
+			return nil, invalid("enum rule needs at least one value")
+		}
+	case RuleRange:
+		if rule.Min == nil && rule.Max == nil {
+			return nil, invalid("range rule needs min or max")
+		}
+	case RuleCompare:
+		if rule.OtherField == "" {
+			return nil, invalid("compare rule needs otherField")
+		}
+		switch rule.Operator {
+		case "==", "!=", "<", "<=", ">", ">=":
+		default:
+			return nil, invalid(fmt.Sprintf("unknown operator %q", rule.Operator))
+		}
+	default:
+		return nil, invalid(fmt.Sprintf("unknown rule type %q", rule.Type))
+	}
+
+	if rule.When != nil {
+		when, err := compileRule(*rule.When)
+		if err != nil {
+			return nil, err
+		}
+		compiled.when = when
+	}
+
+	return compiled, nil
+}
+
+// compareValues compares two field values numerically when both are numbers,
+// otherwise by their string form
+func compareValues(left, right interface{}) int {
+	if cmp, ok := compareNumbers(left, right); ok {
+		return cmp
+	}
+	return strings.Compare(valueToString(left), valueToString(right))
+}
+
+// holds reports whether a record satisfies a condition: the field must be present
+// and the rule must not be violated
+func (c *compiledRule) holds(record JSONRecord) bool {
+	if _, exists := getFieldValue(record.Content, c.rule.Field); !exists {
+		return false
+	}
+	return !c.violatedBy(record)
+}
+
+// violatedBy reports whether a record violates the rule
+func (c *compiledRule) violatedBy(record JSONRecord) bool {
+	if c.when != nil && !c.when.holds(record) {
+		return false
+	}
+
+	value, exists := getFieldValue(record.Content, c.rule.Field)
+
+	switch c.rule.Type {
+	case RuleRequired:
+		return !exists || value == nil
+
+	case RuleRegex:
+		if !exists {
+			return false
+		}
+		return !c.pattern.MatchString(valueToString(value))
+
+	case RuleEnum:
+		if !exists {
+			return false
+		}
+		valueStr := valueToString(value)
+		for _, allowed := range c.rule.Values {
+			if valueStr == allowed {
+				return false
+			}
+		}
+		return true
+
+	case RuleRange:
+		if !exists {
+			return false
+		}
+		number, ok := toFloat64(value)
+		if !ok {
+			return true
+		}
+		if c.rule.Min != nil && number < *c.rule.Min {
+			return true
+		}
+		if c.rule.Max != nil && number > *c.rule.Max {
+			return true
+		}
+		return false
+
+	case RuleCompare:
+		other, otherExists := getFieldValue(record.Content, c.rule.OtherField)
+		if !exists || !otherExists {
+			return false
+		}
+		cmp := compareValues(value, other)
+		switch c.rule.Operator {
+		case "==":
+			return cmp != 0
+		case "!=":
+			return cmp == 0
+		case "<":
+			return cmp >= 0
+		case "<=":
+			return cmp > 0
+		case ">":
+			return cmp <= 0
+		case ">=":
+			return cmp < 0
+		}
+	}
+
+	return false
+}
+
+// SetValidationRules validates and stores the rules used by RunValidation
+func (a *App) SetValidationRules(rules []ValidationRule) error {
+	compiled := make([]*compiledRule, 0, len(rules))
+	for i, rule := range rules {
+		if rule.Name == "" {
+			rule.Name = fmt.Sprintf("rule-%d", i+1)
+		}
+		c, err := compileRule(rule)
+		if err != nil {
+			return err
+		}
+		compiled = append(compiled, c)
+	}
+
+	a.stateMu.Lock()
+	a.validationRules = compiled
+	a.validationReport = nil
+	a.stateMu.Unlock()
+	return nil
+}
+
+// GetValidationRules returns the currently configured validation rules
+func (a *App) GetValidationRules() []ValidationRule {
+	a.stateMu.RLock()
+	defer a.stateMu.RUnlock()
+
+	rules := make([]ValidationRule, 0, len(a.validationRules))
+	for _, c := range a.validationRules {
+		rules = append(rules, c.rule)
+	}
+	return rules
+}
+
+// LoadValidationRules reads a JSON rules file and stores its rules
+func (a *App) LoadValidationRules(filePath string) ([]ValidationRule, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, &JSONLError{
+			Message: "Failed to read rules file",
+			Err:     ErrFileNotFound,
+		}
+	}
+
+	var rulesFile ValidationRulesFile
+	if err := json.Unmarshal(data, &rulesFile); err != nil {
+		return nil, &JSONLError{
+			Message: "Rules file is not valid JSON",
+			Err:     ErrParsingFailed,
+		}
+	}
+
+	if err := a.SetValidationRules(rulesFile.Rules); err != nil {
+		return nil, err
+	}
+
+	return a.GetValidationRules(), nil
+}
+
+// RunValidation evaluates all configured rules over the loaded records
+func (a *App) RunValidation() (*ValidationReport, error) {
+	a.stateMu.Lock()
+	defer a.stateMu.Unlock()
+
+	if a.currentFile == nil || a.cache == nil {
+		return nil, &JSONLError{
+			Message: "No file currently loaded",
+			Err:     ErrNoFileLoaded,
+		}
+	}
+
+	report := &ValidationReport{
+		Rules:          make([]RuleResult, len(a.validationRules)),
+		CheckedRecords: a.cache.totalCount,
+	}
+	for i, c := range a.validationRules {
+		report.Rules[i] = RuleResult{
+			Name:        c.rule.Name,
+			Type:        c.rule.Type,
+			Field:       c.rule.Field,
+			LineNumbers: []int{},
+		}
+	}
+
+	err := a.cache.forEach(func(record JSONRecord) bool {
+		recordInvalid := false
+		for i, c := range a.validationRules {
+			if c.violatedBy(record) {
+				report.Rules[i].ViolationCount++
+				report.Rules[i].LineNumbers = append(report.Rules[i].LineNumbers, record.LineNumber)
+				report.TotalViolations++
+				recordInvalid = true
+			}
+		}
+		if recordInvalid {
+			report.InvalidRecords++
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	a.validationReport = report
+	return report, nil
+}
+
+// GetRuleViolations returns the records violating a rule from the last validation run,
+// paginated like search results
+func (a *App) GetRuleViolations(ruleName string, offset, limit int) (*SearchResult, error) {
+	a.stateMu.RLock()
+	defer a.stateMu.RUnlock()
+
+	if a.currentFile == nil || a.cache == nil {
+		return nil, &JSONLError{
+			Message: "No file currently loaded",
+			Err:     ErrNoFileLoaded,
+		}
+	}
+
+	if a.validationReport == nil {
+		return nil, &JSONLError{
+			Message: "Validation has not been run",
+			Err:     errors.New("no validation report"),
+		}
+	}
+
+	var lineNumbers []int
+	found := false
+	for _, result := range a.validationReport.Rules {
+		if result.Name == ruleName {
+			lineNumbers = result.LineNumbers
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, &JSONLError{
+			Message: fmt.Sprintf("Unknown validation rule %q", ruleName),
+			Err:     ErrInvalidRule,
+		}
+	}
+
+	// Normalize parameters
+	if offset < 0 {
+		offset = 0
+	}
+	if limit <= 0 {
+		limit = 50 // Default limit
+	}
+	if limit > 1000 {
+		limit = 1000 // Cap maximum limit
+	}
+
+	totalMatches := len(lineNumbers)
+	records := []JSONRecord{}
+	if offset < totalMatches {
+		endIndex := offset + limit
+		if endIndex > totalMatches {
+			endIndex = totalMatches
+		}
+		for _, lineNumber := range lineNumbers[offset:endIndex] {
+			record, err := a.recordByLineNumber(lineNumber)
+			if err != nil {
+				continue
+			}
+			records = append(records, *record)
+		}
+	}
+
+	return &SearchResult{
+		Records:      records,
+		Offset:       offset,
+		Limit:        limit,
+		Total:        a.cache.totalCount,
+		TotalMatches: totalMatches,
+		HasMore:      offset+limit < totalMatches,
+		Query:        ruleName,
+		Version:      a.cache.versionToken(),
+	}, nil
+}
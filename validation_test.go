@@ -0,0 +1,104 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestRunValidation(t *testing.T) {
+	app := newTestApp(t, `{"id":1,"status":"ok","code":200,"email":"a@example.com","start":1,"end":5}
+{"id":2,"status":"error","code":500,"email":"not-an-email","start":9,"end":3}
+{"status":"error","code":700,"error":"boom"}
+{"id":4,"status":"unknown","code":"n/a"}`)
+
+	min, max := 100.0, 599.0
+	rules := []ValidationRule{
+		{Name: "id required", Type: RuleRequired, Field: "id"},
+		{Name: "email format", Type: RuleRegex, Field: "email", Pattern: `^[^@]+@[^@]+$`},
+		{Name: "status values", Type: RuleEnum, Field: "status", Values: []string{"ok", "error"}},
+		{Name: "code range", Type: RuleRange, Field: "code", Min: &min, Max: &max},
+		{Name: "start before end", Type: RuleCompare, Field: "start", Operator: "<=", OtherField: "end"},
+		{
+			Name:  "errors have message",
+			Type:  RuleRequired,
+			Field: "error",
+			When:  &ValidationRule{Type: RuleEnum, Field: "status", Values: []string{"error"}},
+		},
+	}
+	if err := app.SetValidationRules(rules); err != nil {
+		t.Fatalf("SetValidationRules returned error: %v", err)
+	}
+
+	report, err := app.RunValidation()
+	if err != nil {
+		t.Fatalf("RunValidation returned error: %v", err)
+	}
+
+	expected := map[string][]int{
+		"id required":         {3},
+		"email format":        {2},
+		"status values":       {4},
+		"code range":          {3, 4},
+		"start before end":    {2},
+		"errors have message": {2},
+	}
+	for _, result := range report.Rules {
+		if !reflect.DeepEqual(result.LineNumbers, expected[result.Name]) {
+			t.Errorf("Rule %q: expected lines %v, got %v", result.Name, expected[result.Name], result.LineNumbers)
+		}
+	}
+	if report.InvalidRecords != 3 || report.TotalViolations != 7 {
+		t.Errorf("Unexpected totals: %+v", report)
+	}
+
+	violations, err := app.GetRuleViolations("code range", 0, 10)
+	if err != nil {
+		t.Fatalf("GetRuleViolations returned error: %v", err)
+	}
+	if violations.TotalMatches != 2 || len(violations.Records) != 2 || violations.Records[1].LineNumber != 4 {
+		t.Errorf("Unexpected violations page: %+v", violations)
+	}
+}
+
+func TestSetValidationRulesRejectsInvalidRules(t *testing.T) {
+	app := &App{}
+
+	tests := []struct {
+		name string
+		rule ValidationRule
+	}{
+		{"Missing field", ValidationRule{Type: RuleRequired}},
+		{"Unknown type", ValidationRule{Type: "unique", Field: "id"}},
+		{"Bad pattern", ValidationRule{Type: RuleRegex, Field: "id", Pattern: "("}},
+		{"Empty enum", ValidationRule{Type: RuleEnum, Field: "id"}},
+		{"Unbounded range", ValidationRule{Type: RuleRange, Field: "id"}},
+		{"Bad operator", ValidationRule{Type: RuleCompare, Field: "a", OtherField: "b", Operator: "~"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := app.SetValidationRules([]ValidationRule{tt.rule}); err == nil {
+				t.Errorf("Expected error for rule %+v", tt.rule)
+			}
+		})
+	}
+}
+
+func TestLoadValidationRules(t *testing.T) {
+	rulesPath := filepath.Join(t.TempDir(), "rules.json")
+	content := `{"rules":[{"type":"required","field":"id"},{"name":"level","type":"enum","field":"level","values":["info","warn"]}]}`
+	if err := os.WriteFile(rulesPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write rules file: %v", err)
+	}
+
+	app := &App{}
+	rules, err := app.LoadValidationRules(rulesPath)
+	if err != nil {
+		t.Fatalf("LoadValidationRules returned error: %v", err)
+	}
+	if len(rules) != 2 || rules[0].Name != "rule-1" || rules[1].Name != "level" {
+		t.Errorf("Unexpected rules: %+v", rules)
+	}
+}
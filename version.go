@@ -0,0 +1,68 @@
+package main
+
+import "fmt"
+
+// versionToken identifies the state of the cached records for pagination consistency
+func (c *RecordCache) versionToken() string {
+	return fmt.Sprintf("v%d", c.version)
+}
+
+// bumpDataVersion marks the loaded records as changed, e.g. after a reload, an
+// appended tail or an edit, and drops results derived from the previous state
+func (a *App) bumpDataVersion() {
+	a.dataVersion++
+	if a.cache == nil {
+		return
+	}
+
+	a.cache.version = a.dataVersion
+	a.cache.distinctValuesMu.Lock()
+	a.cache.distinctValues = nil
+	a.cache.exactValues = nil
+	a.cache.distinctValuesMu.Unlock()
+	a.cache.fieldStatsMu.Lock()
+	a.cache.fieldStats = nil
+	a.cache.fieldStatsMu.Unlock()
+	a.cache.pages.reset()
+	a.cache.searchIndex.Store(nil)
+}
+
+// checkVersion rejects requests carrying a version token issued for other data;
+// an empty token is always accepted
+func (a *App) checkVersion(token string) error {
+	if token == "" || a.cache == nil || token == a.cache.versionToken() {
+		return nil
+	}
+	return &JSONLError{
+		Message: fmt.Sprintf("Data changed since version %s was loaded (now %s)", token, a.cache.versionToken()),
+		Err:     ErrStaleVersion,
+	}
+}
+
+// GetDataVersion returns the version token of the loaded records
+func (a *App) GetDataVersion() (string, error) {
+	a.stateMu.RLock()
+	defer a.stateMu.RUnlock()
+
+	if a.currentFile == nil || a.cache == nil {
+		return "", &JSONLError{
+			Message: "No file currently loaded",
+			Err:     ErrNoFileLoaded,
+		}
+	}
+
+	return a.cache.versionToken(), nil
+}
+
+// GetRecordsAtVersion returns a page of records like GetRecords, but fails with
+// ErrStaleVersion when version no longer matches the loaded records so pages from
+// two different file states are never mixed
+func (a *App) GetRecordsAtVersion(offset, limit int, version string) (*PaginatedRecords, error) {
+	a.stateMu.RLock()
+	defer a.stateMu.RUnlock()
+
+	if err := a.checkVersion(version); err != nil {
+		return nil, err
+	}
+	return a.getRecords(offset, limit)
+}
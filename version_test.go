@@ -0,0 +1,47 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDataVersionTokens(t *testing.T) {
+	app := newTestApp(t, `{"msg":"a"}
+{"msg":"b"}`)
+	app.bumpDataVersion()
+
+	page, err := app.GetRecords(0, 1)
+	if err != nil {
+		t.Fatalf("GetRecords returned error: %v", err)
+	}
+	version := page.Version
+	if current, _ := app.GetDataVersion(); version == "" || version != current {
+		t.Fatalf("Expected page version to match %q, got %q", current, version)
+	}
+
+	if _, err := app.GetRecordsAtVersion(1, 1, version); err != nil {
+		t.Errorf("Expected current version to be accepted, got %v", err)
+	}
+	result, err := app.SearchRecords(SearchOptions{Query: "a", Version: version})
+	if err != nil || result.Version != version {
+		t.Errorf("Expected search at current version to succeed, got %+v, %v", result, err)
+	}
+
+	// Simulate the file changing underneath the UI, while neighbouring pages may
+	// still be prefetched
+	app.stateMu.Lock()
+	app.bumpDataVersion()
+	app.stateMu.Unlock()
+
+	_, err = app.GetRecordsAtVersion(1, 1, version)
+	var jsonlErr *JSONLError
+	if !errors.As(err, &jsonlErr) || !errors.Is(jsonlErr.Err, ErrStaleVersion) {
+		t.Errorf("Expected stale version error for records, got %v", err)
+	}
+	if _, err := app.SearchRecords(SearchOptions{Query: "a", Version: version}); err == nil {
+		t.Errorf("Expected stale version error for search")
+	}
+	if _, err := app.GetRecordsAtVersion(1, 1, ""); err != nil {
+		t.Errorf("Expected requests without a version to be accepted, got %v", err)
+	}
+}